@@ -7,6 +7,7 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/urfave/cli/v3"
 )
@@ -32,20 +33,7 @@ func main() {
 					deployment.StartApi()
 					return nil
 				},
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:        "mongo-url",
-						Usage:       "The mongo connection string",
-						Value:       "mongodb://localhost:27017/",
-						Destination: &internal.MONGO_URL,
-					},
-					&cli.StringFlag{
-						Name:        "db-name",
-						Aliases:     []string{"db"},
-						Usage:       "The mongo database name to use",
-						Value:       "db",
-						Destination: &internal.DB_NAME,
-					},
+				Flags: append(append(append(append(append(storageFlags(), passwordFlags()...), oidcFlags()...), mailFlags()...), rateLimitFlags()...),
 					&cli.StringFlag{
 						Name:        "api-ip",
 						Aliases:     []string{"ip"},
@@ -72,24 +60,154 @@ func main() {
 						Value:       false,
 						Destination: &internal.DEBUG,
 					},
-					&cli.StringFlag{
-						Name:        "data-directory",
-						Aliases:     []string{"data"},
-						Destination: &internal.DATA_DIRECTORY,
-						Required:    true,
-					},
 					&cli.StringFlag{
 						Name:        "jwt-key",
+						Usage:       "HS256 secret file, relative to the data directory, its key id is \"default\"",
 						Destination: &internal.JWT_KEY,
 						Value:       "security/jwt_key",
 					},
+					&cli.StringFlag{
+						Name:        "jwt-keys-directory",
+						Usage:       "Directory of JWT keys named after their key id: <kid>.key for HS256 secrets, <kid>.pem for RSA or Ed25519 keys",
+						Destination: &internal.JWT_KEYS_DIRECTORY,
+					},
+					&cli.StringFlag{
+						Name:        "jwt-signing-key",
+						Usage:       "Id of the key signing new tokens, other keys only verify tokens",
+						Destination: &internal.JWT_SIGNING_KEY_ID,
+						Value:       "default",
+					},
+					&cli.StringFlag{
+						Name:        "media-url-key",
+						Usage:       "Secret file signing media URLs, relative to the data directory, created when missing",
+						Destination: &internal.MEDIA_URL_KEY,
+						Value:       "security/media_url_key",
+					},
+					&cli.IntFlag{
+						Name:        "media-url-ttl",
+						Usage:       "Seconds a signed media URL stays valid at least, and is cached, it is valid up to twice as long",
+						Destination: &internal.MEDIA_URL_TTL,
+						Value:       3600,
+					},
+					&cli.StringFlag{
+						Name:        "public-url",
+						Usage:       "URL of the API as seen from outside, public album previews link to it",
+						Destination: &internal.PUBLIC_URL,
+						Value:       "http://localhost:8080",
+					},
 					&cli.IntFlag{
 						Name:        "compression-interval",
 						Aliases:     []string{"ci"},
 						Destination: &internal.COMPRESSION_TASK_PERIOD,
 						Value:       30,
 					},
+					&cli.BoolFlag{
+						Name:        "quota-include-renditions",
+						Usage:       "Count compressed renditions against user quotas, on top of originals",
+						Destination: &internal.QUOTA_INCLUDE_RENDITIONS,
+						Value:       false,
+					},
+					&cli.IntFlag{
+						Name:        "archive-after-days",
+						Usage:       "Archive originals uploaded more than this number of days ago (0 to disable)",
+						Destination: &internal.ARCHIVE_AFTER_DAYS,
+						Value:       0,
+					},
+					&cli.IntFlag{
+						Name:        "archive-idle-days",
+						Usage:       "Archive originals not downloaded for this number of days (0 to disable)",
+						Destination: &internal.ARCHIVE_IDLE_DAYS,
+						Value:       90,
+					},
+					&cli.BoolFlag{
+						Name:        "setup-endpoint",
+						Usage:       "Allow creating the first admin with POST /setup while there is no user",
+						Destination: &internal.SETUP_ENDPOINT,
+						Value:       true,
+					},
+					&cli.StringFlag{
+						Name:        "setup-token",
+						Usage:       "Token required by the setup endpoint, a random one is printed at startup when unset",
+						Sources:     cli.EnvVars("SETUP_TOKEN"),
+						Destination: &internal.SETUP_TOKEN,
+					},
+					&cli.IntFlag{
+						Name:        "tiering-interval",
+						Usage:       "Seconds between two runs of the tiering task",
+						Destination: &internal.TIERING_TASK_PERIOD,
+						Value:       300,
+					},
+				),
+			},
+			{
+				Name:  "user",
+				Usage: "Manage users",
+				Commands: []*cli.Command{
+					{
+						Name:  "create",
+						Usage: "Create a user, use --admin to create the first admin of a new deployment",
+						Action: func(ctx context.Context, c *cli.Command) error {
+							if internal.DEBUG {
+								slog.SetLogLoggerLevel(slog.LevelDebug)
+							} else {
+								slog.SetLogLoggerLevel(slog.LevelError)
+							}
+							return deployment.CreateUser(c.String("email"), c.String("password"), c.Bool("admin"))
+						},
+						Flags: append(append(storageFlags(), passwordFlags()...),
+							&cli.StringFlag{
+								Name:     "email",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "password",
+								Usage:   "Password of the user, a random one is generated and printed when unset",
+								Sources: cli.EnvVars("USER_PASSWORD"),
+							},
+							&cli.BoolFlag{
+								Name:  "admin",
+								Usage: "Create the user as an admin",
+								Value: false,
+							},
+							&cli.BoolFlag{
+								Name:        "debug",
+								Aliases:     []string{"d"},
+								Value:       false,
+								Destination: &internal.DEBUG,
+							},
+						),
+					},
+				},
+			},
+			{
+				Name:  "fsck",
+				Usage: "Check that stored files agree with the database, and optionally repair them",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if internal.DEBUG {
+						slog.SetLogLoggerLevel(slog.LevelDebug)
+					} else {
+						slog.SetLogLoggerLevel(slog.LevelError)
+					}
+					return deployment.RunFsck(c.Bool("repair"), time.Duration(c.Int("grace-hours"))*time.Hour)
 				},
+				Flags: append(storageFlags(),
+					&cli.BoolFlag{
+						Name:  "repair",
+						Usage: "Delete orphan files and dangling documents, queue missing renditions",
+						Value: false,
+					},
+					&cli.IntFlag{
+						Name:  "grace-hours",
+						Usage: "Ignore files younger than this, they may belong to uploads in progress",
+						Value: 24,
+					},
+					&cli.BoolFlag{
+						Name:        "debug",
+						Aliases:     []string{"d"},
+						Value:       false,
+						Destination: &internal.DEBUG,
+					},
+				),
 			},
 		},
 	}
@@ -98,3 +216,275 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// Flags of the OpenID Connect login
+func oidcFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "oidc-issuer",
+			Usage:       "Issuer URL of the OpenID Connect provider, leave empty to disable OpenID Connect login",
+			Destination: &internal.OIDC_ISSUER,
+		},
+		&cli.StringFlag{
+			Name:        "oidc-client-id",
+			Destination: &internal.OIDC_CLIENT_ID,
+		},
+		&cli.StringFlag{
+			Name:        "oidc-client-secret",
+			Sources:     cli.EnvVars("OIDC_CLIENT_SECRET"),
+			Destination: &internal.OIDC_CLIENT_SECRET,
+		},
+		&cli.StringFlag{
+			Name:        "oidc-redirect-url",
+			Usage:       "URL of the /user/oidc/callback endpoint, as registered at the provider",
+			Destination: &internal.OIDC_REDIRECT_URL,
+		},
+		&cli.StringSliceFlag{
+			Name:        "oidc-scopes",
+			Destination: &internal.OIDC_SCOPES,
+			Value:       []string{"openid", "email", "profile"},
+		},
+		&cli.BoolFlag{
+			Name:        "oidc-provisioning",
+			Usage:       "Create users logging in with OpenID Connect for the first time",
+			Destination: &internal.OIDC_PROVISIONING,
+			Value:       false,
+		},
+		&cli.StringFlag{
+			Name:        "oidc-groups-claim",
+			Destination: &internal.OIDC_GROUPS_CLAIM,
+			Value:       "groups",
+		},
+		&cli.StringFlag{
+			Name:        "oidc-admin-group",
+			Usage:       "Members of this group are admins, others are not (leave empty to manage admins locally)",
+			Destination: &internal.OIDC_ADMIN_GROUP,
+		},
+		&cli.StringFlag{
+			Name:        "oidc-post-login-url",
+			Usage:       "Where users are redirected after logging in with OpenID Connect",
+			Destination: &internal.OIDC_POST_LOGIN_URL,
+			Value:       "/",
+		},
+	}
+}
+
+// Flags of the password hashing and policy
+func passwordFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "password-hash",
+			Usage:       "Algorithm hashing new passwords: bcrypt or argon2id, existing hashes are upgraded when users log in",
+			Destination: &internal.PASSWORD_HASH,
+			Value:       "bcrypt",
+		},
+		&cli.IntFlag{
+			Name:        "bcrypt-cost",
+			Destination: &internal.BCRYPT_COST,
+			Value:       14,
+		},
+		&cli.IntFlag{
+			Name:        "argon2-memory",
+			Usage:       "Memory used by argon2id, in KiB",
+			Destination: &internal.ARGON2_MEMORY,
+			Value:       64 * 1024,
+		},
+		&cli.IntFlag{
+			Name:        "argon2-iterations",
+			Destination: &internal.ARGON2_ITERATIONS,
+			Value:       3,
+		},
+		&cli.IntFlag{
+			Name:        "argon2-parallelism",
+			Destination: &internal.ARGON2_PARALLELISM,
+			Value:       4,
+		},
+		&cli.IntFlag{
+			Name:        "password-min-length",
+			Destination: &internal.PASSWORD_MIN_LENGTH,
+			Value:       8,
+		},
+		&cli.BoolFlag{
+			Name:        "password-forbid-email",
+			Usage:       "Reject passwords containing the user's email address",
+			Destination: &internal.PASSWORD_FORBID_EMAIL,
+			Value:       true,
+		},
+		&cli.StringFlag{
+			Name:        "password-breach-list",
+			Usage:       "File of leaked passwords (or their SHA-1 hashes) to reject, one per line",
+			Destination: &internal.PASSWORD_BREACH_LIST,
+		},
+	}
+}
+
+// Flags of the protections against guessing passwords and tokens
+func rateLimitFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "trusted-proxies",
+			Usage:       "Reverse proxies allowed to give the client IP in X-Forwarded-For, rate limits apply per client IP",
+			Destination: &internal.TRUSTED_PROXIES,
+			Value:       []string{"127.0.0.1", "::1"},
+		},
+		&cli.IntFlag{
+			Name:        "login-ip-rate-limit",
+			Usage:       "Login, password reset and invitation requests allowed per minute and per IP (0 to disable)",
+			Destination: &internal.LOGIN_IP_RATE_LIMIT,
+			Value:       20,
+		},
+		&cli.IntFlag{
+			Name:        "login-account-rate-limit",
+			Usage:       "Login attempts allowed per minute and per account (0 to disable)",
+			Destination: &internal.LOGIN_ACCOUNT_RATE_LIMIT,
+			Value:       10,
+		},
+		&cli.IntFlag{
+			Name:        "shared-link-rate-limit",
+			Usage:       "Requests with an unknown shared link token allowed per minute and per IP (0 to disable)",
+			Destination: &internal.SHARED_LINK_RATE_LIMIT,
+			Value:       10,
+		},
+		&cli.IntFlag{
+			Name:        "lockout-threshold",
+			Usage:       "Failed logins after which an account is locked (0 to disable)",
+			Destination: &internal.LOCKOUT_THRESHOLD,
+			Value:       5,
+		},
+		&cli.IntFlag{
+			Name:        "lockout-duration",
+			Usage:       "Seconds an account is locked for, doubled on each further failure",
+			Destination: &internal.LOCKOUT_DURATION,
+			Value:       60,
+		},
+		&cli.IntFlag{
+			Name:        "lockout-max-duration",
+			Usage:       "Maximum seconds an account is locked for",
+			Destination: &internal.LOCKOUT_MAX_DURATION,
+			Value:       3600,
+		},
+	}
+}
+
+// Flags of the emails sent to users (invitations, password resets)
+func mailFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "app-url",
+			Usage:       "URL of the application, links in the emails point to it",
+			Destination: &internal.APP_URL,
+			Value:       "http://localhost:3000",
+		},
+		&cli.StringFlag{
+			Name:        "mailer",
+			Usage:       "How emails are sent, either log (written to --mail-directory, or logged) or smtp",
+			Destination: &internal.MAILER,
+			Value:       "log",
+		},
+		&cli.StringFlag{
+			Name:        "mail-directory",
+			Usage:       "Directory where the log mailer writes emails, leave empty to only log them",
+			Destination: &internal.MAIL_DIRECTORY,
+		},
+		&cli.StringFlag{
+			Name:        "mail-from",
+			Usage:       "Sender address of the emails",
+			Destination: &internal.MAIL_FROM,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-host",
+			Destination: &internal.SMTP_HOST,
+		},
+		&cli.IntFlag{
+			Name:        "smtp-port",
+			Destination: &internal.SMTP_PORT,
+			Value:       587,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-username",
+			Destination: &internal.SMTP_USERNAME,
+		},
+		&cli.StringFlag{
+			Name:        "smtp-password",
+			Sources:     cli.EnvVars("SMTP_PASSWORD"),
+			Destination: &internal.SMTP_PASSWORD,
+		},
+	}
+}
+
+// Flags needed by every command accessing the database and the stored files
+func storageFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "mongo-url",
+			Usage:       "The mongo connection string",
+			Value:       "mongodb://localhost:27017/",
+			Destination: &internal.MONGO_URL,
+		},
+		&cli.StringFlag{
+			Name:        "db-name",
+			Aliases:     []string{"db"},
+			Usage:       "The mongo database name to use",
+			Value:       "db",
+			Destination: &internal.DB_NAME,
+		},
+		&cli.StringFlag{
+			Name:        "data-directory",
+			Aliases:     []string{"data"},
+			Destination: &internal.DATA_DIRECTORY,
+			Required:    true,
+		},
+		&cli.StringFlag{
+			Name:        "storage",
+			Usage:       "Where media files are stored: local (data directory) or s3",
+			Value:       "local",
+			Destination: &internal.STORAGE_BACKEND,
+		},
+		&cli.StringFlag{
+			Name:        "s3-endpoint",
+			Usage:       "The S3 compatible endpoint (host:port)",
+			Destination: &internal.S3_ENDPOINT,
+		},
+		&cli.StringFlag{
+			Name:        "s3-bucket",
+			Usage:       "The bucket holding the media files",
+			Value:       "data-storage",
+			Destination: &internal.S3_BUCKET,
+		},
+		&cli.StringFlag{
+			Name:        "s3-access-key",
+			Sources:     cli.EnvVars("S3_ACCESS_KEY"),
+			Destination: &internal.S3_ACCESS_KEY,
+		},
+		&cli.StringFlag{
+			Name:        "s3-secret-key",
+			Sources:     cli.EnvVars("S3_SECRET_KEY"),
+			Destination: &internal.S3_SECRET_KEY,
+		},
+		&cli.StringFlag{
+			Name:        "s3-region",
+			Value:       "us-east-1",
+			Destination: &internal.S3_REGION,
+		},
+		&cli.BoolFlag{
+			Name:        "s3-ssl",
+			Usage:       "Use HTTPS to reach the S3 endpoint",
+			Value:       true,
+			Destination: &internal.S3_USE_SSL,
+		},
+		&cli.StringFlag{
+			Name:        "archive",
+			Usage:       "Where rarely accessed originals are moved: local (archive directory) or s3 (archive bucket), disabled if empty",
+			Destination: &internal.ARCHIVE_BACKEND,
+		},
+		&cli.StringFlag{
+			Name:        "archive-directory",
+			Destination: &internal.ARCHIVE_DIRECTORY,
+		},
+		&cli.StringFlag{
+			Name:        "archive-s3-bucket",
+			Value:       "data-storage-archive",
+			Destination: &internal.ARCHIVE_S3_BUCKET,
+		},
+	}
+}
@@ -3,4 +3,7 @@ package common
 const (
 	ORIGINAL_MEDIA_DIRECTORY = "originalMedias"
 	COMPRESSED_DIRECTORY     = "compressedMedias"
+	DOWNLOAD_DIRECTORY       = "downloads"
+	// Local directory where chunked uploads are staged until complete
+	UPLOAD_DIRECTORY = "uploads"
 )
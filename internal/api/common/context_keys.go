@@ -3,4 +3,8 @@ package common
 const (
 	USER        = "user"
 	SHARED_LINK = "shared_link"
+	// The API key used to authenticate the user (if any)
+	API_KEY = "api_key"
+	// The session of the access token used to authenticate the user (if any)
+	SESSION = "session"
 )
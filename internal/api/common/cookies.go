@@ -0,0 +1,19 @@
+package common
+
+import (
+	"data-storage-svc/internal"
+	"data-storage-svc/internal/api/security"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cookie holding the session of a shared link visit, one per link so visitors can open several links
+func LinkSessionCookie(sharedLinkId primitive.ObjectID) string {
+	return "link_session_" + sharedLinkId.Hex()
+}
+
+// Start a visit of a shared link, the following requests of the visitor do not count as uses
+func SetLinkSessionCookie(c *gin.Context, sharedLinkId primitive.ObjectID, sessionToken string) {
+	c.SetCookie(LinkSessionCookie(sharedLinkId), sessionToken, int(security.LINK_SESSION_DURATION.Seconds()), "/", internal.API_DOMAIN, !internal.DEBUG, true)
+}
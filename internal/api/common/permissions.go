@@ -8,38 +8,66 @@ import (
 )
 
 type PermissionsManager interface {
+	// Effective role of the user in the album, granted to them or to one of their groups, empty without any
+	GetAlbumRole(user *model.User, albumId *primitive.ObjectID) model.AlbumRole
 	CanListUsers(user *model.User) bool
 	CanCreateUser(user *model.User) bool
+	CanInviteUser(user *model.User, albumId *primitive.ObjectID) bool
+	CanCheckStorage(user *model.User) bool
+	CanManageQuotas(user *model.User) bool
+	CanManageUser(user *model.User, userId *primitive.ObjectID) bool
+	CanManageApiKeys(user *model.User, apiKey *model.ApiKey) bool
+	CanManageSessions(user *model.User, apiKey *model.ApiKey) bool
+	CanChangePassword(user *model.User, apiKey *model.ApiKey) bool
+	CanEditProfile(user *model.User, apiKey *model.ApiKey) bool
+	CanManageTwoFactor(user *model.User, apiKey *model.ApiKey) bool
 	CanCreateAlbum(user *model.User) bool
 	CanGetAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool
 	CanGetAllMediasForAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool
-	CanEditMediasInAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool
+	CanAddMediaToAlbum(user *model.User, albumId *primitive.ObjectID, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool
+	CanRemoveMediaFromAlbum(user *model.User, albumId *primitive.ObjectID, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool
 	CanDeleteAlbum(user *model.User, albumId *primitive.ObjectID) bool
 	CanListAlbumAccesses(user *model.User, albumId *primitive.ObjectID) bool
 	CanEditAlbumAccesses(user *model.User, albumId *primitive.ObjectID) bool
-	CanInitDownloadForAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool
+	CanEditAlbumVisibility(user *model.User, albumId *primitive.ObjectID) bool
+	CanShareAlbumWithGroup(user *model.User, albumId *primitive.ObjectID, groupId *primitive.ObjectID) bool
+	CanInitDownloadForAlbum(user *model.User, albumId *primitive.ObjectID, renditions bool, sharedLink *model.SharedLink) bool
 	CanConsumeDownload(user *model.User, downloadId *primitive.ObjectID, sharedLink *model.SharedLink) bool
 	CanGetDownload(user *model.User, downloadId *primitive.ObjectID, sharedLink *model.SharedLink) bool
 	CanCreateMedia(user *model.User, sharedLink *model.SharedLink) bool
 	CanGetMedia(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool
+	CanGetOriginal(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool
+	CanGetMetaData(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool
 	CanDeleteMedia(user *model.User, mediaId *primitive.ObjectID) bool
+	CanShareMedia(user *model.User, mediaId *primitive.ObjectID) bool
+	CanRevokeMediaAccess(user *model.User, mediaId *primitive.ObjectID, userId *primitive.ObjectID) bool
 	CanCreateSharedLink(user *model.User, albumId *primitive.ObjectID) bool
 	CanListSharedLinks(user *model.User, albumId *primitive.ObjectID) bool
 	CanDeleteSharedLink(user *model.User, sharedLink *model.SharedLink) bool
 	CanUpdateSharedLink(user *model.User, sharedLink *model.SharedLink) bool
+	CanCreateGroup(user *model.User) bool
+	CanGetGroup(user *model.User, groupId *primitive.ObjectID) bool
+	CanEditGroup(user *model.User, groupId *primitive.ObjectID) bool
+	CanRemoveGroupMember(user *model.User, groupId *primitive.ObjectID, memberId *primitive.ObjectID) bool
+	CanProposeAlbumTransfer(user *model.User, albumId *primitive.ObjectID) bool
+	CanListAlbumTransfers(user *model.User, albumId *primitive.ObjectID) bool
+	CanGetTransfer(user *model.User, transfer *model.OwnershipTransfer) bool
+	CanAnswerTransfer(user *model.User, transfer *model.OwnershipTransfer) bool
+	CanCancelTransfer(user *model.User, transfer *model.OwnershipTransfer) bool
 }
 
 type permissionsManager struct {
 	albumAccessRepository  repository.AlbumAccessRepository
 	albumRepository        repository.AlbumRepository
 	downloadRepository     repository.DownloadRepository
+	groupRepository        repository.GroupRepository
 	mediaAccessRepository  repository.MediaAccessRepository
 	mediaInAblumRepository repository.MediaInAlbumRepository
 	mediaRepository        repository.MediaRepository
 }
 
-func NewPermissionsManager(albumAccessRepository repository.AlbumAccessRepository, albumRepository repository.AlbumRepository, downloadRepository repository.DownloadRepository, mediaAccessRepository repository.MediaAccessRepository, mediaInAblumRepository repository.MediaInAlbumRepository, mediaRepository repository.MediaRepository) PermissionsManager {
-	return permissionsManager{albumAccessRepository: albumAccessRepository, albumRepository: albumRepository, downloadRepository: downloadRepository, mediaAccessRepository: mediaAccessRepository, mediaInAblumRepository: mediaInAblumRepository, mediaRepository: mediaRepository}
+func NewPermissionsManager(albumAccessRepository repository.AlbumAccessRepository, albumRepository repository.AlbumRepository, downloadRepository repository.DownloadRepository, groupRepository repository.GroupRepository, mediaAccessRepository repository.MediaAccessRepository, mediaInAblumRepository repository.MediaInAlbumRepository, mediaRepository repository.MediaRepository) PermissionsManager {
+	return permissionsManager{albumAccessRepository: albumAccessRepository, albumRepository: albumRepository, downloadRepository: downloadRepository, groupRepository: groupRepository, mediaAccessRepository: mediaAccessRepository, mediaInAblumRepository: mediaInAblumRepository, mediaRepository: mediaRepository}
 }
 
 func (p permissionsManager) CanListUsers(user *model.User) bool {
@@ -50,21 +78,98 @@ func (p permissionsManager) CanCreateUser(user *model.User) bool {
 	return user != nil && user.IsAdmin
 }
 
+// Admins invite anyone, album owners invite people to their album
+func (p permissionsManager) CanInviteUser(user *model.User, albumId *primitive.ObjectID) bool {
+	if albumId == nil {
+		return user != nil && user.IsAdmin
+	}
+	return p.CanEditAlbumAccesses(user, albumId)
+}
+
+func (p permissionsManager) CanCheckStorage(user *model.User) bool {
+	return user != nil && user.IsAdmin
+}
+
+func (p permissionsManager) CanManageQuotas(user *model.User) bool {
+	return user != nil && user.IsAdmin
+}
+
+// Admins cannot disable, demote or delete themselves, so there is always an admin left
+func (p permissionsManager) CanManageUser(user *model.User, userId *primitive.ObjectID) bool {
+	return user != nil && user.IsAdmin && user.Id != *userId
+}
+
+// API keys cannot be used to create or revoke other API keys
+func (p permissionsManager) CanManageApiKeys(user *model.User, apiKey *model.ApiKey) bool {
+	return user != nil && apiKey == nil
+}
+
+// Sessions and password are managed by the user themselves, not by scripts
+func (p permissionsManager) CanManageSessions(user *model.User, apiKey *model.ApiKey) bool {
+	return user != nil && apiKey == nil
+}
+
+func (p permissionsManager) CanChangePassword(user *model.User, apiKey *model.ApiKey) bool {
+	return user != nil && apiKey == nil
+}
+
+func (p permissionsManager) CanEditProfile(user *model.User, apiKey *model.ApiKey) bool {
+	return user != nil && apiKey == nil
+}
+
+func (p permissionsManager) CanManageTwoFactor(user *model.User, apiKey *model.ApiKey) bool {
+	return user != nil && apiKey == nil
+}
+
 func (p permissionsManager) CanCreateAlbum(user *model.User) bool {
 	return user != nil
 }
 
+// Links with any scope see the album itself, so visitors of a drop box link know where they upload
 func (p permissionsManager) CanGetAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	return p.getAlbumAccessOrNil(user, albumId) != nil || (sharedLink != nil && sharedLink.AlbumId.Hex() == albumId.Hex())
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_VIEWER) || (sharedLink != nil && sharedLink.AlbumId.Hex() == albumId.Hex())
 }
 
 func (p permissionsManager) CanGetAllMediasForAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	return p.CanGetAlbum(user, albumId, sharedLink)
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_VIEWER) || linkAllows(sharedLink, albumId, model.SHARED_LINK_SCOPE_VIEW)
 }
 
-func (p permissionsManager) CanEditMediasInAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	access := p.getAlbumAccessOrNil(user, albumId)
-	return (access != nil && access.CanEdit) || (sharedLink != nil && sharedLink.AlbumId.Hex() == albumId.Hex() && sharedLink.CanEdit)
+// Contributors add their own medias, and links uploading medias add the medias uploaded through a link of the same
+// creator. Adding any other media needs the editor role or the add existing scope. Without media, checks if some media
+// can be added.
+func (p permissionsManager) CanAddMediaToAlbum(user *model.User, albumId *primitive.ObjectID, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	role := p.GetAlbumRole(user, albumId)
+	if role.AtLeast(model.ALBUM_ROLE_EDITOR) || linkAllows(sharedLink, albumId, model.SHARED_LINK_SCOPE_ADD_EXISTING) {
+		return true
+	}
+	if role.AtLeast(model.ALBUM_ROLE_CONTRIBUTOR) {
+		return mediaId == nil || p.isMediaAuthor(user, mediaId)
+	}
+	if !linkAllows(sharedLink, albumId, model.SHARED_LINK_SCOPE_UPLOAD) {
+		return false
+	}
+	if mediaId == nil {
+		return true
+	}
+	media, _ := p.mediaRepository.Get(mediaId)
+	return media != nil && media.UploadedViaSharedLink && media.ChargedTo != nil && media.ChargedTo.Hex() == sharedLink.CreatedBy.Hex()
+}
+
+// Contributors remove the medias they added, removing any media needs the editor role or the remove scope. Without
+// media, checks if some media can be removed.
+func (p permissionsManager) CanRemoveMediaFromAlbum(user *model.User, albumId *primitive.ObjectID, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	role := p.GetAlbumRole(user, albumId)
+	if role.AtLeast(model.ALBUM_ROLE_EDITOR) || linkAllows(sharedLink, albumId, model.SHARED_LINK_SCOPE_REMOVE) {
+		return true
+	}
+	if !role.AtLeast(model.ALBUM_ROLE_CONTRIBUTOR) {
+		return false
+	}
+	if mediaId == nil {
+		return true
+	}
+	mediaInAlbum, _ := p.mediaInAblumRepository.Get(mediaId, albumId)
+	return mediaInAlbum != nil && !mediaInAlbum.AddedBySharedLink && mediaInAlbum.AddedBy != nil && mediaInAlbum.AddedBy.Hex() == user.Id.Hex()
 }
 
 func (p permissionsManager) CanDeleteAlbum(user *model.User, albumId *primitive.ObjectID) bool {
@@ -72,15 +177,54 @@ func (p permissionsManager) CanDeleteAlbum(user *model.User, albumId *primitive.
 }
 
 func (p permissionsManager) CanListAlbumAccesses(user *model.User, albumId *primitive.ObjectID) bool {
-	return p.isAlbumAuthor(user, albumId)
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER)
 }
 
 func (p permissionsManager) CanEditAlbumAccesses(user *model.User, albumId *primitive.ObjectID) bool {
-	return p.isAlbumAuthor(user, albumId)
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER)
+}
+
+func (p permissionsManager) CanEditAlbumVisibility(user *model.User, albumId *primitive.ObjectID) bool {
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER)
 }
 
-func (p permissionsManager) CanInitDownloadForAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	return p.CanGetAlbum(user, albumId, sharedLink)
+// Albums are shared with the groups the user is a member of, other groups are not theirs to fill
+func (p permissionsManager) CanShareAlbumWithGroup(user *model.User, albumId *primitive.ObjectID, groupId *primitive.ObjectID) bool {
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER) && p.isGroupMember(user, groupId)
+}
+
+// The owner gives the album away, admins give the albums of people who left
+func (p permissionsManager) CanProposeAlbumTransfer(user *model.User, albumId *primitive.ObjectID) bool {
+	return (user != nil && user.IsAdmin && p.getAlbum(albumId) != nil) || p.isAlbumAuthor(user, albumId)
+}
+
+func (p permissionsManager) CanListAlbumTransfers(user *model.User, albumId *primitive.ObjectID) bool {
+	return (user != nil && user.IsAdmin && p.getAlbum(albumId) != nil) || p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER)
+}
+
+// The users involved in a transfer see it
+func (p permissionsManager) CanGetTransfer(user *model.User, transfer *model.OwnershipTransfer) bool {
+	return user != nil && transfer != nil && (user.IsAdmin || user.Id == transfer.FromUserId || user.Id == transfer.ToUserId || user.Id == transfer.ProposedBy)
+}
+
+// Only the recipient accepts or declines a transfer
+func (p permissionsManager) CanAnswerTransfer(user *model.User, transfer *model.OwnershipTransfer) bool {
+	return user != nil && transfer != nil && user.Id == transfer.ToUserId
+}
+
+func (p permissionsManager) CanCancelTransfer(user *model.User, transfer *model.OwnershipTransfer) bool {
+	return user != nil && transfer != nil && (user.IsAdmin || user.Id == transfer.FromUserId || user.Id == transfer.ProposedBy)
+}
+
+// Archives of the originals need the download originals scope, archives of the renditions either download scope
+func (p permissionsManager) CanInitDownloadForAlbum(user *model.User, albumId *primitive.ObjectID, renditions bool, sharedLink *model.SharedLink) bool {
+	if p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_VIEWER) {
+		return true
+	}
+	if renditions {
+		return linkAllows(sharedLink, albumId, model.SHARED_LINK_SCOPE_DOWNLOAD_RENDITIONS, model.SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS)
+	}
+	return linkAllows(sharedLink, albumId, model.SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS)
 }
 
 func (p permissionsManager) CanConsumeDownload(user *model.User, downloadId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
@@ -92,54 +236,135 @@ func (p permissionsManager) CanGetDownload(user *model.User, downloadId *primiti
 }
 
 func (p permissionsManager) CanCreateMedia(user *model.User, sharedLink *model.SharedLink) bool {
-	return user != nil || (sharedLink != nil && sharedLink.CanEdit)
+	return user != nil || (sharedLink != nil && sharedLink.HasScope(model.SHARED_LINK_SCOPE_UPLOAD))
 }
 
+// Get the rendition of a media
 func (p permissionsManager) CanGetMedia(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	if p.isMediaAuthor(user, mediaId) {
-		// If user owns this media, directly grant acccess
-		return true
+	return p.canUserGetMedia(user, mediaId) ||
+		p.linkAllowsMedia(sharedLink, mediaId, model.SHARED_LINK_SCOPE_VIEW, model.SHARED_LINK_SCOPE_DOWNLOAD_RENDITIONS, model.SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS)
+}
 
-	} else if sharedLink != nil && p.mediaInAblumRepository.IsInAlbum(mediaId, &sharedLink.AlbumId) {
-		// Otherwise check if user is accessing this media via a shared link
-		return true
-	} else {
-		// Finally check if this media belongs to an album that user is allowed to view
-		userAlbums, err := p.albumAccessRepository.GetAllByUser(&user.Id)
-		isInUsersAlbums := false
-		if err == nil {
-			for _, album := range userAlbums {
-				if p.mediaInAblumRepository.IsInAlbum(mediaId, album.AlbumId) {
-					isInUsersAlbums = true
-					break
-				}
-			}
-		}
-		return isInUsersAlbums
-	}
+func (p permissionsManager) CanGetOriginal(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	return p.canUserGetMedia(user, mediaId) || p.linkAllowsMedia(sharedLink, mediaId, model.SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS)
+}
+
+func (p permissionsManager) CanGetMetaData(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	return p.canUserGetMedia(user, mediaId) || p.linkAllowsMedia(sharedLink, mediaId, model.SHARED_LINK_SCOPE_VIEW_METADATA)
 }
 
 func (p permissionsManager) CanDeleteMedia(user *model.User, mediaId *primitive.ObjectID) bool {
 	return p.isMediaAuthor(user, mediaId)
 }
 
+// The uploader shares a media with other users, and lists who it is shared with
+func (p permissionsManager) CanShareMedia(user *model.User, mediaId *primitive.ObjectID) bool {
+	return p.isMediaAuthor(user, mediaId)
+}
+
+// The uploader revokes accesses, users give up the medias shared with them
+func (p permissionsManager) CanRevokeMediaAccess(user *model.User, mediaId *primitive.ObjectID, userId *primitive.ObjectID) bool {
+	return p.isMediaAuthor(user, mediaId) || (user != nil && userId != nil && user.Id == *userId)
+}
+
 func (p permissionsManager) CanCreateSharedLink(user *model.User, albumId *primitive.ObjectID) bool {
-	return p.isAlbumAuthor(user, albumId)
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER)
 }
 
 func (p permissionsManager) CanListSharedLinks(user *model.User, albumId *primitive.ObjectID) bool {
-	return p.isAlbumAuthor(user, albumId)
+	return p.hasAlbumRole(user, albumId, model.ALBUM_ROLE_CO_OWNER)
 }
 
+// Links are managed by their creator, and by the co-owners of their album
 func (p permissionsManager) CanDeleteSharedLink(user *model.User, sharedLink *model.SharedLink) bool {
-	return user != nil && sharedLink != nil && sharedLink.CreatedBy.Hex() == user.Id.Hex()
+	return user != nil && sharedLink != nil && (sharedLink.CreatedBy.Hex() == user.Id.Hex() || p.hasAlbumRole(user, &sharedLink.AlbumId, model.ALBUM_ROLE_CO_OWNER))
 }
 
 func (p permissionsManager) CanUpdateSharedLink(user *model.User, sharedLink *model.SharedLink) bool {
-	return user != nil && sharedLink != nil && sharedLink.CreatedBy.Hex() == user.Id.Hex()
+	return p.CanDeleteSharedLink(user, sharedLink)
+}
+
+func (p permissionsManager) CanCreateGroup(user *model.User) bool {
+	return user != nil
+}
+
+func (p permissionsManager) CanGetGroup(user *model.User, groupId *primitive.ObjectID) bool {
+	return p.isGroupMember(user, groupId)
+}
+
+func (p permissionsManager) CanEditGroup(user *model.User, groupId *primitive.ObjectID) bool {
+	group := p.getGroup(groupId)
+	return user != nil && group != nil && group.OwnerId == user.Id
+}
+
+// The owner removes anyone, members leave by removing themselves
+func (p permissionsManager) CanRemoveGroupMember(user *model.User, groupId *primitive.ObjectID, memberId *primitive.ObjectID) bool {
+	return p.CanEditGroup(user, groupId) || (memberId != nil && user != nil && *memberId == user.Id && p.isGroupMember(user, groupId))
+}
+
+// The author owns the album, other users have the highest role granted to them or their groups
+func (p permissionsManager) GetAlbumRole(user *model.User, albumId *primitive.ObjectID) model.AlbumRole {
+	if user == nil || albumId == nil {
+		return ""
+	}
+	if p.isAlbumAuthor(user, albumId) {
+		return model.ALBUM_ROLE_OWNER
+	}
+	access := p.getAlbumAccessOrNil(user, albumId)
+	if access == nil {
+		return ""
+	}
+	return access.Role
 }
 
 // Utility private methods
+func (p permissionsManager) hasAlbumRole(user *model.User, albumId *primitive.ObjectID, role model.AlbumRole) bool {
+	return p.GetAlbumRole(user, albumId).AtLeast(role)
+}
+
+func (p permissionsManager) canUserGetMedia(user *model.User, mediaId *primitive.ObjectID) bool {
+	if user == nil {
+		return false
+	}
+	if p.isMediaAuthor(user, mediaId) {
+		// If user owns this media, directly grant acccess
+		return true
+	}
+	// The media may have been shared with the user directly
+	if p.getMediaAccessOrNil(user, mediaId) != nil {
+		return true
+	}
+	// Otherwise check if this media belongs to an album that user is allowed to view
+	userAlbums, err := p.albumAccessRepository.GetAllEffectiveByUser(&user.Id)
+	if err != nil {
+		return false
+	}
+	for _, album := range userAlbums {
+		if p.mediaInAblumRepository.IsInAlbum(mediaId, album.AlbumId) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if a media is in the album of a link having one of the scopes
+func (p permissionsManager) linkAllowsMedia(sharedLink *model.SharedLink, mediaId *primitive.ObjectID, scopes ...model.SharedLinkScope) bool {
+	return sharedLink != nil && linkAllows(sharedLink, &sharedLink.AlbumId, scopes...) && p.mediaInAblumRepository.IsInAlbum(mediaId, &sharedLink.AlbumId)
+}
+
+// Check if a link is for the album and has one of the scopes
+func linkAllows(sharedLink *model.SharedLink, albumId *primitive.ObjectID, scopes ...model.SharedLinkScope) bool {
+	if sharedLink == nil || albumId == nil || sharedLink.AlbumId.Hex() != albumId.Hex() {
+		return false
+	}
+	for _, scope := range scopes {
+		if sharedLink.HasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p permissionsManager) isMediaAuthor(user *model.User, mediaId *primitive.ObjectID) bool {
 	media := p.getMediaOrNil(user, mediaId)
 	return media != nil && media.UploadedBy.Hex() == user.Id.Hex()
@@ -165,7 +390,7 @@ func (p permissionsManager) getAlbumAccessOrNil(user *model.User, albumId *primi
 	if user == nil || albumId == nil {
 		return nil
 	}
-	userAlbumAccess, _ := p.albumAccessRepository.Get(&user.Id, albumId)
+	userAlbumAccess, _ := p.albumAccessRepository.GetEffective(&user.Id, albumId)
 	return userAlbumAccess
 }
 
@@ -185,6 +410,19 @@ func (p permissionsManager) getDownload(downloadId *primitive.ObjectID) *model.D
 	return download
 }
 
+func (p permissionsManager) getGroup(groupId *primitive.ObjectID) *model.Group {
+	if groupId == nil {
+		return nil
+	}
+	group, _ := p.groupRepository.Get(groupId)
+	return group
+}
+
+func (p permissionsManager) isGroupMember(user *model.User, groupId *primitive.ObjectID) bool {
+	group := p.getGroup(groupId)
+	return user != nil && group != nil && group.HasMember(user.Id)
+}
+
 func (p permissionsManager) isAlbumAuthor(user *model.User, albumId *primitive.ObjectID) bool {
 	album := p.getAlbum(albumId)
 	return user != nil && album != nil && user.Id.Hex() == album.AuthorId.Hex()
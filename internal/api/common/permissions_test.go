@@ -0,0 +1,212 @@
+package common_test
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSharedLinkScopes(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	otherAlbumId := primitive.NewObjectID()
+	creatorId := primitive.NewObjectID()
+	inAlbumId := primitive.NewObjectID()
+	uploadedId := primitive.NewObjectID()
+	foreignId := primitive.NewObjectID()
+
+	mediaInAlbumRepository := &mocks.MediaInAlbumRepository{}
+	mediaInAlbumRepository.On("IsInAlbum", &inAlbumId, &albumId).Return(true)
+	mediaRepository := &mocks.MediaRepository{}
+	mediaRepository.On("Get", &uploadedId).Return(&model.Media{Id: uploadedId, UploadedViaSharedLink: true, ChargedTo: &creatorId}, nil)
+	mediaRepository.On("Get", &foreignId).Return(&model.Media{Id: foreignId, UploadedBy: &creatorId, ChargedTo: &creatorId}, nil)
+	permissionsManager := common.NewPermissionsManager(nil, nil, nil, nil, nil, mediaInAlbumRepository, mediaRepository)
+
+	dropBox := &model.SharedLink{AlbumId: albumId, CreatedBy: creatorId, Scopes: []model.SharedLinkScope{model.SHARED_LINK_SCOPE_UPLOAD}}
+	viewOnly := &model.SharedLink{AlbumId: albumId, CreatedBy: creatorId, Scopes: []model.SharedLinkScope{model.SHARED_LINK_SCOPE_VIEW}}
+
+	// Drop box links see the album, upload and add their uploads, nothing else
+	assert.True(t, permissionsManager.CanGetAlbum(nil, &albumId, dropBox))
+	assert.False(t, permissionsManager.CanGetAllMediasForAlbum(nil, &albumId, dropBox))
+	assert.True(t, permissionsManager.CanCreateMedia(nil, dropBox))
+	assert.True(t, permissionsManager.CanAddMediaToAlbum(nil, &albumId, &uploadedId, dropBox))
+	assert.False(t, permissionsManager.CanAddMediaToAlbum(nil, &albumId, &foreignId, dropBox))
+	assert.False(t, permissionsManager.CanAddMediaToAlbum(nil, &otherAlbumId, &uploadedId, dropBox))
+	assert.False(t, permissionsManager.CanRemoveMediaFromAlbum(nil, &albumId, &uploadedId, dropBox))
+	assert.False(t, permissionsManager.CanGetMedia(nil, &inAlbumId, dropBox))
+	assert.False(t, permissionsManager.CanInitDownloadForAlbum(nil, &albumId, true, dropBox))
+
+	// View only links browse renditions, without originals nor location
+	assert.True(t, permissionsManager.CanGetAllMediasForAlbum(nil, &albumId, viewOnly))
+	assert.True(t, permissionsManager.CanGetMedia(nil, &inAlbumId, viewOnly))
+	assert.False(t, permissionsManager.CanGetOriginal(nil, &inAlbumId, viewOnly))
+	assert.False(t, permissionsManager.CanGetMetaData(nil, &inAlbumId, viewOnly))
+	assert.False(t, permissionsManager.CanInitDownloadForAlbum(nil, &albumId, false, viewOnly))
+	assert.False(t, permissionsManager.CanInitDownloadForAlbum(nil, &albumId, true, viewOnly))
+	assert.False(t, permissionsManager.CanCreateMedia(nil, viewOnly))
+	assert.False(t, permissionsManager.CanAddMediaToAlbum(nil, &albumId, &uploadedId, viewOnly))
+
+	// Renditions can be downloaded without the originals
+	renditions := &model.SharedLink{AlbumId: albumId, Scopes: []model.SharedLinkScope{model.SHARED_LINK_SCOPE_DOWNLOAD_RENDITIONS}}
+	assert.True(t, permissionsManager.CanInitDownloadForAlbum(nil, &albumId, true, renditions))
+	assert.False(t, permissionsManager.CanInitDownloadForAlbum(nil, &albumId, false, renditions))
+	assert.True(t, permissionsManager.CanGetMedia(nil, &inAlbumId, renditions))
+	assert.False(t, permissionsManager.CanGetOriginal(nil, &inAlbumId, renditions))
+}
+
+func TestGroupPermissions(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	groupId := primitive.NewObjectID()
+	owner := &model.User{Id: primitive.NewObjectID()}
+	member := &model.User{Id: primitive.NewObjectID()}
+	stranger := &model.User{Id: primitive.NewObjectID()}
+
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &member.Id}, nil)
+	groupRepository := &mocks.GroupRepository{}
+	groupRepository.On("Get", &groupId).Return(&model.Group{Id: groupId, OwnerId: owner.Id, MemberIds: []primitive.ObjectID{owner.Id, member.Id}}, nil)
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	albumAccessRepository.On("GetEffective", mock.Anything, &albumId).Return(nil, mongo.ErrNoDocuments)
+	permissionsManager := common.NewPermissionsManager(albumAccessRepository, albumRepository, nil, groupRepository, nil, nil, nil)
+
+	// Members see the group, the owner manages it
+	assert.True(t, permissionsManager.CanGetGroup(member, &groupId))
+	assert.False(t, permissionsManager.CanGetGroup(stranger, &groupId))
+	assert.True(t, permissionsManager.CanEditGroup(owner, &groupId))
+	assert.False(t, permissionsManager.CanEditGroup(member, &groupId))
+
+	// Members leave, only the owner removes others
+	assert.True(t, permissionsManager.CanRemoveGroupMember(member, &groupId, &member.Id))
+	assert.True(t, permissionsManager.CanRemoveGroupMember(owner, &groupId, &member.Id))
+	assert.False(t, permissionsManager.CanRemoveGroupMember(member, &groupId, &owner.Id))
+	assert.False(t, permissionsManager.CanRemoveGroupMember(stranger, &groupId, &stranger.Id))
+
+	// Album authors share with their own groups only
+	assert.True(t, permissionsManager.CanShareAlbumWithGroup(member, &albumId, &groupId))
+	assert.False(t, permissionsManager.CanShareAlbumWithGroup(owner, &albumId, &groupId))
+	otherGroupId := primitive.NewObjectID()
+	groupRepository.On("Get", &otherGroupId).Return(&model.Group{Id: otherGroupId, OwnerId: stranger.Id, MemberIds: []primitive.ObjectID{stranger.Id}}, nil)
+	assert.False(t, permissionsManager.CanShareAlbumWithGroup(member, &albumId, &otherGroupId))
+}
+
+func TestAlbumRoles(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	owner := &model.User{Id: primitive.NewObjectID()}
+	users := map[model.AlbumRole]*model.User{}
+	for _, role := range []model.AlbumRole{model.ALBUM_ROLE_VIEWER, model.ALBUM_ROLE_CONTRIBUTOR, model.ALBUM_ROLE_EDITOR, model.ALBUM_ROLE_CO_OWNER} {
+		users[role] = &model.User{Id: primitive.NewObjectID()}
+	}
+	stranger := &model.User{Id: primitive.NewObjectID()}
+	contributor := users[model.ALBUM_ROLE_CONTRIBUTOR]
+	ownMediaId := primitive.NewObjectID()
+	otherMediaId := primitive.NewObjectID()
+
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &owner.Id}, nil)
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	for role, user := range users {
+		albumAccessRepository.On("GetEffective", &user.Id, &albumId).Return(&model.UserAlbumAccess{UserId: &user.Id, AlbumId: &albumId, Role: role}, nil)
+	}
+	albumAccessRepository.On("GetEffective", &stranger.Id, &albumId).Return(nil, mongo.ErrNoDocuments)
+	mediaRepository := &mocks.MediaRepository{}
+	mediaRepository.On("Get", &ownMediaId).Return(&model.Media{Id: ownMediaId, UploadedBy: &contributor.Id}, nil)
+	mediaRepository.On("Get", &otherMediaId).Return(&model.Media{Id: otherMediaId, UploadedBy: &owner.Id}, nil)
+	mediaInAlbumRepository := &mocks.MediaInAlbumRepository{}
+	mediaInAlbumRepository.On("Get", &ownMediaId, &albumId).Return(&model.MediaInAlbum{MediaId: &ownMediaId, AlbumId: &albumId, AddedBy: &contributor.Id}, nil)
+	mediaInAlbumRepository.On("Get", &otherMediaId, &albumId).Return(&model.MediaInAlbum{MediaId: &otherMediaId, AlbumId: &albumId, AddedBy: &owner.Id}, nil)
+	permissionsManager := common.NewPermissionsManager(albumAccessRepository, albumRepository, nil, nil, nil, mediaInAlbumRepository, mediaRepository)
+
+	// The author owns the album whatever their access
+	assert.Equal(t, model.ALBUM_ROLE_OWNER, permissionsManager.GetAlbumRole(owner, &albumId))
+	assert.Equal(t, model.ALBUM_ROLE_EDITOR, permissionsManager.GetAlbumRole(users[model.ALBUM_ROLE_EDITOR], &albumId))
+	assert.Equal(t, model.AlbumRole(""), permissionsManager.GetAlbumRole(stranger, &albumId))
+
+	// Viewers see the album
+	assert.True(t, permissionsManager.CanGetAllMediasForAlbum(users[model.ALBUM_ROLE_VIEWER], &albumId, nil))
+	assert.True(t, permissionsManager.CanInitDownloadForAlbum(users[model.ALBUM_ROLE_VIEWER], &albumId, false, nil))
+	assert.False(t, permissionsManager.CanAddMediaToAlbum(users[model.ALBUM_ROLE_VIEWER], &albumId, nil, nil))
+	assert.False(t, permissionsManager.CanGetAlbum(stranger, &albumId, nil))
+
+	// Contributors add their medias, and remove what they added
+	assert.True(t, permissionsManager.CanAddMediaToAlbum(contributor, &albumId, &ownMediaId, nil))
+	assert.False(t, permissionsManager.CanAddMediaToAlbum(contributor, &albumId, &otherMediaId, nil))
+	assert.True(t, permissionsManager.CanRemoveMediaFromAlbum(contributor, &albumId, &ownMediaId, nil))
+	assert.False(t, permissionsManager.CanRemoveMediaFromAlbum(contributor, &albumId, &otherMediaId, nil))
+
+	// Editors manage all medias, not the sharing
+	assert.True(t, permissionsManager.CanAddMediaToAlbum(users[model.ALBUM_ROLE_EDITOR], &albumId, &otherMediaId, nil))
+	assert.True(t, permissionsManager.CanRemoveMediaFromAlbum(users[model.ALBUM_ROLE_EDITOR], &albumId, &otherMediaId, nil))
+	assert.False(t, permissionsManager.CanEditAlbumAccesses(users[model.ALBUM_ROLE_EDITOR], &albumId))
+	assert.False(t, permissionsManager.CanCreateSharedLink(users[model.ALBUM_ROLE_EDITOR], &albumId))
+
+	// Co-owners manage the sharing and the links of others, only the owner deletes the album
+	coOwner := users[model.ALBUM_ROLE_CO_OWNER]
+	assert.True(t, permissionsManager.CanEditAlbumAccesses(coOwner, &albumId))
+	assert.True(t, permissionsManager.CanCreateSharedLink(coOwner, &albumId))
+	assert.True(t, permissionsManager.CanUpdateSharedLink(coOwner, &model.SharedLink{AlbumId: albumId, CreatedBy: owner.Id}))
+	assert.False(t, permissionsManager.CanUpdateSharedLink(users[model.ALBUM_ROLE_EDITOR], &model.SharedLink{AlbumId: albumId, CreatedBy: owner.Id}))
+	assert.False(t, permissionsManager.CanDeleteAlbum(coOwner, &albumId))
+	assert.True(t, permissionsManager.CanDeleteAlbum(owner, &albumId))
+}
+
+func TestTransferPermissions(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	owner := &model.User{Id: primitive.NewObjectID()}
+	admin := &model.User{Id: primitive.NewObjectID(), IsAdmin: true}
+	recipient := &model.User{Id: primitive.NewObjectID()}
+	stranger := &model.User{Id: primitive.NewObjectID()}
+
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &owner.Id}, nil)
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	albumAccessRepository.On("GetEffective", mock.Anything, &albumId).Return(nil, mongo.ErrNoDocuments)
+	permissionsManager := common.NewPermissionsManager(albumAccessRepository, albumRepository, nil, nil, nil, nil, nil)
+
+	// The owner or an admin proposes
+	assert.True(t, permissionsManager.CanProposeAlbumTransfer(owner, &albumId))
+	assert.True(t, permissionsManager.CanProposeAlbumTransfer(admin, &albumId))
+	assert.False(t, permissionsManager.CanProposeAlbumTransfer(stranger, &albumId))
+
+	// Only the recipient answers, the proposer cancels
+	transfer := &model.OwnershipTransfer{AlbumId: albumId, FromUserId: owner.Id, ToUserId: recipient.Id, ProposedBy: admin.Id}
+	assert.True(t, permissionsManager.CanAnswerTransfer(recipient, transfer))
+	assert.False(t, permissionsManager.CanAnswerTransfer(owner, transfer))
+	assert.True(t, permissionsManager.CanCancelTransfer(owner, transfer))
+	assert.True(t, permissionsManager.CanCancelTransfer(admin, transfer))
+	assert.False(t, permissionsManager.CanCancelTransfer(recipient, transfer))
+	assert.True(t, permissionsManager.CanGetTransfer(recipient, transfer))
+	assert.False(t, permissionsManager.CanGetTransfer(stranger, transfer))
+}
+
+func TestMediaSharingPermissions(t *testing.T) {
+	mediaId := primitive.NewObjectID()
+	uploader := &model.User{Id: primitive.NewObjectID()}
+	friend := &model.User{Id: primitive.NewObjectID()}
+	stranger := &model.User{Id: primitive.NewObjectID()}
+
+	mediaRepository := &mocks.MediaRepository{}
+	mediaRepository.On("Get", &mediaId).Return(&model.Media{Id: mediaId, UploadedBy: &uploader.Id}, nil)
+	mediaAccessRepository := &mocks.MediaAccessRepository{}
+	mediaAccessRepository.On("Get", &friend.Id, &mediaId).Return(&model.UserMediaAccess{UserId: &friend.Id, MediaId: &mediaId}, nil)
+	mediaAccessRepository.On("Get", &stranger.Id, &mediaId).Return(nil, mongo.ErrNoDocuments)
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	albumAccessRepository.On("GetAllEffectiveByUser", &stranger.Id).Return([]model.UserAlbumAccess{}, nil)
+	permissionsManager := common.NewPermissionsManager(albumAccessRepository, nil, nil, nil, mediaAccessRepository, nil, mediaRepository)
+
+	// A media shared directly is seen without any album
+	assert.True(t, permissionsManager.CanGetMedia(friend, &mediaId, nil))
+	assert.True(t, permissionsManager.CanGetOriginal(friend, &mediaId, nil))
+	assert.False(t, permissionsManager.CanGetMedia(stranger, &mediaId, nil))
+
+	// Only the uploader shares, users give up what is shared with them
+	assert.True(t, permissionsManager.CanShareMedia(uploader, &mediaId))
+	assert.False(t, permissionsManager.CanShareMedia(friend, &mediaId))
+	assert.True(t, permissionsManager.CanRevokeMediaAccess(uploader, &mediaId, &friend.Id))
+	assert.True(t, permissionsManager.CanRevokeMediaAccess(friend, &mediaId, &friend.Id))
+	assert.False(t, permissionsManager.CanRevokeMediaAccess(stranger, &mediaId, &friend.Id))
+}
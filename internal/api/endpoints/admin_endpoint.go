@@ -0,0 +1,316 @@
+package endpoints
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AdminEndpoint interface {
+	common.EndpointGroup
+	// Check storage consistency (GET), or check and repair it (POST)
+	Fsck(c *gin.Context)
+	// Get the quota applying to users without a specific quota
+	GetDefaultQuota(c *gin.Context)
+	// Set the quota applying to users without a specific quota
+	SetDefaultQuota(c *gin.Context)
+	// Get the storage used by a user and their quota
+	GetUserUsage(c *gin.Context)
+	// Set a user specific quota
+	SetUserQuota(c *gin.Context)
+	// Remove a user specific quota, the default quota applies again
+	ResetUserQuota(c *gin.Context)
+	// Disable, enable, promote or demote a user
+	UpdateUser(c *gin.Context)
+	// Set a new password for a user, a generated one is returned if none is given
+	ResetUserPassword(c *gin.Context)
+	// Delete a user, their albums and medias are transferred to another user or purged
+	DeleteUser(c *gin.Context)
+}
+type adminEndpoint struct {
+	common.EndpointGroup
+	fsckService           services.FsckService
+	quotaService          services.QuotaService
+	userManagementService services.UserManagementService
+}
+
+func NewAdminEndpoint(
+	// Common dependencies
+	commonMiddlewares []gin.HandlerFunc,
+	permissionsManager common.PermissionsManager,
+	// Service dependencies
+	fsckService services.FsckService,
+	quotaService services.QuotaService,
+	userManagementService services.UserManagementService,
+) AdminEndpoint {
+	adminEndpoint := adminEndpoint{fsckService: fsckService, quotaService: quotaService, userManagementService: userManagementService}
+
+	endpoint := common.NewEndpoint(
+		"Admin",
+		"/admin",
+		commonMiddlewares,
+		map[common.MethodPath][]gin.HandlerFunc{
+			{Method: "GET", Path: "/fsck"}:                   {adminEndpoint.Fsck},
+			{Method: "POST", Path: "/fsck"}:                  {adminEndpoint.Fsck},
+			{Method: "GET", Path: "/quota"}:                  {adminEndpoint.GetDefaultQuota},
+			{Method: "PUT", Path: "/quota"}:                  {adminEndpoint.SetDefaultQuota},
+			{Method: "GET", Path: "/user/:userId/usage"}:     {middlewares.PathParamIdMiddleware("userId"), adminEndpoint.GetUserUsage},
+			{Method: "PUT", Path: "/user/:userId/quota"}:     {middlewares.PathParamIdMiddleware("userId"), adminEndpoint.SetUserQuota},
+			{Method: "DELETE", Path: "/user/:userId/quota"}:  {middlewares.PathParamIdMiddleware("userId"), adminEndpoint.ResetUserQuota},
+			{Method: "PATCH", Path: "/user/:userId"}:         {middlewares.PathParamIdMiddleware("userId"), adminEndpoint.UpdateUser},
+			{Method: "POST", Path: "/user/:userId/password"}: {middlewares.PathParamIdMiddleware("userId"), adminEndpoint.ResetUserPassword},
+			{Method: "DELETE", Path: "/user/:userId"}:        {middlewares.PathParamIdMiddleware("userId"), adminEndpoint.DeleteUser},
+		},
+		permissionsManager,
+	)
+
+	adminEndpoint.EndpointGroup = endpoint
+	return &adminEndpoint
+}
+
+func (e *adminEndpoint) Fsck(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanCheckStorage(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// Files younger than the grace period may belong to uploads in progress
+	graceHours, err := strconv.Atoi(c.DefaultQuery("graceHours", "24"))
+	if err != nil || graceHours < 0 {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	report, svcErr := e.fsckService.Run(c.Request.Method == "POST", time.Duration(graceHours)*time.Hour)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, report)
+}
+
+func (e *adminEndpoint) GetDefaultQuota(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageQuotas(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	quota, svcErr := e.quotaService.GetDefaultQuota()
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, quota)
+}
+
+func (e *adminEndpoint) SetDefaultQuota(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageQuotas(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var quota model.StorageQuota
+	if err := c.BindJSON(&quota); err != nil {
+		return
+	}
+
+	if svcErr := e.quotaService.SetDefaultQuota(quota); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (e *adminEndpoint) GetUserUsage(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	userId := utils.GetIdFromContext("userId", c)
+
+	if !e.GetPermissionsManager().CanManageQuotas(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	usage, svcErr := e.quotaService.GetUsage(&userId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, usage)
+}
+
+func (e *adminEndpoint) SetUserQuota(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	userId := utils.GetIdFromContext("userId", c)
+
+	if !e.GetPermissionsManager().CanManageQuotas(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var quota model.StorageQuota
+	if err := c.BindJSON(&quota); err != nil {
+		return
+	}
+
+	if svcErr := e.quotaService.SetUserQuota(&userId, &quota); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (e *adminEndpoint) ResetUserQuota(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	userId := utils.GetIdFromContext("userId", c)
+
+	if !e.GetPermissionsManager().CanManageQuotas(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if svcErr := e.quotaService.SetUserQuota(&userId, nil); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+type UpdateUserBody struct {
+	// Fields left out are not changed
+	Disabled *bool `json:"disabled"`
+	IsAdmin  *bool `json:"isAdmin"`
+}
+
+func (e *adminEndpoint) UpdateUser(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	userId := utils.GetIdFromContext("userId", c)
+
+	if !e.GetPermissionsManager().CanManageUser(user, &userId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var updateUserBody UpdateUserBody
+	if err := c.BindJSON(&updateUserBody); err != nil {
+		return
+	}
+
+	if updateUserBody.Disabled != nil {
+		if svcErr := e.userManagementService.SetDisabled(&userId, *updateUserBody.Disabled); svcErr != nil {
+			svcErr.Apply(c)
+			return
+		}
+	}
+	if updateUserBody.IsAdmin != nil {
+		if svcErr := e.userManagementService.SetAdmin(&userId, *updateUserBody.IsAdmin); svcErr != nil {
+			svcErr.Apply(c)
+			return
+		}
+	}
+	c.Status(http.StatusOK)
+}
+
+type ResetUserPasswordBody struct {
+	// Optional, a random password is generated otherwise
+	Password string `json:"password"`
+}
+
+func (e *adminEndpoint) ResetUserPassword(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	userId := utils.GetIdFromContext("userId", c)
+
+	if !e.GetPermissionsManager().CanManageUser(user, &userId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var resetUserPasswordBody ResetUserPasswordBody
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&resetUserPasswordBody); err != nil {
+			return
+		}
+	}
+
+	password, svcErr := e.userManagementService.ResetPassword(&userId, resetUserPasswordBody.Password)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	if resetUserPasswordBody.Password != "" {
+		c.Status(http.StatusOK)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"password": password})
+}
+
+func (e *adminEndpoint) DeleteUser(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	userId := utils.GetIdFromContext("userId", c)
+
+	if !e.GetPermissionsManager().CanManageUser(user, &userId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// Deleting data cannot be undone, the caller must ask for it explicitly
+	var transferTo *primitive.ObjectID
+	if rawTransferTo := c.Query("transferTo"); rawTransferTo != "" {
+		id, err := primitive.ObjectIDFromHex(rawTransferTo)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid transferTo user id"})
+			return
+		}
+		transferTo = &id
+	} else if c.Query("purge") != "true" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "either transferTo or purge=true is required"})
+		return
+	}
+
+	if svcErr := e.userManagementService.Delete(&userId, transferTo); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
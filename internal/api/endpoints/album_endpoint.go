@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AlbumEndpoint interface {
@@ -37,11 +38,14 @@ type AlbumEndpoint interface {
 	CreateAccess(c *gin.Context)
 	// Check if the user has a given permission on this album
 	Can(c *gin.Context)
+	// Make the album public or private
+	SetVisibility(c *gin.Context)
 }
 type albumEndpoint struct {
 	common.EndpointGroup
 	albumService       services.AlbumService
 	albumAccessService services.AlbumAccessService
+	groupService       services.GroupService
 	mediaService       services.MediaService
 	userService        services.UserService
 }
@@ -58,6 +62,7 @@ func NewAlbumEndpoint(
 	// Service dependencies
 	albumService services.AlbumService,
 	albumAccessService services.AlbumAccessService,
+	groupService services.GroupService,
 	mediaService services.MediaService,
 	userService services.UserService,
 ) AlbumEndpoint {
@@ -65,6 +70,7 @@ func NewAlbumEndpoint(
 	albumEndpoint := albumEndpoint{
 		albumService:       albumService,
 		albumAccessService: albumAccessService,
+		groupService:       groupService,
 		mediaService:       mediaService,
 		userService:        userService,
 	}
@@ -105,6 +111,8 @@ func NewAlbumEndpoint(
 			{Method: "GET", Path: "/:albumId/access"}:    {middlewares.PathParamIdMiddleware("albumId"), albumEndpoint.GetAllAccesses},
 			{Method: "POST", Path: "/:albumId/access"}:   {middlewares.PathParamIdMiddleware("albumId"), albumEndpoint.CreateAccess},
 			{Method: "DELETE", Path: "/:albumId/access"}: {middlewares.PathParamIdMiddleware("albumId"), albumEndpoint.CreateAccess},
+			// Album public page
+			{Method: "PUT", Path: "/:albumId/visibility"}: {middlewares.PathParamIdMiddleware("albumId"), albumEndpoint.SetVisibility},
 			// Album permissions checks
 			{Method: "GET", Path: "/:albumId/can/:permission"}: {middlewares.PathParamIdMiddleware("albumId"), albumEndpoint.Can},
 		},
@@ -243,8 +251,8 @@ func (e *albumEndpoint) AddMedia(c *gin.Context) {
 	// Decoding album ID from the request body
 	albumId := utils.GetIdFromContext("albumId", c)
 
-	// Check if user is allowed to edit the album
-	if !e.GetPermissionsManager().CanEditMediasInAlbum(user, &albumId, sharedLink) {
+	// Check if user is allowed to add this media to the album
+	if !e.GetPermissionsManager().CanAddMediaToAlbum(user, &albumId, &mediaId, sharedLink) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
@@ -291,8 +299,8 @@ func (e *albumEndpoint) DeleteMedia(c *gin.Context) {
 	// Decoding album ID from the request body
 	albumId := utils.GetIdFromContext("albumId", c)
 
-	// Check if user is allowed to edit the album
-	if !e.GetPermissionsManager().CanEditMediasInAlbum(user, &albumId, sharedLink) {
+	// Check if user is allowed to remove medias from the album
+	if !e.GetPermissionsManager().CanRemoveMediaFromAlbum(user, &albumId, &mediaId, sharedLink) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
@@ -327,6 +335,40 @@ func (e *albumEndpoint) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+type VisibilityBody struct {
+	Public bool   `json:"public"`
+	Slug   string `json:"slug,omitempty"`
+	Listed bool   `json:"listed,omitempty"`
+}
+
+func (e *albumEndpoint) SetVisibility(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	albumId := utils.GetIdFromContext("albumId", c)
+
+	var visibilityBody VisibilityBody
+	if err := c.BindJSON(&visibilityBody); err != nil {
+		slog.Debug("Couldn't decode visibility body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	// Only the author can publish the album
+	if !e.GetPermissionsManager().CanEditAlbumVisibility(user, &albumId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	album, svcErr := e.albumService.SetVisibility(&albumId, services.AlbumVisibility{Public: visibilityBody.Public, Slug: visibilityBody.Slug, Listed: visibilityBody.Listed})
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, album)
+}
+
 func (e *albumEndpoint) GetAlbumThumbnail(c *gin.Context) {
 	user, sharedLink, err := utils.GetUserOrSharedLink(c)
 	if err != nil {
@@ -335,7 +377,8 @@ func (e *albumEndpoint) GetAlbumThumbnail(c *gin.Context) {
 
 	albumId := utils.GetIdFromContext("albumId", c)
 
-	if !e.GetPermissionsManager().CanGetAlbum(user, &albumId, sharedLink) {
+	// The thumbnail is one of the album medias
+	if !e.GetPermissionsManager().CanGetAllMediasForAlbum(user, &albumId, sharedLink) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
@@ -346,11 +389,12 @@ func (e *albumEndpoint) GetAlbumThumbnail(c *gin.Context) {
 		return
 	}
 
-	mimeType, file, modTime, svcErr := e.mediaService.GetData(&media.Id, *media.StorageFileName, media.CompressedFileName, true)
+	mimeType, file, modTime, svcErr := e.mediaService.GetData(media, true)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
 	}
+	defer file.Close()
 	c.Header("Content-Type", *mimeType)
 
 	http.ServeContent(c.Writer, c.Request, "", *modTime, file)
@@ -374,9 +418,14 @@ func (e *albumEndpoint) GetAllAccesses(c *gin.Context) {
 		return
 	}
 
+	// Individual grants have an email, group grants a group
 	type Result struct {
-		Email   string `json:"email"`
-		CanEdit bool   `json:"canEdit"`
+		Email     string          `json:"email,omitempty"`
+		GroupId   string          `json:"groupId,omitempty"`
+		GroupName string          `json:"groupName,omitempty"`
+		Role      model.AlbumRole `json:"role"`
+		// Kept for clients written before roles
+		CanEdit bool `json:"canEdit"`
 	}
 
 	var result []Result = make([]Result, 0)
@@ -389,8 +438,22 @@ func (e *albumEndpoint) GetAllAccesses(c *gin.Context) {
 		}
 		// Do not include the user's access as it is implicit
 		if userShared.Id.Hex() != user.Id.Hex() {
-			result = append(result, Result{Email: userShared.Email, CanEdit: access.CanEdit})
+			result = append(result, Result{Email: userShared.Email, Role: access.Role, CanEdit: access.Role.AtLeast(model.ALBUM_ROLE_EDITOR)})
+		}
+	}
+
+	groupAccesses, svcErr := e.albumAccessService.GetAllGroupAccesses(&albumId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	for _, access := range groupAccesses {
+		group, svcErr := e.groupService.GetById(access.GroupId)
+		if svcErr != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
 		}
+		result = append(result, Result{GroupId: group.Id.Hex(), GroupName: group.Name, Role: access.Role, CanEdit: access.Role.AtLeast(model.ALBUM_ROLE_EDITOR)})
 	}
 
 	c.IndentedJSON(http.StatusOK, result)
@@ -398,7 +461,19 @@ func (e *albumEndpoint) GetAllAccesses(c *gin.Context) {
 
 type AccessBody struct {
 	UserEmail string `json:"email"`
-	AllowEdit bool   `json:"allowEdit,omitempty"`
+	// Share with a group instead of a user
+	GroupId string          `json:"groupId,omitempty"`
+	Role    model.AlbumRole `json:"role,omitempty"`
+	// Before roles, gives the editor role instead of the viewer one
+	AllowEdit bool `json:"allowEdit,omitempty"`
+}
+
+// The role granted by an access request
+func (b *AccessBody) role() model.AlbumRole {
+	if b.Role == "" {
+		return model.AlbumRoleFromCanEdit(b.AllowEdit)
+	}
+	return b.Role
 }
 
 func (e *albumEndpoint) CreateAccess(c *gin.Context) {
@@ -421,6 +496,11 @@ func (e *albumEndpoint) CreateAccess(c *gin.Context) {
 		return
 	}
 
+	if accessBody.GroupId != "" {
+		e.createGroupAccess(c, user, &albumId, &accessBody)
+		return
+	}
+
 	// Get user to share/unshare the album with
 	userToShareWith, svcErr := e.userService.GetByEmail(accessBody.UserEmail)
 	if svcErr != nil {
@@ -428,15 +508,19 @@ func (e *albumEndpoint) CreateAccess(c *gin.Context) {
 		return
 	}
 
-	// Cannot change the owner's access
-	if userToShareWith.Id.Hex() == user.Id.Hex() {
+	// Cannot change one's own access, nor the owner's
+	if userToShareWith.Id.Hex() == user.Id.Hex() || e.GetPermissionsManager().GetAlbumRole(userToShareWith, &albumId) == model.ALBUM_ROLE_OWNER {
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
 	if c.Request.Method == "POST" {
-		// Create/modify the access
-		svcErr = e.albumAccessService.GrantAccess(&userToShareWith.Id, &albumId, accessBody.AllowEdit)
+		// Create/modify the access, the album has a single owner
+		if !accessBody.role().IsGrantable() {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "this role cannot be granted"})
+			return
+		}
+		svcErr = e.albumAccessService.GrantAccess(&userToShareWith.Id, &albumId, accessBody.role())
 		if svcErr != nil {
 			svcErr.Apply(c)
 			return
@@ -455,6 +539,29 @@ func (e *albumEndpoint) CreateAccess(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (e *albumEndpoint) createGroupAccess(c *gin.Context, user *model.User, albumId *primitive.ObjectID, accessBody *AccessBody) {
+	groupId, svcErr := utils.DecodeBodyId(accessBody.GroupId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	if !e.GetPermissionsManager().CanShareAlbumWithGroup(user, albumId, groupId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if c.Request.Method == "POST" {
+		svcErr = e.albumAccessService.GrantGroupAccess(groupId, albumId, accessBody.role())
+	} else {
+		svcErr = e.albumAccessService.RevokeGroupAccess(groupId, albumId)
+	}
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 func (e *albumEndpoint) Can(c *gin.Context) {
 	user, sharedLink, err := utils.GetUserOrSharedLink(c)
 	if err != nil {
@@ -462,24 +569,36 @@ func (e *albumEndpoint) Can(c *gin.Context) {
 	}
 	albumId := utils.GetIdFromContext("albumId", c)
 	permission := c.Param("permission")
+	permissionsManager := e.GetPermissionsManager()
 
+	var allowed bool
 	switch permission {
+	case "role":
+		allowed = permissionsManager.GetAlbumRole(user, &albumId) != ""
 	case "delete":
-		if e.GetPermissionsManager().CanDeleteAlbum(user, &albumId) {
-			c.Status(http.StatusOK)
-			return
-		}
-	case "addmedia", "deletemedia":
-		if e.GetPermissionsManager().CanEditMediasInAlbum(user, &albumId, sharedLink) {
-			c.Status(http.StatusOK)
-			return
-		}
+		allowed = permissionsManager.CanDeleteAlbum(user, &albumId)
+	case "addmedia":
+		allowed = permissionsManager.CanAddMediaToAlbum(user, &albumId, nil, sharedLink)
+	case "deletemedia":
+		allowed = permissionsManager.CanRemoveMediaFromAlbum(user, &albumId, nil, sharedLink)
+	case "viewmedias":
+		allowed = permissionsManager.CanGetAllMediasForAlbum(user, &albumId, sharedLink)
+	case "upload":
+		allowed = permissionsManager.CanCreateMedia(user, sharedLink) && permissionsManager.CanAddMediaToAlbum(user, &albumId, nil, sharedLink)
+	case "download":
+		allowed = permissionsManager.CanInitDownloadForAlbum(user, &albumId, false, sharedLink)
+	case "downloadrenditions":
+		allowed = permissionsManager.CanInitDownloadForAlbum(user, &albumId, true, sharedLink)
 	case "editaccesses":
-		if e.GetPermissionsManager().CanEditAlbumAccesses(user, &albumId) {
-			c.Status(http.StatusOK)
-			return
-		}
+		allowed = permissionsManager.CanEditAlbumAccesses(user, &albumId)
+	case "sharedlinks":
+		allowed = permissionsManager.CanCreateSharedLink(user, &albumId)
 	}
 
-	c.AbortWithStatus(http.StatusUnauthorized)
+	if !allowed {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	// Contributors add and remove their own medias only, the role tells clients which
+	c.JSON(http.StatusOK, gin.H{"role": permissionsManager.GetAlbumRole(user, &albumId)})
 }
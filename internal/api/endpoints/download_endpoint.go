@@ -61,7 +61,8 @@ type DownloadAlbumBody struct {
 	AlbumId    string   `json:"albumId"`
 	Everything bool     `json:"everything"`
 	MediaList  []string `json:"mediaList"`
-	// TODO allow to specify media quality MediasQuality model.MediaQuality `json:"mediasQuality"`
+	// Zip the renditions instead of the originals, medias without rendition are left out
+	Renditions bool `json:"renditions"`
 }
 
 func (e *downloadEndpoint) InitDownload(c *gin.Context) {
@@ -84,7 +85,7 @@ func (e *downloadEndpoint) InitDownload(c *gin.Context) {
 		return
 	}
 
-	if !e.GetPermissionsManager().CanInitDownloadForAlbum(user, albumId, sharedLink) {
+	if !e.GetPermissionsManager().CanInitDownloadForAlbum(user, albumId, downloadAlbumBody.Renditions, sharedLink) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
@@ -96,7 +97,7 @@ func (e *downloadEndpoint) InitDownload(c *gin.Context) {
 			c.AbortWithStatus(http.StatusBadRequest)
 			return
 		}
-		downloadId, svcErr := e.downloadService.InitDownload(albumId, addedById, isSharedLink)
+		downloadId, svcErr := e.downloadService.InitDownload(albumId, addedById, isSharedLink, downloadAlbumBody.Renditions)
 		if svcErr != nil {
 			svcErr.Apply(c)
 			return
@@ -128,14 +129,16 @@ func (e *downloadEndpoint) Download(c *gin.Context) {
 		return
 	}
 
-	data, svcErr := e.downloadService.GetData(&downloadId)
+	data, modTime, svcErr := e.downloadService.GetData(&downloadId)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
 	}
+	defer data.Close()
 
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", download.DownloadName))
-	c.Data(http.StatusOK, "application/x-zip", data)
+	c.Header("Content-Type", "application/x-zip")
+	http.ServeContent(c.Writer, c.Request, "", *modTime, data)
 }
 
 func (e *downloadEndpoint) Get(c *gin.Context) {
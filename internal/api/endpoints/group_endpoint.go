@@ -0,0 +1,232 @@
+package endpoints
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupEndpoint interface {
+	common.EndpointGroup
+	// Create a group owned by the user
+	Create(c *gin.Context)
+	// List the groups the user is a member of
+	GetAll(c *gin.Context)
+	// Get a group and its members
+	GetOne(c *gin.Context)
+	// Delete a group, its members lose the accesses granted to it
+	Delete(c *gin.Context)
+	// Add a member to the group, by email
+	AddMember(c *gin.Context)
+	// Remove a member from the group, by email
+	RemoveMember(c *gin.Context)
+}
+
+type groupEndpoint struct {
+	common.EndpointGroup
+	groupService services.GroupService
+	userService  services.UserService
+}
+
+func NewGroupEndpoint(
+	// Common dependencies
+	commonMiddlewares []gin.HandlerFunc,
+	permissionsManager common.PermissionsManager,
+	// Service dependencies
+	groupService services.GroupService,
+	userService services.UserService,
+) GroupEndpoint {
+	groupEndpoint := groupEndpoint{groupService: groupService, userService: userService}
+
+	endpoint := common.NewEndpoint(
+		"Groups",
+		"/group",
+		commonMiddlewares,
+		map[common.MethodPath][]gin.HandlerFunc{
+			{Method: "POST", Path: ""}:                   {groupEndpoint.Create},
+			{Method: "GET", Path: ""}:                    {groupEndpoint.GetAll},
+			{Method: "GET", Path: "/:groupId"}:           {middlewares.PathParamIdMiddleware("groupId"), groupEndpoint.GetOne},
+			{Method: "DELETE", Path: "/:groupId"}:        {middlewares.PathParamIdMiddleware("groupId"), groupEndpoint.Delete},
+			{Method: "POST", Path: "/:groupId/member"}:   {middlewares.PathParamIdMiddleware("groupId"), groupEndpoint.AddMember},
+			{Method: "DELETE", Path: "/:groupId/member"}: {middlewares.PathParamIdMiddleware("groupId"), groupEndpoint.RemoveMember},
+		},
+		permissionsManager,
+	)
+
+	groupEndpoint.EndpointGroup = endpoint
+	return &groupEndpoint
+}
+
+type CreateGroupBody struct {
+	Name string `json:"name"`
+}
+
+func (e *groupEndpoint) Create(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	var createGroupBody CreateGroupBody
+	if err := c.BindJSON(&createGroupBody); err != nil {
+		slog.Debug("Couldn't decode create group body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if !e.GetPermissionsManager().CanCreateGroup(user) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	group, svcErr := e.groupService.Create(&user.Id, createGroupBody.Name)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, group)
+}
+
+func (e *groupEndpoint) GetAll(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	groups, svcErr := e.groupService.GetAllForUser(&user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, groups)
+}
+
+func (e *groupEndpoint) GetOne(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	groupId := utils.GetIdFromContext("groupId", c)
+
+	// Members see each other
+	if !e.GetPermissionsManager().CanGetGroup(user, &groupId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	group, svcErr := e.groupService.GetById(&groupId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	type Member struct {
+		Email string `json:"email"`
+		Owner bool   `json:"owner"`
+	}
+	var members []Member = make([]Member, 0)
+	for _, memberId := range group.MemberIds {
+		member, err := e.userService.GetById(memberId)
+		if err != nil {
+			// Deleted users are removed from their groups, a missing member is not worth failing for
+			continue
+		}
+		members = append(members, Member{Email: member.Email, Owner: memberId == group.OwnerId})
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"group": group, "members": members})
+}
+
+func (e *groupEndpoint) Delete(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	groupId := utils.GetIdFromContext("groupId", c)
+
+	// Only the owner can delete the group
+	if !e.GetPermissionsManager().CanEditGroup(user, &groupId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if svcErr := e.groupService.Delete(&groupId); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type MemberBody struct {
+	UserEmail string `json:"email"`
+}
+
+// Decode the member of a membership request
+func (e *groupEndpoint) getMember(c *gin.Context) *model.User {
+	var memberBody MemberBody
+	if err := c.BindJSON(&memberBody); err != nil {
+		slog.Debug("Couldn't decode body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return nil
+	}
+	member, svcErr := e.userService.GetByEmail(memberBody.UserEmail)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return nil
+	}
+	return member
+}
+
+func (e *groupEndpoint) AddMember(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	groupId := utils.GetIdFromContext("groupId", c)
+
+	// Only the owner can add members
+	if !e.GetPermissionsManager().CanEditGroup(user, &groupId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	member := e.getMember(c)
+	if member == nil {
+		return
+	}
+	if svcErr := e.groupService.AddMember(&groupId, &member.Id); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (e *groupEndpoint) RemoveMember(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	groupId := utils.GetIdFromContext("groupId", c)
+
+	member := e.getMember(c)
+	if member == nil {
+		return
+	}
+
+	// The owner removes members, members leave the group
+	if !e.GetPermissionsManager().CanRemoveGroupMember(user, &groupId, &member.Id) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if svcErr := e.groupService.RemoveMember(&groupId, &member.Id); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -3,17 +3,18 @@ package endpoints
 import (
 	"data-storage-svc/internal/api/common"
 	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/api/security"
 	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/utils"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
-	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/tus/tusd/v2/pkg/filelocker"
 	"github.com/tus/tusd/v2/pkg/filestore"
 	"github.com/tus/tusd/v2/pkg/handler"
@@ -33,31 +34,54 @@ type MediaEndpoint interface {
 	Get(c *gin.Context)
 	// Get media meta data by id
 	GetMetaData(c *gin.Context)
+	// Get a signed URL giving access to a media without credentials for a while, for <img src> and caches
+	GetSignedUrl(c *gin.Context)
 	// Delete a specific media by id
 	Delete(c *gin.Context)
+	// List the medias shared directly with the user
+	ListShared(c *gin.Context)
+	// Return the list of users a media is shared with
+	GetAllAccesses(c *gin.Context)
+	// Share a media with a user, by email
+	CreateAccess(c *gin.Context)
+	// Stop sharing a media with a user, by email
+	DeleteAccess(c *gin.Context)
 }
 type mediaEndpoint struct {
 	common.EndpointGroup
+	mediaUrlSigner     security.MediaUrlSigner
 	mediaService       services.MediaService
 	mediaAccessService services.MediaAccessService
+	quotaService       services.QuotaService
+	userService        services.UserService
 }
 
 func NewMediaEndpoint(
 	commonMiddlewares []gin.HandlerFunc,
 	permissionsManager common.PermissionsManager,
+	// Signs the media URLs
+	mediaUrlSigner security.MediaUrlSigner,
 	mediaService services.MediaService,
 	mediaAccessService services.MediaAccessService,
+	quotaService services.QuotaService,
+	userService services.UserService,
 ) MediaEndpoint {
 	mediaEndpoint := mediaEndpoint{
+		mediaUrlSigner:     mediaUrlSigner,
 		mediaService:       mediaService,
 		mediaAccessService: mediaAccessService,
+		quotaService:       quotaService,
+		userService:        userService,
 	}
 
-	// Create the TUS store and locker
-	targetFolderOriginal, err := utils.GetDataDir("originalMedias")
+	// Create the TUS store and locker, uploads are staged locally then moved to the storage backend once complete
+	uploadFolder, err := utils.GetDataDir(common.UPLOAD_DIRECTORY)
+	if err != nil {
+		log.Fatalf("unable to create upload directory: %s", err)
+	}
 
-	store := filestore.New(targetFolderOriginal)
-	locker := filelocker.New(targetFolderOriginal)
+	store := filestore.New(uploadFolder)
+	locker := filelocker.New(uploadFolder)
 	composer := tusd.NewStoreComposer()
 	store.UseIn(composer)
 	locker.UseIn(composer)
@@ -90,6 +114,13 @@ func NewMediaEndpoint(
 			{Method: "HEAD", Path: "/:mediaId"}:     {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.Get},
 			{Method: "GET", Path: "/:mediaId/meta"}: {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.GetMetaData},
 			{Method: "DELETE", Path: "/:mediaId"}:   {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.Delete},
+			// Signed URLs, usable without credentials for a while
+			{Method: "GET", Path: "/:mediaId/url"}: {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.GetSignedUrl},
+			// Direct sharing of a single media
+			{Method: "GET", Path: "/shared"}:             {mediaEndpoint.ListShared},
+			{Method: "GET", Path: "/:mediaId/access"}:    {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.GetAllAccesses},
+			{Method: "POST", Path: "/:mediaId/access"}:   {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.CreateAccess},
+			{Method: "DELETE", Path: "/:mediaId/access"}: {middlewares.PathParamIdMiddleware("mediaId"), mediaEndpoint.DeleteAccess},
 			// Handle media chunk uploads with TUS to support huge file upload
 			{Method: "POST", Path: "/chunkupload"}:             {gin.WrapH(http.StripPrefix("/media/chunkupload", http.HandlerFunc(handler.PostFile)))},
 			{Method: "HEAD", Path: "/chunkupload/:uploadId"}:   {gin.WrapH(http.StripPrefix("/media/chunkupload", http.HandlerFunc(handler.HeadFile)))},
@@ -123,8 +154,7 @@ func (e *mediaEndpoint) PreCreate(hook tusd.HookEvent) (tusd.HTTPResponse, tusd.
 	}
 
 	mimeType := hook.Upload.MetaData["filetype"]
-	fileExtension, err := utils.MimeTypeToFileExtension(mimeType)
-	if err != nil {
+	if _, err := utils.MimeTypeToFileExtension(mimeType); err != nil {
 		return tusd.HTTPResponse{}, handler.FileInfoChanges{}, handler.ErrInvalidContentType
 	}
 
@@ -132,19 +162,26 @@ func (e *mediaEndpoint) PreCreate(hook tusd.HookEvent) (tusd.HTTPResponse, tusd.
 	if !e.GetPermissionsManager().CanCreateMedia(user, sharedLink) {
 		return abortUnauthorized()
 	}
-	storageFileName := uuid.NewString() + "." + fileExtension
-	dataDir, err := utils.GetDataDir("originalMedias")
-	if err != nil {
-		return tusd.HTTPResponse{}, handler.FileInfoChanges{}, handler.NewError("400", "Bad request", http.StatusBadRequest)
+
+	// Uploads through a shared link count against the link creator quota
+	var chargedTo primitive.ObjectID
+	if user != nil {
+		chargedTo = user.Id
+	} else {
+		chargedTo = sharedLink.CreatedBy
+	}
+	// The upload length is needed to check the quota before receiving any data
+	if hook.Upload.SizeIsDeferred {
+		return tusd.HTTPResponse{}, handler.FileInfoChanges{}, handler.NewError("400", "Upload-Length is required", http.StatusBadRequest)
+	}
+	if svcErr := e.quotaService.CheckQuota(&chargedTo, hook.Upload.Size); svcErr != nil {
+		return tusd.HTTPResponse{}, handler.FileInfoChanges{}, handler.NewError(svcErr.GetMessage(), svcErr.GetMessage(), svcErr.GetCode())
 	}
 
 	changes := handler.FileInfoChanges{
-		Storage: map[string]string{
-			"Path": filepath.Join(dataDir, storageFileName),
-		},
 		MetaData: map[string]string{
-			"filename":         storageFileName,
 			"originalFilename": originalFilename,
+			"chargedTo":        chargedTo.Hex(),
 		},
 	}
 
@@ -160,13 +197,16 @@ func (e *mediaEndpoint) PreCreate(hook tusd.HookEvent) (tusd.HTTPResponse, tusd.
 }
 
 func (e *mediaEndpoint) PreFinish(hook handler.HookEvent) (handler.HTTPResponse, error) {
-	// Remove the .info file
-	dataDir, err := utils.GetDataDir("originalMedias")
-	if err == nil {
-		if err := os.Remove(filepath.Join(dataDir, hook.Upload.ID+".info")); err != nil {
-			slog.Error("couldn't remove .info file", "upload ID", hook.Upload.ID, "error", err)
+	// The staged upload is not needed anymore once moved to the storage backend
+	uploadPath := hook.Upload.Storage["Path"]
+	defer func() {
+		for _, stagedFile := range []string{uploadPath, hook.Upload.Storage["InfoPath"]} {
+			if err := os.Remove(stagedFile); err != nil {
+				slog.Error("couldn't remove staged upload file", "upload ID", hook.Upload.ID, "file", stagedFile, "error", err)
+			}
 		}
-	}
+	}()
+
 	hexUserId := hook.Upload.MetaData["userId"]
 	hexSharedLinkId := hook.Upload.MetaData["sharedLinkId"]
 	userId, errUserId := primitive.ObjectIDFromHex(hexUserId)
@@ -176,13 +216,19 @@ func (e *mediaEndpoint) PreFinish(hook handler.HookEvent) (handler.HTTPResponse,
 	if errUserId == nil {
 		userIdPtr = &userId
 	}
+	var chargedToPtr *primitive.ObjectID = nil
+	if chargedTo, err := primitive.ObjectIDFromHex(hook.Upload.MetaData["chargedTo"]); err == nil {
+		chargedToPtr = &chargedTo
+	}
+
+	uploadedData, err := os.Open(uploadPath)
+	if err != nil {
+		slog.Error("couldn't open staged upload", "upload ID", hook.Upload.ID, "error", err)
+		return tusd.HTTPResponse{}, handler.NewError("500", "Internal server error", http.StatusInternalServerError)
+	}
 
-	mediaId, svcErr := e.mediaService.Create(hook.Upload.MetaData["originalFilename"], hook.Upload.MetaData["filename"], userIdPtr, errSharedId != nil)
+	mediaId, svcErr := e.mediaService.Create(hook.Upload.MetaData["originalFilename"], userIdPtr, errSharedId == nil, chargedToPtr, uploadedData)
 	if svcErr != nil {
-		// Couldn't create the media, delete the file
-		if err := os.Remove(filepath.Join(dataDir, hook.Upload.MetaData["filename"])); err != nil {
-			slog.Error("couldn't remove media file that couldn't be added to DB", "filename", hook.Upload.MetaData["filename"], "error", err)
-		}
 		return tusd.HTTPResponse{}, handler.NewError(svcErr.GetMessage(), svcErr.GetMessage(), svcErr.GetCode())
 	}
 	return handler.HTTPResponse{
@@ -223,7 +269,8 @@ func (e *mediaEndpoint) Get(c *gin.Context) {
 		compressedQuality = false
 	}
 
-	if !e.GetPermissionsManager().CanGetMedia(user, &mediaId, sharedLink) {
+	if compressedQuality && !e.GetPermissionsManager().CanGetMedia(user, &mediaId, sharedLink) ||
+		!compressedQuality && !e.GetPermissionsManager().CanGetOriginal(user, &mediaId, sharedLink) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
@@ -235,11 +282,12 @@ func (e *mediaEndpoint) Get(c *gin.Context) {
 		return
 	}
 	// Get the media data
-	mimeType, mediaFile, modTime, svcErr := e.mediaService.GetData(&mediaId, *media.StorageFileName, media.CompressedFileName, compressedQuality)
+	mimeType, mediaFile, modTime, svcErr := e.mediaService.GetData(media, compressedQuality)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
 	}
+	defer mediaFile.Close()
 	c.Header("Content-Type", *mimeType)
 
 	if c.Request.Method == "GET" {
@@ -247,6 +295,40 @@ func (e *mediaEndpoint) Get(c *gin.Context) {
 	}
 }
 
+func (e *mediaEndpoint) GetSignedUrl(c *gin.Context) {
+	user, sharedLink, err := utils.GetUserOrSharedLink(c)
+	if err != nil {
+		return
+	}
+	mediaId := utils.GetIdFromContext("mediaId", c)
+	original := c.DefaultQuery("compressed", "true") == "false"
+
+	// The URL gives what the caller could get itself
+	if !original && !e.GetPermissionsManager().CanGetMedia(user, &mediaId, sharedLink) ||
+		original && !e.GetPermissionsManager().CanGetOriginal(user, &mediaId, sharedLink) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	media, svcErr := e.mediaService.GetById(&mediaId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	// The file name is part of the URL, so the file is served without looking the media up
+	fileName, svcErr := e.mediaService.GetStoredFileName(media, !original)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	rendition := security.MEDIA_URL_RENDITION
+	if original {
+		rendition = security.MEDIA_URL_ORIGINAL
+	}
+	signedUrl, expiry := signedMediaUrl(e.mediaUrlSigner, mediaId.Hex(), rendition, *fileName, time.Now())
+	c.IndentedJSON(http.StatusOK, gin.H{"url": signedUrl, "expiresAt": expiry})
+}
+
 func (e *mediaEndpoint) GetMetaData(c *gin.Context) {
 	user, sharedLink, err := utils.GetUserOrSharedLink(c)
 	if err != nil {
@@ -254,7 +336,7 @@ func (e *mediaEndpoint) GetMetaData(c *gin.Context) {
 	}
 	mediaId := utils.GetIdFromContext("mediaId", c)
 
-	if !e.GetPermissionsManager().CanGetMedia(user, &mediaId, sharedLink) {
+	if !e.GetPermissionsManager().CanGetMetaData(user, &mediaId, sharedLink) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
@@ -296,3 +378,135 @@ func (e *mediaEndpoint) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func (e *mediaEndpoint) ListShared(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	medias, svcErr := e.mediaService.GetAllSharedWithUser(&user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, medias)
+}
+
+func (e *mediaEndpoint) GetAllAccesses(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	mediaId := utils.GetIdFromContext("mediaId", c)
+
+	if !e.GetPermissionsManager().CanShareMedia(user, &mediaId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	accesses, svcErr := e.mediaAccessService.GetAllForMedia(&mediaId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	type Result struct {
+		Email string `json:"email"`
+	}
+	var result []Result = make([]Result, 0)
+	for _, access := range accesses {
+		// Do not include the user's access as it is implicit
+		if *access.UserId == user.Id {
+			continue
+		}
+		userShared, svcErr := e.userService.GetById(*access.UserId)
+		if svcErr != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		result = append(result, Result{Email: userShared.Email})
+	}
+
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+type MediaAccessBody struct {
+	UserEmail string `json:"email"`
+}
+
+// Decode the user of a media access request
+func (e *mediaEndpoint) getAccessUser(c *gin.Context) *model.User {
+	var accessBody MediaAccessBody
+	if err := c.BindJSON(&accessBody); err != nil {
+		slog.Debug("Couldn't decode body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return nil
+	}
+	userShared, svcErr := e.userService.GetByEmail(accessBody.UserEmail)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return nil
+	}
+	return userShared
+}
+
+func (e *mediaEndpoint) CreateAccess(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	mediaId := utils.GetIdFromContext("mediaId", c)
+
+	if !e.GetPermissionsManager().CanShareMedia(user, &mediaId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userShared := e.getAccessUser(c)
+	if userShared == nil {
+		return
+	}
+	// The uploader always has access to their media
+	if userShared.Id == user.Id {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if svcErr := e.mediaAccessService.GrantAccess(&userShared.Id, &mediaId); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (e *mediaEndpoint) DeleteAccess(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	mediaId := utils.GetIdFromContext("mediaId", c)
+
+	userShared := e.getAccessUser(c)
+	if userShared == nil {
+		return
+	}
+
+	// The uploader revokes accesses, users give up the medias shared with them
+	if !e.GetPermissionsManager().CanRevokeMediaAccess(user, &mediaId, &userShared.Id) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	// The uploader cannot lose access to their media
+	if e.GetPermissionsManager().CanDeleteMedia(userShared, &mediaId) {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if svcErr := e.mediaAccessService.RevokeAccess(&userShared.Id, &mediaId); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,207 @@
+package endpoints
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PublicEndpoint interface {
+	common.EndpointGroup
+	// List the public albums listed in the directory
+	GetDirectory(c *gin.Context)
+	// Get a public album and the signed URLs of its renditions
+	GetAlbum(c *gin.Context)
+	// Render the HTML gallery of a public album, with its OpenGraph preview
+	GetGallery(c *gin.Context)
+}
+
+type publicEndpoint struct {
+	common.EndpointGroup
+	mediaUrlSigner security.MediaUrlSigner
+	// URL of the API as seen from outside, previews need absolute URLs
+	publicUrl    string
+	albumService services.AlbumService
+	mediaService services.MediaService
+}
+
+// What anyone can see of a public album
+type PublicAlbum struct {
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Slug         string    `json:"slug"`
+	CreationDate time.Time `json:"creationDate"`
+}
+
+// A rendition of a public album media
+type PublicMedia struct {
+	Id         string     `json:"id"`
+	UploadTime *time.Time `json:"uploadTime"`
+	Url        string     `json:"url"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+}
+
+func NewPublicEndpoint(
+	// Common dependencies
+	commonMiddlewares []gin.HandlerFunc,
+	// Signs the URLs of the renditions
+	mediaUrlSigner security.MediaUrlSigner,
+	publicUrl string,
+	// Service dependencies
+	albumService services.AlbumService,
+	mediaService services.MediaService,
+) PublicEndpoint {
+	publicEndpoint := publicEndpoint{
+		mediaUrlSigner: mediaUrlSigner,
+		publicUrl:      strings.TrimSuffix(publicUrl, "/"),
+		albumService:   albumService,
+		mediaService:   mediaService,
+	}
+
+	endpoint := common.NewEndpoint(
+		"Public albums",
+		"/public",
+		commonMiddlewares,
+		map[common.MethodPath][]gin.HandlerFunc{
+			{Method: "GET", Path: "/albums"}:               {publicEndpoint.GetDirectory},
+			{Method: "GET", Path: "/albums/:slug"}:         {publicEndpoint.GetAlbum},
+			{Method: "GET", Path: "/albums/:slug/gallery"}: {publicEndpoint.GetGallery},
+		},
+		nil,
+	)
+
+	publicEndpoint.EndpointGroup = endpoint
+	return &publicEndpoint
+}
+
+func toPublicAlbum(album *model.Album) PublicAlbum {
+	return PublicAlbum{Title: album.Title, Description: album.Description, Slug: album.Slug, CreationDate: album.CreationDate}
+}
+
+func (e *publicEndpoint) GetDirectory(c *gin.Context) {
+	albums, svcErr := e.albumService.GetPublicDirectory()
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	var result []PublicAlbum = make([]PublicAlbum, 0)
+	for _, album := range albums {
+		result = append(result, toPublicAlbum(&album))
+	}
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// The public album of the slug and its renditions, medias not compressed yet are left out
+func (e *publicEndpoint) getAlbumAndMedias(c *gin.Context) (*model.Album, []PublicMedia, bool) {
+	album, svcErr := e.albumService.GetPublicAlbum(c.Param("slug"))
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return nil, nil, false
+	}
+	mediasInAlbum, svcErr := e.albumService.GetMedias(album.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return nil, nil, false
+	}
+
+	now := time.Now()
+	var medias []PublicMedia = make([]PublicMedia, 0)
+	for _, m := range mediasInAlbum {
+		media, svcErr := e.mediaService.GetById(m.MediaId)
+		if svcErr != nil {
+			svcErr.Apply(c)
+			return nil, nil, false
+		}
+		// Anonymous visitors only get renditions, and never trigger compressions nor restores
+		if media.CompressedFileName == nil {
+			continue
+		}
+		signedUrl, expiry := signedMediaUrl(e.mediaUrlSigner, media.Id.Hex(), security.MEDIA_URL_RENDITION, *media.CompressedFileName, now)
+		medias = append(medias, PublicMedia{Id: media.Id.Hex(), UploadTime: media.UploadTime, Url: signedUrl, ExpiresAt: expiry})
+	}
+	return album, medias, true
+}
+
+func (e *publicEndpoint) GetAlbum(c *gin.Context) {
+	album, medias, ok := e.getAlbumAndMedias(c)
+	if !ok {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"album": toPublicAlbum(album), "medias": medias})
+}
+
+var galleryTemplate = template.Must(template.New("gallery").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Album.Title}}</title>
+<meta name="description" content="{{.Album.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Album.Title}}">
+<meta property="og:description" content="{{.Album.Description}}">
+<meta property="og:url" content="{{.PageUrl}}">
+{{if .ImageUrl}}<meta property="og:image" content="{{.ImageUrl}}">
+<meta name="twitter:card" content="summary_large_image">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}<style>
+body { margin: 0; font-family: sans-serif; background: #111; color: #eee; }
+header { padding: 1.5rem; }
+h1 { margin: 0 0 .5rem; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(240px, 1fr)); gap: 4px; padding: 4px; }
+.grid img { width: 100%; height: 240px; object-fit: cover; display: block; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Album.Title}}</h1>
+<p>{{.Album.Description}}</p>
+</header>
+<main class="grid">
+{{range .Medias}}<a href="{{.Url}}"><img src="{{.Url}}" loading="lazy" alt=""></a>
+{{end}}</main>
+</body>
+</html>
+`))
+
+func (e *publicEndpoint) GetGallery(c *gin.Context) {
+	album, medias, ok := e.getAlbumAndMedias(c)
+	if !ok {
+		return
+	}
+
+	// Previews are fetched by other servers, and the signed paths are relative to the API, which may be served under a
+	// prefix: the gallery needs absolute URLs
+	for i := range medias {
+		medias[i].Url = e.publicUrl + medias[i].Url
+	}
+	data := struct {
+		Album    PublicAlbum
+		Medias   []PublicMedia
+		PageUrl  string
+		ImageUrl string
+	}{
+		Album:   toPublicAlbum(album),
+		Medias:  medias,
+		PageUrl: e.publicUrl + "/public/albums/" + url.PathEscape(album.Slug) + "/gallery",
+	}
+	if len(medias) > 0 {
+		data.ImageUrl = medias[0].Url
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := galleryTemplate.Execute(c.Writer, data); err != nil {
+		slog.Error("couldn't render the gallery", "slug", album.Slug, "error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package endpoints_test
+
+import (
+	"data-storage-svc/internal/api/endpoints"
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGalleryUsesPublicUrl(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	mediaId := primitive.NewObjectID()
+	fileName := "rendition.jpg"
+	albumService := &mocks.AlbumService{}
+	albumService.On("GetPublicAlbum", "holidays").Return(&model.Album{Id: &albumId, Title: "Holidays", Slug: "holidays"}, nil)
+	albumService.On("GetMedias", &albumId).Return([]model.MediaInAlbum{{MediaId: &mediaId}}, nil)
+	mediaService := &mocks.MediaService{}
+	mediaService.On("GetById", &mediaId).Return(&model.Media{Id: mediaId, CompressedFileName: &fileName}, nil)
+	mediaUrlSigner := &mocks.MediaUrlSigner{}
+	mediaUrlSigner.On("Expiry", mock.Anything).Return(time.Unix(1000, 0))
+	mediaUrlSigner.On("Sign", mediaId.Hex(), security.MEDIA_URL_RENDITION, fileName, time.Unix(1000, 0)).Return("signature")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// The API is served under a prefix
+	endpoint := endpoints.NewPublicEndpoint([]gin.HandlerFunc{}, mediaUrlSigner, "https://example.com/api/", albumService, mediaService)
+	for methodAndPath, handlers := range endpoint.GetEndpointsList() {
+		router.Handle(methodAndPath.Method, endpoint.GetGroupUrl()+methodAndPath.Path, handlers...)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/public/albums/holidays/gallery", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mediaUrl := "https://example.com/api/signed/" + mediaId.Hex() + "/" + security.MEDIA_URL_RENDITION + "/" + fileName + "?exp=1000&amp;sig=signature"
+	page := recorder.Body.String()
+	assert.Contains(t, page, `<meta property="og:image" content="`+mediaUrl+`">`)
+	assert.Contains(t, page, `<a href="`+mediaUrl+`"><img src="`+mediaUrl+`"`)
+	assert.False(t, strings.Contains(page, `"/signed/`))
+}
@@ -0,0 +1,77 @@
+package endpoints
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetupEndpoint interface {
+	common.EndpointGroup
+	// Tell if the first admin is yet to be created
+	GetStatus(c *gin.Context)
+	// Create the first admin with the setup token printed at startup
+	CreateAdmin(c *gin.Context)
+}
+
+type setupEndpoint struct {
+	common.EndpointGroup
+	setupService services.SetupService
+}
+
+func NewSetupEndpoint(
+	// Common dependencies
+	commonMiddlewares []gin.HandlerFunc,
+	permissionsManager common.PermissionsManager,
+	// Limits the attempts to guess the setup token
+	authRateLimit gin.HandlerFunc,
+	// Service dependencies
+	setupService services.SetupService,
+) SetupEndpoint {
+	setupEndpoint := setupEndpoint{setupService: setupService}
+
+	endpoint := common.NewEndpoint(
+		"Setup",
+		"/setup",
+		commonMiddlewares,
+		map[common.MethodPath][]gin.HandlerFunc{
+			{Method: "GET", Path: ""}:  {setupEndpoint.GetStatus},
+			{Method: "POST", Path: ""}: {authRateLimit, setupEndpoint.CreateAdmin},
+		},
+		permissionsManager,
+	)
+
+	setupEndpoint.EndpointGroup = endpoint
+	return &setupEndpoint
+}
+
+func (e *setupEndpoint) GetStatus(c *gin.Context) {
+	pending, svcErr := e.setupService.IsPending()
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pending": pending})
+}
+
+type SetupBody struct {
+	Token    string `json:"token"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (e *setupEndpoint) CreateAdmin(c *gin.Context) {
+	var setupBody SetupBody
+	if err := c.BindJSON(&setupBody); err != nil {
+		return
+	}
+
+	userId, svcErr := e.setupService.CreateAdmin(setupBody.Token, setupBody.Email, setupBody.Password)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"id": userId})
+}
@@ -3,6 +3,7 @@ package endpoints
 import (
 	"data-storage-svc/internal/api/common"
 	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/utils"
 	"log/slog"
 	"net/http"
@@ -21,6 +22,8 @@ type SharedLinkEndpoint interface {
 	Delete(c *gin.Context)
 	// Update a given shared link
 	Update(c *gin.Context)
+	// Give the password of a protected shared link, the link can be used for a while
+	Unlock(c *gin.Context)
 }
 type sharedLinkEndpoint struct {
 	common.EndpointGroup
@@ -32,6 +35,8 @@ func NewSharedLinkEndpoint(
 	// Common dependencies
 	commonMiddlewares []gin.HandlerFunc,
 	permissionsManager common.PermissionsManager,
+	// Limits the attempts to guess link passwords
+	authRateLimit gin.HandlerFunc,
 	//Services dependencies
 	sharedLinkService services.SharedLinkService,
 	albumService services.AlbumService,
@@ -51,6 +56,8 @@ func NewSharedLinkEndpoint(
 			{Method: "GET", Path: ""}:    {sharedLinkEndpoint.List},
 			{Method: "DELETE", Path: ""}: {sharedLinkEndpoint.Delete},
 			{Method: "PATCH", Path: ""}:  {sharedLinkEndpoint.Update},
+			// Visitors unlocking a link
+			{Method: "POST", Path: "/unlock"}: {authRateLimit, sharedLinkEndpoint.Unlock},
 		},
 		permissionsManager,
 	)
@@ -60,9 +67,16 @@ func NewSharedLinkEndpoint(
 }
 
 type CreateBody struct {
-	AlbumId   string `json:"albumId"`
-	TTL       int    `json:"ttl"`
-	AllowEdit bool   `json:"allowEdit"`
+	AlbumId string `json:"albumId"`
+	// Seconds until the link expires
+	TTL int `json:"ttl"`
+	// What visitors can do in the album, the default scopes when left out
+	Scopes []model.SharedLinkScope `json:"scopes"`
+	// Only used when scopes are left out, adds the scopes editing the album medias
+	AllowEdit bool  `json:"allowEdit"`
+	MaxUses   int64 `json:"maxUses"`
+	// Optional, visitors must give it before using the link
+	Password string `json:"password"`
 }
 
 func (e *sharedLinkEndpoint) Create(c *gin.Context) {
@@ -89,8 +103,12 @@ func (e *sharedLinkEndpoint) Create(c *gin.Context) {
 		return
 	}
 
+	scopes := createLinkBody.Scopes
+	if scopes == nil {
+		scopes = model.DefaultSharedLinkScopes(createLinkBody.AllowEdit)
+	}
 	expirationTime := time.Now().Add(time.Second * time.Duration(createLinkBody.TTL))
-	link, svcErr := e.sharedLinkService.Create(*albumId, user.Id, expirationTime, createLinkBody.AllowEdit)
+	link, svcErr := e.sharedLinkService.Create(*albumId, user.Id, expirationTime, scopes, createLinkBody.MaxUses, createLinkBody.Password)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
@@ -124,13 +142,26 @@ func (e *sharedLinkEndpoint) List(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, sharedLinks)
 }
 
+// The token is sent in the body, where it is not resolved as the link of the request: expired, used up or locked links
+// can be deleted too
+type DeleteLinkBody struct {
+	Token string `json:"token"`
+}
+
 func (e *sharedLinkEndpoint) Delete(c *gin.Context) {
 	user, err := utils.GetUser(c)
 	if err != nil {
 		return
 	}
 
-	sharedLink, svcErr := e.sharedLinkService.GetByToken(c.Query("token"))
+	var deleteBody DeleteLinkBody
+	if err := c.BindJSON(&deleteBody); err != nil {
+		slog.Debug("Couldn't decode body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	sharedLink, svcErr := e.sharedLinkService.GetByToken(deleteBody.Token)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
@@ -151,9 +182,18 @@ func (e *sharedLinkEndpoint) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Fields left out are not changed
 type UpdateBody struct {
-	Token     string `json:"token"`
-	AllowEdit bool   `json:"allowEdit"`
+	Token  string                  `json:"token"`
+	Scopes []model.SharedLinkScope `json:"scopes"`
+	// Only used when scopes are left out, replaces the scopes by the default ones
+	AllowEdit *bool `json:"allowEdit"`
+	// Seconds from now until the link expires
+	TTL     *int   `json:"ttl"`
+	MaxUses *int64 `json:"maxUses"`
+	Revoked *bool  `json:"revoked"`
+	// An empty password removes the protection
+	Password *string `json:"password"`
 }
 
 func (e *sharedLinkEndpoint) Update(c *gin.Context) {
@@ -181,11 +221,42 @@ func (e *sharedLinkEndpoint) Update(c *gin.Context) {
 		return
 	}
 
-	svcErr = e.sharedLinkService.Update(sharedLink.Id, updateBody.AllowEdit)
+	update := services.SharedLinkUpdate{Scopes: updateBody.Scopes, MaxUses: updateBody.MaxUses, Revoked: updateBody.Revoked, Password: updateBody.Password}
+	if update.Scopes == nil && updateBody.AllowEdit != nil {
+		update.Scopes = model.DefaultSharedLinkScopes(*updateBody.AllowEdit)
+	}
+	if updateBody.TTL != nil {
+		expirationTime := time.Now().Add(time.Second * time.Duration(*updateBody.TTL))
+		update.ExpirationDate = &expirationTime
+	}
+	sharedLink, svcErr = e.sharedLinkService.Update(sharedLink.Id, update)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.IndentedJSON(http.StatusOK, sharedLink)
+}
+
+type UnlockBody struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (e *sharedLinkEndpoint) Unlock(c *gin.Context) {
+	var unlockBody UnlockBody
+	if err := c.BindJSON(&unlockBody); err != nil {
+		slog.Debug("Couldn't decode body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	sharedLink, sessionToken, svcErr := e.sharedLinkService.Unlock(unlockBody.Token, unlockBody.Password)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	common.SetLinkSessionCookie(c, sharedLink.Id, sessionToken)
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,56 @@
+package endpoints_test
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/endpoints"
+	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A router serving the shared link routes to a logged in user, behind the shared link middleware like in production
+func sharedLinkRouter(user *model.User, sharedLinkRepository *mocks.SharedLinkRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	setUser := func(c *gin.Context) { c.Set(common.USER, user) }
+	linkMiddleware := middlewares.SharedLinkMiddleware(sharedLinkRepository, &mocks.TokenModule{}, ratelimit.NewMemoryStore(time.Now), ratelimit.PerMinute(10))
+	permissionsManager := common.NewPermissionsManager(nil, nil, nil, nil, nil, nil, nil)
+	sharedLinkService := services.NewSharedLinkService(sharedLinkRepository, nil, nil, nil)
+	endpoint := endpoints.NewSharedLinkEndpoint([]gin.HandlerFunc{}, permissionsManager, func(c *gin.Context) {}, sharedLinkService, nil)
+
+	group := router.Group(endpoint.GetGroupUrl(), setUser, linkMiddleware)
+	for methodAndPath, handlers := range endpoint.GetEndpointsList() {
+		group.Handle(methodAndPath.Method, methodAndPath.Path, handlers...)
+	}
+	return router
+}
+
+func TestDeleteExpiredSharedLink(t *testing.T) {
+	creator := &model.User{Id: primitive.NewObjectID()}
+	expired := &model.SharedLink{Id: primitive.NewObjectID(), CreatedBy: creator.Id, Token: "expired", ExpirationDate: time.Now().Add(-time.Hour)}
+	sharedLinkRepository := &mocks.SharedLinkRepository{}
+	sharedLinkRepository.On("GetByToken", "expired").Return(expired, nil)
+	sharedLinkRepository.On("Delete", expired.Id).Return(nil)
+	router := sharedLinkRouter(creator, sharedLinkRepository)
+
+	// The link is not resolved as the link of the request, so its expiration does not get in the way
+	request := httptest.NewRequest(http.MethodDelete, "/sharedlink", strings.NewReader(`{"token": "expired"}`))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	sharedLinkRepository.AssertCalled(t, "Delete", expired.Id)
+	sharedLinkRepository.AssertNotCalled(t, "Use", mock.Anything, mock.Anything)
+}
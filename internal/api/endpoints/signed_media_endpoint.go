@@ -0,0 +1,100 @@
+package endpoints
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/storage"
+	"data-storage-svc/internal/utils"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SignedMediaEndpoint interface {
+	common.EndpointGroup
+	// Get a media file with a signed URL, without looking anything up in the database
+	Get(c *gin.Context)
+}
+
+type signedMediaEndpoint struct {
+	common.EndpointGroup
+	mediaUrlSigner security.MediaUrlSigner
+	// Where media files are stored
+	storage storage.Backend
+}
+
+func NewSignedMediaEndpoint(
+	// Common dependencies
+	commonMiddlewares []gin.HandlerFunc,
+	// Checks the media URLs
+	mediaUrlSigner security.MediaUrlSigner,
+	storage storage.Backend,
+) SignedMediaEndpoint {
+	signedMediaEndpoint := signedMediaEndpoint{mediaUrlSigner: mediaUrlSigner, storage: storage}
+
+	endpoint := common.NewEndpoint(
+		"Signed medias",
+		"/signed",
+		commonMiddlewares,
+		map[common.MethodPath][]gin.HandlerFunc{
+			{Method: "GET", Path: "/:mediaId/:rendition/:fileName"}:  {signedMediaEndpoint.Get},
+			{Method: "HEAD", Path: "/:mediaId/:rendition/:fileName"}: {signedMediaEndpoint.Get},
+		},
+		nil,
+	)
+
+	signedMediaEndpoint.EndpointGroup = endpoint
+	return &signedMediaEndpoint
+}
+
+// Signed URL of a stored file of a media, relative to the API root
+func signedMediaUrl(mediaUrlSigner security.MediaUrlSigner, mediaId string, rendition string, fileName string, now time.Time) (string, time.Time) {
+	expiry := mediaUrlSigner.Expiry(now)
+	signature := mediaUrlSigner.Sign(mediaId, rendition, fileName, expiry)
+	return fmt.Sprintf("/signed/%s/%s/%s?exp=%d&sig=%s", mediaId, rendition, url.PathEscape(fileName), expiry.Unix(), signature), expiry
+}
+
+func (e *signedMediaEndpoint) Get(c *gin.Context) {
+	mediaId, rendition, fileName := c.Param("mediaId"), c.Param("rendition"), c.Param("fileName")
+	expiryUnix, err := strconv.ParseInt(c.Query("exp"), 10, 64)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	expiry := time.Unix(expiryUnix, 0)
+	now := time.Now()
+	if !e.mediaUrlSigner.Verify(mediaId, rendition, fileName, expiry, c.Query("sig"), now) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	directory := common.COMPRESSED_DIRECTORY
+	if rendition == security.MEDIA_URL_ORIGINAL {
+		directory = common.ORIGINAL_MEDIA_DIRECTORY
+	}
+	file, info, err := storage.Open(e.storage, path.Join(directory, fileName))
+	if err != nil {
+		// The original may have been archived since the URL was signed
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+	mimeType, err := utils.DetectMimeType(file)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	// Stored files never change, caches keep them as long as the URL is valid
+	c.Header("Content-Type", mimeType)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int64(expiry.Sub(now).Seconds())))
+	c.Header("Expires", expiry.UTC().Format(http.TimeFormat))
+	if c.Request.Method == "GET" {
+		http.ServeContent(c.Writer, c.Request, "", info.ModTime, file)
+	}
+}
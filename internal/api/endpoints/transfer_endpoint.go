@@ -0,0 +1,206 @@
+package endpoints
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferEndpoint interface {
+	common.EndpointGroup
+	// Propose to give an album to another user
+	Propose(c *gin.Context)
+	// List the pending transfers of the user, or all the transfers of an album
+	GetAll(c *gin.Context)
+	// Get a transfer
+	GetOne(c *gin.Context)
+	// Accept a transfer, the user becomes the owner of the album
+	Accept(c *gin.Context)
+	// Decline a transfer
+	Decline(c *gin.Context)
+	// Withdraw a transfer before it is answered
+	Cancel(c *gin.Context)
+}
+
+type transferEndpoint struct {
+	common.EndpointGroup
+	ownershipTransferService services.OwnershipTransferService
+	userService              services.UserService
+}
+
+func NewTransferEndpoint(
+	// Common dependencies
+	commonMiddlewares []gin.HandlerFunc,
+	permissionsManager common.PermissionsManager,
+	// Service dependencies
+	ownershipTransferService services.OwnershipTransferService,
+	userService services.UserService,
+) TransferEndpoint {
+	transferEndpoint := transferEndpoint{ownershipTransferService: ownershipTransferService, userService: userService}
+
+	endpoint := common.NewEndpoint(
+		"Ownership transfers",
+		"/transfer",
+		commonMiddlewares,
+		map[common.MethodPath][]gin.HandlerFunc{
+			{Method: "POST", Path: ""}:                     {transferEndpoint.Propose},
+			{Method: "GET", Path: ""}:                      {transferEndpoint.GetAll},
+			{Method: "GET", Path: "/:transferId"}:          {middlewares.PathParamIdMiddleware("transferId"), transferEndpoint.GetOne},
+			{Method: "POST", Path: "/:transferId/accept"}:  {middlewares.PathParamIdMiddleware("transferId"), transferEndpoint.Accept},
+			{Method: "POST", Path: "/:transferId/decline"}: {middlewares.PathParamIdMiddleware("transferId"), transferEndpoint.Decline},
+			{Method: "DELETE", Path: "/:transferId"}:       {middlewares.PathParamIdMiddleware("transferId"), transferEndpoint.Cancel},
+		},
+		permissionsManager,
+	)
+
+	transferEndpoint.EndpointGroup = endpoint
+	return &transferEndpoint
+}
+
+type ProposeTransferBody struct {
+	AlbumId string `json:"albumId"`
+	// Email of the recipient
+	UserEmail string `json:"email"`
+	// Also give the medias of the owner in the album
+	IncludeMedias bool `json:"includeMedias"`
+}
+
+func (e *transferEndpoint) Propose(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+	var proposeBody ProposeTransferBody
+	if err := c.BindJSON(&proposeBody); err != nil {
+		slog.Debug("Couldn't decode transfer body", "error", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	albumId, svcErr := utils.DecodeBodyId(proposeBody.AlbumId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	// The owner, or an admin
+	if !e.GetPermissionsManager().CanProposeAlbumTransfer(user, albumId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	recipient, svcErr := e.userService.GetByEmail(proposeBody.UserEmail)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	transfer, svcErr := e.ownershipTransferService.Propose(&user.Id, albumId, &recipient.Id, proposeBody.IncludeMedias)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, transfer)
+}
+
+func (e *transferEndpoint) GetAll(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	var transfers []model.OwnershipTransfer
+	var svcErr utils.ServiceError
+	if c.Query("albumId") != "" {
+		albumId, err := utils.DecodeQueryId("albumId", c)
+		if err != nil {
+			return
+		}
+		// The history of the album owners
+		if !e.GetPermissionsManager().CanListAlbumTransfers(user, albumId) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		transfers, svcErr = e.ownershipTransferService.GetAllForAlbum(albumId)
+	} else {
+		transfers, svcErr = e.ownershipTransferService.GetAllPendingForUser(&user.Id)
+	}
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, transfers)
+}
+
+// Get the transfer of the path, if the user is allowed to
+func (e *transferEndpoint) getTransfer(c *gin.Context, allowed func(*model.User, *model.OwnershipTransfer) bool) (*model.User, *model.OwnershipTransfer) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return nil, nil
+	}
+	transferId := utils.GetIdFromContext("transferId", c)
+
+	transfer, svcErr := e.ownershipTransferService.GetById(&transferId)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return nil, nil
+	}
+	if !allowed(user, transfer) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return nil, nil
+	}
+	return user, transfer
+}
+
+func (e *transferEndpoint) GetOne(c *gin.Context) {
+	_, transfer := e.getTransfer(c, e.GetPermissionsManager().CanGetTransfer)
+	if transfer == nil {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, transfer)
+}
+
+func (e *transferEndpoint) Accept(c *gin.Context) {
+	user, transfer := e.getTransfer(c, e.GetPermissionsManager().CanAnswerTransfer)
+	if transfer == nil {
+		return
+	}
+
+	transfer, svcErr := e.ownershipTransferService.Accept(&transfer.Id, &user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, transfer)
+}
+
+func (e *transferEndpoint) Decline(c *gin.Context) {
+	user, transfer := e.getTransfer(c, e.GetPermissionsManager().CanAnswerTransfer)
+	if transfer == nil {
+		return
+	}
+
+	if svcErr := e.ownershipTransferService.Decline(&transfer.Id, &user.Id); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (e *transferEndpoint) Cancel(c *gin.Context) {
+	// The owner or the admin who proposed the transfer
+	user, transfer := e.getTransfer(c, e.GetPermissionsManager().CanCancelTransfer)
+	if transfer == nil {
+		return
+	}
+
+	if svcErr := e.ownershipTransferService.Cancel(&transfer.Id, &user.Id); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -3,50 +3,152 @@ package endpoints
 import (
 	"data-storage-svc/internal"
 	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/api/security"
 	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/utils"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserEndpoint interface {
 	common.EndpointGroup
 	// Create (register) a new user
 	Create(c *gin.Context)
+	// Invite someone by email, optionally to an album
+	Invite(c *gin.Context)
+	// Create the account of an invited user
+	AcceptInvitation(c *gin.Context)
+	// Email a password reset link
+	ForgotPassword(c *gin.Context)
+	// Set a new password from a password reset link
+	ResetPassword(c *gin.Context)
 	// Fetch a JWT token to authenticate user
 	FetchToken(c *gin.Context)
-	// Logout, i.e. delete the JWT token
+	// Second login step for users with two-factor authentication
+	FetchTokenSecondFactor(c *gin.Context)
+	// Redirect the user to the OpenID Connect provider to log in
+	OidcLogin(c *gin.Context)
+	// Callback of the OpenID Connect provider, opens a session and redirects to the application
+	OidcCallback(c *gin.Context)
+	// Get a new JWT token from a refresh token
+	Refresh(c *gin.Context)
+	// Logout, i.e. revoke the current session and delete the tokens
 	Logout(c *gin.Context)
 	// List all users (admin)
 	List(c *gin.Context)
 	// Check permission related to users
 	PermissionCheck(c *gin.Context)
+	// Get the profile of the current user
+	GetMe(c *gin.Context)
+	// Update the profile of the current user
+	UpdateMe(c *gin.Context)
+	// Get the storage used by the current user and their quota
+	GetUsage(c *gin.Context)
+	// Change the password of the current user, all their sessions are revoked
+	ChangePassword(c *gin.Context)
+	// Start enrolling a TOTP authenticator app
+	StartTotpEnrollment(c *gin.Context)
+	// Enable TOTP once the user proved their app works, returns recovery codes
+	ConfirmTotpEnrollment(c *gin.Context)
+	// Disable TOTP
+	DisableTotp(c *gin.Context)
+	// Replace the recovery codes of the current user
+	RegenerateRecoveryCodes(c *gin.Context)
+	// List the active sessions of the current user
+	ListSessions(c *gin.Context)
+	// Revoke a session of the current user
+	RevokeSession(c *gin.Context)
+	// Revoke all sessions of the current user
+	RevokeAllSessions(c *gin.Context)
+	// List the API keys of the current user
+	ListApiKeys(c *gin.Context)
+	// Create an API key for the current user, the key is only returned once
+	CreateApiKey(c *gin.Context)
+	// Revoke an API key of the current user
+	RevokeApiKey(c *gin.Context)
 }
 type userEndpoint struct {
 	common.EndpointGroup
-	userService services.UserService
+	userService          services.UserService
+	quotaService         services.QuotaService
+	apiKeyService        services.ApiKeyService
+	sessionService       services.SessionService
+	oidcService          services.OidcService
+	twoFactorService     services.TwoFactorService
+	invitationService    services.InvitationService
+	passwordResetService services.PasswordResetService
 }
 
 func NewUserEndpoint(
 	// Common dependencies
 	commonMiddlewares []gin.HandlerFunc,
 	permissionsManager common.PermissionsManager,
+	// Limits the requests checking credentials or tokens sent by email
+	authRateLimit gin.HandlerFunc,
 	// Service dependencies
 	userService services.UserService,
+	quotaService services.QuotaService,
+	apiKeyService services.ApiKeyService,
+	sessionService services.SessionService,
+	oidcService services.OidcService,
+	twoFactorService services.TwoFactorService,
+	invitationService services.InvitationService,
+	passwordResetService services.PasswordResetService,
 ) UserEndpoint {
-	userEndpoint := userEndpoint{userService: userService}
+	userEndpoint := userEndpoint{
+		userService:          userService,
+		quotaService:         quotaService,
+		apiKeyService:        apiKeyService,
+		sessionService:       sessionService,
+		oidcService:          oidcService,
+		twoFactorService:     twoFactorService,
+		invitationService:    invitationService,
+		passwordResetService: passwordResetService,
+	}
 
 	endpoint := common.NewEndpoint(
 		"Users",
 		"/user",
 		commonMiddlewares,
 		map[common.MethodPath][]gin.HandlerFunc{
-			{Method: "POST", Path: ""}:                {userEndpoint.Create},
-			{Method: "POST", Path: "/jwt"}:            {userEndpoint.FetchToken},
-			{Method: "POST", Path: "/logout"}:         {userEndpoint.Logout},
-			{Method: "GET", Path: ""}:                 {userEndpoint.List},
-			{Method: "GET", Path: "/can/:permission"}: {userEndpoint.PermissionCheck},
+			{Method: "POST", Path: ""}:                       {userEndpoint.Create},
+			{Method: "POST", Path: "/invitations"}:           {userEndpoint.Invite},
+			{Method: "POST", Path: "/invitations/accept"}:    {authRateLimit, userEndpoint.AcceptInvitation},
+			{Method: "POST", Path: "/password/forgot"}:       {authRateLimit, userEndpoint.ForgotPassword},
+			{Method: "POST", Path: "/password/reset"}:        {authRateLimit, userEndpoint.ResetPassword},
+			{Method: "POST", Path: "/jwt"}:                   {authRateLimit, userEndpoint.FetchToken},
+			{Method: "POST", Path: "/jwt/2fa"}:               {authRateLimit, userEndpoint.FetchTokenSecondFactor},
+			{Method: "GET", Path: "/oidc/login"}:             {userEndpoint.OidcLogin},
+			{Method: "GET", Path: "/oidc/callback"}:          {userEndpoint.OidcCallback},
+			{Method: "POST", Path: "/refresh"}:               {userEndpoint.Refresh},
+			{Method: "POST", Path: "/logout"}:                {userEndpoint.Logout},
+			{Method: "GET", Path: ""}:                        {userEndpoint.List},
+			{Method: "GET", Path: "/can/:permission"}:        {userEndpoint.PermissionCheck},
+			{Method: "GET", Path: "/me"}:                     {userEndpoint.GetMe},
+			{Method: "PATCH", Path: "/me"}:                   {userEndpoint.UpdateMe},
+			{Method: "GET", Path: "/me/usage"}:               {userEndpoint.GetUsage},
+			{Method: "PUT", Path: "/me/password"}:            {userEndpoint.ChangePassword},
+			{Method: "POST", Path: "/me/2fa/totp"}:           {userEndpoint.StartTotpEnrollment},
+			{Method: "POST", Path: "/me/2fa/totp/confirm"}:   {userEndpoint.ConfirmTotpEnrollment},
+			{Method: "DELETE", Path: "/me/2fa/totp"}:         {userEndpoint.DisableTotp},
+			{Method: "POST", Path: "/me/2fa/recovery-codes"}: {userEndpoint.RegenerateRecoveryCodes},
+			{Method: "GET", Path: "/sessions"}:               {userEndpoint.ListSessions},
+			{Method: "DELETE", Path: "/sessions"}:            {userEndpoint.RevokeAllSessions},
+			{Method: "DELETE", Path: "/sessions/:sessionId"}: {
+				middlewares.PathParamIdMiddleware("sessionId"),
+				userEndpoint.RevokeSession,
+			},
+			{Method: "GET", Path: "/me/apikeys"}:  {userEndpoint.ListApiKeys},
+			{Method: "POST", Path: "/me/apikeys"}: {userEndpoint.CreateApiKey},
+			{Method: "DELETE", Path: "/me/apikeys/:apiKeyId"}: {
+				middlewares.PathParamIdMiddleware("apiKeyId"),
+				userEndpoint.RevokeApiKey,
+			},
 		},
 		permissionsManager,
 	)
@@ -77,7 +179,7 @@ func (e *userEndpoint) Create(c *gin.Context) {
 		return
 	}
 
-	_, svcErr := e.userService.Create(registerUserBody.Email, registerUserBody.Password)
+	_, svcErr := e.userService.Create(registerUserBody.Email, registerUserBody.Password, false)
 	if svcErr != nil {
 		svcErr.Apply(c)
 		return
@@ -86,6 +188,102 @@ func (e *userEndpoint) Create(c *gin.Context) {
 	c.Status(http.StatusCreated)
 }
 
+type InviteBody struct {
+	Email string `json:"email"`
+	// Optional, album shared with the invited user
+	AlbumId string `json:"albumId"`
+	// Role of the invited user in the album, viewer by default
+	Role model.AlbumRole `json:"role,omitempty"`
+	// Before roles, gives the editor role
+	CanEdit bool `json:"canEdit"`
+}
+
+func (e *userEndpoint) Invite(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	var inviteBody InviteBody
+	if err := c.BindJSON(&inviteBody); err != nil {
+		return
+	}
+	var albumId *primitive.ObjectID
+	if inviteBody.AlbumId != "" {
+		id, err := primitive.ObjectIDFromHex(inviteBody.AlbumId)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid album id"})
+			return
+		}
+		albumId = &id
+	}
+
+	if !e.GetPermissionsManager().CanInviteUser(user, albumId) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	role := inviteBody.Role
+	if role == "" {
+		role = model.AlbumRoleFromCanEdit(inviteBody.CanEdit)
+	}
+	if svcErr := e.invitationService.Invite(user, inviteBody.Email, albumId, role); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
+
+type TokenPasswordBody struct {
+	// Token received by email
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (e *userEndpoint) AcceptInvitation(c *gin.Context) {
+	var acceptBody TokenPasswordBody
+	if err := c.BindJSON(&acceptBody); err != nil {
+		return
+	}
+
+	if _, svcErr := e.invitationService.Accept(acceptBody.Token, acceptBody.Password); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
+
+type ForgotPasswordBody struct {
+	Email string `json:"email"`
+}
+
+func (e *userEndpoint) ForgotPassword(c *gin.Context) {
+	var forgotPasswordBody ForgotPasswordBody
+	if err := c.BindJSON(&forgotPasswordBody); err != nil {
+		return
+	}
+
+	if svcErr := e.passwordResetService.RequestReset(forgotPasswordBody.Email); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	// Same answer whether the user exists or not
+	c.Status(http.StatusAccepted)
+}
+
+func (e *userEndpoint) ResetPassword(c *gin.Context) {
+	var resetBody TokenPasswordBody
+	if err := c.BindJSON(&resetBody); err != nil {
+		return
+	}
+
+	if svcErr := e.passwordResetService.Reset(resetBody.Token, resetBody.Password); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 type FetchJWTBody struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -98,21 +296,147 @@ func (e *userEndpoint) FetchToken(c *gin.Context) {
 		return
 	}
 
-	jwt, err := e.userService.GenerateToken(fetchJWTBody.Email, fetchJWTBody.Password)
+	tokens, mfaToken, err := e.userService.Login(fetchJWTBody.Email, fetchJWTBody.Password, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		err.Apply(c)
 		return
 	}
+	if mfaToken != nil {
+		// No session yet, the code must be sent to /user/jwt/2fa with this token
+		c.JSON(http.StatusOK, gin.H{"mfaRequired": true, "mfaToken": mfaToken})
+		return
+	}
 
-	c.SetCookie("jwt", *jwt, 3600, "/", internal.API_DOMAIN, !internal.DEBUG, true)
-	c.SetCookie("user", fetchJWTBody.Email, 3600, "/", internal.API_DOMAIN, !internal.DEBUG, false)
-	c.JSON(http.StatusOK, gin.H{"jwt": jwt})
+	setSessionCookies(c, tokens)
+	c.SetCookie("user", fetchJWTBody.Email, int(security.REFRESH_TOKEN_DURATION.Seconds()), "/", internal.API_DOMAIN, !internal.DEBUG, false)
+	c.JSON(http.StatusOK, tokens)
+}
+
+// The login state only travels between the login and callback endpoints
+const (
+	OIDC_STATE_COOKIE = "oidc_state"
+	OIDC_STATE_PATH   = "/user/oidc"
+	// Time given to the user to log in at the provider
+	OIDC_STATE_DURATION = 10 * 60
+)
+
+func (e *userEndpoint) OidcLogin(c *gin.Context) {
+	url, state, svcErr := e.oidcService.StartLogin()
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	cookie := strings.Join([]string{state.State, state.Nonce, state.CodeVerifier}, ".")
+	c.SetCookie(OIDC_STATE_COOKIE, cookie, OIDC_STATE_DURATION, OIDC_STATE_PATH, internal.API_DOMAIN, !internal.DEBUG, true)
+	c.Redirect(http.StatusFound, *url)
+}
+
+func (e *userEndpoint) OidcCallback(c *gin.Context) {
+	cookie, err := c.Cookie(OIDC_STATE_COOKIE)
+	c.SetCookie(OIDC_STATE_COOKIE, "", -1, OIDC_STATE_PATH, internal.API_DOMAIN, !internal.DEBUG, true)
+	parts := strings.Split(cookie, ".")
+	if err != nil || len(parts) != 3 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "login expired, please try again"})
+		return
+	}
+	state := services.OidcLoginState{State: parts[0], Nonce: parts[1], CodeVerifier: parts[2]}
+	if c.Query("state") != state.State {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid login state"})
+		return
+	}
+	if providerError := c.Query("error"); providerError != "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": providerError})
+		return
+	}
+
+	user, tokens, svcErr := e.oidcService.FinishLogin(c.Query("code"), &state, c.Request.UserAgent(), c.ClientIP())
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+
+	setSessionCookies(c, tokens)
+	c.SetCookie("user", user.Email, int(security.REFRESH_TOKEN_DURATION.Seconds()), "/", internal.API_DOMAIN, !internal.DEBUG, false)
+	c.Redirect(http.StatusFound, internal.OIDC_POST_LOGIN_URL)
+}
+
+type SecondFactorBody struct {
+	MfaToken string `json:"mfaToken"`
+	// TOTP code or recovery code
+	Code string `json:"code"`
+}
+
+func (e *userEndpoint) FetchTokenSecondFactor(c *gin.Context) {
+	var secondFactorBody SecondFactorBody
+	if err := c.BindJSON(&secondFactorBody); err != nil {
+		return
+	}
+
+	user, tokens, err := e.twoFactorService.CompleteLogin(secondFactorBody.MfaToken, secondFactorBody.Code, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		err.Apply(c)
+		return
+	}
+
+	setSessionCookies(c, tokens)
+	c.SetCookie("user", user.Email, int(security.REFRESH_TOKEN_DURATION.Seconds()), "/", internal.API_DOMAIN, !internal.DEBUG, false)
+	c.JSON(http.StatusOK, tokens)
+}
+
+type RefreshBody struct {
+	// Optional, the refresh token cookie is used otherwise
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (e *userEndpoint) Refresh(c *gin.Context) {
+	var refreshBody RefreshBody
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&refreshBody); err != nil {
+			return
+		}
+	}
+	refreshToken := refreshBody.RefreshToken
+	if refreshToken == "" {
+		refreshToken, _ = c.Cookie(REFRESH_TOKEN_COOKIE)
+	}
+	if refreshToken == "" {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := e.sessionService.Refresh(refreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		err.Apply(c)
+		return
+	}
+
+	setSessionCookies(c, tokens)
+	c.JSON(http.StatusOK, tokens)
 }
 
 func (e *userEndpoint) Logout(c *gin.Context) {
+	// Revoke the session server side, from the access token or from the refresh token once the access token expired
+	if session := utils.GetSession(c); session != nil {
+		e.sessionService.Revoke(&session.UserId, &session.Id)
+	} else if refreshToken, err := c.Cookie(REFRESH_TOKEN_COOKIE); err == nil && refreshToken != "" {
+		e.sessionService.RevokeByRefreshToken(refreshToken)
+	}
+	clearSessionCookies(c)
+	c.Status(http.StatusOK)
+}
+
+// The refresh token is only sent to the user endpoints
+const REFRESH_TOKEN_COOKIE = "refresh_token"
+
+func setSessionCookies(c *gin.Context, tokens *model.SessionTokens) {
+	c.SetCookie("jwt", tokens.AccessToken, int(tokens.ExpiresIn), "/", internal.API_DOMAIN, !internal.DEBUG, true)
+	c.SetCookie(REFRESH_TOKEN_COOKIE, tokens.RefreshToken, int(security.REFRESH_TOKEN_DURATION.Seconds()), "/user", internal.API_DOMAIN, !internal.DEBUG, true)
+}
+
+func clearSessionCookies(c *gin.Context) {
 	c.SetCookie("jwt", "", -1, "/", internal.API_DOMAIN, !internal.DEBUG, true)
+	c.SetCookie(REFRESH_TOKEN_COOKIE, "", -1, "/user", internal.API_DOMAIN, !internal.DEBUG, true)
 	c.SetCookie("user", "", -1, "/", internal.API_DOMAIN, !internal.DEBUG, false)
-	c.Status(http.StatusOK)
 }
 
 func (e *userEndpoint) List(c *gin.Context) {
@@ -162,3 +486,318 @@ func (e *userEndpoint) PermissionCheck(c *gin.Context) {
 	// By default, not authorized
 	c.AbortWithStatus(http.StatusUnauthorized)
 }
+
+func (e *userEndpoint) GetMe(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	profile := *user
+	profile.PasswordHash = ""
+	c.IndentedJSON(http.StatusOK, profile)
+}
+
+type UpdateMeBody struct {
+	Email string `json:"email"`
+	// Required to change the email
+	CurrentPassword string `json:"currentPassword"`
+}
+
+func (e *userEndpoint) UpdateMe(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanEditProfile(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var updateMeBody UpdateMeBody
+	if err := c.BindJSON(&updateMeBody); err != nil {
+		return
+	}
+
+	profile := *user
+	if updateMeBody.Email != "" {
+		updated, svcErr := e.userService.ChangeEmail(&user.Id, updateMeBody.CurrentPassword, updateMeBody.Email)
+		if svcErr != nil {
+			svcErr.Apply(c)
+			return
+		}
+		profile = *updated
+		c.SetCookie("user", profile.Email, int(security.REFRESH_TOKEN_DURATION.Seconds()), "/", internal.API_DOMAIN, !internal.DEBUG, false)
+	}
+	profile.PasswordHash = ""
+	c.IndentedJSON(http.StatusOK, profile)
+}
+
+func (e *userEndpoint) GetUsage(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	usage, svcErr := e.quotaService.GetUsage(&user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, usage)
+}
+
+type ChangePasswordBody struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+func (e *userEndpoint) ChangePassword(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanChangePassword(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var changePasswordBody ChangePasswordBody
+	if err := c.BindJSON(&changePasswordBody); err != nil {
+		return
+	}
+
+	if svcErr := e.userService.ChangePassword(&user.Id, changePasswordBody.CurrentPassword, changePasswordBody.NewPassword); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	// The current session has been revoked as well
+	clearSessionCookies(c)
+	c.Status(http.StatusOK)
+}
+
+type TwoFactorCodeBody struct {
+	// TOTP code or recovery code
+	Code string `json:"code"`
+}
+
+func (e *userEndpoint) StartTotpEnrollment(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageTwoFactor(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	enrollment, svcErr := e.twoFactorService.StartTotpEnrollment(&user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+func (e *userEndpoint) ConfirmTotpEnrollment(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageTwoFactor(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var codeBody TwoFactorCodeBody
+	if err := c.BindJSON(&codeBody); err != nil {
+		return
+	}
+
+	recoveryCodes, svcErr := e.twoFactorService.ConfirmTotpEnrollment(&user.Id, codeBody.Code)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": recoveryCodes})
+}
+
+func (e *userEndpoint) DisableTotp(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageTwoFactor(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var codeBody TwoFactorCodeBody
+	if err := c.BindJSON(&codeBody); err != nil {
+		return
+	}
+
+	if svcErr := e.twoFactorService.DisableTotp(&user.Id, codeBody.Code); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (e *userEndpoint) RegenerateRecoveryCodes(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageTwoFactor(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var codeBody TwoFactorCodeBody
+	if err := c.BindJSON(&codeBody); err != nil {
+		return
+	}
+
+	recoveryCodes, svcErr := e.twoFactorService.RegenerateRecoveryCodes(&user.Id, codeBody.Code)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": recoveryCodes})
+}
+
+func (e *userEndpoint) ListSessions(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageSessions(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	sessions, svcErr := e.sessionService.GetAllForUser(&user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	if current := utils.GetSession(c); current != nil {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].Id == current.Id
+		}
+	}
+	c.IndentedJSON(http.StatusOK, sessions)
+}
+
+func (e *userEndpoint) RevokeSession(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageSessions(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	sessionId := utils.GetIdFromContext("sessionId", c)
+	if svcErr := e.sessionService.Revoke(&user.Id, &sessionId); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (e *userEndpoint) RevokeAllSessions(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageSessions(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if svcErr := e.sessionService.RevokeAll(&user.Id); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	clearSessionCookies(c)
+	c.Status(http.StatusOK)
+}
+
+func (e *userEndpoint) ListApiKeys(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageApiKeys(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	apiKeys, svcErr := e.apiKeyService.GetAllForUser(&user.Id)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, apiKeys)
+}
+
+type CreateApiKeyBody struct {
+	Name   string              `json:"name"`
+	Scopes []model.ApiKeyScope `json:"scopes"`
+}
+
+func (e *userEndpoint) CreateApiKey(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageApiKeys(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var createApiKeyBody CreateApiKeyBody
+	if err := c.BindJSON(&createApiKeyBody); err != nil {
+		return
+	}
+
+	apiKey, key, svcErr := e.apiKeyService.Create(&user.Id, createApiKeyBody.Name, createApiKeyBody.Scopes)
+	if svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"apiKey": apiKey, "key": key})
+}
+
+func (e *userEndpoint) RevokeApiKey(c *gin.Context) {
+	user, err := utils.GetUser(c)
+	if err != nil {
+		return
+	}
+
+	if !e.GetPermissionsManager().CanManageApiKeys(user, utils.GetApiKey(c)) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	apiKeyId := utils.GetIdFromContext("apiKeyId", c)
+	if svcErr := e.apiKeyService.Revoke(&user.Id, &apiKeyId); svcErr != nil {
+		svcErr.Apply(c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
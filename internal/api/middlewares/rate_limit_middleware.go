@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"data-storage-svc/internal/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limit the requests of each client IP address, answers 429 once the client's bucket is empty. Endpoints sharing a
+// name share the buckets.
+func RateLimitMiddleware(store ratelimit.Store, name string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if allowed, retryAfter := store.Take(name+":"+ctx.ClientIP(), limit); !allowed {
+			abortTooManyRequests(ctx, retryAfter)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func abortTooManyRequests(ctx *gin.Context, retryAfter time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+}
@@ -2,21 +2,98 @@ package middlewares
 
 import (
 	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/ratelimit"
 	"data-storage-svc/internal/repository"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SharedLinkMiddleware(sharedLinkRepository repository.SharedLinkRepository) gin.HandlerFunc {
+// Header carrying the token of a shared link
+const SHARE_TOKEN_HEADER = "X-Share-Token"
+
+// Path parameter carrying the token of a shared link, for the routes under /s/:shareToken
+const SHARE_TOKEN_PARAM = "shareToken"
+
+// Resolve the shared link of the request, if any. The token is taken from the path, the X-Share-Token header or the
+// token query parameter, in this order. Revoked, expired or used up links are answered 410, password protected links
+// are only resolved once unlocked. A use is counted once per visit: the first request of a visitor starts a link
+// session, the following ones only check the link. Only unknown tokens count against the limit, so that browsing an
+// album through a link is never limited.
+func SharedLinkMiddleware(sharedLinkRepository repository.SharedLinkRepository, tokenModule security.TokenModule, store ratelimit.Store, invalidTokenLimit ratelimit.Limit) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check if we have a token in the request
-		sharedLinkToken := c.Query("token")
-		if sharedLinkToken != "" {
-			link, err := sharedLinkRepository.GetByToken(sharedLinkToken)
-			// Add it to the context if any
-			if err == nil && link != nil {
-				c.Set(common.SHARED_LINK, link)
-			}
+		sharedLinkToken := getSharedLinkToken(c)
+		if sharedLinkToken == "" {
+			return
+		}
+		key := "sharedLink:" + c.ClientIP()
+		if allowed, retryAfter := store.Peek(key, invalidTokenLimit); !allowed {
+			abortTooManyRequests(c, retryAfter)
+			return
+		}
+		link, err := sharedLinkRepository.GetByToken(sharedLinkToken)
+		if err != nil {
+			store.Take(key, invalidTokenLimit)
+			return
+		}
+		now := time.Now()
+		if !link.IsActive(now) {
+			abortLinkGone(c)
+			return
+		}
+		// The visit already started, when the link was unlocked or on a previous request
+		if hasSession(c, tokenModule, link) {
+			c.Set(common.SHARED_LINK, link)
+			return
+		}
+		if !link.IsUsable(now) {
+			abortLinkGone(c)
+			return
+		}
+		if link.PasswordProtected {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "this shared link needs a password", "passwordRequired": true})
+			return
+		}
+		// A new visit counts the use, unless another request used the link up in the meantime
+		link, err = sharedLinkRepository.Use(sharedLinkToken, now)
+		if err != nil {
+			abortLinkGone(c)
+			return
+		}
+		// Clients without cookies start a new visit on each request
+		if sessionToken, err := tokenModule.CreateLinkSessionToken(&link.Id); err == nil {
+			common.SetLinkSessionCookie(c, link.Id, sessionToken)
 		}
+		// Add it to the context
+		c.Set(common.SHARED_LINK, link)
+	}
+}
+
+// The query parameter is kept for existing clients, it ends up in access logs and browser history
+func getSharedLinkToken(c *gin.Context) string {
+	if token := c.Param(SHARE_TOKEN_PARAM); token != "" {
+		return token
+	}
+	if token := c.GetHeader(SHARE_TOKEN_HEADER); token != "" {
+		return token
+	}
+	return c.Query("token")
+}
+
+func abortLinkGone(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusGone, gin.H{"error": "this shared link has expired or has been revoked"})
+}
+
+// Check if the visitor started a visit of the link lately, by unlocking it or by a previous request
+func hasSession(c *gin.Context, tokenModule security.TokenModule, link *model.SharedLink) bool {
+	sessionToken, err := c.Cookie(common.LinkSessionCookie(link.Id))
+	if err != nil {
+		return false
 	}
+	sharedLinkId, err := tokenModule.ParseLinkSessionToken(sessionToken)
+	return err == nil && *sharedLinkId == link.Id
 }
@@ -0,0 +1,71 @@
+package middlewares_test
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/api/middlewares"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Requests made with a link, each answered 200 once the link is resolved
+func sharedLinkRequest(router *gin.Engine, token string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/album", nil)
+	request.Header.Set(middlewares.SHARE_TOKEN_HEADER, token)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestSharedLinkUsesCountedPerVisit(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	link := &model.SharedLink{Id: primitive.NewObjectID(), Token: "link", ExpirationDate: tomorrow, MaxUses: 1}
+	usedUp := &model.SharedLink{Id: link.Id, Token: "link", ExpirationDate: tomorrow, MaxUses: 1, Uses: 1}
+	sharedLinkRepository := &mocks.SharedLinkRepository{}
+	sharedLinkRepository.On("GetByToken", "link").Return(link, nil).Once()
+	sharedLinkRepository.On("GetByToken", "link").Return(usedUp, nil)
+	sharedLinkRepository.On("Use", "link", mock.Anything).Return(usedUp, nil).Once()
+	sharedLinkRepository.On("Use", "link", mock.Anything).Return((*model.SharedLink)(nil), mongo.ErrNoDocuments)
+	tokenModule := &mocks.TokenModule{}
+	tokenModule.On("CreateLinkSessionToken", &link.Id).Return("visit", nil)
+	tokenModule.On("ParseLinkSessionToken", "visit").Return(&link.Id, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.SharedLinkMiddleware(sharedLinkRepository, tokenModule, ratelimit.NewMemoryStore(time.Now), ratelimit.PerMinute(10)))
+	router.GET("/album", func(c *gin.Context) {
+		if _, exists := c.Get(common.SHARED_LINK); exists {
+			c.Status(http.StatusOK)
+		}
+	})
+
+	// The first request starts the visit and takes the only use of the link
+	first := sharedLinkRequest(router, "link", nil)
+	assert.Equal(t, http.StatusOK, first.Code)
+	cookies := first.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, common.LinkSessionCookie(link.Id), cookies[0].Name)
+
+	// The following requests of the visit go on without counting
+	for range 5 {
+		assert.Equal(t, http.StatusOK, sharedLinkRequest(router, "link", cookies).Code)
+	}
+	sharedLinkRepository.AssertNumberOfCalls(t, "Use", 1)
+
+	// Another visitor finds the link used up
+	assert.Equal(t, http.StatusGone, sharedLinkRequest(router, "link", nil).Code)
+	sharedLinkRepository.AssertNumberOfCalls(t, "Use", 1)
+}
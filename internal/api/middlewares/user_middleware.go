@@ -4,42 +4,62 @@ import (
 	"context"
 	"data-storage-svc/internal/api/common"
 	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 )
 
-// Decode a fetch user in DB if any, do NOT reject the request if no user is found
-func UserMiddleware(userRepository repository.UserRepository) gin.HandlerFunc {
+// Do not write the last used time of an API key on every single request
+const API_KEY_LAST_USED_PRECISION = time.Minute
+
+// Decode a fetch user in DB if any, do NOT reject the request if no user is found. The user is authenticated with a JWT,
+// from the jwt cookie or the Authorization: Bearer header, or with a personal API key from the Authorization header.
+func UserMiddleware(userRepository repository.UserRepository, apiKeyRepository repository.ApiKeyRepository, sessionRepository repository.SessionRepository, tokenModule security.TokenModule) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Check if a jwt is embedded in the request
-		authCookie, err := ctx.Cookie("jwt")
-		if err != nil || authCookie == "" {
-			ctx.Next()
-			return
+		// Check if a token is embedded in the request, the Authorization header wins over the cookie
+		authToken := ""
+		if authorization := ctx.GetHeader("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+			authToken = strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+		} else if authCookie, err := ctx.Cookie("jwt"); err == nil {
+			authToken = authCookie
 		}
-
-		// Parse the jwt
-		token, err := jwt.Parse(authCookie, func(token *jwt.Token) (interface{}, error) {
-			return security.GetSecretKey(), nil
-		})
-
-		if err != nil || !token.Valid {
-			// The token is not valid, no user to get
+		if authToken == "" {
 			ctx.Next()
 			return
 		}
 
-		// JWT is valid, extract email claim from the token
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			email := claims["email"].(string)
-			user, err := userRepository.GetByEmail(email)
-			if err != nil {
-				// Couldn't get the user from the JWT, maybe it was delete recently, after the token was generated
-				ctx.Next()
+		var user *model.User
+		if security.IsApiKey(authToken) {
+			apiKey, apiKeyUser := authenticateApiKey(authToken, userRepository, apiKeyRepository)
+			if apiKey == nil {
+				// Scripts explicitly sent a key, tell them it is not valid rather than proceeding anonymously
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+				return
+			}
+			if !isSafeMethod(ctx.Request.Method) && !apiKey.HasScope(model.API_KEY_SCOPE_WRITE) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key is not allowed to modify data"})
 				return
 			}
+			if !apiKey.HasScope(model.API_KEY_SCOPE_READ) && !apiKey.HasScope(model.API_KEY_SCOPE_WRITE) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key has no scope"})
+				return
+			}
+			ctx.Set(common.API_KEY, apiKey)
+			user = apiKeyUser
+		} else {
+			var session *model.Session
+			user, session = authenticateJwt(authToken, tokenModule, userRepository, sessionRepository)
+			if session != nil {
+				ctx.Set(common.SESSION, session)
+			}
+		}
+
+		if user != nil {
 			// Store the user in context
 			ctx.Set(common.USER, user)
 			newCtx := context.WithValue(ctx.Request.Context(), common.USER, user)
@@ -48,3 +68,44 @@ func UserMiddleware(userRepository repository.UserRepository) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+func authenticateJwt(authToken string, tokenModule security.TokenModule, userRepository repository.UserRepository, sessionRepository repository.SessionRepository) (*model.User, *model.Session) {
+	userId, sessionId, err := tokenModule.ParseToken(authToken)
+	if err != nil {
+		// The token is not valid, no user to get
+		return nil, nil
+	}
+	user, err := userRepository.GetById(userId)
+	if err != nil || user.Disabled {
+		// Couldn't get the user from the JWT, maybe it was delete recently, after the token was generated
+		return nil, nil
+	}
+	// The session must still exist, so revoking it takes effect right away
+	session, err := sessionRepository.GetById(sessionId)
+	if err != nil || session.UserId != user.Id {
+		return nil, nil
+	}
+	return user, session
+}
+
+func authenticateApiKey(key string, userRepository repository.UserRepository, apiKeyRepository repository.ApiKeyRepository) (*model.ApiKey, *model.User) {
+	apiKey, err := apiKeyRepository.GetByHash(security.HashApiKey(key))
+	if err != nil || apiKey.Revoked {
+		return nil, nil
+	}
+	user, err := userRepository.GetById(&apiKey.UserId)
+	if err != nil || user.Disabled {
+		return nil, nil
+	}
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > API_KEY_LAST_USED_PRECISION {
+		if err := apiKeyRepository.SetLastUsed(&apiKey.Id, now); err != nil {
+			slog.Error("couldn't update api key last used time", "apiKeyId", apiKey.Id.Hex(), "error", err)
+		}
+	}
+	return apiKey, user
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// All API keys start with this prefix, to tell them apart from JWTs in the Authorization header
+const API_KEY_PREFIX = "dsk_"
+
+// Number of characters of the key kept in DB to recognize it
+const API_KEY_DISPLAYED_LENGTH = 12
+
+// Generate a new random API key, along with its displayable prefix
+func GenerateApiKey() (string, string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return "", "", err
+	}
+	key := API_KEY_PREFIX + secret
+	return key, key[:API_KEY_DISPLAYED_LENGTH], nil
+}
+
+// Hash of an API key as stored in DB
+func HashApiKey(key string) string {
+	return hashSecret(key)
+}
+
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, API_KEY_PREFIX)
+}
+
+// 32 random bytes, URL safe
+func randomSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Secrets generated by the server are random and long, a fast hash is enough
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
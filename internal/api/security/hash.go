@@ -1,25 +1,144 @@
 package security
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HASH_BCRYPT   = "bcrypt"
+	HASH_ARGON2ID = "argon2id"
+)
+
+const (
+	ARGON2_SALT_LENGTH = 16
+	ARGON2_KEY_LENGTH  = 32
+)
 
 type HashModule interface {
 	HashPassword(password string) (string, error)
 	VerifyPassword(password, hash string) bool
+	// Check if a hash was made with another algorithm or other parameters than the current ones, so the password
+	// should be hashed again the next time it is known
+	NeedsRehash(hash string) bool
+}
+
+// Algorithm and cost of new password hashes. Hashes made with any algorithm are verified.
+type HashConfig struct {
+	// bcrypt or argon2id
+	Algorithm  string
+	BcryptCost int
+	// Memory in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
 }
 
 type hashModule struct {
+	config HashConfig
 }
 
-func NewHashModule() HashModule {
-	return hashModule{}
+func NewHashModule(config HashConfig) (HashModule, error) {
+	switch config.Algorithm {
+	case HASH_BCRYPT:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost should be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case HASH_ARGON2ID:
+		if config.Argon2Memory == 0 || config.Argon2Iterations == 0 || config.Argon2Parallelism == 0 {
+			return nil, fmt.Errorf("argon2id memory, iterations and parallelism should be positive")
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm [%s]", config.Algorithm)
+	}
+	return hashModule{config}, nil
 }
 
 func (h hashModule) HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if h.config.Algorithm == HASH_ARGON2ID {
+		salt := make([]byte, ARGON2_SALT_LENGTH)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		params := argon2Params{h.config.Argon2Memory, h.config.Argon2Iterations, h.config.Argon2Parallelism}
+		return params.encode(salt, params.key(password, salt)), nil
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
 	return string(bytes), err
 }
 
 func (h hashModule) VerifyPassword(password, hash string) bool {
+	if strings.HasPrefix(hash, "$"+HASH_ARGON2ID+"$") {
+		params, salt, key, err := decodeArgon2(hash)
+		if err != nil {
+			return false
+		}
+		return subtle.ConstantTimeCompare(key, params.key(password, salt)) == 1
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+func (h hashModule) NeedsRehash(hash string) bool {
+	if h.config.Algorithm == HASH_ARGON2ID {
+		params, _, _, err := decodeArgon2(hash)
+		return err != nil || params != argon2Params{h.config.Argon2Memory, h.config.Argon2Iterations, h.config.Argon2Parallelism}
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.config.BcryptCost
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func (p argon2Params) key(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, ARGON2_KEY_LENGTH)
+}
+
+// PHC string format: $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func (p argon2Params) encode(salt []byte, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", HASH_ARGON2ID, argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != HASH_ARGON2ID {
+		return params, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %s", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	return params, salt, key, nil
+}
+
+// Generate a random password, for users whose password is reset by an admin
+func GeneratePassword() (string, error) {
+	password := make([]byte, 12)
+	if _, err := rand.Read(password); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(password), nil
+}
@@ -0,0 +1,43 @@
+package security_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashAlgorithms(t *testing.T) {
+	bcryptModule, err := security.NewHashModule(security.HashConfig{Algorithm: security.HASH_BCRYPT, BcryptCost: 4})
+	assert.NoError(t, err)
+	argon2Module, err := security.NewHashModule(security.HashConfig{Algorithm: security.HASH_ARGON2ID, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1})
+	assert.NoError(t, err)
+
+	bcryptHash, err := bcryptModule.HashPassword("azertyuiop")
+	assert.NoError(t, err)
+	argon2Hash, err := argon2Module.HashPassword("azertyuiop")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	// Both hashes are verified whatever the configured algorithm
+	for _, module := range []security.HashModule{bcryptModule, argon2Module} {
+		assert.True(t, module.VerifyPassword("azertyuiop", bcryptHash))
+		assert.True(t, module.VerifyPassword("azertyuiop", argon2Hash))
+		assert.False(t, module.VerifyPassword("qwertyuiop", bcryptHash))
+		assert.False(t, module.VerifyPassword("qwertyuiop", argon2Hash))
+	}
+
+	// Hashes made with another algorithm or cost need a rehash
+	assert.False(t, bcryptModule.NeedsRehash(bcryptHash))
+	assert.True(t, bcryptModule.NeedsRehash(argon2Hash))
+	assert.False(t, argon2Module.NeedsRehash(argon2Hash))
+	assert.True(t, argon2Module.NeedsRehash(bcryptHash))
+	strongerBcrypt, _ := security.NewHashModule(security.HashConfig{Algorithm: security.HASH_BCRYPT, BcryptCost: 5})
+	assert.True(t, strongerBcrypt.NeedsRehash(bcryptHash))
+	strongerArgon2, _ := security.NewHashModule(security.HashConfig{Algorithm: security.HASH_ARGON2ID, Argon2Memory: 2048, Argon2Iterations: 1, Argon2Parallelism: 1})
+	assert.True(t, strongerArgon2.NeedsRehash(argon2Hash))
+
+	_, err = security.NewHashModule(security.HashConfig{Algorithm: "md5"})
+	assert.Error(t, err)
+}
@@ -1,69 +1,146 @@
 package security
 
 import (
-	"data-storage-svc/internal"
-	"data-storage-svc/internal/utils"
+	"errors"
 	"log/slog"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Access tokens are short-lived, sessions are kept alive with refresh tokens
+const ACCESS_TOKEN_DURATION = 15 * time.Minute
+
+// Time given to users to type their second factor after their password
+const MFA_TOKEN_DURATION = 5 * time.Minute
+
+// Time a password protected shared link stays unlocked
+const LINK_SESSION_DURATION = time.Hour
+
+const (
+	// Issuer of the tokens
+	TOKEN_ISSUER = "data-storage-svc"
+	// Tokens are only meant for this API
+	TOKEN_AUDIENCE = "data-storage-api"
+	// Audience of the tokens proving the password of a user awaiting their second factor, they give no access
+	MFA_TOKEN_AUDIENCE = "data-storage-mfa"
+	// Audience of the tokens proving the password of a shared link, the link is identified by the subject
+	LINK_SESSION_AUDIENCE = "data-storage-link"
+)
+
+// Claims of an access token, the user is identified by the subject
+type AccessClaims struct {
+	SessionId string `json:"sid"`
+	jwt.StandardClaims
+}
+
 type TokenModule interface {
-	CreateToken(Email *string) (string, error)
+	// Create a signed access token for a user session
+	CreateToken(userId *primitive.ObjectID, sessionId *primitive.ObjectID) (string, error)
+	// Verify an access token and extract the user and session ids
+	ParseToken(token string) (*primitive.ObjectID, *primitive.ObjectID, error)
+	// Create a token for a user who passed the password step, to be exchanged with their second factor
+	CreateMfaToken(userId *primitive.ObjectID) (string, error)
+	// Verify a second factor token and extract the user id
+	ParseMfaToken(token string) (*primitive.ObjectID, error)
+	// Create a token for a visitor who gave the password of a shared link
+	CreateLinkSessionToken(sharedLinkId *primitive.ObjectID) (string, error)
+	// Verify a shared link session token and extract the shared link id
+	ParseLinkSessionToken(token string) (*primitive.ObjectID, error)
 }
 
 type tokenModule struct {
+	keyring *Keyring
 }
 
-func NewTokenModule() TokenModule {
-	return tokenModule{}
+func NewTokenModule(keyring *Keyring) TokenModule {
+	return tokenModule{keyring}
 }
 
-var secretKey []byte = nil
+func (t tokenModule) CreateToken(userId *primitive.ObjectID, sessionId *primitive.ObjectID) (string, error) {
+	standardClaims, err := newStandardClaims(userId, TOKEN_AUDIENCE, ACCESS_TOKEN_DURATION)
+	if err != nil {
+		return "", err
+	}
+	return t.sign(AccessClaims{SessionId: sessionId.Hex(), StandardClaims: *standardClaims})
+}
 
-func (t tokenModule) CreateToken(Email *string) (string, error) {
-	// Create the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
-		jwt.MapClaims{
-			"email": Email,
-			"exp":   time.Now().Add(time.Hour * 24).Unix(),
-		})
+func (t tokenModule) ParseToken(token string) (*primitive.ObjectID, *primitive.ObjectID, error) {
+	var claims AccessClaims
+	userId, err := t.parse(token, &claims, &claims.StandardClaims, TOKEN_AUDIENCE)
+	if err != nil {
+		return nil, nil, err
+	}
+	sessionId, err := primitive.ObjectIDFromHex(claims.SessionId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return userId, &sessionId, nil
+}
 
-	// Sign the token
-	tokenString, err := token.SignedString(GetSecretKey())
+func (t tokenModule) CreateMfaToken(userId *primitive.ObjectID) (string, error) {
+	claims, err := newStandardClaims(userId, MFA_TOKEN_AUDIENCE, MFA_TOKEN_DURATION)
 	if err != nil {
-		slog.Debug("Couldn't sign the JWT", "err", err)
 		return "", err
 	}
-	return tokenString, nil
+	return t.sign(claims)
 }
 
-func GetSecretKey() []byte {
-	if secretKey == nil {
-		loadedKey, err := loadSecretKey()
-		if err != nil {
-			slog.Error("Couldn't load the JWT secret key", "err", err)
-			panic(err)
-		}
-		secretKey = loadedKey
+func (t tokenModule) ParseMfaToken(token string) (*primitive.ObjectID, error) {
+	var claims jwt.StandardClaims
+	return t.parse(token, &claims, &claims, MFA_TOKEN_AUDIENCE)
+}
+
+func (t tokenModule) CreateLinkSessionToken(sharedLinkId *primitive.ObjectID) (string, error) {
+	claims, err := newStandardClaims(sharedLinkId, LINK_SESSION_AUDIENCE, LINK_SESSION_DURATION)
+	if err != nil {
+		return "", err
 	}
-	return secretKey
+	return t.sign(claims)
 }
 
-func loadSecretKey() ([]byte, error) {
-	secretKeyFilePath, err := utils.GetDataDir(internal.JWT_KEY)
+func (t tokenModule) ParseLinkSessionToken(token string) (*primitive.ObjectID, error) {
+	var claims jwt.StandardClaims
+	return t.parse(token, &claims, &claims, LINK_SESSION_AUDIENCE)
+}
+
+func newStandardClaims(subject *primitive.ObjectID, audience string, duration time.Duration) (*jwt.StandardClaims, error) {
+	now := time.Now()
+	tokenId, err := randomSecret()
 	if err != nil {
-		slog.Debug("Cannot load secret key")
 		return nil, err
 	}
+	return &jwt.StandardClaims{
+		Subject:   subject.Hex(),
+		Issuer:    TOKEN_ISSUER,
+		Audience:  audience,
+		Id:        tokenId,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(duration).Unix(),
+	}, nil
+}
 
-	loadedKey, err := os.ReadFile(secretKeyFilePath)
+func (t tokenModule) sign(claims jwt.Claims) (string, error) {
+	tokenString, err := t.keyring.Sign(claims)
+	if err != nil {
+		slog.Debug("Couldn't sign the JWT", "err", err)
+		return "", err
+	}
+	return tokenString, nil
+}
+
+// Verify a token, its issuer and audience, and extract the id of its subject
+func (t tokenModule) parse(token string, claims jwt.Claims, standardClaims *jwt.StandardClaims, audience string) (*primitive.ObjectID, error) {
+	if err := t.keyring.Parse(token, claims); err != nil {
+		return nil, err
+	}
+	if !standardClaims.VerifyIssuer(TOKEN_ISSUER, true) || !standardClaims.VerifyAudience(audience, true) {
+		return nil, errors.New("token not issued for this use")
+	}
+	userId, err := primitive.ObjectIDFromHex(standardClaims.Subject)
 	if err != nil {
-		slog.Debug("Cannot read secretKey file content")
 		return nil, err
 	}
-	slog.Debug("JWT secret key successfully loaded")
-	return loadedKey, nil
+	return &userId, nil
 }
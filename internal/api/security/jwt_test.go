@@ -0,0 +1,227 @@
+package security_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"data-storage-svc/internal/api/security"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testKeys(t *testing.T) (security.SigningKey, security.SigningKey, security.SigningKey) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	secret := []byte("a-very-long-and-random-hs256-secret")
+	return security.SigningKey{Id: "hmac", Method: jwt.SigningMethodHS256, PrivateKey: secret, PublicKey: secret},
+		security.SigningKey{Id: "rsa", Method: jwt.SigningMethodRS256, PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey},
+		security.SigningKey{Id: "ed", Method: jwt.SigningMethodEdDSA, PrivateKey: edPrivateKey, PublicKey: edPublicKey}
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	hmacKey, rsaKey, edKey := testKeys(t)
+	userId := primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+
+	for _, key := range []security.SigningKey{hmacKey, rsaKey, edKey} {
+		t.Run(key.Id, func(t *testing.T) {
+			keyring, err := security.NewKeyring(key.Id, hmacKey, rsaKey, edKey)
+			assert.NoError(t, err)
+			tokenModule := security.NewTokenModule(keyring)
+
+			token, err := tokenModule.CreateToken(&userId, &sessionId)
+			assert.NoError(t, err)
+			parsedUserId, parsedSessionId, err := tokenModule.ParseToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, userId, *parsedUserId)
+			assert.Equal(t, sessionId, *parsedSessionId)
+		})
+	}
+}
+
+func TestMfaToken(t *testing.T) {
+	hmacKey, _, _ := testKeys(t)
+	keyring, err := security.NewKeyring("hmac", hmacKey)
+	assert.NoError(t, err)
+	tokenModule := security.NewTokenModule(keyring)
+	userId := primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+
+	mfaToken, err := tokenModule.CreateMfaToken(&userId)
+	assert.NoError(t, err)
+	parsedUserId, err := tokenModule.ParseMfaToken(mfaToken)
+	assert.NoError(t, err)
+	assert.Equal(t, userId, *parsedUserId)
+
+	// Tokens are not interchangeable
+	_, _, err = tokenModule.ParseToken(mfaToken)
+	assert.Error(t, err)
+	accessToken, err := tokenModule.CreateToken(&userId, &sessionId)
+	assert.NoError(t, err)
+	_, err = tokenModule.ParseMfaToken(accessToken)
+	assert.Error(t, err)
+}
+
+func TestLinkSessionToken(t *testing.T) {
+	hmacKey, _, _ := testKeys(t)
+	keyring, err := security.NewKeyring("hmac", hmacKey)
+	assert.NoError(t, err)
+	tokenModule := security.NewTokenModule(keyring)
+	sharedLinkId := primitive.NewObjectID()
+
+	linkToken, err := tokenModule.CreateLinkSessionToken(&sharedLinkId)
+	assert.NoError(t, err)
+	parsedLinkId, err := tokenModule.ParseLinkSessionToken(linkToken)
+	assert.NoError(t, err)
+	assert.Equal(t, sharedLinkId, *parsedLinkId)
+
+	// A link session does not authenticate a user, nor the other way around
+	_, _, err = tokenModule.ParseToken(linkToken)
+	assert.Error(t, err)
+	mfaToken, err := tokenModule.CreateMfaToken(&sharedLinkId)
+	assert.NoError(t, err)
+	_, err = tokenModule.ParseLinkSessionToken(mfaToken)
+	assert.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	hmacKey, rsaKey, edKey := testKeys(t)
+	userId := primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+
+	oldKeyring, err := security.NewKeyring("hmac", hmacKey)
+	assert.NoError(t, err)
+	oldToken, err := security.NewTokenModule(oldKeyring).CreateToken(&userId, &sessionId)
+	assert.NoError(t, err)
+
+	// The new key signs, the old one still verifies the tokens it signed
+	rotatedKeyring, err := security.NewKeyring("ed", hmacKey, edKey)
+	assert.NoError(t, err)
+	_, _, err = security.NewTokenModule(rotatedKeyring).ParseToken(oldToken)
+	assert.NoError(t, err)
+
+	// Once removed, the old key does not verify anything anymore
+	newKeyring, err := security.NewKeyring("ed", edKey, rsaKey)
+	assert.NoError(t, err)
+	_, _, err = security.NewTokenModule(newKeyring).ParseToken(oldToken)
+	assert.Error(t, err)
+
+	// A retired key without private key cannot sign
+	_, err = security.NewKeyring("rsa", security.SigningKey{Id: "rsa", Method: jwt.SigningMethodRS256, PublicKey: rsaKey.PublicKey})
+	assert.Error(t, err)
+}
+
+func TestRejectedTokens(t *testing.T) {
+	hmacKey, rsaKey, _ := testKeys(t)
+	keyring, err := security.NewKeyring("rsa", hmacKey, rsaKey)
+	assert.NoError(t, err)
+	tokenModule := security.NewTokenModule(keyring)
+	userId := primitive.NewObjectID()
+
+	validClaims := func() security.AccessClaims {
+		return security.AccessClaims{
+			SessionId: primitive.NewObjectID().Hex(),
+			StandardClaims: jwt.StandardClaims{
+				Subject:   userId.Hex(),
+				Issuer:    security.TOKEN_ISSUER,
+				Audience:  security.TOKEN_AUDIENCE,
+				ExpiresAt: time.Now().Add(time.Minute).Unix(),
+			},
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid interface{}, claims jwt.Claims, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != nil {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return signed
+	}
+	rsaPublicPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPublicKey(t, &rsaKey.PrivateKey.(*rsa.PrivateKey).PublicKey)})
+	otherAudience := validClaims()
+	otherAudience.Audience = "another-api"
+	otherIssuer := validClaims()
+	otherIssuer.Issuer = "another-issuer"
+	expired := validClaims()
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{"no kid", sign(jwt.SigningMethodRS256, nil, validClaims(), rsaKey.PrivateKey)},
+		{"unknown kid", sign(jwt.SigningMethodRS256, "unknown", validClaims(), rsaKey.PrivateKey)},
+		{"algorithm confusion", sign(jwt.SigningMethodHS256, "rsa", validClaims(), rsaPublicPem)},
+		{"none algorithm", sign(jwt.SigningMethodNone, "rsa", validClaims(), jwt.UnsafeAllowNoneSignatureType)},
+		{"other audience", sign(jwt.SigningMethodRS256, "rsa", otherAudience, rsaKey.PrivateKey)},
+		{"other issuer", sign(jwt.SigningMethodRS256, "rsa", otherIssuer, rsaKey.PrivateKey)},
+		{"expired", sign(jwt.SigningMethodRS256, "rsa", expired, rsaKey.PrivateKey)},
+		{"legacy email claim", sign(jwt.SigningMethodHS256, "hmac", jwt.MapClaims{"email": "test@test.fr", "exp": time.Now().Add(time.Minute).Unix()}, hmacKey.PrivateKey)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tokenModule.ParseToken(tc.token)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	directory := t.TempDir()
+	legacyKeyPath := filepath.Join(t.TempDir(), "jwt_key")
+	assert.NoError(t, os.WriteFile(legacyKeyPath, []byte("legacy-secret"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "2025-hmac.key"), []byte("hmac-secret"), 0600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rsaPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "2026-rsa.pem"), rsaPem, 0600))
+
+	_, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	edDer, err := x509.MarshalPKCS8PrivateKey(edPrivateKey)
+	assert.NoError(t, err)
+	edPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDer})
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "2026-ed.pem"), edPem, 0600))
+
+	// Retired key, only its public part is kept
+	retiredPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPublicKey(t, &rsaKey.PublicKey)})
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "2024-rsa.pem"), retiredPem, 0600))
+
+	userId := primitive.NewObjectID()
+	sessionId := primitive.NewObjectID()
+	for _, signingKeyId := range []string{security.LEGACY_KEY_ID, "2025-hmac", "2026-rsa", "2026-ed"} {
+		t.Run(signingKeyId, func(t *testing.T) {
+			keyring, err := security.LoadKeyring(legacyKeyPath, directory, signingKeyId)
+			assert.NoError(t, err)
+			tokenModule := security.NewTokenModule(keyring)
+			token, err := tokenModule.CreateToken(&userId, &sessionId)
+			assert.NoError(t, err)
+			_, _, err = tokenModule.ParseToken(token)
+			assert.NoError(t, err)
+		})
+	}
+
+	_, err = security.LoadKeyring(legacyKeyPath, directory, "2024-rsa")
+	assert.Error(t, err)
+	_, err = security.LoadKeyring(legacyKeyPath, directory, "missing")
+	assert.Error(t, err)
+}
+
+func mustMarshalPublicKey(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	return der
+}
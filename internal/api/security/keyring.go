@@ -0,0 +1,157 @@
+package security
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Id of the key loaded from the legacy single key file
+const LEGACY_KEY_ID = "default"
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// A key used to sign and/or verify tokens, the algorithm is bound to the key
+type SigningKey struct {
+	Id     string
+	Method jwt.SigningMethod
+	// Key used to sign tokens, nil for retired keys only kept to verify tokens they signed
+	PrivateKey interface{}
+	// Key used to verify tokens
+	PublicKey interface{}
+}
+
+// Set of keys accepted to verify tokens, selected by the kid header, one of them signs new tokens. Rotating keys is
+// adding a new key, making it the signing one, then removing the old one once the tokens it signed have expired.
+type Keyring struct {
+	keys         map[string]SigningKey
+	signingKeyId string
+}
+
+func NewKeyring(signingKeyId string, keys ...SigningKey) (*Keyring, error) {
+	keyring := Keyring{keys: map[string]SigningKey{}, signingKeyId: signingKeyId}
+	for _, key := range keys {
+		if _, exists := keyring.keys[key.Id]; exists {
+			return nil, fmt.Errorf("duplicated key id %s", key.Id)
+		}
+		keyring.keys[key.Id] = key
+	}
+	signingKey, exists := keyring.keys[signingKeyId]
+	if !exists {
+		return nil, fmt.Errorf("signing key %s not found", signingKeyId)
+	}
+	if signingKey.PrivateKey == nil {
+		return nil, fmt.Errorf("signing key %s has no private key", signingKeyId)
+	}
+	return &keyring, nil
+}
+
+// Sign claims with the signing key, its id is set in the kid header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	signingKey := k.keys[k.signingKeyId]
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	token.Header["kid"] = signingKey.Id
+	return token.SignedString(signingKey.PrivateKey)
+}
+
+// Parse and verify a token into claims. The token must name a known key, and be signed with the algorithm of this
+// key, whatever its header claims.
+func (k *Keyring) Parse(tokenString string, claims jwt.Claims) error {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}}
+	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		keyId, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		key, exists := k.keys[keyId]
+		if !exists {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), keyId)
+		}
+		return key.PublicKey, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+// Load the keyring from the legacy HS256 key file (if it exists) and from a directory of keys (if set). In the
+// directory, the file name without extension is the key id:
+//   - <kid>.key holds a HS256 secret
+//   - <kid>.pem holds a RSA (RS256) or Ed25519 (EdDSA) private key, or only a public key to verify a retired key
+func LoadKeyring(legacyKeyPath string, directory string, signingKeyId string) (*Keyring, error) {
+	keys := []SigningKey{}
+	if legacyKeyPath != "" {
+		if secret, err := os.ReadFile(legacyKeyPath); err == nil {
+			keys = append(keys, SigningKey{Id: LEGACY_KEY_ID, Method: jwt.SigningMethodHS256, PrivateKey: secret, PublicKey: secret})
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if directory != "" {
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			key, err := loadKey(filepath.Join(directory, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("couldn't load key %s: %w", entry.Name(), err)
+			}
+			if key != nil {
+				keys = append(keys, *key)
+			}
+		}
+	}
+	slog.Debug("JWT keys loaded", "count", len(keys), "signingKey", signingKeyId)
+	return NewKeyring(signingKeyId, keys...)
+}
+
+func loadKey(path string) (*SigningKey, error) {
+	extension := filepath.Ext(path)
+	keyId := strings.TrimSuffix(filepath.Base(path), extension)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch extension {
+	case ".key":
+		return &SigningKey{Id: keyId, Method: jwt.SigningMethodHS256, PrivateKey: content, PublicKey: content}, nil
+	case ".pem":
+		return parsePemKey(keyId, content)
+	default:
+		slog.Warn("Ignoring file in JWT keys directory", "file", path)
+		return nil, nil
+	}
+}
+
+func parsePemKey(keyId string, content []byte) (*SigningKey, error) {
+	if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(content); err == nil {
+		return &SigningKey{Id: keyId, Method: jwt.SigningMethodRS256, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}, nil
+	}
+	if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(content); err == nil {
+		return &SigningKey{Id: keyId, Method: jwt.SigningMethodEdDSA, PrivateKey: privateKey, PublicKey: privateKey.(crypto.Signer).Public()}, nil
+	}
+	if publicKey, err := jwt.ParseRSAPublicKeyFromPEM(content); err == nil {
+		return &SigningKey{Id: keyId, Method: jwt.SigningMethodRS256, PublicKey: publicKey}, nil
+	}
+	if publicKey, err := jwt.ParseEdPublicKeyFromPEM(content); err == nil {
+		return &SigningKey{Id: keyId, Method: jwt.SigningMethodEdDSA, PublicKey: publicKey}, nil
+	}
+	return nil, errors.New("unsupported PEM key, expected a RSA or Ed25519 key")
+}
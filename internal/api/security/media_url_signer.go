@@ -0,0 +1,54 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Signed URL of the rendition of a media
+	MEDIA_URL_RENDITION = "rendition"
+	// Signed URL of the original file of a media
+	MEDIA_URL_ORIGINAL = "original"
+)
+
+// Signs the URLs of stored media files, so they can be served without looking the media up
+type MediaUrlSigner interface {
+	// Expiry of the URLs signed now. URLs signed during the same period share their expiry, so they are the same URL
+	// and caches can keep the file.
+	Expiry(now time.Time) time.Time
+	// Sign the access to a stored file of a media until the expiry
+	Sign(mediaId string, rendition string, fileName string, expiry time.Time) string
+	// Check the signature of an access, and that it has not expired
+	Verify(mediaId string, rendition string, fileName string, expiry time.Time, signature string, now time.Time) bool
+}
+
+type mediaUrlSigner struct {
+	secret []byte
+	// URLs are valid at least this long, at most twice as long
+	ttl time.Duration
+}
+
+func NewMediaUrlSigner(secret []byte, ttl time.Duration) MediaUrlSigner {
+	return mediaUrlSigner{secret: secret, ttl: ttl}
+}
+
+func (s mediaUrlSigner) Expiry(now time.Time) time.Time {
+	return now.Truncate(s.ttl).Add(2 * s.ttl)
+}
+
+func (s mediaUrlSigner) Sign(mediaId string, rendition string, fileName string, expiry time.Time) string {
+	// Path segments cannot contain a slash, the message cannot be read in two ways
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{mediaId, rendition, fileName, strconv.FormatInt(expiry.Unix(), 10)}, "/")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s mediaUrlSigner) Verify(mediaId string, rendition string, fileName string, expiry time.Time, signature string, now time.Time) bool {
+	expected := s.Sign(mediaId, rendition, fileName, expiry)
+	return hmac.Equal([]byte(expected), []byte(signature)) && now.Before(expiry)
+}
@@ -0,0 +1,33 @@
+package security_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaUrlSigner(t *testing.T) {
+	signer := security.NewMediaUrlSigner([]byte("secret"), time.Hour)
+	now := time.Date(2024, 6, 1, 10, 20, 0, 0, time.UTC)
+
+	// Valid between one and two periods, the same within a period
+	expiry := signer.Expiry(now)
+	assert.Equal(t, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), expiry)
+	assert.Equal(t, expiry, signer.Expiry(now.Add(30*time.Minute)))
+
+	signature := signer.Sign("mediaId", security.MEDIA_URL_RENDITION, "file.jpg", expiry)
+	assert.Equal(t, signature, signer.Sign("mediaId", security.MEDIA_URL_RENDITION, "file.jpg", expiry))
+	assert.True(t, signer.Verify("mediaId", security.MEDIA_URL_RENDITION, "file.jpg", expiry, signature, now))
+
+	// Any change invalidates the signature
+	assert.False(t, signer.Verify("otherId", security.MEDIA_URL_RENDITION, "file.jpg", expiry, signature, now))
+	assert.False(t, signer.Verify("mediaId", security.MEDIA_URL_ORIGINAL, "file.jpg", expiry, signature, now))
+	assert.False(t, signer.Verify("mediaId", security.MEDIA_URL_RENDITION, "other.jpg", expiry, signature, now))
+	assert.False(t, signer.Verify("mediaId", security.MEDIA_URL_RENDITION, "file.jpg", expiry.Add(time.Hour), signature, now))
+	assert.False(t, security.NewMediaUrlSigner([]byte("other"), time.Hour).Verify("mediaId", security.MEDIA_URL_RENDITION, "file.jpg", expiry, signature, now))
+
+	// Expired
+	assert.False(t, signer.Verify("mediaId", security.MEDIA_URL_RENDITION, "file.jpg", expiry, signature, expiry))
+}
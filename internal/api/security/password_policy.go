@@ -0,0 +1,96 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// bcrypt ignores what comes after 72 bytes, a longer password would not be as strong as it looks
+const PASSWORD_MAX_LENGTH = 72
+
+type PasswordPolicy interface {
+	// Check if a password is acceptable for the user with this email, the error tells the user what is wrong
+	Check(password string, email string) error
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int64
+	// Reject passwords containing the email address, or its part before the @
+	ForbidEmail bool
+	// File of known breached passwords, one per line. Lines may also be SHA-1 hashes of the passwords, optionally
+	// followed by :<count> as in the Have I Been Pwned downloads. Empty to skip the check.
+	BreachListFile string
+}
+
+type passwordPolicy struct {
+	config PasswordPolicyConfig
+	// SHA-1 of the breached passwords, upper case hex
+	breached map[string]bool
+}
+
+// Create a password policy, the breach list is loaded in memory at once
+func NewPasswordPolicy(config PasswordPolicyConfig) (PasswordPolicy, error) {
+	policy := passwordPolicy{config: config, breached: map[string]bool{}}
+	if config.BreachListFile == "" {
+		return policy, nil
+	}
+	file, err := os.Open(config.BreachListFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open breached passwords list: %s", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSha1(hash) {
+			policy.breached[strings.ToUpper(hash)] = true
+		} else {
+			policy.breached[sha1Hex(line)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read breached passwords list: %s", err)
+	}
+	return policy, nil
+}
+
+func (p passwordPolicy) Check(password string, email string) error {
+	if int64(utf8.RuneCountInString(password)) < p.config.MinLength {
+		return fmt.Errorf("password should be at least %d characters long", p.config.MinLength)
+	}
+	if len(password) > PASSWORD_MAX_LENGTH {
+		return fmt.Errorf("password should be at most %d bytes long", PASSWORD_MAX_LENGTH)
+	}
+	if p.config.ForbidEmail && email != "" {
+		lowerPassword := strings.ToLower(password)
+		localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+		if strings.Contains(lowerPassword, strings.ToLower(email)) || (len(localPart) >= 3 && strings.Contains(lowerPassword, localPart)) {
+			return fmt.Errorf("password should not contain your email address")
+		}
+	}
+	if p.breached[sha1Hex(password)] {
+		return fmt.Errorf("this password appears in a list of leaked passwords, please choose another one")
+	}
+	return nil
+}
+
+func sha1Hex(value string) string {
+	hash := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+func isSha1(value string) bool {
+	if len(value) != 2*sha1.Size {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
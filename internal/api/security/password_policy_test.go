@@ -0,0 +1,47 @@
+package security_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	breachList := filepath.Join(t.TempDir(), "breached.txt")
+	// A plain password, and the SHA-1 of "password123" in the Have I Been Pwned format
+	assert.NoError(t, os.WriteFile(breachList, []byte("letmein2024\n\nCBFDAC6008F9CAB4083784CBD1874F76618D2A97:2254650\n"), 0600))
+
+	policy, err := security.NewPasswordPolicy(security.PasswordPolicyConfig{MinLength: 8, ForbidEmail: true, BreachListFile: breachList})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"valid", "correct horse battery", true},
+		{"too short", "azerty", false},
+		{"too long", strings.Repeat("a", 73), false},
+		{"contains email", "my-John.Doe@test.fr!", false},
+		{"contains email local part", "JOHN.DOE-2024", false},
+		{"breached", "letmein2024", false},
+		{"breached hash", "password123", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.password, "john.doe@test.fr")
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	_, err = security.NewPasswordPolicy(security.PasswordPolicyConfig{BreachListFile: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A session is kept alive as long as its refresh token is used within this delay
+const REFRESH_TOKEN_DURATION = 30 * 24 * time.Hour
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// Generate a new refresh token for a session. The token embeds the session id, so the session can be fetched
+// without indexing token hashes, followed by a random secret.
+func GenerateRefreshToken(sessionId *primitive.ObjectID) (string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return "", err
+	}
+	return sessionId.Hex() + "." + secret, nil
+}
+
+// Extract the session id of a refresh token
+func ParseRefreshToken(refreshToken string) (*primitive.ObjectID, error) {
+	rawSessionId, secret, found := strings.Cut(refreshToken, ".")
+	if !found || secret == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	sessionId, err := primitive.ObjectIDFromHex(rawSessionId)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	return &sessionId, nil
+}
+
+// Hash of a refresh token as stored in DB
+func HashRefreshToken(refreshToken string) string {
+	return hashSecret(refreshToken)
+}
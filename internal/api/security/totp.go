@@ -0,0 +1,95 @@
+package security
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// Name shown in authenticator apps
+	TOTP_ISSUER = "Album"
+	TOTP_PERIOD = 30
+	// Codes of the previous and next periods are accepted too, to tolerate clock drift
+	TOTP_SKEW = 1
+	// Number of recovery codes given on enrollment
+	RECOVERY_CODES_COUNT = 10
+)
+
+var totpOptions = totp.ValidateOpts{Period: TOTP_PERIOD, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+// A new TOTP secret, with the URI and QR code to provision it in an authenticator app
+type TotpEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+	// PNG image of the URI, as a data URL
+	QrCode string `json:"qrCode"`
+}
+
+func GenerateTotp(accountName string) (*TotpEnrollment, error) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: TOTP_ISSUER, AccountName: accountName, Period: TOTP_PERIOD})
+	if err != nil {
+		return nil, err
+	}
+	image, err := key.Image(256, 256)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image); err != nil {
+		return nil, err
+	}
+	return &TotpEnrollment{
+		Secret: key.Secret(),
+		Uri:    key.URL(),
+		QrCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()),
+	}, nil
+}
+
+// Check a TOTP code, a code can only be used once: it must be from a later period than lastUsedStep. Returns the
+// period of the code, to be stored as the new last used step.
+func ValidateTotp(secret string, code string, now time.Time, lastUsedStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	currentStep := now.Unix() / TOTP_PERIOD
+	for step := currentStep - TOTP_SKEW; step <= currentStep+TOTP_SKEW; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*TOTP_PERIOD, 0), totpOptions)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Generate single use recovery codes, formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RECOVERY_CODES_COUNT)
+	for i := range codes {
+		random := make([]byte, 6)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(random))
+		codes[i] = encoded[:5] + "-" + encoded[5:10]
+	}
+	return codes, nil
+}
+
+// Hash of a recovery code as stored in DB, dashes, spaces and case typed by the user are ignored
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashSecret(normalized)
+}
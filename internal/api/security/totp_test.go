@@ -0,0 +1,44 @@
+package security_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTotp(t *testing.T) {
+	// RFC 6238 test secret, "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	now := time.Unix(59, 0)
+
+	step, valid := security.ValidateTotp(secret, "287082", now, 0)
+	assert.True(t, valid)
+	assert.Equal(t, int64(1), step)
+
+	// Previous and next periods are accepted, not the ones after
+	_, valid = security.ValidateTotp(secret, "287082", now.Add(30*time.Second), 0)
+	assert.True(t, valid)
+	_, valid = security.ValidateTotp(secret, "287082", now.Add(90*time.Second), 0)
+	assert.False(t, valid)
+
+	// A code cannot be used twice
+	_, valid = security.ValidateTotp(secret, "287082", now, step)
+	assert.False(t, valid)
+
+	_, valid = security.ValidateTotp(secret, "000000", now, 0)
+	assert.False(t, valid)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := security.GenerateRecoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes, security.RECOVERY_CODES_COUNT)
+	assert.Len(t, codes[0], 11)
+	assert.NotEqual(t, codes[0], codes[1])
+
+	// Typing variations do not matter
+	assert.Equal(t, security.HashRecoveryCode(codes[0]), security.HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))))
+}
@@ -0,0 +1,20 @@
+package security
+
+// Generate a random single use token sent by email, along with the hash stored in DB
+func GenerateUserToken() (string, string, error) {
+	token, err := randomSecret()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashUserToken(token), nil
+}
+
+// Hash of a token sent by email, as stored in DB
+func HashUserToken(token string) string {
+	return hashSecret(token)
+}
+
+// Generate the token printed at startup, required to create the first admin
+func GenerateSetupToken() (string, error) {
+	return randomSecret()
+}
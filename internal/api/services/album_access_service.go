@@ -10,8 +10,8 @@ import (
 )
 
 type AlbumAccessService interface {
-	// Grant access to an album for a given user
-	GrantAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID, canEdit bool) utils.ServiceError
+	// Grant access to an album for a given user, or change their role
+	GrantAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError
 	// Get all album access authorizations for a given user
 	GetAllForUser(userId *primitive.ObjectID) ([]model.UserAlbumAccess, error)
 	// Revoke access for the given user and given album
@@ -20,6 +20,12 @@ type AlbumAccessService interface {
 	RevokeAllAccesses(albumId *primitive.ObjectID) error
 	// List all accesses granted for a given album
 	GetAllAccesses(albumId *primitive.ObjectID) ([]model.UserAlbumAccess, utils.ServiceError)
+	// Grant access to an album for all the members of a group, or change their role
+	GrantGroupAccess(groupId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError
+	// Revoke access for the given group and given album
+	RevokeGroupAccess(groupId *primitive.ObjectID, albumId *primitive.ObjectID) utils.ServiceError
+	// List all accesses granted to groups for a given album
+	GetAllGroupAccesses(albumId *primitive.ObjectID) ([]model.GroupAlbumAccess, utils.ServiceError)
 }
 
 type albumAccessService struct {
@@ -30,8 +36,11 @@ func NewAlbumAccessService(albumAccessRepository repository.AlbumAccessRepositor
 	return albumAccessService{albumAccessRepository}
 }
 
-func (s albumAccessService) GrantAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID, canEdit bool) utils.ServiceError {
-	err := s.albumAccessRepository.Create(userId, albumId, canEdit)
+func (s albumAccessService) GrantAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError {
+	if !role.IsValid() {
+		return utils.NewServiceError(http.StatusBadRequest, "unknown role")
+	}
+	err := s.albumAccessRepository.Create(userId, albumId, role)
 	if err != nil {
 		return utils.NewServiceError(http.StatusInternalServerError, "couldn't grant access to album")
 	}
@@ -39,7 +48,7 @@ func (s albumAccessService) GrantAccess(userId *primitive.ObjectID, albumId *pri
 }
 
 func (s albumAccessService) GetAllForUser(userId *primitive.ObjectID) ([]model.UserAlbumAccess, error) {
-	return s.albumAccessRepository.GetAllByUser(userId)
+	return s.albumAccessRepository.GetAllEffectiveByUser(userId)
 }
 
 func (s albumAccessService) RevokeAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID) utils.ServiceError {
@@ -60,3 +69,29 @@ func (s albumAccessService) GetAllAccesses(albumId *primitive.ObjectID) ([]model
 	}
 	return accesses, nil
 }
+
+func (s albumAccessService) GrantGroupAccess(groupId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError {
+	// Groups never own an album
+	if !role.IsGrantable() {
+		return utils.NewServiceError(http.StatusBadRequest, "this role cannot be granted")
+	}
+	if err := s.albumAccessRepository.CreateForGroup(groupId, albumId, role); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't grant access to album")
+	}
+	return nil
+}
+
+func (s albumAccessService) RevokeGroupAccess(groupId *primitive.ObjectID, albumId *primitive.ObjectID) utils.ServiceError {
+	if err := s.albumAccessRepository.RemoveForGroup(groupId, albumId); err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "couldn't revoke access")
+	}
+	return nil
+}
+
+func (s albumAccessService) GetAllGroupAccesses(albumId *primitive.ObjectID) ([]model.GroupAlbumAccess, utils.ServiceError) {
+	accesses, err := s.albumAccessRepository.GetAllGroupsByAlbum(albumId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't find album accesses")
+	}
+	return accesses, nil
+}
@@ -4,9 +4,15 @@ import (
 	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
 	"data-storage-svc/internal/utils"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/text/unicode/norm"
 )
 
 type AlbumService interface {
@@ -28,8 +34,30 @@ type AlbumService interface {
 	DeleteMediaFromAll(mediaId *primitive.ObjectID) utils.ServiceError
 	// Delete an album
 	Delete(albumId *primitive.ObjectID) utils.ServiceError
+	// Make an album public or private. A public album without slug gets one from its title.
+	SetVisibility(albumId *primitive.ObjectID, visibility AlbumVisibility) (*model.Album, utils.ServiceError)
+	// Get a public album by its slug
+	GetPublicAlbum(slug string) (*model.Album, utils.ServiceError)
+	// Get the public albums listed in the public directory
+	GetPublicDirectory() ([]model.Album, utils.ServiceError)
 }
 
+// Who can see an album without account nor link
+type AlbumVisibility struct {
+	Public bool
+	// Empty to keep the current slug, or to get one from the title
+	Slug   string
+	Listed bool
+}
+
+// Slugs are lower case words separated by dashes
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const (
+	SLUG_MIN_LENGTH = 3
+	SLUG_MAX_LENGTH = 64
+)
+
 type albumService struct {
 	// Repository dependencies
 	albumRepository        repository.AlbumRepository
@@ -55,7 +83,7 @@ func (s albumService) Create(album *model.Album) (*primitive.ObjectID, utils.Ser
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create album")
 	}
 	// Grant edit access to the owner
-	svcErr := s.albumAccessService.GrantAccess(album.AuthorId, albumId, true)
+	svcErr := s.albumAccessService.GrantAccess(album.AuthorId, albumId, model.ALBUM_ROLE_OWNER)
 	if svcErr != nil {
 		return nil, svcErr
 	}
@@ -162,3 +190,99 @@ func (s albumService) Delete(albumId *primitive.ObjectID) utils.ServiceError {
 	}
 	return nil
 }
+
+func (s albumService) SetVisibility(albumId *primitive.ObjectID, visibility AlbumVisibility) (*model.Album, utils.ServiceError) {
+	album, svcErr := s.GetAlbumById(albumId)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	if visibility.Listed && !visibility.Public {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "only public albums can be listed")
+	}
+	if visibility.Slug != "" && visibility.Slug != album.Slug {
+		if len(visibility.Slug) < SLUG_MIN_LENGTH || len(visibility.Slug) > SLUG_MAX_LENGTH || !slugPattern.MatchString(visibility.Slug) {
+			return nil, utils.NewServiceError(http.StatusBadRequest, "slugs are 3 to 64 lower case letters, digits and dashes")
+		}
+		if _, err := s.albumRepository.GetBySlug(visibility.Slug); err == nil {
+			return nil, utils.NewServiceError(http.StatusConflict, "this slug is already used by another album")
+		}
+		album.Slug = visibility.Slug
+	} else if album.Slug == "" && visibility.Public {
+		slug, svcErr := s.availableSlug(album.Title)
+		if svcErr != nil {
+			return nil, svcErr
+		}
+		album.Slug = slug
+	}
+	album.Public = visibility.Public
+	album.Listed = visibility.Listed
+	if err := s.albumRepository.Update(album); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, utils.NewServiceError(http.StatusConflict, "this slug is already used by another album")
+		}
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't update album")
+	}
+	return album, nil
+}
+
+// A slug made from the title, numbered when another album has it already
+func (s albumService) availableSlug(title string) (string, utils.ServiceError) {
+	base := Slugify(title)
+	for i := 1; i <= 100; i++ {
+		slug := base
+		if i > 1 {
+			slug = fmt.Sprintf("%s-%d", base, i)
+		}
+		if _, err := s.albumRepository.GetBySlug(slug); err == mongo.ErrNoDocuments {
+			return slug, nil
+		} else if err != nil {
+			return "", utils.NewServiceError(http.StatusInternalServerError, "couldn't update album")
+		}
+	}
+	return "", utils.NewServiceError(http.StatusConflict, "couldn't find a free slug, choose one")
+}
+
+// Make a slug from a title: lower case ASCII letters and digits, other characters become dashes
+func Slugify(title string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(norm.NFKD.String(title)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			dash = false
+		case unicode.Is(unicode.Mn, r):
+			// Accents left by the decomposition
+		default:
+			dash = true
+		}
+	}
+	slug := builder.String()
+	if len(slug) > SLUG_MAX_LENGTH-4 {
+		// Room left for a number
+		slug = strings.TrimRight(slug[:SLUG_MAX_LENGTH-4], "-")
+	}
+	for len(slug) < SLUG_MIN_LENGTH {
+		slug = strings.Trim(slug+"-album", "-")
+	}
+	return slug
+}
+
+func (s albumService) GetPublicAlbum(slug string) (*model.Album, utils.ServiceError) {
+	album, err := s.albumRepository.GetBySlug(slug)
+	if err != nil || !album.Public {
+		return nil, utils.NewServiceError(http.StatusNotFound, "Album not found")
+	}
+	return album, nil
+}
+
+func (s albumService) GetPublicDirectory() ([]model.Album, utils.ServiceError) {
+	albums, err := s.albumRepository.GetAllListed()
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list public albums")
+	}
+	return albums, nil
+}
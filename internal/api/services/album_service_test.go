@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "summer-holidays-2024", services.Slugify("Summer holidays 2024"))
+	assert.Equal(t, "ete-a-la-plage", services.Slugify("  Été à la plage !"))
+	assert.Equal(t, "album", services.Slugify("???"))
+	assert.Equal(t, "ab-album", services.Slugify("AB"))
+	// Room is left for a number
+	assert.Equal(t, services.SLUG_MAX_LENGTH-4, len(services.Slugify(strings.Repeat("a", 100))))
+}
+
+func TestSetAlbumVisibility(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	otherId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name              string
+		album             model.Album
+		visibility        services.AlbumVisibility
+		expectedSlug      string
+		expectedErrorCode *int
+	}{
+		{"Slug from the title", model.Album{Title: "Summer"}, services.AlbumVisibility{Public: true, Listed: true}, "summer-2", nil},
+		{"Chosen slug", model.Album{Title: "Summer"}, services.AlbumVisibility{Public: true, Slug: "beach"}, "beach", nil},
+		{"Slug kept", model.Album{Title: "Summer", Slug: "old"}, services.AlbumVisibility{Public: true}, "old", nil},
+		{"Slug kept when private", model.Album{Title: "Summer", Slug: "old", Public: true}, services.AlbumVisibility{}, "old", nil},
+		{"Private album has no slug", model.Album{Title: "Summer"}, services.AlbumVisibility{}, "", nil},
+		{"Slug taken", model.Album{Title: "Summer"}, services.AlbumVisibility{Public: true, Slug: "summer"}, "", utils.IntPtr(409)},
+		{"Invalid slug", model.Album{Title: "Summer"}, services.AlbumVisibility{Public: true, Slug: "Not a slug"}, "", utils.IntPtr(400)},
+		{"Listed private album", model.Album{Title: "Summer"}, services.AlbumVisibility{Listed: true}, "", utils.IntPtr(400)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			album := tc.album
+			album.Id = &albumId
+			albumRepository := &mocks.AlbumRepository{}
+			albumRepository.On("GetById", albumId).Return(&album, nil)
+			albumRepository.On("GetBySlug", "summer").Return(&model.Album{Id: &otherId, Slug: "summer"}, nil)
+			albumRepository.On("GetBySlug", mock.Anything).Return(nil, mongo.ErrNoDocuments)
+			albumRepository.On("Update", mock.Anything).Return(nil)
+
+			svc := services.NewAlbumService(albumRepository, nil, nil, nil, nil)
+			updated, err := svc.SetVisibility(&albumId, tc.visibility)
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				albumRepository.AssertNotCalled(t, "Update", mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedSlug, updated.Slug)
+			assert.Equal(t, tc.visibility.Public, updated.Public)
+			assert.Equal(t, tc.visibility.Listed, updated.Listed)
+		})
+	}
+}
+
+func TestGetPublicAlbum(t *testing.T) {
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetBySlug", "public").Return(&model.Album{Slug: "public", Public: true}, nil)
+	albumRepository.On("GetBySlug", "private").Return(&model.Album{Slug: "private"}, nil)
+	albumRepository.On("GetBySlug", mock.Anything).Return(nil, mongo.ErrNoDocuments)
+	svc := services.NewAlbumService(albumRepository, nil, nil, nil, nil)
+
+	album, err := svc.GetPublicAlbum("public")
+	assert.Nil(t, err)
+	assert.Equal(t, "public", album.Slug)
+
+	// Private albums are hidden as if they did not exist
+	_, err = svc.GetPublicAlbum("private")
+	assert.Equal(t, 404, err.GetCode())
+	_, err = svc.GetPublicAlbum("missing")
+	assert.Equal(t, 404, err.GetCode())
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ApiKeyService interface {
+	// Create a new API key for a user, the key itself is only returned here
+	Create(userId *primitive.ObjectID, name string, scopes []model.ApiKeyScope) (*model.ApiKey, *string, utils.ServiceError)
+	// List all API keys of a user (including revoked ones)
+	GetAllForUser(userId *primitive.ObjectID) ([]model.ApiKey, utils.ServiceError)
+	// Revoke an API key of a user, it cannot be used anymore
+	Revoke(userId *primitive.ObjectID, apiKeyId *primitive.ObjectID) utils.ServiceError
+}
+
+type apiKeyService struct {
+	// Repository dependencies
+	apiKeyRepository repository.ApiKeyRepository
+}
+
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepository) apiKeyService {
+	return apiKeyService{apiKeyRepository}
+}
+
+func (s apiKeyService) Create(userId *primitive.ObjectID, name string, scopes []model.ApiKeyScope) (*model.ApiKey, *string, utils.ServiceError) {
+	if len(name) == 0 || len(name) > 100 {
+		return nil, nil, utils.NewServiceError(http.StatusBadRequest, "invalid api key name")
+	}
+	if len(scopes) == 0 {
+		return nil, nil, utils.NewServiceError(http.StatusBadRequest, "at least one scope is needed")
+	}
+	for _, scope := range scopes {
+		if scope != model.API_KEY_SCOPE_READ && scope != model.API_KEY_SCOPE_WRITE {
+			return nil, nil, utils.NewServiceError(http.StatusBadRequest, "unknown scope")
+		}
+	}
+
+	key, prefix, err := security.GenerateApiKey()
+	if err != nil {
+		slog.Error("couldn't generate api key", "error", err)
+		return nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create api key")
+	}
+	apiKey := model.ApiKey{
+		UserId:    *userId,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   security.HashApiKey(key),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	apiKeyId, err := s.apiKeyRepository.Create(&apiKey)
+	if err != nil {
+		return nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create api key")
+	}
+	apiKey.Id = *apiKeyId
+	return &apiKey, &key, nil
+}
+
+func (s apiKeyService) GetAllForUser(userId *primitive.ObjectID) ([]model.ApiKey, utils.ServiceError) {
+	apiKeys, err := s.apiKeyRepository.GetAllForUser(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list api keys")
+	}
+	return apiKeys, nil
+}
+
+func (s apiKeyService) Revoke(userId *primitive.ObjectID, apiKeyId *primitive.ObjectID) utils.ServiceError {
+	err := s.apiKeyRepository.Revoke(userId, apiKeyId)
+	if err == mongo.ErrNoDocuments {
+		return utils.NewServiceError(http.StatusNotFound, "api key not found")
+	}
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't revoke api key")
+	}
+	return nil
+}
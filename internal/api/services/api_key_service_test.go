@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	userId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name              string
+		keyName           string
+		scopes            []model.ApiKeyScope
+		expectedErrorCode *int
+	}{
+		{
+			name:              "Read only key",
+			keyName:           "backup script",
+			scopes:            []model.ApiKeyScope{model.API_KEY_SCOPE_READ},
+			expectedErrorCode: nil,
+		},
+		{
+			name:              "Read write key",
+			keyName:           "uploader",
+			scopes:            []model.ApiKeyScope{model.API_KEY_SCOPE_READ, model.API_KEY_SCOPE_WRITE},
+			expectedErrorCode: nil,
+		},
+		{
+			name:              "Empty name",
+			keyName:           "",
+			scopes:            []model.ApiKeyScope{model.API_KEY_SCOPE_READ},
+			expectedErrorCode: utils.IntPtr(400),
+		},
+		{
+			name:              "No scope",
+			keyName:           "backup script",
+			scopes:            []model.ApiKeyScope{},
+			expectedErrorCode: utils.IntPtr(400),
+		},
+		{
+			name:              "Unknown scope",
+			keyName:           "backup script",
+			scopes:            []model.ApiKeyScope{"admin"},
+			expectedErrorCode: utils.IntPtr(400),
+		},
+	}
+
+	apiKeyRepositoryMock := &mocks.ApiKeyRepository{}
+	apiKeyRepositoryMock.On("Create", mock.Anything).Return(func(apiKey *model.ApiKey) (*primitive.ObjectID, error) {
+		id := primitive.NewObjectID()
+		return &id, nil
+	})
+
+	svc := services.NewApiKeyService(apiKeyRepositoryMock)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiKey, key, err := svc.Create(&userId, tc.keyName, tc.scopes)
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, security.IsApiKey(*key))
+			// Only the hash of the key is stored
+			assert.Equal(t, security.HashApiKey(*key), apiKey.KeyHash)
+			assert.NotContains(t, apiKey.KeyHash, *key)
+			assert.True(t, strings.HasPrefix(*key, apiKey.Prefix))
+			assert.Equal(t, userId, apiKey.UserId)
+		})
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	userId := primitive.NewObjectID()
+	apiKeyId := primitive.NewObjectID()
+	otherApiKeyId := primitive.NewObjectID()
+
+	apiKeyRepositoryMock := &mocks.ApiKeyRepository{}
+	apiKeyRepositoryMock.On("Revoke", &userId, &apiKeyId).Return(nil)
+	apiKeyRepositoryMock.On("Revoke", &userId, &otherApiKeyId).Return(mongo.ErrNoDocuments)
+
+	svc := services.NewApiKeyService(apiKeyRepositoryMock)
+
+	assert.Nil(t, svc.Revoke(&userId, &apiKeyId))
+	err := svc.Revoke(&userId, &otherApiKeyId)
+	assert.NotNil(t, err)
+	assert.Equal(t, 404, err.GetCode())
+}
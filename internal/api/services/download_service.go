@@ -2,15 +2,17 @@ package services
 
 import (
 	"archive/zip"
+	"data-storage-svc/internal/api/common"
 	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/storage"
 	"data-storage-svc/internal/utils"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,14 +20,14 @@ import (
 )
 
 type DownloadService interface {
-	// Initiate the creation of a zip file (to download the album)
-	InitDownload(albumId *primitive.ObjectID, initiator *primitive.ObjectID, isInitatedBySharedLink bool) (*primitive.ObjectID, utils.ServiceError)
+	// Initiate the creation of a zip file (to download the album), with the originals or with the renditions
+	InitDownload(albumId *primitive.ObjectID, initiator *primitive.ObjectID, isInitatedBySharedLink bool, renditions bool) (*primitive.ObjectID, utils.ServiceError)
 	// Check if a download is ready to be downloaded
 	IsReady(downloadId *primitive.ObjectID) bool
 	// Get a download by id
 	Get(downloadId *primitive.ObjectID) (*model.Download, utils.ServiceError)
-	// Get the data of the download, ie. the zip file bytes
-	GetData(downloadId *primitive.ObjectID) ([]byte, utils.ServiceError)
+	// Get the data of the download, ie. the zip file bytes, and its modification time. The returned reader must be closed.
+	GetData(downloadId *primitive.ObjectID) (io.ReadSeekCloser, *time.Time, utils.ServiceError)
 }
 
 type downloadService struct {
@@ -35,13 +37,17 @@ type downloadService struct {
 	mediaRepository        repository.MediaRepository
 	mediaInAlbumRepository repository.MediaInAlbumRepository
 	// Service dependencies
+	// Where media files and zip files are stored
+	storage storage.Backend
+	// Where rarely accessed originals are moved (nil if tiering is disabled)
+	archive storage.Backend
 }
 
-func NewDownloadService(albumRepository repository.AlbumRepository, downloadRepository repository.DownloadRepository, mediaRepository repository.MediaRepository, mediaInAlbumRepository repository.MediaInAlbumRepository) downloadService {
-	return downloadService{albumRepository: albumRepository, downloadRepository: downloadRepository, mediaRepository: mediaRepository, mediaInAlbumRepository: mediaInAlbumRepository}
+func NewDownloadService(albumRepository repository.AlbumRepository, downloadRepository repository.DownloadRepository, mediaRepository repository.MediaRepository, mediaInAlbumRepository repository.MediaInAlbumRepository, storage storage.Backend, archive storage.Backend) downloadService {
+	return downloadService{albumRepository: albumRepository, downloadRepository: downloadRepository, mediaRepository: mediaRepository, mediaInAlbumRepository: mediaInAlbumRepository, storage: storage, archive: archive}
 }
 
-func (s downloadService) InitDownload(albumId *primitive.ObjectID, initiator *primitive.ObjectID, isInitatedBySharedLink bool) (*primitive.ObjectID, utils.ServiceError) {
+func (s downloadService) InitDownload(albumId *primitive.ObjectID, initiator *primitive.ObjectID, isInitatedBySharedLink bool, renditions bool) (*primitive.ObjectID, utils.ServiceError) {
 
 	album, err := s.albumRepository.GetById(*albumId)
 	if err != nil {
@@ -74,59 +80,68 @@ func (s downloadService) InitDownload(albumId *primitive.ObjectID, initiator *pr
 	}
 
 	// Start the zip archive file creation, asynchronously
-	go s.createZipFile(zipFileName, *downloadId, mediasToDownload)
+	go s.createZipFile(zipFileName, *downloadId, mediasToDownload, renditions)
 
 	return downloadId, nil
 }
 
 // Create the zip file to download. Must be called in a different go routine as it can take a very long time
-func (s downloadService) createZipFile(zipFileName string, downloadId primitive.ObjectID, medias []model.Media) error {
-	downloadFolder, err := utils.GetDataDir("downloads")
-	if err != nil {
-		slog.Debug("couldn't open media folder", "error", err)
-		return err
-	}
-	fileLocation := filepath.Join(downloadFolder, zipFileName)
-	slog.Debug("Creating a new zip archive", "zipFileLocation", fileLocation)
-	zipFileArchive, err := os.Create(fileLocation)
-	if err != nil {
-		panic(err)
-	}
-	defer zipFileArchive.Close()
-
-	mediaFolder, err := utils.GetDataDir("originalMedias")
-	if err != nil {
-		return fmt.Errorf("couldn't create zip archive, cannot find medias folder")
-	}
-
-	zipWriter := zip.NewWriter(zipFileArchive)
-	// Don't forget to close the zip writer
-	defer zipWriter.Close()
-
-	// Write all media files inside the zip file
-	for _, media := range medias {
-		// Open the media file to be written in zip file
-		mediaFile, err := os.Open(filepath.Join(mediaFolder, *media.StorageFileName))
-		if err == nil {
-			// Create a writer targetting the zip file
-			writer, err := zipWriter.Create(*media.OriginalFileName)
-			if err != nil {
-				slog.Error("Couldn't open zip file writer for file", "err", err)
-			} else {
-				// Writing the file into the zip file
-				_, err := io.Copy(writer, mediaFile)
+func (s downloadService) createZipFile(zipFileName string, downloadId primitive.ObjectID, medias []model.Media, renditions bool) error {
+	zipKey := path.Join(common.DOWNLOAD_DIRECTORY, zipFileName)
+	slog.Debug("Creating a new zip archive", "zipFileKey", zipKey)
+
+	// Stream the zip archive to the storage backend as it is written
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		zipWriter := zip.NewWriter(pipeWriter)
+		// Write all media files inside the zip file
+		for _, media := range medias {
+			// Open the media file to be written in zip file, archived originals are read in place as zipping is already
+			// done in the background
+			mediaStorage := s.storage
+			key := path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName)
+			fileName := *media.OriginalFileName
+			if renditions {
+				// Never fall back to the original, the zip may be for a link not allowed to get originals
+				if media.CompressedFileName == nil {
+					slog.Debug("Media has no rendition, left out of the download", "mediaId", media.Id.Hex())
+					continue
+				}
+				key = path.Join(common.COMPRESSED_DIRECTORY, *media.CompressedFileName)
+				fileName = strings.TrimSuffix(fileName, path.Ext(fileName)) + path.Ext(*media.CompressedFileName)
+			} else if media.IsArchived() && s.archive != nil {
+				mediaStorage = s.archive
+			}
+			mediaFile, err := mediaStorage.Get(key, 0, -1)
+			if err == nil {
+				// Create a writer targetting the zip file
+				writer, err := zipWriter.Create(fileName)
 				if err != nil {
-					slog.Error("couldn't write file in zip file", "error", err)
+					slog.Error("Couldn't open zip file writer for file", "err", err)
+				} else {
+					// Writing the file into the zip file
+					_, err := io.Copy(writer, mediaFile)
+					if err != nil {
+						slog.Error("couldn't write file in zip file", "error", err)
+					}
 				}
+				// Ultimately, close the original file
+				mediaFile.Close()
+			} else {
+				slog.Error("Couldn't open media file for download", "mediaFile", media.Id.String())
 			}
-			// Ultimately, close the original file
-			mediaFile.Close()
-		} else {
-			slog.Error("Couldn't open media file for download", "mediaFile", media.Id.String())
 		}
+		// Don't forget to close the zip writer
+		pipeWriter.CloseWithError(zipWriter.Close())
+	}()
+
+	if err := s.storage.Put(zipKey, pipeReader, -1); err != nil {
+		pipeReader.CloseWithError(err)
+		slog.Error("couldn't store zip archive", "zipFileKey", zipKey, "error", err)
+		return fmt.Errorf("couldn't create zip archive - error [%s]", err)
 	}
 	s.downloadRepository.MarkAsReady(&downloadId)
-	slog.Debug("Zip file created", "zipFileLocation", fileLocation)
+	slog.Debug("Zip file created", "zipFileKey", zipKey)
 	return nil
 }
 
@@ -146,23 +161,19 @@ func (s downloadService) Get(downloadId *primitive.ObjectID) (*model.Download, u
 	return download, nil
 }
 
-func (s downloadService) GetData(downloadId *primitive.ObjectID) ([]byte, utils.ServiceError) {
-	downloadDir, err := utils.GetDataDir("downloads")
-	if err != nil {
-		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't find download")
-	}
+func (s downloadService) GetData(downloadId *primitive.ObjectID) (io.ReadSeekCloser, *time.Time, utils.ServiceError) {
 	download, svcErr := s.Get(downloadId)
 	if svcErr != nil {
-		return nil, svcErr
+		return nil, nil, svcErr
 	}
 	// Check that the download is ready before downloading (otherwise it will be corrupted)
 	if !download.IsReady {
-		return nil, utils.NewServiceError(http.StatusBadRequest, "download is not yet ready")
+		return nil, nil, utils.NewServiceError(http.StatusBadRequest, "download is not yet ready")
 	}
 
-	data, err := os.ReadFile(filepath.Join(downloadDir, *download.ZipFileName))
+	data, info, err := storage.Open(s.storage, path.Join(common.DOWNLOAD_DIRECTORY, *download.ZipFileName))
 	if err != nil {
-		return nil, utils.NewServiceError(http.StatusInternalServerError, "download error")
+		return nil, nil, utils.NewServiceError(http.StatusInternalServerError, "download error")
 	}
-	return data, nil
+	return data, &info.ModTime, nil
 }
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"data-storage-svc/internal"
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/compression"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/storage"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type FsckService interface {
+	// Check that the files in the data directory agree with the media and download documents, repair issues if asked to.
+	// Files and documents younger than the grace period are ignored as they may belong to an upload or download in progress.
+	Run(repair bool, gracePeriod time.Duration) (*model.FsckReport, utils.ServiceError)
+}
+
+type fsckService struct {
+	// Repository dependencies
+	mediaRepository        repository.MediaRepository
+	downloadRepository     repository.DownloadRepository
+	mediaInAlbumRepository repository.MediaInAlbumRepository
+	mediaAccessRepository  repository.MediaAccessRepository
+	blobRepository         repository.BlobRepository
+	// Service dependencies
+	quotaService QuotaService
+	// Where media files are stored
+	storage storage.Backend
+	// Where rarely accessed originals are moved (nil if tiering is disabled)
+	archive storage.Backend
+	// Where chunked uploads are staged, always on the local disk
+	staging storage.Backend
+}
+
+func NewFsckService(mediaRepository repository.MediaRepository, downloadRepository repository.DownloadRepository, mediaInAlbumRepository repository.MediaInAlbumRepository, mediaAccessRepository repository.MediaAccessRepository, blobRepository repository.BlobRepository, quotaService QuotaService, storageBackend storage.Backend, archiveBackend storage.Backend) FsckService {
+	return fsckService{
+		mediaRepository:        mediaRepository,
+		downloadRepository:     downloadRepository,
+		mediaInAlbumRepository: mediaInAlbumRepository,
+		mediaAccessRepository:  mediaAccessRepository,
+		blobRepository:         blobRepository,
+		quotaService:           quotaService,
+		storage:                storageBackend,
+		archive:                archiveBackend,
+		staging:                storage.NewLocalBackend(internal.DATA_DIRECTORY),
+	}
+}
+
+func (s fsckService) Run(repair bool, gracePeriod time.Duration) (*model.FsckReport, utils.ServiceError) {
+	medias, err := s.mediaRepository.GetAll()
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list medias")
+	}
+	downloads, err := s.downloadRepository.GetAll()
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list downloads")
+	}
+
+	report := model.FsckReport{CheckedAt: time.Now(), Repair: repair, Issues: make([]model.FsckIssue, 0)}
+	graceLimit := report.CheckedAt.Add(-gracePeriod)
+
+	// Check reference counts first, media documents removed below release their blob
+	blobIssues, err := s.checkBlobs(medias, repair)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list blobs")
+	}
+	report.Issues = append(report.Issues, blobIssues...)
+
+	// Check every media document against its files, remember which files are in use
+	referenced := map[string]bool{}
+	originalHashes := map[string]string{}
+	for _, media := range medias {
+		if media.StorageFileName != nil {
+			referenced[path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName)] = true
+		}
+		if media.CompressedFileName != nil {
+			referenced[path.Join(common.COMPRESSED_DIRECTORY, *media.CompressedFileName)] = true
+		}
+		report.Issues = append(report.Issues, s.checkMedia(&media, originalHashes, graceLimit, repair)...)
+	}
+
+	// Check every download document against its zip file
+	for _, download := range downloads {
+		if download.ZipFileName != nil {
+			referenced[path.Join(common.DOWNLOAD_DIRECTORY, *download.ZipFileName)] = true
+		}
+		if issue := s.checkDownload(&download, graceLimit, repair); issue != nil {
+			report.Issues = append(report.Issues, *issue)
+		}
+	}
+
+	// Finally look for files that no document points to
+	for _, directory := range []string{common.ORIGINAL_MEDIA_DIRECTORY, common.COMPRESSED_DIRECTORY, common.DOWNLOAD_DIRECTORY} {
+		issues, err := s.checkOrphanFiles(s.storage, directory, referenced, graceLimit, repair)
+		if err != nil {
+			return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list stored files")
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	if s.archive != nil {
+		issues, err := s.checkOrphanFiles(s.archive, common.ORIGINAL_MEDIA_DIRECTORY, referenced, graceLimit, repair)
+		if err != nil {
+			return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list archived files")
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	// Completed uploads are moved out of the staging directory, anything old left there belongs to an aborted upload
+	issues, err := s.checkOrphanFiles(s.staging, common.UPLOAD_DIRECTORY, map[string]bool{}, graceLimit, repair)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list staged uploads")
+	}
+	report.Issues = append(report.Issues, issues...)
+
+	slog.Debug("Storage check done", "repair", repair, "issues", len(report.Issues), "unrepaired", report.Unrepaired())
+	return &report, nil
+}
+
+func (s fsckService) checkMedia(media *model.Media, originalHashes map[string]string, graceLimit time.Time, repair bool) []model.FsckIssue {
+	issues := make([]model.FsckIssue, 0)
+
+	// The original file must exist, otherwise the media document is useless
+	storageFileName := ""
+	if media.StorageFileName != nil {
+		storageFileName = *media.StorageFileName
+	}
+	originalKey := path.Join(common.ORIGINAL_MEDIA_DIRECTORY, storageFileName)
+	originalStorage := s.storage
+	if media.IsArchived() {
+		if s.archive == nil {
+			// Never consider archived originals as missing because the archive is not configured
+			slog.Warn("media is archived but no archive storage is configured, skipping its original", "mediaId", media.Id.Hex())
+			return issues
+		}
+		originalStorage = s.archive
+	}
+	var err error
+	hash := ""
+	if storageFileName == "" {
+		err = os.ErrNotExist
+	} else if media.Hash == nil {
+		// Medias without hash predate deduplication, only their original existence can be checked
+		_, err = originalStorage.Stat(originalKey)
+	} else {
+		hash, err = s.hashOriginal(originalStorage, originalKey, originalHashes)
+	}
+	if err != nil {
+		issue := model.FsckIssue{Kind: model.FSCK_MISSING_ORIGINAL, Path: originalKey, DocumentId: &media.Id}
+		if repair {
+			issue.Repaired = s.removeMediaDocument(media)
+		}
+		return append(issues, issue)
+	}
+
+	// The original content must match the hash computed at upload time. There is no way to repair it, only report it.
+	if media.Hash != nil && hash != *media.Hash {
+		issues = append(issues, model.FsckIssue{Kind: model.FSCK_CORRUPTED_ORIGINAL, Path: originalKey, DocumentId: &media.Id})
+	}
+
+	// The compressed rendition must exist, unless the media was uploaded recently and is still waiting for compression
+	renditionMissing := false
+	renditionKey := ""
+	if media.CompressedFileName != nil {
+		renditionKey = path.Join(common.COMPRESSED_DIRECTORY, *media.CompressedFileName)
+		_, err := s.storage.Stat(renditionKey)
+		renditionMissing = err != nil
+	} else {
+		renditionMissing = media.UploadTime == nil || media.UploadTime.Before(graceLimit)
+	}
+	if renditionMissing {
+		issue := model.FsckIssue{Kind: model.FSCK_MISSING_RENDITION, Path: renditionKey, DocumentId: &media.Id}
+		if repair {
+			issue.Repaired = s.queueRendition(media)
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// Hash an original file, once for all the medias sharing it. Originals that couldn't be read entirely get an empty hash.
+func (s fsckService) hashOriginal(backend storage.Backend, key string, originalHashes map[string]string) (string, error) {
+	if hash, ok := originalHashes[key]; ok {
+		return hash, nil
+	}
+	original, err := backend.Get(key, 0, -1)
+	if err != nil {
+		return "", err
+	}
+	defer original.Close()
+
+	hash := ""
+	if computed, err := utils.HashData(original); err == nil {
+		hash = *computed
+	}
+	originalHashes[key] = hash
+	return hash, nil
+}
+
+// Check that every blob counts the medias using it, and that every stored content is tracked by a blob
+func (s fsckService) checkBlobs(medias []model.Media, repair bool) ([]model.FsckIssue, error) {
+	issues := make([]model.FsckIssue, 0)
+	blobs, err := s.blobRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	references := map[string]int64{}
+	for _, media := range medias {
+		if media.StorageFileName != nil {
+			references[*media.StorageFileName] += 1
+		}
+	}
+
+	tracked := map[string]bool{}
+	for _, blob := range blobs {
+		tracked[blob.Hash] = true
+		count := references[blob.StorageFileName]
+		if count == blob.RefCount {
+			continue
+		}
+		issue := model.FsckIssue{Kind: model.FSCK_BLOB_REFCOUNT, Path: path.Join(common.ORIGINAL_MEDIA_DIRECTORY, blob.StorageFileName)}
+		if repair {
+			if count == 0 {
+				// The file is not used anymore, it will be reported as orphan
+				err = s.blobRepository.Delete(blob.Hash)
+			} else {
+				blob.RefCount = count
+				err = s.blobRepository.Save(&blob)
+			}
+			if err != nil {
+				slog.Error("couldn't fix blob reference count", "hash", blob.Hash, "error", err)
+			} else {
+				issue.Repaired = true
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	// Medias uploaded before deduplication, the first one found for a given hash becomes the shared blob
+	for _, media := range medias {
+		if media.Hash == nil || media.StorageFileName == nil || tracked[*media.Hash] {
+			continue
+		}
+		tracked[*media.Hash] = true
+		originalKey := path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName)
+		issue := model.FsckIssue{Kind: model.FSCK_UNTRACKED_BLOB, Path: originalKey, DocumentId: &media.Id}
+		if repair {
+			originalStorage := s.storage
+			if media.IsArchived() && s.archive != nil {
+				originalStorage = s.archive
+			}
+			size := int64(0)
+			if info, err := originalStorage.Stat(originalKey); err == nil {
+				size = info.Size
+			}
+			blob := model.Blob{Hash: *media.Hash, StorageFileName: *media.StorageFileName, Size: size, RefCount: references[*media.StorageFileName], CreatedAt: media.UploadTime}
+			if err := s.blobRepository.Save(&blob); err != nil {
+				slog.Error("couldn't create blob", "hash", blob.Hash, "error", err)
+			} else {
+				issue.Repaired = true
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func (s fsckService) checkDownload(download *model.Download, graceLimit time.Time, repair bool) *model.FsckIssue {
+	zipKey := ""
+	if download.ZipFileName != nil {
+		zipKey = path.Join(common.DOWNLOAD_DIRECTORY, *download.ZipFileName)
+		if _, err := s.storage.Stat(zipKey); err == nil {
+			return nil
+		}
+	}
+	// A download still being zipped has no file yet
+	if !download.IsReady && download.StartedAt != nil && download.StartedAt.After(graceLimit) {
+		return nil
+	}
+	issue := model.FsckIssue{Kind: model.FSCK_DANGLING_DOWNLOAD, Path: zipKey, DocumentId: download.Id}
+	if repair {
+		if err := s.downloadRepository.Delete(download.Id); err != nil {
+			slog.Error("couldn't delete dangling download", "downloadId", download.Id.Hex(), "error", err)
+		} else {
+			issue.Repaired = true
+		}
+	}
+	return &issue
+}
+
+func (s fsckService) checkOrphanFiles(backend storage.Backend, directory string, referenced map[string]bool, graceLimit time.Time, repair bool) ([]model.FsckIssue, error) {
+	issues := make([]model.FsckIssue, 0)
+	objects, err := backend.List(directory + "/")
+	if err != nil {
+		slog.Error("couldn't list stored files", "directory", directory, "error", err)
+		return nil, err
+	}
+	for _, object := range objects {
+		if referenced[object.Key] || object.ModTime.After(graceLimit) {
+			continue
+		}
+		kind := model.FSCK_ORPHAN_FILE
+		if ext := path.Ext(object.Key); directory == common.UPLOAD_DIRECTORY || ext == ".info" || ext == ".lock" {
+			kind = model.FSCK_LEFTOVER_UPLOAD
+		}
+		issue := model.FsckIssue{Kind: kind, Path: object.Key}
+		if repair {
+			if err := backend.Delete(object.Key); err != nil {
+				slog.Error("couldn't remove orphan file", "file", object.Key, "error", err)
+			} else {
+				issue.Repaired = true
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// Remove a media document and everything pointing to it
+func (s fsckService) removeMediaDocument(media *model.Media) bool {
+	mediaId := &media.Id
+	if err := s.mediaAccessRepository.RemoveAll(mediaId); err != nil {
+		slog.Error("couldn't remove media accesses", "mediaId", mediaId.Hex(), "error", err)
+		return false
+	}
+	if err := s.mediaInAlbumRepository.RemoveMediaFromAllAlbums(mediaId); err != nil {
+		slog.Error("couldn't remove media from albums", "mediaId", mediaId.Hex(), "error", err)
+		return false
+	}
+	// Release the usage of the media like a deletion does, the document is kept when it couldn't be
+	if media.ChargedTo != nil {
+		if svcErr := s.quotaService.Charge(media.ChargedTo, -media.ChargedBytes, -1); svcErr != nil {
+			return false
+		}
+	}
+	if err := s.mediaRepository.Delete(mediaId); err != nil {
+		slog.Error("couldn't remove media", "mediaId", mediaId.Hex(), "error", err)
+		if media.ChargedTo != nil {
+			s.quotaService.Charge(media.ChargedTo, media.ChargedBytes, 1)
+		}
+		return false
+	}
+	if media.Hash != nil && media.StorageFileName != nil {
+		if _, err := s.blobRepository.Release(*media.Hash, *media.StorageFileName); err != nil {
+			slog.Error("couldn't release media blob", "mediaId", mediaId.Hex(), "error", err)
+			return false
+		}
+	}
+	return true
+}
+
+// Forget the missing rendition and queue the media for compression. When the queue is not consumed (e.g. from the CLI),
+// the rendition is regenerated the next time it is requested.
+func (s fsckService) queueRendition(media *model.Media) bool {
+	if media.CompressedFileName != nil {
+		if err := s.mediaRepository.UpdateAllByStorageFileName(*media.StorageFileName, bson.M{"compressedFileName": nil}); err != nil {
+			slog.Error("couldn't reset compressed file name", "mediaId", media.Id.Hex(), "error", err)
+			return false
+		}
+	}
+	compression.AddToCompressQueue(&media.Id)
+	return true
+}
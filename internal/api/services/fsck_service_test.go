@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"crypto/sha256"
+	"data-storage-svc/internal"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/storage"
+	"data-storage-svc/internal/utils"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A storage backend counting the files read
+type countingBackend struct {
+	storage.Backend
+	reads map[string]int
+}
+
+func (b countingBackend) Get(key string, offset int64, length int64) (io.ReadCloser, error) {
+	b.reads[key] += 1
+	return b.Backend.Get(key, offset, length)
+}
+
+func fsckHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Write a file in the data directory, old enough to be checked unless recent
+func writeFsckFile(t *testing.T, key string, content string, recent bool) {
+	filePath := filepath.Join(internal.DATA_DIRECTORY, filepath.FromSlash(key))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+	if !recent {
+		old := time.Now().Add(-2 * time.Hour)
+		assert.Nil(t, os.Chtimes(filePath, old, old))
+	}
+}
+
+func fsckFileExists(key string) bool {
+	_, err := os.Stat(filepath.Join(internal.DATA_DIRECTORY, filepath.FromSlash(key)))
+	return err == nil
+}
+
+func TestFsck(t *testing.T) {
+	for _, repair := range []bool{false, true} {
+		t.Run(map[bool]string{false: "Report", true: "Repair"}[repair], func(t *testing.T) {
+			internal.DATA_DIRECTORY = t.TempDir()
+			uploadTime := time.Now().Add(-2 * time.Hour)
+			userId := primitive.NewObjectID()
+			sharedName, missingName, corruptedName := "shared.jpg", "missing.jpg", "corrupted.jpg"
+			sharedHash, missingHash, corruptedHash := fsckHash("shared"), fsckHash("missing"), fsckHash("original")
+			sharedRendition, corruptedRendition := "shared.webp", "corrupted.webp"
+
+			// Two medias share an original, one lost its original, one original changed since its upload
+			first := model.Media{Id: primitive.NewObjectID(), StorageFileName: &sharedName, Hash: &sharedHash, CompressedFileName: &sharedRendition, UploadTime: &uploadTime}
+			second := model.Media{Id: primitive.NewObjectID(), StorageFileName: &sharedName, Hash: &sharedHash, CompressedFileName: &sharedRendition, UploadTime: &uploadTime}
+			missing := model.Media{Id: primitive.NewObjectID(), StorageFileName: &missingName, Hash: &missingHash, UploadTime: &uploadTime, ChargedTo: &userId, ChargedBytes: 10}
+			corrupted := model.Media{Id: primitive.NewObjectID(), StorageFileName: &corruptedName, Hash: &corruptedHash, CompressedFileName: &corruptedRendition, UploadTime: &uploadTime}
+			writeFsckFile(t, "originalMedias/shared.jpg", "shared", false)
+			writeFsckFile(t, "originalMedias/corrupted.jpg", "tampered", false)
+			writeFsckFile(t, "compressedMedias/shared.webp", "rendition", false)
+			writeFsckFile(t, "compressedMedias/corrupted.webp", "rendition", false)
+
+			// Files no document points to, the recent one may belong to an upload in progress
+			writeFsckFile(t, "originalMedias/orphan.jpg", "orphan", false)
+			writeFsckFile(t, "originalMedias/recent.jpg", "recent", true)
+			writeFsckFile(t, "uploads/aborted.info", "{}", false)
+
+			// A ready download lost its zip file
+			readyZip, goneZip := "ready.zip", "gone.zip"
+			ready := model.Download{Id: utils.Ptr(primitive.NewObjectID()), ZipFileName: &readyZip, IsReady: true}
+			dangling := model.Download{Id: utils.Ptr(primitive.NewObjectID()), ZipFileName: &goneZip, IsReady: true}
+			writeFsckFile(t, "downloads/ready.zip", "zip", false)
+
+			mediaRepository := &mocks.MediaRepository{}
+			mediaRepository.On("GetAll").Return([]model.Media{first, second, missing, corrupted}, nil)
+			mediaRepository.On("Delete", &missing.Id).Return(nil)
+			downloadRepository := &mocks.DownloadRepository{}
+			downloadRepository.On("GetAll").Return([]model.Download{ready, dangling}, nil)
+			downloadRepository.On("Delete", dangling.Id).Return(nil)
+			// The shared blob only counts one of its medias
+			blobRepository := &mocks.BlobRepository{}
+			blobRepository.On("GetAll").Return([]model.Blob{
+				{Hash: sharedHash, StorageFileName: sharedName, RefCount: 1},
+				{Hash: missingHash, StorageFileName: missingName, RefCount: 1},
+				{Hash: corruptedHash, StorageFileName: corruptedName, RefCount: 1},
+			}, nil)
+			blobRepository.On("Save", mock.MatchedBy(func(blob *model.Blob) bool { return blob.Hash == sharedHash && blob.RefCount == 2 })).Return(nil)
+			blobRepository.On("Release", missingHash, missingName).Return(true, nil)
+			mediaAccessRepository := &mocks.MediaAccessRepository{}
+			mediaAccessRepository.On("RemoveAll", &missing.Id).Return(nil)
+			mediaInAlbumRepository := &mocks.MediaInAlbumRepository{}
+			mediaInAlbumRepository.On("RemoveMediaFromAllAlbums", &missing.Id).Return(nil)
+			quotaService := &mocks.QuotaService{}
+			quotaService.On("Charge", &userId, int64(-10), int64(-1)).Return(nil)
+
+			backend := countingBackend{storage.NewLocalBackend(internal.DATA_DIRECTORY), map[string]int{}}
+			svc := services.NewFsckService(mediaRepository, downloadRepository, mediaInAlbumRepository, mediaAccessRepository, blobRepository, quotaService, backend, nil)
+			report, err := svc.Run(repair, time.Hour)
+			assert.Nil(t, err)
+
+			kinds := map[model.FsckIssueKind]string{}
+			for _, issue := range report.Issues {
+				kinds[issue.Kind] = issue.Path
+				// A corrupted original cannot be repaired
+				assert.Equal(t, repair && issue.Kind != model.FSCK_CORRUPTED_ORIGINAL, issue.Repaired, issue.Kind)
+			}
+			assert.Len(t, report.Issues, 6)
+			assert.Equal(t, "originalMedias/missing.jpg", kinds[model.FSCK_MISSING_ORIGINAL])
+			assert.Equal(t, "originalMedias/corrupted.jpg", kinds[model.FSCK_CORRUPTED_ORIGINAL])
+			assert.Equal(t, "originalMedias/shared.jpg", kinds[model.FSCK_BLOB_REFCOUNT])
+			assert.Equal(t, "originalMedias/orphan.jpg", kinds[model.FSCK_ORPHAN_FILE])
+			assert.Equal(t, "uploads/aborted.info", kinds[model.FSCK_LEFTOVER_UPLOAD])
+			assert.Equal(t, "downloads/gone.zip", kinds[model.FSCK_DANGLING_DOWNLOAD])
+			// The shared original is hashed once for both its medias
+			assert.Equal(t, 1, backend.reads["originalMedias/shared.jpg"])
+			assert.True(t, fsckFileExists("originalMedias/recent.jpg"))
+
+			if !repair {
+				assert.Equal(t, 6, report.Unrepaired())
+				assert.True(t, fsckFileExists("originalMedias/orphan.jpg"))
+				mediaRepository.AssertNotCalled(t, "Delete", mock.Anything)
+				blobRepository.AssertNotCalled(t, "Save", mock.Anything)
+				quotaService.AssertNotCalled(t, "Charge", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, 1, report.Unrepaired())
+			assert.False(t, fsckFileExists("originalMedias/orphan.jpg"))
+			assert.False(t, fsckFileExists("uploads/aborted.info"))
+			// The media without original is removed along with everything pointing to it, its usage is released
+			mediaRepository.AssertExpectations(t)
+			mediaAccessRepository.AssertExpectations(t)
+			mediaInAlbumRepository.AssertExpectations(t)
+			blobRepository.AssertExpectations(t)
+			downloadRepository.AssertExpectations(t)
+			quotaService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GroupService interface {
+	// Create a group, its owner is its first member
+	Create(ownerId *primitive.ObjectID, name string) (*model.Group, utils.ServiceError)
+	// Get a group by id
+	GetById(groupId *primitive.ObjectID) (*model.Group, utils.ServiceError)
+	// List the groups a user is a member of
+	GetAllForUser(userId *primitive.ObjectID) ([]model.Group, utils.ServiceError)
+	// Add a member to a group, they get access to the albums shared with it
+	AddMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError
+	// Remove a member from a group, they lose access to the albums shared with it only
+	RemoveMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError
+	// Delete a group and the accesses granted to it
+	Delete(groupId *primitive.ObjectID) utils.ServiceError
+}
+
+type groupService struct {
+	// Repository dependencies
+	groupRepository       repository.GroupRepository
+	albumAccessRepository repository.AlbumAccessRepository
+}
+
+func NewGroupService(groupRepository repository.GroupRepository, albumAccessRepository repository.AlbumAccessRepository) groupService {
+	return groupService{groupRepository, albumAccessRepository}
+}
+
+func (s groupService) Create(ownerId *primitive.ObjectID, name string) (*model.Group, utils.ServiceError) {
+	if len(name) == 0 || len(name) > 100 {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "invalid group name")
+	}
+	group := model.Group{
+		Name:         name,
+		OwnerId:      *ownerId,
+		MemberIds:    []primitive.ObjectID{*ownerId},
+		CreationDate: time.Now(),
+	}
+	groupId, err := s.groupRepository.Create(&group)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create group")
+	}
+	group.Id = *groupId
+	return &group, nil
+}
+
+func (s groupService) GetById(groupId *primitive.ObjectID) (*model.Group, utils.ServiceError) {
+	group, err := s.groupRepository.Get(groupId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "group not found")
+	}
+	return group, nil
+}
+
+func (s groupService) GetAllForUser(userId *primitive.ObjectID) ([]model.Group, utils.ServiceError) {
+	groups, err := s.groupRepository.GetAllByMember(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list groups")
+	}
+	return groups, nil
+}
+
+func (s groupService) AddMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	if err := s.groupRepository.AddMember(groupId, userId); err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "group not found")
+	}
+	return nil
+}
+
+func (s groupService) RemoveMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	group, svcErr := s.GetById(groupId)
+	if svcErr != nil {
+		return svcErr
+	}
+	// A group always has its owner among its members
+	if group.OwnerId == *userId {
+		return utils.NewServiceError(http.StatusBadRequest, "the owner cannot leave the group, delete it instead")
+	}
+	if !group.HasMember(*userId) {
+		return utils.NewServiceError(http.StatusNotFound, "user is not a member of the group")
+	}
+	if err := s.groupRepository.RemoveMember(groupId, userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't remove member")
+	}
+	return nil
+}
+
+func (s groupService) Delete(groupId *primitive.ObjectID) utils.ServiceError {
+	if err := s.albumAccessRepository.RemoveAllForGroup(groupId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete group")
+	}
+	if err := s.groupRepository.Delete(groupId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete group")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateGroup(t *testing.T) {
+	ownerId := primitive.NewObjectID()
+	groupRepository := &mocks.GroupRepository{}
+	groupRepository.On("Create", mock.Anything).Return(&primitive.ObjectID{}, nil)
+	svc := services.NewGroupService(groupRepository, nil)
+
+	// The owner is the first member
+	group, err := svc.Create(&ownerId, "Family")
+	assert.Nil(t, err)
+	assert.Equal(t, ownerId, group.OwnerId)
+	assert.True(t, group.HasMember(ownerId))
+
+	_, err = svc.Create(&ownerId, "")
+	assert.Equal(t, 400, err.GetCode())
+}
+
+func TestRemoveGroupMember(t *testing.T) {
+	groupId := primitive.NewObjectID()
+	ownerId := primitive.NewObjectID()
+	memberId := primitive.NewObjectID()
+	strangerId := primitive.NewObjectID()
+
+	groupRepository := &mocks.GroupRepository{}
+	groupRepository.On("Get", &groupId).Return(&model.Group{Id: groupId, OwnerId: ownerId, MemberIds: []primitive.ObjectID{ownerId, memberId}}, nil)
+	groupRepository.On("RemoveMember", &groupId, &memberId).Return(nil)
+	svc := services.NewGroupService(groupRepository, nil)
+
+	assert.Nil(t, svc.RemoveMember(&groupId, &memberId))
+	// The owner cannot leave their group
+	assert.Equal(t, 400, svc.RemoveMember(&groupId, &ownerId).GetCode())
+	assert.Equal(t, 404, svc.RemoveMember(&groupId, &strangerId).GetCode())
+	groupRepository.AssertNumberOfCalls(t, "RemoveMember", 1)
+}
+
+func TestDeleteGroup(t *testing.T) {
+	groupId := primitive.NewObjectID()
+	groupRepository := &mocks.GroupRepository{}
+	groupRepository.On("Delete", &groupId).Return(nil)
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	albumAccessRepository.On("RemoveAllForGroup", &groupId).Return(nil)
+	svc := services.NewGroupService(groupRepository, albumAccessRepository)
+
+	// Members lose the accesses granted to the group
+	assert.Nil(t, svc.Delete(&groupId))
+	albumAccessRepository.AssertExpectations(t)
+	groupRepository.AssertExpectations(t)
+}
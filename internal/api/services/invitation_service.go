@@ -0,0 +1,144 @@
+package services
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/mail"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Time given to the recipient to create their account
+const INVITATION_DURATION = 7 * 24 * time.Hour
+
+type InvitationService interface {
+	// Invite someone by email, optionally to an album. Existing users are given access to the album right away.
+	Invite(inviter *model.User, email string, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError
+	// Create the account of an invited user with the password they chose, every album they were invited to is shared
+	// with them
+	Accept(token string, password string) (*primitive.ObjectID, utils.ServiceError)
+}
+
+type invitationService struct {
+	// Repository dependencies
+	userTokenRepository repository.UserTokenRepository
+	userRepository      repository.UserRepository
+	albumRepository     repository.AlbumRepository
+	hashModule          security.HashModule
+	passwordPolicy      security.PasswordPolicy
+	// Service dependencies
+	albumAccessService AlbumAccessService
+	mailer             mail.Mailer
+	// URL of the application, links in the emails point to it
+	appUrl string
+}
+
+func NewInvitationService(userTokenRepository repository.UserTokenRepository, userRepository repository.UserRepository, albumRepository repository.AlbumRepository, hashModule security.HashModule, passwordPolicy security.PasswordPolicy, albumAccessService AlbumAccessService, mailer mail.Mailer, appUrl string) invitationService {
+	return invitationService{userTokenRepository, userRepository, albumRepository, hashModule, passwordPolicy, albumAccessService, mailer, appUrl}
+}
+
+func (s invitationService) Invite(inviter *model.User, email string, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError {
+	if !utils.IsValidEmail(email) {
+		return utils.NewServiceError(http.StatusBadRequest, "invalid email address")
+	}
+	if albumId != nil && !role.IsGrantable() {
+		return utils.NewServiceError(http.StatusBadRequest, "this role cannot be granted")
+	}
+	invitedTo := "the photo album"
+	if albumId != nil {
+		album, err := s.albumRepository.GetById(*albumId)
+		if err != nil {
+			return utils.NewServiceError(http.StatusNotFound, "album not found")
+		}
+		invitedTo = fmt.Sprintf("the album \"%s\"", album.Title)
+	}
+	if existing, err := s.userRepository.GetByEmail(email); err == nil {
+		if albumId == nil {
+			return utils.NewServiceError(http.StatusConflict, "a user already exists with this email")
+		}
+		return s.albumAccessService.GrantAccess(&existing.Id, albumId, role)
+	}
+
+	token, hash, err := security.GenerateUserToken()
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't create invitation")
+	}
+	now := time.Now()
+	invitation := model.UserToken{
+		Kind:      model.USER_TOKEN_INVITATION,
+		TokenHash: hash,
+		Email:     email,
+		AlbumId:   albumId,
+		Role:      role,
+		CreatedBy: &inviter.Id,
+		CreatedAt: now,
+		ExpiresAt: now.Add(INVITATION_DURATION),
+	}
+	invitationId, err := s.userTokenRepository.Create(&invitation)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't create invitation")
+	}
+
+	message := mail.Message{
+		To:      email,
+		Subject: fmt.Sprintf("%s invited you to %s", inviter.Email, invitedTo),
+		Body: fmt.Sprintf("%s invited you to %s.\n\nCreate your account: %s/invitation?token=%s\n\nThis invitation expires on %s.\n",
+			inviter.Email, invitedTo, s.appUrl, token, invitation.ExpiresAt.Format(time.RFC1123)),
+	}
+	if err := s.mailer.Send(message); err != nil {
+		slog.Error("couldn't send invitation", "email", email, "error", err)
+		s.userTokenRepository.Delete(invitationId)
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't send invitation email")
+	}
+	return nil
+}
+
+func (s invitationService) Accept(token string, password string) (*primitive.ObjectID, utils.ServiceError) {
+	invitation, err := s.userTokenRepository.GetByHash(model.USER_TOKEN_INVITATION, security.HashUserToken(token))
+	if err != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "invalid or expired invitation")
+	}
+	if _, err := s.userRepository.GetByEmail(invitation.Email); err != mongo.ErrNoDocuments {
+		return nil, utils.NewServiceError(http.StatusConflict, "an account already exists for this email, please log in")
+	}
+	if svcErr := checkPassword(s.passwordPolicy, password, invitation.Email); svcErr != nil {
+		return nil, svcErr
+	}
+	hash, err := s.hashModule.HashPassword(password)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create account")
+	}
+	userId, err := s.userRepository.Create(&model.User{Email: invitation.Email, PasswordHash: hash, JoinDate: time.Now()})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, utils.NewServiceError(http.StatusConflict, "an account already exists for this email, please log in")
+		}
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create account")
+	}
+
+	// The user may have been invited to several albums before creating their account
+	invitations, err := s.userTokenRepository.GetAllForEmail(model.USER_TOKEN_INVITATION, invitation.Email)
+	if err != nil {
+		slog.Error("couldn't list invitations", "email", invitation.Email, "error", err)
+		invitations = []model.UserToken{*invitation}
+	}
+	for _, pending := range invitations {
+		if pending.AlbumId == nil || time.Now().After(pending.ExpiresAt) {
+			continue
+		}
+		if svcErr := s.albumAccessService.GrantAccess(userId, pending.AlbumId, pending.Role); svcErr != nil {
+			slog.Error("couldn't share invited album", "userId", userId.Hex(), "albumId", pending.AlbumId.Hex())
+		}
+	}
+	if err := s.userTokenRepository.DeleteAllForEmail(model.USER_TOKEN_INVITATION, invitation.Email); err != nil {
+		slog.Error("couldn't delete invitations", "email", invitation.Email, "error", err)
+	}
+	return userId, nil
+}
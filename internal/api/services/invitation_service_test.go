@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mail"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Get the token out of the link sent by email
+func tokenFromMessage(t *testing.T, message mail.Message) string {
+	_, after, found := strings.Cut(message.Body, "token=")
+	if !found {
+		t.Fatalf("no token in email: %s", message.Body)
+	}
+	return strings.Fields(after)[0]
+}
+
+func TestInvite(t *testing.T) {
+	inviter := model.User{Id: primitive.NewObjectID(), Email: "owner@test.fr"}
+	albumId := primitive.NewObjectID()
+	existingId := primitive.NewObjectID()
+
+	userTokenRepository := &mocks.UserTokenRepository{}
+	userRepository := &mocks.UserRepository{}
+	albumRepository := &mocks.AlbumRepository{}
+	albumAccessService := &mocks.AlbumAccessService{}
+	mailer := &mocks.Mailer{}
+
+	userRepository.On("GetByEmail", "existing@test.fr").Return(&model.User{Id: existingId, Email: "existing@test.fr"}, nil)
+	userRepository.On("GetByEmail", "new@test.fr").Return((*model.User)(nil), mongo.ErrNoDocuments)
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, Title: "Holidays"}, nil)
+	albumAccessService.On("GrantAccess", &existingId, &albumId, model.ALBUM_ROLE_EDITOR).Return(nil)
+	invitationId := primitive.NewObjectID()
+	userTokenRepository.On("Create", mock.Anything).Return(&invitationId, nil)
+	var sent mail.Message
+	mailer.On("Send", mock.Anything).Run(func(args mock.Arguments) { sent = args.Get(0).(mail.Message) }).Return(nil)
+
+	svc := services.NewInvitationService(userTokenRepository, userRepository, albumRepository, nil, nil, albumAccessService, mailer, "https://photos.test.fr")
+
+	// Invalid email
+	err := svc.Invite(&inviter, "invalidEmail", nil, "")
+	assert.Equal(t, 400, err.GetCode())
+
+	// Existing users have nothing to create, they are given access right away
+	err = svc.Invite(&inviter, "existing@test.fr", nil, "")
+	assert.Equal(t, 409, err.GetCode())
+	assert.Nil(t, svc.Invite(&inviter, "existing@test.fr", &albumId, model.ALBUM_ROLE_EDITOR))
+	albumAccessService.AssertCalled(t, "GrantAccess", &existingId, &albumId, model.ALBUM_ROLE_EDITOR)
+	// Albums have a single owner
+	err = svc.Invite(&inviter, "existing@test.fr", &albumId, model.ALBUM_ROLE_OWNER)
+	assert.Equal(t, 400, err.GetCode())
+	mailer.AssertNotCalled(t, "Send", mock.Anything)
+
+	// New user gets an email with a link, only the hash of its token is stored
+	assert.Nil(t, svc.Invite(&inviter, "new@test.fr", &albumId, model.ALBUM_ROLE_VIEWER))
+	assert.Equal(t, "new@test.fr", sent.To)
+	assert.Contains(t, sent.Body, "https://photos.test.fr/invitation?token=")
+	assert.Contains(t, sent.Subject, "Holidays")
+	stored := userTokenRepository.Calls[0].Arguments.Get(0).(*model.UserToken)
+	assert.Equal(t, model.USER_TOKEN_INVITATION, stored.Kind)
+	assert.Equal(t, security.HashUserToken(tokenFromMessage(t, sent)), stored.TokenHash)
+	assert.Equal(t, &albumId, stored.AlbumId)
+	assert.Equal(t, &inviter.Id, stored.CreatedBy)
+	assert.Equal(t, model.ALBUM_ROLE_VIEWER, stored.Role)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	otherAlbumId := primitive.NewObjectID()
+	token, hash, _ := security.GenerateUserToken()
+	expiredToken, expiredHash, _ := security.GenerateUserToken()
+	invitation := model.UserToken{Kind: model.USER_TOKEN_INVITATION, TokenHash: hash, Email: "new@test.fr", AlbumId: &albumId, Role: model.ALBUM_ROLE_VIEWER, ExpiresAt: time.Now().Add(time.Hour)}
+
+	userTokenRepository := &mocks.UserTokenRepository{}
+	userRepository := &mocks.UserRepository{}
+	hashModule := &mocks.HashModule{}
+	albumAccessService := &mocks.AlbumAccessService{}
+
+	userTokenRepository.On("GetByHash", model.USER_TOKEN_INVITATION, hash).Return(&invitation, nil)
+	userTokenRepository.On("GetByHash", model.USER_TOKEN_INVITATION, expiredHash).Return(&model.UserToken{Email: "new@test.fr", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+	userTokenRepository.On("GetByHash", model.USER_TOKEN_INVITATION, mock.Anything).Return((*model.UserToken)(nil), mongo.ErrNoDocuments)
+	// Invited to another album before
+	userTokenRepository.On("GetAllForEmail", model.USER_TOKEN_INVITATION, "new@test.fr").Return([]model.UserToken{
+		invitation,
+		{Kind: model.USER_TOKEN_INVITATION, Email: "new@test.fr", AlbumId: &otherAlbumId, Role: model.ALBUM_ROLE_EDITOR, ExpiresAt: time.Now().Add(time.Hour)},
+	}, nil)
+	userTokenRepository.On("DeleteAllForEmail", model.USER_TOKEN_INVITATION, "new@test.fr").Return(nil)
+	userRepository.On("GetByEmail", "new@test.fr").Return((*model.User)(nil), mongo.ErrNoDocuments)
+	userId := primitive.NewObjectID()
+	userRepository.On("Create", mock.MatchedBy(func(user *model.User) bool {
+		return user.Email == "new@test.fr" && user.PasswordHash == "hashedpassword"
+	})).Return(&userId, nil)
+	hashModule.On("HashPassword", "achosenpassword").Return("hashedpassword", nil)
+	albumAccessService.On("GrantAccess", &userId, &albumId, model.ALBUM_ROLE_VIEWER).Return(nil)
+	albumAccessService.On("GrantAccess", &userId, &otherAlbumId, model.ALBUM_ROLE_EDITOR).Return(nil)
+
+	svc := services.NewInvitationService(userTokenRepository, userRepository, nil, hashModule, testPasswordPolicy(), albumAccessService, nil, "")
+
+	_, err := svc.Accept("unknowntoken", "achosenpassword")
+	assert.Equal(t, 400, err.GetCode())
+	_, err = svc.Accept(expiredToken, "achosenpassword")
+	assert.Equal(t, 400, err.GetCode())
+	_, err = svc.Accept(token, "a")
+	assert.Equal(t, 400, err.GetCode())
+	userRepository.AssertNotCalled(t, "Create", mock.Anything)
+
+	createdId, err := svc.Accept(token, "achosenpassword")
+	assert.Nil(t, err)
+	assert.Equal(t, userId, *createdId)
+	// Every album the user was invited to is shared
+	albumAccessService.AssertExpectations(t)
+	userTokenRepository.AssertCalled(t, "DeleteAllForEmail", model.USER_TOKEN_INVITATION, "new@test.fr")
+}
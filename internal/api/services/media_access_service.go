@@ -1,16 +1,24 @@
 package services
 
 import (
+	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
 	"data-storage-svc/internal/utils"
 	"net/http"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MediaAccessService interface {
-	// Grant access to a media for a given user
+	// Grant access to a media for a given user, granting it again changes nothing
 	GrantAccess(userId *primitive.ObjectID, mediaId *primitive.ObjectID) utils.ServiceError
+	// Revoke the access of a user to a media
+	RevokeAccess(userId *primitive.ObjectID, mediaId *primitive.ObjectID) utils.ServiceError
+	// List the accesses to a media
+	GetAllForMedia(mediaId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError)
+	// List the medias a user has access to
+	GetAllForUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError)
 	// Revoke all accesses to a media for all users
 	RevokeAll(mediaId *primitive.ObjectID) utils.ServiceError
 	// Check if a user can view a given media
@@ -27,12 +35,35 @@ func NewMediaAccessService(mediaAccessRepository repository.MediaAccessRepositor
 
 func (s mediaAccessService) GrantAccess(userId *primitive.ObjectID, mediaId *primitive.ObjectID) utils.ServiceError {
 	err := s.mediaAccessRepository.Create(userId, mediaId)
-	if err != nil {
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
 		return utils.NewServiceError(http.StatusInternalServerError, "couldn't grant access to media")
 	}
 	return nil
 }
 
+func (s mediaAccessService) RevokeAccess(userId *primitive.ObjectID, mediaId *primitive.ObjectID) utils.ServiceError {
+	if err := s.mediaAccessRepository.Remove(userId, mediaId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't revoke access to media")
+	}
+	return nil
+}
+
+func (s mediaAccessService) GetAllForMedia(mediaId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError) {
+	accesses, err := s.mediaAccessRepository.GetAllForMedia(mediaId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list media accesses")
+	}
+	return accesses, nil
+}
+
+func (s mediaAccessService) GetAllForUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError) {
+	accesses, err := s.mediaAccessRepository.GetAllForUser(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list media accesses")
+	}
+	return accesses, nil
+}
+
 func (s mediaAccessService) RevokeAll(mediaId *primitive.ObjectID) utils.ServiceError {
 	err := s.mediaAccessRepository.RemoveAll(mediaId)
 	if err != nil {
@@ -1,33 +1,45 @@
 package services
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"data-storage-svc/internal"
 	"data-storage-svc/internal/api/common"
 	"data-storage-svc/internal/compression"
 	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/storage"
 	"data-storage-svc/internal/utils"
+	"encoding/hex"
+	"io"
 	"log/slog"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
 	"time"
 
 	"github.com/evanoberholster/imagemeta"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MediaService interface {
-	// Create a new media resource
-	Create(originalFilename, storageFilename string, uploader *primitive.ObjectID, uploadedViaSharedLink bool) (*primitive.ObjectID, utils.ServiceError)
+	// Create a new media resource, storing the given data and counting it against the chargedTo user quota. Content
+	// already stored is shared with the existing medias, and uploading the same content twice returns the existing media.
+	Create(originalFilename string, uploader *primitive.ObjectID, uploadedViaSharedLink bool, chargedTo *primitive.ObjectID, data io.ReadCloser) (*primitive.ObjectID, utils.ServiceError)
 	// Get media by id
 	GetById(mediaId *primitive.ObjectID) (*model.Media, utils.ServiceError)
-	// Get the media data (i.e. bytes of the file stored on disk)
-	GetData(mediaId *primitive.ObjectID, storageFileName string, compressedFilename *string, compressed bool) (*string, *os.File, *time.Time, utils.ServiceError)
+	// Get the media data (i.e. bytes of the stored file), the returned reader must be closed. Archived originals are
+	// restored in the background.
+	GetData(media *model.Media, compressed bool) (*string, io.ReadSeekCloser, *time.Time, utils.ServiceError)
+	// Get the name of the stored file holding the rendition or the original, once available in the main storage. Like
+	// GetData, renditions are built and archived originals restored in the background.
+	GetStoredFileName(media *model.Media, compressed bool) (*string, utils.ServiceError)
 	// Get the media metadata (i.e. exif data contained in original file)
 	GetMetaData(mediaId *primitive.ObjectID) (*model.MetaData, utils.ServiceError)
-	// Get all media accessible to a given user
-	GetAllSharedWithUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError)
+	// Get the medias shared directly with a given user, medias of their albums left out
+	GetAllSharedWithUser(userId *primitive.ObjectID) ([]model.Media, utils.ServiceError)
 	// Get all medias uploaded by user
 	GetAllUploadedByUser(userId *primitive.ObjectID) ([]model.Media, utils.ServiceError)
 	// Delete a specific media
@@ -40,43 +52,130 @@ type mediaService struct {
 	// Repository dependencies
 	mediaRepository        repository.MediaRepository
 	mediaInAblumRepository repository.MediaInAlbumRepository
+	blobRepository         repository.BlobRepository
 	// Service dependencies
 	mediaAccessService MediaAccessService
 	albumService       AlbumService
+	quotaService       QuotaService
+	// Where media files are stored
+	storage storage.Backend
+	// Where rarely accessed originals are moved (nil if tiering is disabled)
+	archive storage.Backend
 }
 
-func NewMediaService(mediaRepository repository.MediaRepository, mediaInAblumRepository repository.MediaInAlbumRepository, mediaAccessService MediaAccessService, albumService AlbumService) mediaService {
-	return mediaService{mediaRepository, mediaInAblumRepository, mediaAccessService, albumService}
+func NewMediaService(mediaRepository repository.MediaRepository, mediaInAblumRepository repository.MediaInAlbumRepository, blobRepository repository.BlobRepository, mediaAccessService MediaAccessService, albumService AlbumService, quotaService QuotaService, storage storage.Backend, archive storage.Backend) mediaService {
+	return mediaService{mediaRepository, mediaInAblumRepository, blobRepository, mediaAccessService, albumService, quotaService, storage, archive}
 }
 
-func (s mediaService) Create(originalFilename, storageFilename string, uploader *primitive.ObjectID, uploadedViaSharedLink bool) (*primitive.ObjectID, utils.ServiceError) {
+func (s mediaService) Create(originalFilename string, uploader *primitive.ObjectID, uploadedViaSharedLink bool, chargedTo *primitive.ObjectID, data io.ReadCloser) (*primitive.ObjectID, utils.ServiceError) {
+	if len(originalFilename) == 0 {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "invalid file name")
+	}
+	if data == nil {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "no data provided")
+	}
+	defer data.Close()
+
+	// Detect the media type from the data itself, fallback on the file name for types that cannot be sniffed (e.g. heic)
+	reader := bufio.NewReaderSize(data, 512)
+	header, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "couldn't read media data")
+	}
+	_, extension, _ := utils.CheckFileExtension(header)
+	if extension == "" {
+		extension, err = utils.FileNameToFileExtension(originalFilename)
+		if err != nil {
+			return nil, utils.NewServiceError(http.StatusBadRequest, "unsupported media type")
+		}
+	}
+
+	// Store the data, computing its hash on the fly
 	uploadTime := time.Now()
-	mediaDirectory, err := utils.GetDataDir(common.ORIGINAL_MEDIA_DIRECTORY)
+	storageFilename := uuid.NewString() + "." + extension
+	storageKey := path.Join(common.ORIGINAL_MEDIA_DIRECTORY, storageFilename)
+	hasher := sha256.New()
+	if err := s.storage.Put(storageKey, io.TeeReader(reader, hasher), -1); err != nil {
+		slog.Error("couldn't store media", "key", storageKey, "error", err)
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "unexpected error while creating media")
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	// Uploading the same content twice gives back the existing media
+	if existing, err := s.mediaRepository.GetByHash(hash, uploader); err == nil {
+		s.deleteStoredFile(storageKey)
+		slog.Debug("Media already uploaded by this user", "mediaId", existing.Id.Hex())
+		return &existing.Id, nil
+	}
+
+	// Reference the content, it is stored once for all the medias having the same hash
+	info, err := s.storage.Stat(storageKey)
 	if err != nil {
+		s.deleteStoredFile(storageKey)
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "unexpected error while creating media")
 	}
-	hash, err := utils.HashMedia(filepath.Join(mediaDirectory, storageFilename))
+	blob, err := s.blobRepository.Acquire(hash, storageFilename, info.Size)
 	if err != nil {
-		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't compute file hash")
-	}
-	mediaId, err := s.mediaRepository.Create(
-		&model.Media{
-			OriginalFileName:      &originalFilename,
-			StorageFileName:       &storageFilename,
-			UploadedBy:            uploader,
-			UploadTime:            &uploadTime,
-			UploadedViaSharedLink: uploadedViaSharedLink,
-			Hash:                  hash,
-		},
-	)
+		s.deleteStoredFile(storageKey)
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "unexpected error while creating media")
+	}
+	media := model.Media{
+		OriginalFileName:      &originalFilename,
+		StorageFileName:       &blob.StorageFileName,
+		UploadedBy:            uploader,
+		UploadTime:            &uploadTime,
+		UploadedViaSharedLink: uploadedViaSharedLink,
+		Hash:                  &hash,
+		OriginalTier:          model.TIER_HOT,
+		Size:                  blob.Size,
+		ChargedTo:             chargedTo,
+		ChargedBytes:          blob.Size,
+	}
+	if blob.StorageFileName != storageFilename {
+		// The content was already stored, drop the new copy and reuse the existing rendition
+		s.deleteStoredFile(storageKey)
+		if sibling, err := s.mediaRepository.GetOneByStorageFileName(blob.StorageFileName); err == nil {
+			media.CompressedFileName = sibling.CompressedFileName
+			media.OriginalTier = sibling.OriginalTier
+			media.LastAccessTime = sibling.LastAccessTime
+		}
+		if media.CompressedFileName != nil && internal.QUOTA_INCLUDE_RENDITIONS {
+			if info, err := s.storage.Stat(path.Join(common.COMPRESSED_DIRECTORY, *media.CompressedFileName)); err == nil {
+				media.ChargedBytes += info.Size
+			}
+		}
+	}
+
+	mediaId, err := s.mediaRepository.Create(&media)
 	if err != nil {
+		// Couldn't create the media, drop the reference to the stored data
+		if svcErr := s.releaseBlob(&media); svcErr != nil {
+			slog.Error("couldn't release media data that couldn't be added to DB", "hash", hash)
+		}
 		if mongo.IsDuplicateKeyError(err) {
+			// Same content uploaded concurrently by the same user
+			if existing, err := s.mediaRepository.GetByHash(hash, uploader); err == nil {
+				return &existing.Id, nil
+			}
 			return nil, utils.NewServiceError(http.StatusConflict, "media already exists")
 		}
 		return nil, utils.NewServiceError(http.StatusBadRequest, "couldn't upload file")
 	}
+	// Usage is counted per media, even when its content is shared. A media that couldn't be counted is not kept.
+	if chargedTo != nil {
+		if svcErr := s.quotaService.Charge(chargedTo, media.ChargedBytes, 1); svcErr != nil {
+			if err := s.mediaRepository.Delete(mediaId); err != nil {
+				slog.Error("couldn't remove media whose usage couldn't be counted", "mediaId", mediaId.Hex(), "error", err)
+			} else if svcErr := s.releaseBlob(&media); svcErr != nil {
+				slog.Error("couldn't release media data whose usage couldn't be counted", "hash", hash)
+			}
+			return nil, svcErr
+		}
+	}
 	// Add this media to the compression queue
-	compression.AddToCompressQueue(mediaId)
+	if media.CompressedFileName == nil {
+		compression.AddToCompressQueue(mediaId)
+	}
 	return mediaId, nil
 }
 
@@ -88,56 +187,63 @@ func (s mediaService) GetById(mediaId *primitive.ObjectID) (*model.Media, utils.
 	return media, nil
 }
 
-func (s mediaService) GetData(mediaId *primitive.ObjectID, storageFileName string, compressedFilename *string, compressed bool) (*string, *os.File, *time.Time, utils.ServiceError) {
-	// Choose the compressed version if needed
-	directory := ""
-	filename := ""
+func (s mediaService) GetData(media *model.Media, compressed bool) (*string, io.ReadSeekCloser, *time.Time, utils.ServiceError) {
+	fileName, svcErr := s.GetStoredFileName(media, compressed)
+	if svcErr != nil {
+		return nil, nil, nil, svcErr
+	}
+	directory := common.ORIGINAL_MEDIA_DIRECTORY
 	if compressed {
 		directory = common.COMPRESSED_DIRECTORY
-		if compressedFilename == nil {
-			compression.AddToCompressQueue(mediaId)
-			return nil, nil, nil, utils.NewServiceError(http.StatusAccepted, "media is being compressed")
-		}
-		filename = *compressedFilename
-	} else {
-		directory = common.ORIGINAL_MEDIA_DIRECTORY
-		filename = storageFileName
-	}
-	// Use corresponding directory
-	mediaDirectory, err := utils.GetDataDir(directory)
-	if err != nil {
-		return nil, nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't find media")
 	}
 	// Open file
-	filepath := filepath.Join(mediaDirectory, filename)
-	file, err := os.Open(filepath)
+	file, info, err := storage.Open(s.storage, path.Join(directory, *fileName))
 	if err != nil {
 		return nil, nil, nil, utils.NewServiceError(http.StatusNotFound, "couldn't open requested file")
 	}
-	fileheader, err := utils.GetFileHeader(filepath)
+	mimeType, err := utils.DetectMimeType(file)
 	if err != nil {
+		file.Close()
 		return nil, nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't get media mime type")
 	}
-	mimeType, _, _ := utils.CheckFileExtension(fileheader)
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't read file info")
-	}
-	modTime := fileInfo.ModTime()
+	modTime := info.ModTime
 	return &mimeType, file, &modTime, nil
 }
 
+func (s mediaService) GetStoredFileName(media *model.Media, compressed bool) (*string, utils.ServiceError) {
+	// Choose the compressed version if needed
+	if compressed {
+		if media.CompressedFileName == nil {
+			// The rendition is built from the original, it must be in the main storage first
+			if media.IsArchived() {
+				return nil, s.restore(media)
+			}
+			compression.AddToCompressQueue(&media.Id)
+			return nil, utils.NewServiceError(http.StatusAccepted, "media is being compressed")
+		}
+		return media.CompressedFileName, nil
+	}
+	if media.IsArchived() {
+		return nil, s.restore(media)
+	}
+	// Keep track of original accesses for the tiering policy
+	if err := s.mediaRepository.UpdateAllByStorageFileName(*media.StorageFileName, bson.M{"lastAccessTime": time.Now()}); err != nil {
+		slog.Error("couldn't update media last access time", "mediaId", media.Id.Hex(), "error", err)
+	}
+	return media.StorageFileName, nil
+}
+
 func (s mediaService) GetMetaData(mediaId *primitive.ObjectID) (*model.MetaData, utils.ServiceError) {
 	media, svcErr := s.GetById(mediaId)
 	if svcErr != nil {
 		return nil, svcErr
 	}
-	mediaDir, err := utils.GetDataDir("originalMedias")
-	if err != nil {
-		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't get meta data")
+	// Meta data are at the beginning of the file, read them in place even from the archive
+	backend, svcErr := s.originalStorage(media)
+	if svcErr != nil {
+		return nil, svcErr
 	}
-	mediaFilePath := filepath.Join(mediaDir, *media.StorageFileName)
-	mediaFile, err := os.Open(mediaFilePath)
+	mediaFile, _, err := storage.Open(backend, path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName))
 	if err != nil {
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't get meta data")
 	}
@@ -160,8 +266,20 @@ func (s mediaService) GetMetaData(mediaId *primitive.ObjectID) (*model.MetaData,
 	return &metaData, nil
 }
 
-func (s mediaService) GetAllSharedWithUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError) {
-	return nil, utils.NewServiceError(http.StatusNotImplemented, "not yet implemented")
+func (s mediaService) GetAllSharedWithUser(userId *primitive.ObjectID) ([]model.Media, utils.ServiceError) {
+	accesses, svcErr := s.mediaAccessService.GetAllForUser(userId)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	var medias []model.Media = make([]model.Media, 0)
+	for _, access := range accesses {
+		media, err := s.mediaRepository.Get(access.MediaId)
+		if err != nil {
+			continue
+		}
+		medias = append(medias, *media)
+	}
+	return medias, nil
 }
 
 func (s mediaService) GetAllUploadedByUser(userId *primitive.ObjectID) ([]model.Media, utils.ServiceError) {
@@ -173,13 +291,6 @@ func (s mediaService) GetAllUploadedByUser(userId *primitive.ObjectID) ([]model.
 }
 
 func (s mediaService) Delete(mediaId *primitive.ObjectID) utils.ServiceError {
-	originalMediaFolder, err := utils.GetDataDir("originalMedias")
-	compressedMediaFolder, err2 := utils.GetDataDir("compressedMedias")
-
-	if err != nil || err2 != nil {
-		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete media")
-	}
-
 	// Get the media meta-data
 	media, svcErr := s.GetById(mediaId)
 	if svcErr != nil {
@@ -198,21 +309,86 @@ func (s mediaService) Delete(mediaId *primitive.ObjectID) utils.ServiceError {
 		return svcErr
 	}
 
-	err = s.mediaRepository.Delete(mediaId)
+	// Release the usage first, the media is kept when it couldn't be so that deleting it again releases it
+	if media.ChargedTo != nil {
+		if svcErr := s.quotaService.Charge(media.ChargedTo, -media.ChargedBytes, -1); svcErr != nil {
+			return svcErr
+		}
+	}
+	err := s.mediaRepository.Delete(mediaId)
 	if err != nil {
+		if media.ChargedTo != nil {
+			s.quotaService.Charge(media.ChargedTo, media.ChargedBytes, 1)
+		}
 		return utils.NewServiceError(http.StatusInternalServerError, "unable to delete media")
 	}
 
-	// Finally, remove the media file from disk storage
-	err = os.Remove(filepath.Join(originalMediaFolder, *media.StorageFileName))
-	err2 = os.Remove(filepath.Join(compressedMediaFolder, *media.StorageFileName))
-	if err != nil || err2 != nil {
+	// Finally, remove the media files from storage unless another media shares them
+	return s.releaseBlob(media)
+}
+
+func (s mediaService) IsInAlbum(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) bool {
+	return s.mediaInAblumRepository.IsInAlbum(mediaId, albumId)
+}
+
+// Get the storage holding the original file of a media
+func (s mediaService) originalStorage(media *model.Media) (storage.Backend, utils.ServiceError) {
+	if !media.IsArchived() {
+		return s.storage, nil
+	}
+	if s.archive == nil {
+		slog.Error("media is archived but no archive storage is configured", "mediaId", media.Id.Hex())
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "media original is not available")
+	}
+	return s.archive, nil
+}
+
+// Request an archived original back to the main storage, the tiering task will move it
+func (s mediaService) restore(media *model.Media) utils.ServiceError {
+	if s.archive == nil {
+		slog.Error("media is archived but no archive storage is configured", "mediaId", media.Id.Hex())
+		return utils.NewServiceError(http.StatusInternalServerError, "media original is not available")
+	}
+	if media.OriginalTier == model.TIER_ARCHIVED {
+		if err := s.mediaRepository.UpdateAllByStorageFileName(*media.StorageFileName, bson.M{"originalTier": model.TIER_RESTORING}); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't restore media")
+		}
+	}
+	return utils.NewServiceError(http.StatusAccepted, "media is being restored from archive")
+}
+
+// Drop the reference of a media to its stored data, removing the files once no media references them anymore
+func (s mediaService) releaseBlob(media *model.Media) utils.ServiceError {
+	// Medias without hash predate deduplication, they own their files
+	lastReference := true
+	if media.Hash != nil {
+		var err error
+		lastReference, err = s.blobRepository.Release(*media.Hash, *media.StorageFileName)
+		if err != nil {
+			slog.Error("couldn't release media data", "hash", *media.Hash, "error", err)
+			return utils.NewServiceError(http.StatusInternalServerError, "unable to delete media")
+		}
+	}
+	if !lastReference {
+		return nil
+	}
+	originalStorage, svcErr := s.originalStorage(media)
+	if svcErr != nil {
+		return svcErr
+	}
+	err := originalStorage.Delete(path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName))
+	if err == nil && media.CompressedFileName != nil {
+		err = s.storage.Delete(path.Join(common.COMPRESSED_DIRECTORY, *media.CompressedFileName))
+	}
+	if err != nil {
 		slog.Debug("Couldn't remove media file from storage", "error", err)
 		return utils.NewServiceError(http.StatusInternalServerError, "unable to delete media")
 	}
 	return nil
 }
 
-func (s mediaService) IsInAlbum(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) bool {
-	return s.mediaInAblumRepository.IsInAlbum(mediaId, albumId)
+func (s mediaService) deleteStoredFile(key string) {
+	if err := s.storage.Delete(key); err != nil {
+		slog.Error("couldn't remove stored media data", "key", key, "error", err)
+	}
 }
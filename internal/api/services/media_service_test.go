@@ -5,6 +5,7 @@ import (
 	"data-storage-svc/internal/api/services"
 	"data-storage-svc/internal/mocks"
 	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/storage"
 	"data-storage-svc/internal/utils"
 	"io"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestCreate(t *testing.T) {
@@ -53,7 +55,7 @@ func TestCreate(t *testing.T) {
 		},
 		{
 			name:                "Create JPG success",
-			uploader:            ObjIdFromHex("67fbd784c491ff384ee6287d"),
+			uploader:            ObjIdFromHex("67fbd784c491ff384ee6287e"),
 			filename:            "cat.jpg",
 			data:                getData("cat.jpg"),
 			uploadViaSharedLink: false,
@@ -67,24 +69,56 @@ func TestCreate(t *testing.T) {
 			uploadViaSharedLink: false,
 			expectedErrorCode:   nil,
 		},
+		{
+			name:                "Same content uploaded again by the same user",
+			uploader:            ObjIdFromHex("67fbd784c491ff384ee6287d"),
+			filename:            "cat_copy.jpg",
+			data:                getData("cat.jpg"),
+			uploadViaSharedLink: false,
+			expectedErrorCode:   nil,
+		},
 	}
 
 	internal.DATA_DIRECTORY = t.TempDir()
 
+	// Remember created medias and stored blobs to answer lookups by hash
+	createdMedias := map[string]*model.Media{}
+	blobs := map[string]*model.Blob{}
 	mediaRepositoryMock := mocks.MediaRepository{}
+	mediaRepositoryMock.On("GetByHash", mock.Anything, mock.Anything).Return(func(hash string, uploader *primitive.ObjectID) (*model.Media, error) {
+		if media, ok := createdMedias[hash+uploader.Hex()]; ok {
+			return media, nil
+		}
+		return nil, mongo.ErrNoDocuments
+	})
+	mediaRepositoryMock.On("GetOneByStorageFileName", mock.Anything).Return(nil, mongo.ErrNoDocuments)
+	blobRepositoryMock := mocks.BlobRepository{}
+	blobRepositoryMock.On("Acquire", mock.Anything, mock.Anything, mock.Anything).Return(func(hash string, storageFileName string, size int64) (*model.Blob, error) {
+		if blob, ok := blobs[hash]; ok {
+			blob.RefCount += 1
+			return blob, nil
+		}
+		blobs[hash] = &model.Blob{Hash: hash, StorageFileName: storageFileName, Size: size, RefCount: 1}
+		return blobs[hash], nil
+	})
+	recordMedia := func(args mock.Arguments) {
+		media := args.Get(0).(*model.Media)
+		createdMedias[*media.Hash+media.UploadedBy.Hex()] = media
+	}
+
 	// Expect create call for jpg
 	mediaRepositoryMock.On("Create", mock.MatchedBy(func(media *model.Media) bool {
 		if *media.OriginalFileName != "cat.jpg" {
 			return false
 		}
-		if media.UploadedBy.Hex() != "67fbd784c491ff384ee6287d" {
+		if media.UploadedBy.Hex() != "67fbd784c491ff384ee6287e" {
 			return false
 		}
 		if time.Since(*media.UploadTime).Seconds() > 2 {
 			return false
 		}
 		return true
-	})).Return(utils.Ptr(primitive.NewObjectID()), nil).Once()
+	})).Run(recordMedia).Return(utils.Ptr(primitive.NewObjectID()), nil).Once()
 
 	// Expect create call for png
 	mediaRepositoryMock.On("Create", mock.MatchedBy(func(media *model.Media) bool {
@@ -98,7 +132,7 @@ func TestCreate(t *testing.T) {
 			return false
 		}
 		return true
-	})).Return(utils.Ptr(primitive.NewObjectID()), nil).Once()
+	})).Run(recordMedia).Return(utils.Ptr(primitive.NewObjectID()), nil).Once()
 
 	// Expect create call for exe
 	mediaRepositoryMock.On("Create", mock.MatchedBy(func(media *model.Media) bool {
@@ -112,17 +146,20 @@ func TestCreate(t *testing.T) {
 			return false
 		}
 		return true
-	})).Return(utils.Ptr(primitive.NewObjectID()), nil).Once()
+	})).Run(recordMedia).Return(utils.Ptr(primitive.NewObjectID()), nil).Once()
 
 	mediaInAlbumRepositoryMock := mocks.MediaInAlbumRepository{}
 	mediaAccessServiceMock := mocks.MediaAccessService{}
 	albumServiceMock := mocks.AlbumService{}
 
-	mediaService := services.NewMediaService(&mediaRepositoryMock, &mediaInAlbumRepositoryMock, &mediaAccessServiceMock, &albumServiceMock)
+	quotaServiceMock := mocks.QuotaService{}
+	quotaServiceMock.On("Charge", mock.Anything, mock.Anything, int64(1)).Return(nil)
+
+	mediaService := services.NewMediaService(&mediaRepositoryMock, &mediaInAlbumRepositoryMock, &blobRepositoryMock, &mediaAccessServiceMock, &albumServiceMock, &quotaServiceMock, storage.NewLocalBackend(internal.DATA_DIRECTORY), nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			createdId, err := mediaService.Create(tc.filename, &tc.uploader, tc.uploadViaSharedLink, tc.data)
+			createdId, err := mediaService.Create(tc.filename, &tc.uploader, tc.uploadViaSharedLink, &tc.uploader, tc.data)
 			if tc.expectedErrorCode != nil {
 				assert.Nil(t, createdId)
 				assert.NotNil(t, err)
@@ -134,24 +171,67 @@ func TestCreate(t *testing.T) {
 		})
 	}
 	mediaRepositoryMock.AssertExpectations(t)
+	// Every created media counts against its uploader quota, re-uploads are not counted twice
+	quotaServiceMock.AssertNumberOfCalls(t, "Charge", 3)
 
 	originals, _ := utils.GetDataDir("originalMedias")
 	originalsFiles, _ := os.ReadDir(originals)
 
-	// Assert all uploaded files have been saved, compression is done after
-	assert.Equal(t, 3, len(originalsFiles))
+	// Assert each uploaded content has been saved once, compression is done after
+	assert.Equal(t, 2, len(originalsFiles))
+	// The cat picture is shared by two medias, its re-upload by the same user created nothing
+	refCounts := []int64{}
+	for _, blob := range blobs {
+		refCounts = append(refCounts, blob.RefCount)
+	}
+	assert.ElementsMatch(t, []int64{1, 2}, refCounts)
+}
+
+func TestCreateChargeFailure(t *testing.T) {
+	internal.DATA_DIRECTORY = t.TempDir()
+	mediaId := primitive.NewObjectID()
+	mediaRepositoryMock := mocks.MediaRepository{}
+	mediaRepositoryMock.On("GetByHash", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
+	mediaRepositoryMock.On("Create", mock.Anything).Return(&mediaId, nil)
+	mediaRepositoryMock.On("Delete", &mediaId).Return(nil)
+	blobRepositoryMock := mocks.BlobRepository{}
+	blobRepositoryMock.On("Acquire", mock.Anything, mock.Anything, mock.Anything).Return(func(hash string, storageFileName string, size int64) (*model.Blob, error) {
+		return &model.Blob{Hash: hash, StorageFileName: storageFileName, Size: size, RefCount: 1}, nil
+	})
+	blobRepositoryMock.On("Release", mock.Anything, mock.Anything).Return(true, nil)
+	quotaServiceMock := mocks.QuotaService{}
+	quotaServiceMock.On("Charge", mock.Anything, mock.Anything, int64(1)).Return(utils.NewServiceError(500, "couldn't update storage usage"))
+	mediaService := services.NewMediaService(&mediaRepositoryMock, nil, &blobRepositoryMock, nil, nil, &quotaServiceMock, storage.NewLocalBackend(internal.DATA_DIRECTORY), nil)
+
+	// A media whose usage couldn't be counted is not kept
+	uploader := primitive.NewObjectID()
+	createdId, err := mediaService.Create("cat.jpg", &uploader, false, &uploader, getData("cat.jpg"))
+	assert.Nil(t, createdId)
+	assert.Equal(t, 500, err.GetCode())
+	mediaRepositoryMock.AssertCalled(t, "Delete", &mediaId)
+	originals, _ := utils.GetDataDir("originalMedias")
+	originalsFiles, _ := os.ReadDir(originals)
+	assert.Empty(t, originalsFiles)
 }
 
 func TestMemory(t *testing.T) {
 	internal.DATA_DIRECTORY = t.TempDir()
 	mediaRepositoryMock := mocks.MediaRepository{}
+	mediaRepositoryMock.On("GetByHash", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments)
 	mediaRepositoryMock.On("Create", mock.Anything).Return(utils.Ptr(primitive.NewObjectID()), nil)
+	blobRepositoryMock := mocks.BlobRepository{}
+	blobRepositoryMock.On("Acquire", mock.Anything, mock.Anything, mock.Anything).Return(func(hash string, storageFileName string, size int64) (*model.Blob, error) {
+		return &model.Blob{Hash: hash, StorageFileName: storageFileName, Size: size, RefCount: 1}, nil
+	})
 
 	mediaInAlbumRepositoryMock := mocks.MediaInAlbumRepository{}
 	mediaAccessServiceMock := mocks.MediaAccessService{}
 	albumServiceMock := mocks.AlbumService{}
 
-	mediaService := services.NewMediaService(&mediaRepositoryMock, &mediaInAlbumRepositoryMock, &mediaAccessServiceMock, &albumServiceMock)
+	quotaServiceMock := mocks.QuotaService{}
+	quotaServiceMock.On("Charge", mock.Anything, mock.Anything, int64(1)).Return(nil)
+
+	mediaService := services.NewMediaService(&mediaRepositoryMock, &mediaInAlbumRepositoryMock, &blobRepositoryMock, &mediaAccessServiceMock, &albumServiceMock, &quotaServiceMock, storage.NewLocalBackend(internal.DATA_DIRECTORY), nil)
 
 	uploader := primitive.NewObjectID()
 
@@ -167,12 +247,34 @@ func TestMemory(t *testing.T) {
 
 	for range 100 {
 		data := getData("big_image.jpg")
-		newId, err := mediaService.Create("newFile.jpg", &uploader, false, data)
+		newId, err := mediaService.Create("newFile.jpg", &uploader, false, &uploader, data)
 		assert.NotNil(t, newId)
 		assert.Nil(t, err)
 	}
 }
 
+func TestGetAllSharedWithUser(t *testing.T) {
+	userId := primitive.NewObjectID()
+	otherId := primitive.NewObjectID()
+	shared := model.Media{Id: primitive.NewObjectID(), UploadedBy: &otherId}
+	deletedId := primitive.NewObjectID()
+
+	mediaAccessServiceMock := mocks.MediaAccessService{}
+	mediaAccessServiceMock.On("GetAllForUser", &userId).Return([]model.UserMediaAccess{
+		{UserId: &userId, MediaId: &shared.Id}, {UserId: &userId, MediaId: &deletedId},
+	}, nil)
+	mediaRepositoryMock := mocks.MediaRepository{}
+	mediaRepositoryMock.On("Get", &shared.Id).Return(&shared, nil)
+	mediaRepositoryMock.On("Get", &deletedId).Return(nil, mongo.ErrNoDocuments)
+	mediaService := services.NewMediaService(&mediaRepositoryMock, nil, nil, &mediaAccessServiceMock, nil, nil, nil, nil)
+
+	// Deleted medias are left out
+	medias, err := mediaService.GetAllSharedWithUser(&userId)
+	assert.Nil(t, err)
+	assert.Len(t, medias, 1)
+	assert.Equal(t, shared.Id, medias[0].Id)
+}
+
 func getData(filename string) io.ReadCloser {
 	file, err := os.Open(filepath.Join("testdata", filename))
 	if err != nil {
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/oauth2"
+)
+
+type OidcConfig struct {
+	// Issuer URL of the provider, OpenID Connect login is disabled when empty
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	// URL of the callback endpoint, as registered at the provider
+	RedirectUrl string
+	Scopes      []string
+	// Create users logging in for the first time, otherwise only existing users can log in
+	Provisioning bool
+	// Claim listing the groups of the user, and the group granting admin rights (mapping disabled when empty)
+	GroupsClaim string
+	AdminGroup  string
+}
+
+// Secrets of a login attempt, kept by the browser between the redirection to the provider and the callback
+type OidcLoginState struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+type OidcService interface {
+	// Check if OpenID Connect login is configured
+	Enabled() bool
+	// Start a login, returns the provider URL to redirect the user to and the state to check on callback
+	StartLogin() (*string, *OidcLoginState, utils.ServiceError)
+	// Finish a login with the authorization code returned by the provider, the user is provisioned if needed
+	FinishLogin(code string, state *OidcLoginState, userAgent string, ip string) (*model.User, *model.SessionTokens, utils.ServiceError)
+}
+
+type oidcService struct {
+	config OidcConfig
+	// Repository dependencies
+	userRepository repository.UserRepository
+	// Service dependencies
+	sessionService SessionService
+
+	// The provider is discovered on first use, so the API starts even if the provider is down
+	lock     sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOidcService(config OidcConfig, userRepository repository.UserRepository, sessionService SessionService) *oidcService {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+	if !slices.Contains(config.Scopes, oidc.ScopeOpenID) {
+		config.Scopes = append([]string{oidc.ScopeOpenID}, config.Scopes...)
+	}
+	return &oidcService{config: config, userRepository: userRepository, sessionService: sessionService}
+}
+
+func (s *oidcService) Enabled() bool {
+	return s.config.Issuer != ""
+}
+
+func (s *oidcService) getProvider() (*oidc.Provider, *oauth2.Config, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.provider == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		provider, err := oidc.NewProvider(ctx, s.config.Issuer)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.provider = provider
+	}
+	oauth2Config := oauth2.Config{
+		ClientID:     s.config.ClientId,
+		ClientSecret: s.config.ClientSecret,
+		RedirectURL:  s.config.RedirectUrl,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       s.config.Scopes,
+	}
+	return s.provider, &oauth2Config, nil
+}
+
+func (s *oidcService) StartLogin() (*string, *OidcLoginState, utils.ServiceError) {
+	if !s.Enabled() {
+		return nil, nil, utils.NewServiceError(http.StatusNotFound, "openid connect login is not configured")
+	}
+	_, oauth2Config, err := s.getProvider()
+	if err != nil {
+		slog.Error("couldn't reach openid connect provider", "error", err)
+		return nil, nil, utils.NewServiceError(http.StatusBadGateway, "couldn't reach identity provider")
+	}
+	state := OidcLoginState{
+		State:        oauth2.GenerateVerifier(),
+		Nonce:        oauth2.GenerateVerifier(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+	url := oauth2Config.AuthCodeURL(state.State, oidc.Nonce(state.Nonce), oauth2.S256ChallengeOption(state.CodeVerifier))
+	return &url, &state, nil
+}
+
+// Claims used from the ID token, the groups claim is read separately as its name is configurable
+type oidcIdentity struct {
+	Subject       string      `json:"sub"`
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+}
+
+// Some providers send email_verified as a string
+func (i oidcIdentity) isEmailVerified() bool {
+	switch verified := i.EmailVerified.(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	}
+	return false
+}
+
+func (s *oidcService) FinishLogin(code string, state *OidcLoginState, userAgent string, ip string) (*model.User, *model.SessionTokens, utils.ServiceError) {
+	if !s.Enabled() {
+		return nil, nil, utils.NewServiceError(http.StatusNotFound, "openid connect login is not configured")
+	}
+	provider, oauth2Config, err := s.getProvider()
+	if err != nil {
+		slog.Error("couldn't reach openid connect provider", "error", err)
+		return nil, nil, utils.NewServiceError(http.StatusBadGateway, "couldn't reach identity provider")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		slog.Debug("couldn't exchange openid connect code", "error", err)
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+	}
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.config.ClientId}).Verify(ctx, rawIdToken)
+	if err != nil || idToken.Nonce != state.Nonce {
+		slog.Debug("invalid openid connect id token", "error", err)
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+	}
+
+	var identity oidcIdentity
+	var claims map[string]interface{}
+	if idToken.Claims(&identity) != nil || idToken.Claims(&claims) != nil {
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+	}
+	user, svcErr := s.getOrProvisionUser(&identity)
+	if svcErr != nil {
+		return nil, nil, svcErr
+	}
+
+	update := bson.M{"lastLogin": time.Now()}
+	if s.config.AdminGroup != "" {
+		user.IsAdmin = slices.Contains(groups(claims[s.config.GroupsClaim]), s.config.AdminGroup)
+		update["isAdmin"] = user.IsAdmin
+	}
+	if err := s.userRepository.Update(&user.Id, bson.M{"$set": update}); err != nil {
+		slog.Error("couldn't update user after openid connect login", "userId", user.Id.Hex(), "error", err)
+	}
+
+	tokens, svcErr := s.sessionService.Create(user, userAgent, ip)
+	if svcErr != nil {
+		return nil, nil, svcErr
+	}
+	return user, tokens, nil
+}
+
+// Find the user of an identity: already linked, linked now through a verified email, or created
+func (s *oidcService) getOrProvisionUser(identity *oidcIdentity) (*model.User, utils.ServiceError) {
+	user, err := s.userRepository.GetByOidcSubject(identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't find user")
+	}
+	if identity.Email == "" || !identity.isEmailVerified() {
+		return nil, utils.NewServiceError(http.StatusForbidden, "identity provider did not return a verified email")
+	}
+	user, err = s.userRepository.GetByEmail(identity.Email)
+	if err == nil {
+		if user.OidcSubject != "" {
+			// The account is already linked to another identity
+			return nil, utils.NewServiceError(http.StatusConflict, "account is linked to another identity")
+		}
+		if err := s.userRepository.Update(&user.Id, bson.M{"$set": bson.M{"oidcSubject": identity.Subject}}); err != nil {
+			return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't link account")
+		}
+		user.OidcSubject = identity.Subject
+		return user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't find user")
+	}
+
+	if !s.config.Provisioning {
+		return nil, utils.NewServiceError(http.StatusForbidden, "no account for this identity")
+	}
+	// Provisioned users have no password, they can only log in through the provider
+	user = &model.User{Email: identity.Email, OidcSubject: identity.Subject, JoinDate: time.Now()}
+	userId, err := s.userRepository.Create(user)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create new user")
+	}
+	user.Id = *userId
+	slog.Info("user provisioned from openid connect", "userId", userId.Hex())
+	return user, nil
+}
+
+// Groups claim is either a list or a single string
+func groups(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		groups := []string{}
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+		return groups
+	}
+	return []string{}
+}
@@ -0,0 +1,232 @@
+package services_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Minimal OpenID Connect provider, issuing ID tokens for the identity given when authorizing
+type mockOidcProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	lock   sync.Mutex
+	// Pending authorizations by code
+	authorizations map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+}
+
+func newMockOidcProvider(t *testing.T) *mockOidcProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	provider := &mockOidcProvider{key: key, authorizations: map[string]mockAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                provider.server.URL,
+			"authorization_endpoint":                provider.server.URL + "/authorize",
+			"token_endpoint":                        provider.server.URL + "/token",
+			"jwks_uri":                              provider.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		provider.lock.Lock()
+		authorization, exists := provider.authorizations[r.Form.Get("code")]
+		delete(provider.authorizations, r.Form.Get("code"))
+		provider.lock.Unlock()
+		// PKCE check
+		challenge := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if !exists || base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.codeChallenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   provider.server.URL,
+			"aud":   "test-client",
+			"nonce": authorization.nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}
+		for name, value := range authorization.claims {
+			claims[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		idToken, _ := token.SignedString(key)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+	})
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+// Simulate the user logging in at the provider, returns the authorization code sent to the callback
+func (p *mockOidcProvider) authorize(t *testing.T, loginUrl string, claims jwt.MapClaims) string {
+	parsedUrl, err := url.Parse(loginUrl)
+	assert.NoError(t, err)
+	query := parsedUrl.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "test-client", query.Get("client_id"))
+	code := primitive.NewObjectID().Hex()
+	p.lock.Lock()
+	p.authorizations[code] = mockAuthorization{codeChallenge: query.Get("code_challenge"), nonce: query.Get("nonce"), claims: claims}
+	p.lock.Unlock()
+	return code
+}
+
+func TestOidcLogin(t *testing.T) {
+	provider := newMockOidcProvider(t)
+	existingUserId := primitive.NewObjectID()
+	linkedUserId := primitive.NewObjectID()
+	createdUserId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name              string
+		provisioning      bool
+		claims            jwt.MapClaims
+		tamperState       func(state *services.OidcLoginState)
+		expectedErrorCode *int
+		expectedUserId    *primitive.ObjectID
+		expectedAdmin     bool
+	}{
+		{
+			name:           "Already linked user, admin group",
+			claims:         jwt.MapClaims{"sub": "linked", "email": "linked@test.fr", "email_verified": true, "groups": []string{"photos-admins"}},
+			expectedUserId: &linkedUserId,
+			expectedAdmin:  true,
+		},
+		{
+			name:           "Already linked user, not in admin group anymore",
+			claims:         jwt.MapClaims{"sub": "linked", "email": "linked@test.fr", "email_verified": true, "groups": []string{"family"}},
+			expectedUserId: &linkedUserId,
+			expectedAdmin:  false,
+		},
+		{
+			name:           "Existing user linked by verified email",
+			claims:         jwt.MapClaims{"sub": "existing", "email": "existing@test.fr", "email_verified": true},
+			expectedUserId: &existingUserId,
+		},
+		{
+			name:              "Existing user with unverified email",
+			claims:            jwt.MapClaims{"sub": "existing", "email": "existing@test.fr", "email_verified": false},
+			expectedErrorCode: utils.IntPtr(403),
+		},
+		{
+			name:              "Unknown user without provisioning",
+			claims:            jwt.MapClaims{"sub": "new", "email": "new@test.fr", "email_verified": true},
+			expectedErrorCode: utils.IntPtr(403),
+		},
+		{
+			name:           "Unknown user provisioned",
+			provisioning:   true,
+			claims:         jwt.MapClaims{"sub": "new", "email": "new@test.fr", "email_verified": "true", "groups": "photos-admins"},
+			expectedUserId: &createdUserId,
+			expectedAdmin:  true,
+		},
+		{
+			name:   "Wrong PKCE verifier",
+			claims: jwt.MapClaims{"sub": "linked", "email": "linked@test.fr", "email_verified": true},
+			tamperState: func(state *services.OidcLoginState) {
+				state.CodeVerifier = "another-verifier-another-verifier-another-verifier"
+			},
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:              "Wrong nonce",
+			claims:            jwt.MapClaims{"sub": "linked", "email": "linked@test.fr", "email_verified": true},
+			tamperState:       func(state *services.OidcLoginState) { state.Nonce = "another-nonce" },
+			expectedErrorCode: utils.IntPtr(401),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userRepositoryMock := &mocks.UserRepository{}
+			userRepositoryMock.On("GetByOidcSubject", "linked").Return(&model.User{Id: linkedUserId, Email: "linked@test.fr", OidcSubject: "linked", IsAdmin: !tc.expectedAdmin}, nil)
+			userRepositoryMock.On("GetByOidcSubject", mock.Anything).Return((*model.User)(nil), mongo.ErrNoDocuments)
+			userRepositoryMock.On("GetByEmail", "existing@test.fr").Return(&model.User{Id: existingUserId, Email: "existing@test.fr"}, nil)
+			userRepositoryMock.On("GetByEmail", mock.Anything).Return((*model.User)(nil), mongo.ErrNoDocuments)
+			userRepositoryMock.On("Create", mock.Anything).Return(&createdUserId, nil)
+			userRepositoryMock.On("Update", mock.Anything, mock.Anything).Return(nil)
+			sessionServiceMock := &mocks.SessionService{}
+			sessionServiceMock.On("Create", mock.Anything, "browser", "127.0.0.1").Return(&model.SessionTokens{AccessToken: "jwt"}, nil)
+
+			svc := services.NewOidcService(services.OidcConfig{
+				Issuer:       provider.server.URL,
+				ClientId:     "test-client",
+				ClientSecret: "secret",
+				RedirectUrl:  "http://localhost:8080/user/oidc/callback",
+				Provisioning: tc.provisioning,
+				GroupsClaim:  "groups",
+				AdminGroup:   "photos-admins",
+			}, userRepositoryMock, sessionServiceMock)
+
+			loginUrl, state, err := svc.StartLogin()
+			assert.Nil(t, err)
+			code := provider.authorize(t, *loginUrl, tc.claims)
+			if tc.tamperState != nil {
+				tc.tamperState(state)
+			}
+
+			user, tokens, err := svc.FinishLogin(code, state, "browser", "127.0.0.1")
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				sessionServiceMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "jwt", tokens.AccessToken)
+			assert.Equal(t, *tc.expectedUserId, user.Id)
+			assert.Equal(t, tc.expectedAdmin, user.IsAdmin)
+			// Admin rights follow the groups on every login
+			userRepositoryMock.AssertCalled(t, "Update", tc.expectedUserId, mock.MatchedBy(func(update bson.M) bool {
+				set, ok := update["$set"].(bson.M)
+				return ok && set["isAdmin"] == tc.expectedAdmin
+			}))
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OwnershipTransferService interface {
+	// Propose to give an album to another user, an album has at most one pending transfer
+	Propose(proposedBy *primitive.ObjectID, albumId *primitive.ObjectID, toUserId *primitive.ObjectID, includeMedias bool) (*model.OwnershipTransfer, utils.ServiceError)
+	// Get a transfer by id
+	GetById(transferId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError)
+	// List the transfers of an album, answered ones included, the most recent first
+	GetAllForAlbum(albumId *primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError)
+	// List the pending transfers a user proposed, gives or receives
+	GetAllPendingForUser(userId *primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError)
+	// Accept a transfer: the recipient becomes the owner of the album, and of the previous owner's medias in it when
+	// included, the previous owner stays as a co-owner
+	Accept(transferId *primitive.ObjectID, userId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError)
+	// Decline a transfer, as the recipient
+	Decline(transferId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError
+	// Withdraw a transfer before the recipient answers
+	Cancel(transferId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError
+}
+
+type ownershipTransferService struct {
+	// Repository dependencies
+	ownershipTransferRepository repository.OwnershipTransferRepository
+	albumRepository             repository.AlbumRepository
+	albumAccessRepository       repository.AlbumAccessRepository
+	mediaRepository             repository.MediaRepository
+	mediaInAlbumRepository      repository.MediaInAlbumRepository
+	// Service dependencies
+	quotaService QuotaService
+}
+
+func NewOwnershipTransferService(
+	ownershipTransferRepository repository.OwnershipTransferRepository,
+	albumRepository repository.AlbumRepository,
+	albumAccessRepository repository.AlbumAccessRepository,
+	mediaRepository repository.MediaRepository,
+	mediaInAlbumRepository repository.MediaInAlbumRepository,
+	quotaService QuotaService,
+) ownershipTransferService {
+	return ownershipTransferService{ownershipTransferRepository, albumRepository, albumAccessRepository, mediaRepository, mediaInAlbumRepository, quotaService}
+}
+
+func (s ownershipTransferService) Propose(proposedBy *primitive.ObjectID, albumId *primitive.ObjectID, toUserId *primitive.ObjectID, includeMedias bool) (*model.OwnershipTransfer, utils.ServiceError) {
+	album, err := s.albumRepository.GetById(*albumId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "album not found")
+	}
+	if *album.AuthorId == *toUserId {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "the album already belongs to this user")
+	}
+	if _, err := s.ownershipTransferRepository.GetPendingForAlbum(albumId); err == nil {
+		return nil, utils.NewServiceError(http.StatusConflict, "a transfer of this album is already pending")
+	}
+
+	transfer := model.OwnershipTransfer{
+		AlbumId:       *albumId,
+		FromUserId:    *album.AuthorId,
+		ToUserId:      *toUserId,
+		ProposedBy:    *proposedBy,
+		IncludeMedias: includeMedias,
+		Status:        model.TRANSFER_STATUS_PENDING,
+		CreationDate:  time.Now(),
+	}
+	transferId, err := s.ownershipTransferRepository.Create(&transfer)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't propose transfer")
+	}
+	transfer.Id = *transferId
+	slog.Info("Album ownership transfer proposed", "transferId", transferId.Hex(), "albumId", albumId.Hex(), "from", transfer.FromUserId.Hex(), "to", toUserId.Hex(), "proposedBy", proposedBy.Hex())
+	return &transfer, nil
+}
+
+func (s ownershipTransferService) GetById(transferId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError) {
+	transfer, err := s.ownershipTransferRepository.Get(transferId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "transfer not found")
+	}
+	return transfer, nil
+}
+
+func (s ownershipTransferService) GetAllForAlbum(albumId *primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError) {
+	transfers, err := s.ownershipTransferRepository.GetAllByAlbum(albumId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list transfers")
+	}
+	return transfers, nil
+}
+
+func (s ownershipTransferService) GetAllPendingForUser(userId *primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError) {
+	transfers, err := s.ownershipTransferRepository.GetAllPendingForUser(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list transfers")
+	}
+	return transfers, nil
+}
+
+// Get a transfer that can still be answered
+func (s ownershipTransferService) getPending(transferId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError) {
+	transfer, svcErr := s.GetById(transferId)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	if transfer.Status != model.TRANSFER_STATUS_PENDING {
+		return nil, utils.NewServiceError(http.StatusConflict, "transfer already "+string(transfer.Status))
+	}
+	return transfer, nil
+}
+
+func (s ownershipTransferService) Accept(transferId *primitive.ObjectID, userId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError) {
+	transfer, svcErr := s.getPending(transferId)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	album, err := s.albumRepository.GetById(transfer.AlbumId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "album not found")
+	}
+	// The album was given to someone else in the meantime, e.g. when its owner was deleted
+	if *album.AuthorId != transfer.FromUserId {
+		return nil, utils.NewServiceError(http.StatusConflict, "the album changed owner since the transfer was proposed")
+	}
+
+	var medias []model.Media
+	if transfer.IncludeMedias {
+		medias, svcErr = s.getTransferredMedias(transfer)
+		if svcErr != nil {
+			return nil, svcErr
+		}
+		var bytes int64
+		for _, media := range medias {
+			if media.ChargedTo != nil && *media.ChargedTo == transfer.FromUserId {
+				bytes += media.ChargedBytes
+			}
+		}
+		if bytes > 0 {
+			if svcErr := s.quotaService.CheckQuota(&transfer.ToUserId, bytes); svcErr != nil {
+				return nil, svcErr
+			}
+		}
+	}
+
+	// Answers of the transfer may run at the same time, only the one switching it from pending applies it
+	if err := s.ownershipTransferRepository.Resolve(transferId, model.TRANSFER_STATUS_ACCEPTED, userId, len(medias)); err != nil {
+		return nil, utils.NewServiceError(http.StatusConflict, "transfer already answered")
+	}
+	if svcErr := s.apply(transfer, album, medias); svcErr != nil {
+		if err := s.ownershipTransferRepository.Reopen(transferId); err != nil {
+			slog.Error("Couldn't reopen a transfer that failed", "transferId", transferId.Hex(), "error", err)
+		}
+		return nil, svcErr
+	}
+	slog.Info("Album ownership transferred", "transferId", transferId.Hex(), "albumId", album.Id.Hex(), "from", transfer.FromUserId.Hex(), "to", transfer.ToUserId.Hex(), "medias", len(medias))
+	now := time.Now()
+	transfer.Status = model.TRANSFER_STATUS_ACCEPTED
+	transfer.ResolvedBy = userId
+	transfer.ResolutionDate = &now
+	transfer.TransferredMedias = len(medias)
+	return transfer, nil
+}
+
+// Give the album, and the medias when included, to the recipient of an accepted transfer. The album moves last, so
+// a transfer reopened after a failure can be accepted again: moved medias are no longer listed, and accesses are
+// upserted.
+func (s ownershipTransferService) apply(transfer *model.OwnershipTransfer, album *model.Album, medias []model.Media) utils.ServiceError {
+	for _, media := range medias {
+		if svcErr := s.transferMedia(&media, &transfer.FromUserId, &transfer.ToUserId); svcErr != nil {
+			return svcErr
+		}
+	}
+
+	if err := s.albumAccessRepository.Create(&transfer.ToUserId, album.Id, model.ALBUM_ROLE_OWNER); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer album")
+	}
+	// The previous owner keeps managing the album, the new owner removes them if they wish
+	if err := s.albumAccessRepository.Create(&transfer.FromUserId, album.Id, model.ALBUM_ROLE_CO_OWNER); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer album")
+	}
+	if err := s.albumRepository.SetAuthor(album.Id, &transfer.FromUserId, &transfer.ToUserId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer album")
+	}
+	return nil
+}
+
+// The medias of the previous owner in the album. Medias the recipient uploaded a copy of stay with the previous owner,
+// a user has a single media per content.
+func (s ownershipTransferService) getTransferredMedias(transfer *model.OwnershipTransfer) ([]model.Media, utils.ServiceError) {
+	mediasInAlbum, err := s.mediaInAlbumRepository.ListAllMedias(&transfer.AlbumId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list album medias")
+	}
+	var medias []model.Media = make([]model.Media, 0)
+	for _, mediaInAlbum := range mediasInAlbum {
+		media, err := s.mediaRepository.Get(mediaInAlbum.MediaId)
+		if err != nil || media.UploadedBy == nil || *media.UploadedBy != transfer.FromUserId {
+			continue
+		}
+		if media.Hash != nil {
+			if _, err := s.mediaRepository.GetByHash(*media.Hash, &transfer.ToUserId); err == nil {
+				continue
+			}
+		}
+		medias = append(medias, *media)
+	}
+	return medias, nil
+}
+
+// Give a media to another user, along with its quota charge. The new owner needs no access to it, owners get their
+// medias from uploadedBy.
+func (s ownershipTransferService) transferMedia(media *model.Media, from *primitive.ObjectID, to *primitive.ObjectID) utils.ServiceError {
+	update := bson.M{"uploadedBy": to}
+	charged := media.ChargedTo != nil && *media.ChargedTo == *from
+	if charged {
+		update["chargedTo"] = to
+	}
+	if err := s.mediaRepository.Update(&media.Id, update); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+	}
+	if !charged {
+		return nil
+	}
+	// The media moved already, failing here would leave it with the new owner without the transfer being accepted
+	if svcErr := s.quotaService.Charge(from, -media.ChargedBytes, -1); svcErr != nil {
+		slog.Error("Couldn't release the quota of a transferred media", "mediaId", media.Id.Hex(), "userId", from.Hex(), "bytes", media.ChargedBytes, "error", svcErr.GetMessage())
+	}
+	if svcErr := s.quotaService.Charge(to, media.ChargedBytes, 1); svcErr != nil {
+		slog.Error("Couldn't charge the quota of a transferred media", "mediaId", media.Id.Hex(), "userId", to.Hex(), "bytes", media.ChargedBytes, "error", svcErr.GetMessage())
+	}
+	return nil
+}
+
+func (s ownershipTransferService) Decline(transferId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	return s.resolve(transferId, model.TRANSFER_STATUS_DECLINED, userId)
+}
+
+func (s ownershipTransferService) Cancel(transferId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	return s.resolve(transferId, model.TRANSFER_STATUS_CANCELLED, userId)
+}
+
+// Close a pending transfer without moving anything
+func (s ownershipTransferService) resolve(transferId *primitive.ObjectID, status model.OwnershipTransferStatus, userId *primitive.ObjectID) utils.ServiceError {
+	if _, svcErr := s.getPending(transferId); svcErr != nil {
+		return svcErr
+	}
+	if err := s.ownershipTransferRepository.Resolve(transferId, status, userId, 0); err != nil {
+		return utils.NewServiceError(http.StatusConflict, "transfer already answered")
+	}
+	slog.Info("Album ownership transfer "+string(status), "transferId", transferId.Hex(), "by", userId.Hex())
+	return nil
+}
@@ -0,0 +1,186 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestProposeTransfer(t *testing.T) {
+	albumId := primitive.NewObjectID()
+	pendingAlbumId := primitive.NewObjectID()
+	missingAlbumId := primitive.NewObjectID()
+	ownerId := primitive.NewObjectID()
+	adminId := primitive.NewObjectID()
+	recipientId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name              string
+		albumId           primitive.ObjectID
+		toUserId          primitive.ObjectID
+		expectedErrorCode *int
+	}{
+		{"Proposed", albumId, recipientId, nil},
+		{"Recipient already owns the album", albumId, ownerId, utils.IntPtr(400)},
+		{"Transfer already pending", pendingAlbumId, recipientId, utils.IntPtr(409)},
+		{"Missing album", missingAlbumId, recipientId, utils.IntPtr(404)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			albumRepository := &mocks.AlbumRepository{}
+			albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &ownerId}, nil)
+			albumRepository.On("GetById", pendingAlbumId).Return(&model.Album{Id: &pendingAlbumId, AuthorId: &ownerId}, nil)
+			albumRepository.On("GetById", missingAlbumId).Return(nil, mongo.ErrNoDocuments)
+			transferRepository := &mocks.OwnershipTransferRepository{}
+			transferRepository.On("GetPendingForAlbum", &albumId).Return(nil, mongo.ErrNoDocuments)
+			transferRepository.On("GetPendingForAlbum", &pendingAlbumId).Return(&model.OwnershipTransfer{}, nil)
+			transferRepository.On("Create", mock.Anything).Return(&primitive.ObjectID{}, nil)
+			svc := services.NewOwnershipTransferService(transferRepository, albumRepository, nil, nil, nil, nil)
+
+			// An admin proposes in place of the owner
+			transfer, err := svc.Propose(&adminId, &tc.albumId, &tc.toUserId, true)
+			if tc.expectedErrorCode != nil {
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				transferRepository.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, ownerId, transfer.FromUserId)
+			assert.Equal(t, adminId, transfer.ProposedBy)
+			assert.Equal(t, model.TRANSFER_STATUS_PENDING, transfer.Status)
+		})
+	}
+}
+
+func TestAcceptTransfer(t *testing.T) {
+	transferId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+	ownerId := primitive.NewObjectID()
+	recipientId := primitive.NewObjectID()
+	otherId := primitive.NewObjectID()
+	hash, duplicateHash := "hash", "duplicateHash"
+	owned := model.Media{Id: primitive.NewObjectID(), Hash: &hash, UploadedBy: &ownerId, ChargedTo: &ownerId, ChargedBytes: 100}
+	duplicate := model.Media{Id: primitive.NewObjectID(), Hash: &duplicateHash, UploadedBy: &ownerId, ChargedTo: &ownerId, ChargedBytes: 50}
+	contributed := model.Media{Id: primitive.NewObjectID(), UploadedBy: &otherId}
+
+	transferRepository := &mocks.OwnershipTransferRepository{}
+	transferRepository.On("Get", &transferId).Return(&model.OwnershipTransfer{Id: transferId, AlbumId: albumId, FromUserId: ownerId, ToUserId: recipientId, IncludeMedias: true, Status: model.TRANSFER_STATUS_PENDING}, nil)
+	transferRepository.On("Resolve", &transferId, model.TRANSFER_STATUS_ACCEPTED, &recipientId, 1).Return(nil)
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &ownerId}, nil)
+	albumRepository.On("SetAuthor", &albumId, &ownerId, &recipientId).Return(nil)
+
+	// The recipient owns the album, the previous owner keeps managing it
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	albumAccessRepository.On("Create", &recipientId, &albumId, model.ALBUM_ROLE_OWNER).Return(nil)
+	albumAccessRepository.On("Create", &ownerId, &albumId, model.ALBUM_ROLE_CO_OWNER).Return(nil)
+
+	// Only the owner's medias move, except those the recipient has a copy of
+	mediaInAlbumRepository := &mocks.MediaInAlbumRepository{}
+	mediaInAlbumRepository.On("ListAllMedias", &albumId).Return([]model.MediaInAlbum{{MediaId: &owned.Id}, {MediaId: &duplicate.Id}, {MediaId: &contributed.Id}}, nil)
+	mediaRepository := &mocks.MediaRepository{}
+	mediaRepository.On("Get", &owned.Id).Return(&owned, nil)
+	mediaRepository.On("Get", &duplicate.Id).Return(&duplicate, nil)
+	mediaRepository.On("Get", &contributed.Id).Return(&contributed, nil)
+	mediaRepository.On("GetByHash", hash, &recipientId).Return(nil, mongo.ErrNoDocuments)
+	mediaRepository.On("GetByHash", duplicateHash, &recipientId).Return(&model.Media{}, nil)
+	mediaRepository.On("Update", &owned.Id, bson.M{"uploadedBy": &recipientId, "chargedTo": &recipientId}).Return(nil)
+	quotaService := &mocks.QuotaService{}
+	quotaService.On("CheckQuota", &recipientId, int64(100)).Return(nil)
+	quotaService.On("Charge", &ownerId, int64(-100), int64(-1)).Return(nil)
+	quotaService.On("Charge", &recipientId, int64(100), int64(1)).Return(nil)
+
+	svc := services.NewOwnershipTransferService(transferRepository, albumRepository, albumAccessRepository, mediaRepository, mediaInAlbumRepository, quotaService)
+	transfer, err := svc.Accept(&transferId, &recipientId)
+	assert.Nil(t, err)
+	assert.Equal(t, model.TRANSFER_STATUS_ACCEPTED, transfer.Status)
+	assert.Equal(t, 1, transfer.TransferredMedias)
+	albumRepository.AssertExpectations(t)
+	albumAccessRepository.AssertExpectations(t)
+	mediaRepository.AssertNumberOfCalls(t, "Update", 1)
+	quotaService.AssertExpectations(t)
+	transferRepository.AssertExpectations(t)
+	transferRepository.AssertNotCalled(t, "Reopen", mock.Anything)
+}
+
+func TestAcceptStaleTransfer(t *testing.T) {
+	transferId := primitive.NewObjectID()
+	answeredId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+	ownerId := primitive.NewObjectID()
+	recipientId := primitive.NewObjectID()
+	newOwnerId := primitive.NewObjectID()
+
+	transferRepository := &mocks.OwnershipTransferRepository{}
+	transferRepository.On("Get", &transferId).Return(&model.OwnershipTransfer{Id: transferId, AlbumId: albumId, FromUserId: ownerId, ToUserId: recipientId, Status: model.TRANSFER_STATUS_PENDING}, nil)
+	transferRepository.On("Get", &answeredId).Return(&model.OwnershipTransfer{Id: answeredId, AlbumId: albumId, FromUserId: ownerId, ToUserId: recipientId, Status: model.TRANSFER_STATUS_DECLINED}, nil)
+	// The album was given to someone else since the transfer was proposed
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &newOwnerId}, nil)
+	svc := services.NewOwnershipTransferService(transferRepository, albumRepository, nil, nil, nil, nil)
+
+	_, err := svc.Accept(&transferId, &recipientId)
+	assert.Equal(t, 409, err.GetCode())
+	_, err = svc.Accept(&answeredId, &recipientId)
+	assert.Equal(t, 409, err.GetCode())
+	assert.Equal(t, 409, svc.Cancel(&answeredId, &ownerId).GetCode())
+	albumRepository.AssertNotCalled(t, "SetAuthor", mock.Anything, mock.Anything, mock.Anything)
+	transferRepository.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAcceptTransferAnsweredMeanwhile(t *testing.T) {
+	transferId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+	ownerId := primitive.NewObjectID()
+	recipientId := primitive.NewObjectID()
+
+	// The transfer was declined or accepted by another request after it was read
+	transferRepository := &mocks.OwnershipTransferRepository{}
+	transferRepository.On("Get", &transferId).Return(&model.OwnershipTransfer{Id: transferId, AlbumId: albumId, FromUserId: ownerId, ToUserId: recipientId, Status: model.TRANSFER_STATUS_PENDING}, nil)
+	transferRepository.On("Resolve", &transferId, model.TRANSFER_STATUS_ACCEPTED, &recipientId, 0).Return(mongo.ErrNoDocuments)
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &ownerId}, nil)
+	svc := services.NewOwnershipTransferService(transferRepository, albumRepository, nil, nil, nil, nil)
+
+	_, err := svc.Accept(&transferId, &recipientId)
+	assert.Equal(t, 409, err.GetCode())
+	albumRepository.AssertNotCalled(t, "SetAuthor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAcceptTransferFailureReopens(t *testing.T) {
+	transferId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+	ownerId := primitive.NewObjectID()
+	recipientId := primitive.NewObjectID()
+	owned := model.Media{Id: primitive.NewObjectID(), UploadedBy: &ownerId}
+
+	transferRepository := &mocks.OwnershipTransferRepository{}
+	transferRepository.On("Get", &transferId).Return(&model.OwnershipTransfer{Id: transferId, AlbumId: albumId, FromUserId: ownerId, ToUserId: recipientId, IncludeMedias: true, Status: model.TRANSFER_STATUS_PENDING}, nil)
+	transferRepository.On("Resolve", &transferId, model.TRANSFER_STATUS_ACCEPTED, &recipientId, 1).Return(nil)
+	transferRepository.On("Reopen", &transferId).Return(nil)
+	albumRepository := &mocks.AlbumRepository{}
+	albumRepository.On("GetById", albumId).Return(&model.Album{Id: &albumId, AuthorId: &ownerId}, nil)
+	albumAccessRepository := &mocks.AlbumAccessRepository{}
+	mediaInAlbumRepository := &mocks.MediaInAlbumRepository{}
+	mediaInAlbumRepository.On("ListAllMedias", &albumId).Return([]model.MediaInAlbum{{MediaId: &owned.Id}}, nil)
+	mediaRepository := &mocks.MediaRepository{}
+	mediaRepository.On("Get", &owned.Id).Return(&owned, nil)
+	mediaRepository.On("Update", &owned.Id, mock.Anything).Return(mongo.ErrClientDisconnected)
+	svc := services.NewOwnershipTransferService(transferRepository, albumRepository, albumAccessRepository, mediaRepository, mediaInAlbumRepository, nil)
+
+	_, err := svc.Accept(&transferId, &recipientId)
+	assert.Equal(t, 500, err.GetCode())
+	transferRepository.AssertCalled(t, "Reopen", &transferId)
+	// The album did not move, the transfer can be accepted again
+	albumRepository.AssertNotCalled(t, "SetAuthor", mock.Anything, mock.Anything, mock.Anything)
+	albumAccessRepository.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/mail"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Time given to the user to choose a new password
+const PASSWORD_RESET_DURATION = time.Hour
+
+type PasswordResetService interface {
+	// Email a password reset link to a user. Nothing tells whether a user has this email, so it cannot be used to find
+	// out who has an account.
+	RequestReset(email string) utils.ServiceError
+	// Set a new password with the token of a reset link, all sessions of the user are revoked
+	Reset(token string, newPassword string) utils.ServiceError
+}
+
+type passwordResetService struct {
+	// Repository dependencies
+	userTokenRepository repository.UserTokenRepository
+	userRepository      repository.UserRepository
+	hashModule          security.HashModule
+	passwordPolicy      security.PasswordPolicy
+	// Service dependencies
+	sessionService SessionService
+	mailer         mail.Mailer
+	// URL of the application, links in the emails point to it
+	appUrl string
+}
+
+func NewPasswordResetService(userTokenRepository repository.UserTokenRepository, userRepository repository.UserRepository, hashModule security.HashModule, passwordPolicy security.PasswordPolicy, sessionService SessionService, mailer mail.Mailer, appUrl string) passwordResetService {
+	return passwordResetService{userTokenRepository, userRepository, hashModule, passwordPolicy, sessionService, mailer, appUrl}
+}
+
+func (s passwordResetService) RequestReset(email string) utils.ServiceError {
+	if !utils.IsValidEmail(email) {
+		return utils.NewServiceError(http.StatusBadRequest, "invalid email address")
+	}
+	user, err := s.userRepository.GetByEmail(email)
+	if err != nil || user.Disabled {
+		slog.Debug("Password reset requested for unknown or disabled user", "email", email)
+		return nil
+	}
+
+	token, hash, err := security.GenerateUserToken()
+	if err != nil {
+		slog.Error("couldn't generate password reset token", "error", err)
+		return nil
+	}
+	// Only the last link sent works
+	if err := s.userTokenRepository.DeleteAllForEmail(model.USER_TOKEN_PASSWORD_RESET, email); err != nil {
+		slog.Error("couldn't delete previous password reset tokens", "email", email, "error", err)
+	}
+	now := time.Now()
+	reset := model.UserToken{
+		Kind:      model.USER_TOKEN_PASSWORD_RESET,
+		TokenHash: hash,
+		Email:     email,
+		UserId:    &user.Id,
+		CreatedAt: now,
+		ExpiresAt: now.Add(PASSWORD_RESET_DURATION),
+	}
+	if _, err := s.userTokenRepository.Create(&reset); err != nil {
+		slog.Error("couldn't store password reset token", "email", email, "error", err)
+		return nil
+	}
+
+	message := mail.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your account.\n\nChoose a new password: %s/reset-password?token=%s\n\nThis link expires in %d minutes. If you did not ask for it, ignore this email.\n",
+			s.appUrl, token, int(PASSWORD_RESET_DURATION.Minutes())),
+	}
+	if err := s.mailer.Send(message); err != nil {
+		slog.Error("couldn't send password reset email", "email", email, "error", err)
+	}
+	return nil
+}
+
+func (s passwordResetService) Reset(token string, newPassword string) utils.ServiceError {
+	reset, err := s.userTokenRepository.GetByHash(model.USER_TOKEN_PASSWORD_RESET, security.HashUserToken(token))
+	if err != nil || reset.UserId == nil || time.Now().After(reset.ExpiresAt) {
+		return utils.NewServiceError(http.StatusBadRequest, "invalid or expired token")
+	}
+	if svcErr := checkPassword(s.passwordPolicy, newPassword, reset.Email); svcErr != nil {
+		return svcErr
+	}
+	hash, err := s.hashModule.HashPassword(newPassword)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't reset password")
+	}
+	if err := s.userRepository.Update(reset.UserId, bson.M{"$set": bson.M{"passwordHash": hash}}); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't reset password")
+	}
+	if err := s.userTokenRepository.DeleteAllForEmail(model.USER_TOKEN_PASSWORD_RESET, reset.Email); err != nil {
+		slog.Error("couldn't delete password reset tokens", "email", reset.Email, "error", err)
+	}
+	// Whoever knew the old password must not stay logged in
+	return s.sessionService.RevokeAll(reset.UserId)
+}
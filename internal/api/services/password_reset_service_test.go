@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mail"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestRequestPasswordReset(t *testing.T) {
+	userId := primitive.NewObjectID()
+
+	userTokenRepository := &mocks.UserTokenRepository{}
+	userRepository := &mocks.UserRepository{}
+	mailer := &mocks.Mailer{}
+
+	userRepository.On("GetByEmail", "test@test.fr").Return(&model.User{Id: userId, Email: "test@test.fr"}, nil)
+	userRepository.On("GetByEmail", "disabled@test.fr").Return(&model.User{Id: primitive.NewObjectID(), Email: "disabled@test.fr", Disabled: true}, nil)
+	userRepository.On("GetByEmail", "unknown@test.fr").Return((*model.User)(nil), mongo.ErrNoDocuments)
+	userTokenRepository.On("DeleteAllForEmail", model.USER_TOKEN_PASSWORD_RESET, "test@test.fr").Return(nil)
+	tokenId := primitive.NewObjectID()
+	userTokenRepository.On("Create", mock.Anything).Return(&tokenId, nil)
+	var sent mail.Message
+	mailer.On("Send", mock.Anything).Run(func(args mock.Arguments) { sent = args.Get(0).(mail.Message) }).Return(nil)
+
+	svc := services.NewPasswordResetService(userTokenRepository, userRepository, nil, nil, nil, mailer, "https://photos.test.fr")
+
+	// Unknown and disabled users get the same answer, but no email
+	assert.Nil(t, svc.RequestReset("unknown@test.fr"))
+	assert.Nil(t, svc.RequestReset("disabled@test.fr"))
+	mailer.AssertNotCalled(t, "Send", mock.Anything)
+
+	assert.Nil(t, svc.RequestReset("test@test.fr"))
+	assert.Equal(t, "test@test.fr", sent.To)
+	assert.Contains(t, sent.Body, "https://photos.test.fr/reset-password?token=")
+	stored := userTokenRepository.Calls[1].Arguments.Get(0).(*model.UserToken)
+	assert.Equal(t, model.USER_TOKEN_PASSWORD_RESET, stored.Kind)
+	assert.Equal(t, &userId, stored.UserId)
+	assert.Equal(t, security.HashUserToken(tokenFromMessage(t, sent)), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(services.PASSWORD_RESET_DURATION), stored.ExpiresAt, time.Minute)
+}
+
+func TestResetPasswordWithToken(t *testing.T) {
+	userId := primitive.NewObjectID()
+	token, hash, _ := security.GenerateUserToken()
+	expiredToken, expiredHash, _ := security.GenerateUserToken()
+
+	userTokenRepository := &mocks.UserTokenRepository{}
+	userRepository := &mocks.UserRepository{}
+	hashModule := &mocks.HashModule{}
+	sessionService := &mocks.SessionService{}
+
+	userTokenRepository.On("GetByHash", model.USER_TOKEN_PASSWORD_RESET, hash).
+		Return(&model.UserToken{Email: "test@test.fr", UserId: &userId, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	userTokenRepository.On("GetByHash", model.USER_TOKEN_PASSWORD_RESET, expiredHash).
+		Return(&model.UserToken{Email: "test@test.fr", UserId: &userId, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+	userTokenRepository.On("DeleteAllForEmail", model.USER_TOKEN_PASSWORD_RESET, "test@test.fr").Return(nil)
+	hashModule.On("HashPassword", "anewpassword").Return("newhash", nil)
+	userRepository.On("Update", &userId, bson.M{"$set": bson.M{"passwordHash": "newhash"}}).Return(nil)
+	sessionService.On("RevokeAll", &userId).Return(nil)
+
+	svc := services.NewPasswordResetService(userTokenRepository, userRepository, hashModule, testPasswordPolicy(), sessionService, nil, "")
+
+	err := svc.Reset(expiredToken, "anewpassword")
+	assert.Equal(t, 400, err.GetCode())
+	err = svc.Reset(token, "a")
+	assert.Equal(t, 400, err.GetCode())
+	userRepository.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	assert.Nil(t, svc.Reset(token, "anewpassword"))
+	userRepository.AssertExpectations(t)
+	// The link cannot be used again, and old sessions are gone
+	userTokenRepository.AssertCalled(t, "DeleteAllForEmail", model.USER_TOKEN_PASSWORD_RESET, "test@test.fr")
+	sessionService.AssertCalled(t, "RevokeAll", &userId)
+}
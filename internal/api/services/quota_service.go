@@ -0,0 +1,113 @@
+package services
+
+import (
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type QuotaService interface {
+	// Get the storage used by a user and the quota applying to them
+	GetUsage(userId *primitive.ObjectID) (*model.UserUsage, utils.ServiceError)
+	// Check that a user can store one more file of the given size
+	CheckQuota(userId *primitive.ObjectID, size int64) utils.ServiceError
+	// Account for data stored by a user, negative values when data is removed
+	Charge(userId *primitive.ObjectID, bytes int64, files int64) utils.ServiceError
+	// Get the quota applying to users without a specific quota
+	GetDefaultQuota() (*model.StorageQuota, utils.ServiceError)
+	// Set the quota applying to users without a specific quota
+	SetDefaultQuota(quota model.StorageQuota) utils.ServiceError
+	// Set a user specific quota, nil to apply the default quota
+	SetUserQuota(userId *primitive.ObjectID, quota *model.StorageQuota) utils.ServiceError
+}
+
+type quotaService struct {
+	// Repository dependencies
+	userRepository     repository.UserRepository
+	settingsRepository repository.SettingsRepository
+}
+
+func NewQuotaService(userRepository repository.UserRepository, settingsRepository repository.SettingsRepository) quotaService {
+	return quotaService{userRepository, settingsRepository}
+}
+
+func (s quotaService) GetUsage(userId *primitive.ObjectID) (*model.UserUsage, utils.ServiceError) {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "user not found")
+	}
+	quota := user.Quota
+	if quota == nil {
+		defaultQuota, svcErr := s.GetDefaultQuota()
+		if svcErr != nil {
+			return nil, svcErr
+		}
+		quota = defaultQuota
+	}
+	return &model.UserUsage{Usage: user.Usage, Quota: *quota}, nil
+}
+
+func (s quotaService) CheckQuota(userId *primitive.ObjectID, size int64) utils.ServiceError {
+	usage, svcErr := s.GetUsage(userId)
+	if svcErr != nil {
+		return svcErr
+	}
+	if usage.Quota.MaxBytes > 0 && usage.Usage.Bytes+size > usage.Quota.MaxBytes {
+		return utils.NewServiceError(http.StatusRequestEntityTooLarge, "storage quota exceeded")
+	}
+	if usage.Quota.MaxFiles > 0 && usage.Usage.Files+1 > usage.Quota.MaxFiles {
+		return utils.NewServiceError(http.StatusRequestEntityTooLarge, "file count quota exceeded")
+	}
+	return nil
+}
+
+func (s quotaService) Charge(userId *primitive.ObjectID, bytes int64, files int64) utils.ServiceError {
+	update := bson.M{"$inc": bson.M{"usage.bytes": bytes, "usage.files": files}}
+	if err := s.userRepository.Update(userId, update); err != nil {
+		slog.Error("couldn't update user storage usage", "userId", userId.Hex(), "error", err)
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't update storage usage")
+	}
+	return nil
+}
+
+func (s quotaService) GetDefaultQuota() (*model.StorageQuota, utils.ServiceError) {
+	settings, err := s.settingsRepository.Get()
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't get default quota")
+	}
+	return &settings.DefaultQuota, nil
+}
+
+func (s quotaService) SetDefaultQuota(quota model.StorageQuota) utils.ServiceError {
+	if quota.MaxBytes < 0 || quota.MaxFiles < 0 {
+		return utils.NewServiceError(http.StatusBadRequest, "invalid quota")
+	}
+	if err := s.settingsRepository.Update(bson.M{"defaultQuota": quota}); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't set default quota")
+	}
+	return nil
+}
+
+func (s quotaService) SetUserQuota(userId *primitive.ObjectID, quota *model.StorageQuota) utils.ServiceError {
+	if _, err := s.userRepository.GetById(userId); err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "user not found")
+	}
+	var update bson.M
+	if quota == nil {
+		update = bson.M{"$unset": bson.M{"quota": ""}}
+	} else {
+		if quota.MaxBytes < 0 || quota.MaxFiles < 0 {
+			return utils.NewServiceError(http.StatusBadRequest, "invalid quota")
+		}
+		update = bson.M{"$set": bson.M{"quota": quota}}
+	}
+	if err := s.userRepository.Update(userId, update); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't set user quota")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCheckQuota(t *testing.T) {
+	defaultQuotaUser := primitive.NewObjectID()
+	specificQuotaUser := primitive.NewObjectID()
+	fullUser := primitive.NewObjectID()
+	unknownUser := primitive.NewObjectID()
+
+	testCases := []struct {
+		name              string
+		userId            primitive.ObjectID
+		size              int64
+		expectedErrorCode *int
+	}{
+		{
+			name:              "Unknown user",
+			userId:            unknownUser,
+			size:              10,
+			expectedErrorCode: utils.IntPtr(404),
+		},
+		{
+			name:              "Within default quota",
+			userId:            defaultQuotaUser,
+			size:              100,
+			expectedErrorCode: nil,
+		},
+		{
+			name:              "Exceeds default quota bytes",
+			userId:            defaultQuotaUser,
+			size:              101,
+			expectedErrorCode: utils.IntPtr(413),
+		},
+		{
+			name:              "Specific quota overrides default one",
+			userId:            specificQuotaUser,
+			size:              5000,
+			expectedErrorCode: nil,
+		},
+		{
+			name:              "Exceeds specific quota bytes",
+			userId:            specificQuotaUser,
+			size:              5001,
+			expectedErrorCode: utils.IntPtr(413),
+		},
+		{
+			name:              "Exceeds specific quota files",
+			userId:            fullUser,
+			size:              1,
+			expectedErrorCode: utils.IntPtr(413),
+		},
+	}
+
+	userRepositoryMock := &mocks.UserRepository{}
+	userRepositoryMock.On("GetById", &unknownUser).Return((*model.User)(nil), mongo.ErrNoDocuments)
+	userRepositoryMock.On("GetById", &defaultQuotaUser).Return(&model.User{Id: defaultQuotaUser, Usage: model.StorageUsage{Bytes: 900, Files: 3}}, nil)
+	userRepositoryMock.On("GetById", &specificQuotaUser).Return(&model.User{
+		Id:    specificQuotaUser,
+		Usage: model.StorageUsage{Bytes: 5000, Files: 9},
+		Quota: &model.StorageQuota{MaxBytes: 10000, MaxFiles: 10},
+	}, nil)
+	userRepositoryMock.On("GetById", &fullUser).Return(&model.User{
+		Id:    fullUser,
+		Usage: model.StorageUsage{Bytes: 10, Files: 10},
+		Quota: &model.StorageQuota{MaxFiles: 10},
+	}, nil)
+	settingsRepositoryMock := &mocks.SettingsRepository{}
+	settingsRepositoryMock.On("Get").Return(&model.Settings{DefaultQuota: model.StorageQuota{MaxBytes: 1000}}, nil)
+
+	svc := services.NewQuotaService(userRepositoryMock, settingsRepositoryMock)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.CheckQuota(&tc.userId, tc.size)
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionService interface {
+	// Open a new session for an authenticated user
+	Create(user *model.User, userAgent string, ip string) (*model.SessionTokens, utils.ServiceError)
+	// Issue a new access token for a session, the refresh token is rotated
+	Refresh(refreshToken string, userAgent string, ip string) (*model.SessionTokens, utils.ServiceError)
+	// Revoke the session of a refresh token (logout)
+	RevokeByRefreshToken(refreshToken string) utils.ServiceError
+	// List the active sessions of a user
+	GetAllForUser(userId *primitive.ObjectID) ([]model.Session, utils.ServiceError)
+	// Revoke a session of a user, its tokens cannot be used anymore
+	Revoke(userId *primitive.ObjectID, sessionId *primitive.ObjectID) utils.ServiceError
+	// Revoke all sessions of a user
+	RevokeAll(userId *primitive.ObjectID) utils.ServiceError
+}
+
+type sessionService struct {
+	// Repository dependencies
+	sessionRepository repository.SessionRepository
+	userRepository    repository.UserRepository
+	tokenModule       security.TokenModule
+}
+
+func NewSessionService(sessionRepository repository.SessionRepository, userRepository repository.UserRepository, tokenModule security.TokenModule) sessionService {
+	return sessionService{sessionRepository, userRepository, tokenModule}
+}
+
+func (s sessionService) Create(user *model.User, userAgent string, ip string) (*model.SessionTokens, utils.ServiceError) {
+	if user.Disabled {
+		return nil, utils.NewServiceError(http.StatusForbidden, "account is disabled")
+	}
+	now := time.Now()
+	session := model.Session{
+		Id:         primitive.NewObjectID(),
+		UserId:     user.Id,
+		UserAgent:  userAgent,
+		Ip:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(security.REFRESH_TOKEN_DURATION),
+	}
+	refreshToken, err := security.GenerateRefreshToken(&session.Id)
+	if err != nil {
+		slog.Error("couldn't generate refresh token", "error", err)
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create session")
+	}
+	session.RefreshTokenHash = security.HashRefreshToken(refreshToken)
+	if _, err := s.sessionRepository.Create(&session); err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create session")
+	}
+	return s.tokens(user, &session.Id, refreshToken)
+}
+
+func (s sessionService) Refresh(refreshToken string, userAgent string, ip string) (*model.SessionTokens, utils.ServiceError) {
+	sessionId, err := security.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	session, err := s.sessionRepository.GetById(sessionId)
+	if err != nil || time.Now().After(session.ExpiresAt) {
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	hash := security.HashRefreshToken(refreshToken)
+	if hash == session.PreviousRefreshTokenHash {
+		// A rotated token is used again, either the client or an attacker holds a stolen copy: end the session
+		slog.Warn("refresh token reused, revoking session", "userId", session.UserId.Hex(), "sessionId", session.Id.Hex())
+		s.sessionRepository.Delete(&session.UserId, &session.Id)
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	if hash != session.RefreshTokenHash {
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	user, err := s.userRepository.GetById(&session.UserId)
+	if err != nil || user.Disabled {
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	newRefreshToken, err := security.GenerateRefreshToken(&session.Id)
+	if err != nil {
+		slog.Error("couldn't generate refresh token", "error", err)
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't refresh session")
+	}
+	err = s.sessionRepository.Rotate(&session.Id, hash, security.HashRefreshToken(newRefreshToken), userAgent, ip, time.Now().Add(security.REFRESH_TOKEN_DURATION))
+	if err == mongo.ErrNoDocuments {
+		// Another request rotated the token first
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't refresh session")
+	}
+	return s.tokens(user, &session.Id, newRefreshToken)
+}
+
+func (s sessionService) RevokeByRefreshToken(refreshToken string) utils.ServiceError {
+	sessionId, err := security.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	session, err := s.sessionRepository.GetById(sessionId)
+	if err != nil || security.HashRefreshToken(refreshToken) != session.RefreshTokenHash {
+		return utils.NewServiceError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	return s.Revoke(&session.UserId, &session.Id)
+}
+
+func (s sessionService) tokens(user *model.User, sessionId *primitive.ObjectID, refreshToken string) (*model.SessionTokens, utils.ServiceError) {
+	accessToken, err := s.tokenModule.CreateToken(&user.Id, sessionId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't generate token")
+	}
+	return &model.SessionTokens{
+		SessionId:    *sessionId,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(security.ACCESS_TOKEN_DURATION.Seconds()),
+	}, nil
+}
+
+func (s sessionService) GetAllForUser(userId *primitive.ObjectID) ([]model.Session, utils.ServiceError) {
+	sessions, err := s.sessionRepository.GetAllForUser(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't list sessions")
+	}
+	return sessions, nil
+}
+
+func (s sessionService) Revoke(userId *primitive.ObjectID, sessionId *primitive.ObjectID) utils.ServiceError {
+	err := s.sessionRepository.Delete(userId, sessionId)
+	if err == mongo.ErrNoDocuments {
+		return utils.NewServiceError(http.StatusNotFound, "session not found")
+	}
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't revoke session")
+	}
+	return nil
+}
+
+func (s sessionService) RevokeAll(userId *primitive.ObjectID) utils.ServiceError {
+	if err := s.sessionRepository.DeleteAllForUser(userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't revoke sessions")
+	}
+	return nil
+}
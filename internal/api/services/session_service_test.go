@@ -0,0 +1,113 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestRefreshSession(t *testing.T) {
+	userId := primitive.NewObjectID()
+	activeSessionId := primitive.NewObjectID()
+	expiredSessionId := primitive.NewObjectID()
+	unknownSessionId := primitive.NewObjectID()
+
+	currentToken, _ := security.GenerateRefreshToken(&activeSessionId)
+	rotatedToken, _ := security.GenerateRefreshToken(&activeSessionId)
+	forgedToken, _ := security.GenerateRefreshToken(&activeSessionId)
+	expiredToken, _ := security.GenerateRefreshToken(&expiredSessionId)
+	unknownToken, _ := security.GenerateRefreshToken(&unknownSessionId)
+
+	testCases := []struct {
+		name              string
+		refreshToken      string
+		expectedErrorCode *int
+		expectRevocation  bool
+	}{
+		{
+			name:              "Malformed token",
+			refreshToken:      "notarefreshtoken",
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:              "Unknown session",
+			refreshToken:      unknownToken,
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:              "Expired session",
+			refreshToken:      expiredToken,
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:              "Wrong secret",
+			refreshToken:      forgedToken,
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:              "Rotated token used again",
+			refreshToken:      rotatedToken,
+			expectedErrorCode: utils.IntPtr(401),
+			expectRevocation:  true,
+		},
+		{
+			name:              "Valid token",
+			refreshToken:      currentToken,
+			expectedErrorCode: nil,
+		},
+	}
+
+	sessionRepositoryMock := &mocks.SessionRepository{}
+	sessionRepositoryMock.On("GetById", &unknownSessionId).Return((*model.Session)(nil), mongo.ErrNoDocuments)
+	sessionRepositoryMock.On("GetById", &expiredSessionId).Return(&model.Session{
+		Id:               expiredSessionId,
+		UserId:           userId,
+		RefreshTokenHash: security.HashRefreshToken(expiredToken),
+		ExpiresAt:        time.Now().Add(-time.Hour),
+	}, nil)
+	sessionRepositoryMock.On("GetById", &activeSessionId).Return(&model.Session{
+		Id:                       activeSessionId,
+		UserId:                   userId,
+		RefreshTokenHash:         security.HashRefreshToken(currentToken),
+		PreviousRefreshTokenHash: security.HashRefreshToken(rotatedToken),
+		ExpiresAt:                time.Now().Add(time.Hour),
+	}, nil)
+	sessionRepositoryMock.On("Delete", &userId, &activeSessionId).Return(nil)
+	sessionRepositoryMock.On("Rotate", &activeSessionId, security.HashRefreshToken(currentToken), mock.Anything, "curl/8.0", "127.0.0.1", mock.Anything).Return(nil)
+	userRepositoryMock := &mocks.UserRepository{}
+	userRepositoryMock.On("GetById", &userId).Return(&model.User{Id: userId, Email: "test@test.fr"}, nil)
+	tokenModuleMock := &mocks.TokenModule{}
+	tokenModuleMock.On("CreateToken", &userId, &activeSessionId).Return("anaccesstoken", nil)
+
+	svc := services.NewSessionService(sessionRepositoryMock, userRepositoryMock, tokenModuleMock)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := svc.Refresh(tc.refreshToken, "curl/8.0", "127.0.0.1")
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "anaccesstoken", tokens.AccessToken)
+				// The refresh token is rotated
+				assert.NotEqual(t, tc.refreshToken, tokens.RefreshToken)
+				sessionId, parseErr := security.ParseRefreshToken(tokens.RefreshToken)
+				assert.NoError(t, parseErr)
+				assert.Equal(t, activeSessionId, *sessionId)
+			}
+			if tc.expectRevocation {
+				sessionRepositoryMock.AssertCalled(t, "Delete", &userId, &activeSessionId)
+			}
+		})
+	}
+}
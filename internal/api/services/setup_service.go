@@ -0,0 +1,66 @@
+package services
+
+import (
+	"crypto/subtle"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SetupService interface {
+	// Check if the first admin can still be created, which is the case until a user exists
+	IsPending() (bool, utils.ServiceError)
+	// Create the first admin, the setup token printed at startup is required
+	CreateAdmin(setupToken string, email string, password string) (*primitive.ObjectID, utils.ServiceError)
+}
+
+type setupService struct {
+	// Repository dependencies
+	userRepository repository.UserRepository
+	// Service dependencies
+	userService UserService
+	// Empty when the setup is disabled
+	setupToken string
+	// Two requests at once must not both see an empty users collection
+	mutex *sync.Mutex
+}
+
+func NewSetupService(userRepository repository.UserRepository, userService UserService, setupToken string) setupService {
+	return setupService{userRepository, userService, setupToken, &sync.Mutex{}}
+}
+
+func (s setupService) IsPending() (bool, utils.ServiceError) {
+	if s.setupToken == "" {
+		return false, nil
+	}
+	count, err := s.userRepository.Count()
+	if err != nil {
+		return false, utils.NewServiceError(http.StatusInternalServerError, "couldn't count users")
+	}
+	return count == 0, nil
+}
+
+func (s setupService) CreateAdmin(setupToken string, email string, password string) (*primitive.ObjectID, utils.ServiceError) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pending, svcErr := s.IsPending()
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	if !pending {
+		return nil, utils.NewServiceError(http.StatusNotFound, "setup is not available")
+	}
+	if subtle.ConstantTimeCompare([]byte(setupToken), []byte(s.setupToken)) != 1 {
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid setup token")
+	}
+	userId, svcErr := s.userService.Create(email, password, true)
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	slog.Info("Created the first admin", "userId", userId.Hex())
+	return userId, nil
+}
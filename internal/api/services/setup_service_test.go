@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSetupCreateAdmin(t *testing.T) {
+	testCases := []struct {
+		name              string
+		configuredToken   string
+		usersCount        int64
+		token             string
+		expectedErrorCode *int
+	}{
+		{
+			name:              "Setup disabled",
+			configuredToken:   "",
+			token:             "",
+			expectedErrorCode: utils.IntPtr(404),
+		},
+		{
+			name:              "Users already exist",
+			configuredToken:   "setuptoken",
+			usersCount:        1,
+			token:             "setuptoken",
+			expectedErrorCode: utils.IntPtr(404),
+		},
+		{
+			name:              "Invalid token",
+			configuredToken:   "setuptoken",
+			token:             "guessed",
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:            "First admin",
+			configuredToken: "setuptoken",
+			token:           "setuptoken",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userRepositoryMock := &mocks.UserRepository{}
+			userRepositoryMock.On("Count").Return(tc.usersCount, nil)
+			userServiceMock := &mocks.UserService{}
+			userId := primitive.NewObjectID()
+			userServiceMock.On("Create", "admin@test.fr", "azertyuiop", true).Return(&userId, nil)
+
+			svc := services.NewSetupService(userRepositoryMock, userServiceMock, tc.configuredToken)
+			createdId, err := svc.CreateAdmin(tc.token, "admin@test.fr", "azertyuiop")
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				userServiceMock.AssertNotCalled(t, "Create", "admin@test.fr", "azertyuiop", true)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, userId, *createdId)
+		})
+	}
+}
@@ -2,42 +2,70 @@ package services
 
 import (
 	"crypto/rand"
+	"data-storage-svc/internal/api/security"
 	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
 	"data-storage-svc/internal/utils"
 	"encoding/base64"
+	"fmt"
 	"net/http"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type SharedLinkService interface {
-	// Create a new shared link for the given album id
-	Create(albumId primitive.ObjectID, createdBy primitive.ObjectID, expirationDate time.Time, canEdit bool) (*model.SharedLink, utils.ServiceError)
+	// Create a new shared link for the given album id, scopes tell what visitors can do in the album and maxUses limits
+	// the number of visits made with it, each starting a link session (0 for no limit). Visitors must give the password before using the link,
+	// unless it is empty.
+	Create(albumId primitive.ObjectID, createdBy primitive.ObjectID, expirationDate time.Time, scopes []model.SharedLinkScope, maxUses int64, password string) (*model.SharedLink, utils.ServiceError)
 	// List all shared link for the given album id
 	List(albumId primitive.ObjectID) ([]model.SharedLink, utils.ServiceError)
-	// Get a shared link id by its token
+	// Get a shared link by its token, even if it cannot be used anymore
 	GetByToken(token string) (*model.SharedLink, utils.ServiceError)
 	// Delete a given shared link
 	Delete(sharedLinkId primitive.ObjectID) utils.ServiceError
-	// Update an existing shared link
-	Update(sharedLinkId primitive.ObjectID, canEdit bool) utils.ServiceError
+	// Update an existing shared link, only the given fields are changed
+	Update(sharedLinkId primitive.ObjectID, update SharedLinkUpdate) (*model.SharedLink, utils.ServiceError)
+	// Check the password of a shared link, returns a token proving it for a while
+	Unlock(token string, password string) (*model.SharedLink, string, utils.ServiceError)
+}
+
+// Changes to a shared link, nil fields are left as they are
+type SharedLinkUpdate struct {
+	Scopes         []model.SharedLinkScope
+	ExpirationDate *time.Time
+	MaxUses        *int64
+	Revoked        *bool
+	// An empty password removes the protection
+	Password *string
 }
 
 type sharedLinkService struct {
 	// Repository dependencies
 	sharedLinkRepository  repository.SharedLinkRepository
 	albumAccessRepository repository.AlbumAccessRepository
+	hashModule            security.HashModule
+	tokenModule           security.TokenModule
 	// Service dependencies
 }
 
-func NewSharedLinkService(sharedLinkRepository repository.SharedLinkRepository, albumAccessRepository repository.AlbumAccessRepository) SharedLinkService {
-	return sharedLinkService{sharedLinkRepository: sharedLinkRepository, albumAccessRepository: albumAccessRepository}
+func NewSharedLinkService(sharedLinkRepository repository.SharedLinkRepository, albumAccessRepository repository.AlbumAccessRepository, hashModule security.HashModule, tokenModule security.TokenModule) SharedLinkService {
+	return sharedLinkService{sharedLinkRepository: sharedLinkRepository, albumAccessRepository: albumAccessRepository, hashModule: hashModule, tokenModule: tokenModule}
 }
 
-func (s sharedLinkService) Create(albumId primitive.ObjectID, createdBy primitive.ObjectID, expirationDate time.Time, canEdit bool) (*model.SharedLink, utils.ServiceError) {
-	access, err := s.albumAccessRepository.Get(&createdBy, &albumId)
+func (s sharedLinkService) Create(albumId primitive.ObjectID, createdBy primitive.ObjectID, expirationDate time.Time, scopes []model.SharedLinkScope, maxUses int64, password string) (*model.SharedLink, utils.ServiceError) {
+	if svcErr := checkScopes(scopes); svcErr != nil {
+		return nil, svcErr
+	}
+	if !expirationDate.After(time.Now()) {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "expiration date should be in the future")
+	}
+	if maxUses < 0 {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "max uses cannot be negative")
+	}
+	access, err := s.albumAccessRepository.GetEffective(&createdBy, &albumId)
 	if err != nil || access == nil {
 		return nil, utils.NewServiceError(http.StatusUnauthorized, "cannot create a shared link for this album")
 	}
@@ -45,7 +73,13 @@ func (s sharedLinkService) Create(albumId primitive.ObjectID, createdBy primitiv
 	if err != nil {
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create the shared link")
 	}
-	newLink := model.SharedLink{AlbumId: albumId, CreatedBy: createdBy, CreatedAt: time.Now(), Token: token, ExpirationDate: expirationDate, CanEdit: canEdit}
+	newLink := model.SharedLink{AlbumId: albumId, CreatedBy: createdBy, CreatedAt: time.Now(), Token: token, ExpirationDate: expirationDate, Scopes: scopes, MaxUses: maxUses}
+	if password != "" {
+		if newLink.PasswordHash, err = s.hashModule.HashPassword(password); err != nil {
+			return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create the shared link")
+		}
+		newLink.PasswordProtected = true
+	}
 	_, err = s.sharedLinkRepository.Create(&newLink)
 	if err != nil {
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create the shared link")
@@ -53,6 +87,19 @@ func (s sharedLinkService) Create(albumId primitive.ObjectID, createdBy primitiv
 	return &newLink, nil
 }
 
+// A link does something, and only things it knows about
+func checkScopes(scopes []model.SharedLinkScope) utils.ServiceError {
+	if len(scopes) == 0 {
+		return utils.NewServiceError(http.StatusBadRequest, "a shared link needs at least one scope")
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return utils.NewServiceError(http.StatusBadRequest, fmt.Sprintf("unknown shared link scope %q", scope))
+		}
+	}
+	return nil
+}
+
 func generateRandomString(length int) (string, error) {
 	// Calculate the number of bytes required
 	byteLength := (length * 3) / 4
@@ -93,10 +140,82 @@ func (s sharedLinkService) Delete(sharedLinkId primitive.ObjectID) utils.Service
 	return nil
 }
 
-func (s sharedLinkService) Update(sharedLinkId primitive.ObjectID, canEdit bool) utils.ServiceError {
-	err := s.sharedLinkRepository.Update(sharedLinkId, canEdit)
+func (s sharedLinkService) Update(sharedLinkId primitive.ObjectID, update SharedLinkUpdate) (*model.SharedLink, utils.ServiceError) {
+	set, unset := bson.M{}, bson.M{}
+	if update.Scopes != nil {
+		if svcErr := checkScopes(update.Scopes); svcErr != nil {
+			return nil, svcErr
+		}
+		set["scopes"] = update.Scopes
+		// Scopes replace what the link could do before
+		unset["canEdit"] = ""
+	}
+	if update.ExpirationDate != nil {
+		if !update.ExpirationDate.After(time.Now()) {
+			return nil, utils.NewServiceError(http.StatusBadRequest, "expiration date should be in the future")
+		}
+		set["expiration"] = *update.ExpirationDate
+	}
+	if update.MaxUses != nil {
+		if *update.MaxUses < 0 {
+			return nil, utils.NewServiceError(http.StatusBadRequest, "max uses cannot be negative")
+		}
+		set["maxUses"] = *update.MaxUses
+	}
+	if update.Revoked != nil {
+		set["revoked"] = *update.Revoked
+	}
+	if update.Password != nil {
+		if *update.Password == "" {
+			set["passwordProtected"] = false
+			unset["passwordHash"] = ""
+		} else {
+			hash, err := s.hashModule.HashPassword(*update.Password)
+			if err != nil {
+				return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't update shared link")
+			}
+			set["passwordHash"] = hash
+			set["passwordProtected"] = true
+		}
+	}
+	changes := bson.M{}
+	if len(set) > 0 {
+		changes["$set"] = set
+	}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+	if len(changes) > 0 {
+		if err := s.sharedLinkRepository.Update(sharedLinkId, changes); err != nil {
+			return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't update shared link")
+		}
+	}
+	sharedLink, err := s.sharedLinkRepository.Get(&sharedLinkId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't update shared link")
+	}
+	return sharedLink, nil
+}
+
+func (s sharedLinkService) Unlock(token string, password string) (*model.SharedLink, string, utils.ServiceError) {
+	sharedLink, err := s.sharedLinkRepository.GetByToken(token)
 	if err != nil {
-		return utils.NewServiceError(http.StatusInternalServerError, "couldn't update shared link")
+		return nil, "", utils.NewServiceError(http.StatusNotFound, "couldn't find the shared link")
 	}
-	return nil
+	if !sharedLink.IsUsable(time.Now()) {
+		return nil, "", utils.NewServiceError(http.StatusGone, "this shared link has expired or has been revoked")
+	}
+	if sharedLink.PasswordProtected && !s.hashModule.VerifyPassword(password, sharedLink.PasswordHash) {
+		return nil, "", utils.NewServiceError(http.StatusUnauthorized, "invalid password")
+	}
+	// Unlocking starts a visit, which counts as a use of the link
+	sharedLink, err = s.sharedLinkRepository.Use(token, time.Now())
+	if err != nil {
+		return nil, "", utils.NewServiceError(http.StatusGone, "this shared link has expired or has been revoked")
+	}
+	sessionToken, err := s.tokenModule.CreateLinkSessionToken(&sharedLink.Id)
+	if err != nil {
+		return nil, "", utils.NewServiceError(http.StatusInternalServerError, "couldn't unlock the shared link")
+	}
+	return sharedLink, sessionToken, nil
 }
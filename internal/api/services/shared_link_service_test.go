@@ -0,0 +1,178 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCreateSharedLink(t *testing.T) {
+	userId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name              string
+		expirationDate    time.Time
+		scopes            []model.SharedLinkScope
+		maxUses           int64
+		expectedErrorCode *int
+	}{
+		{"Valid link", time.Now().Add(time.Hour), []model.SharedLinkScope{model.SHARED_LINK_SCOPE_VIEW}, 10, nil},
+		{"Drop box link", time.Now().Add(time.Hour), []model.SharedLinkScope{model.SHARED_LINK_SCOPE_UPLOAD}, 0, nil},
+		{"Already expired", time.Now().Add(-time.Hour), []model.SharedLinkScope{model.SHARED_LINK_SCOPE_VIEW}, 0, utils.IntPtr(400)},
+		{"Negative max uses", time.Now().Add(time.Hour), []model.SharedLinkScope{model.SHARED_LINK_SCOPE_VIEW}, -1, utils.IntPtr(400)},
+		{"No scope", time.Now().Add(time.Hour), []model.SharedLinkScope{}, 0, utils.IntPtr(400)},
+		{"Unknown scope", time.Now().Add(time.Hour), []model.SharedLinkScope{"admin"}, 0, utils.IntPtr(400)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sharedLinkRepository := &mocks.SharedLinkRepository{}
+			sharedLinkRepository.On("Create", mock.Anything).Return(&primitive.ObjectID{}, nil)
+			albumAccessRepository := &mocks.AlbumAccessRepository{}
+			albumAccessRepository.On("GetEffective", &userId, &albumId).Return(&model.UserAlbumAccess{}, nil)
+
+			svc := services.NewSharedLinkService(sharedLinkRepository, albumAccessRepository, nil, nil)
+			link, err := svc.Create(albumId, userId, tc.expirationDate, tc.scopes, tc.maxUses, "")
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				sharedLinkRepository.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.maxUses, link.MaxUses)
+			assert.Equal(t, tc.scopes, link.Scopes)
+			assert.True(t, link.IsUsable(time.Now()))
+		})
+	}
+}
+
+func TestUpdateSharedLink(t *testing.T) {
+	linkId := primitive.NewObjectID()
+	sharedLinkRepository := &mocks.SharedLinkRepository{}
+	sharedLinkRepository.On("Update", linkId, mock.Anything).Return(nil)
+	sharedLinkRepository.On("Get", &linkId).Return(&model.SharedLink{Id: linkId, Revoked: true}, nil)
+	hashModule := &mocks.HashModule{}
+	hashModule.On("HashPassword", "linkpassword").Return("linkhash", nil)
+	svc := services.NewSharedLinkService(sharedLinkRepository, nil, hashModule, nil)
+
+	// Only the given fields change
+	revoked := true
+	link, err := svc.Update(linkId, services.SharedLinkUpdate{Revoked: &revoked})
+	assert.Nil(t, err)
+	assert.True(t, link.Revoked)
+	sharedLinkRepository.AssertCalled(t, "Update", linkId, bson.M{"$set": bson.M{"revoked": true}})
+
+	expiration := time.Now().Add(24 * time.Hour)
+	maxUses := int64(5)
+	_, err = svc.Update(linkId, services.SharedLinkUpdate{ExpirationDate: &expiration, MaxUses: &maxUses})
+	assert.Nil(t, err)
+	sharedLinkRepository.AssertCalled(t, "Update", linkId, bson.M{"$set": bson.M{"expiration": expiration, "maxUses": maxUses}})
+
+	// Setting a password protects the link, an empty one removes the protection
+	password := "linkpassword"
+	_, err = svc.Update(linkId, services.SharedLinkUpdate{Password: &password})
+	assert.Nil(t, err)
+	sharedLinkRepository.AssertCalled(t, "Update", linkId, bson.M{"$set": bson.M{"passwordHash": "linkhash", "passwordProtected": true}})
+	noPassword := ""
+	_, err = svc.Update(linkId, services.SharedLinkUpdate{Password: &noPassword})
+	assert.Nil(t, err)
+	sharedLinkRepository.AssertCalled(t, "Update", linkId, bson.M{"$set": bson.M{"passwordProtected": false}, "$unset": bson.M{"passwordHash": ""}})
+
+	// Scopes replace the edit flag of links created before them
+	scopes := []model.SharedLinkScope{model.SHARED_LINK_SCOPE_VIEW, model.SHARED_LINK_SCOPE_UPLOAD}
+	_, err = svc.Update(linkId, services.SharedLinkUpdate{Scopes: scopes})
+	assert.Nil(t, err)
+	sharedLinkRepository.AssertCalled(t, "Update", linkId, bson.M{"$set": bson.M{"scopes": scopes}, "$unset": bson.M{"canEdit": ""}})
+	_, err = svc.Update(linkId, services.SharedLinkUpdate{Scopes: []model.SharedLinkScope{}})
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.GetCode())
+
+	past := time.Now().Add(-time.Minute)
+	_, err = svc.Update(linkId, services.SharedLinkUpdate{ExpirationDate: &past})
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.GetCode())
+}
+
+func TestUnlockSharedLink(t *testing.T) {
+	linkId := primitive.NewObjectID()
+	tomorrow := time.Now().Add(24 * time.Hour)
+	sharedLinkRepository := &mocks.SharedLinkRepository{}
+	sharedLinkRepository.On("GetByToken", "protected").Return(&model.SharedLink{Id: linkId, ExpirationDate: tomorrow, PasswordProtected: true, PasswordHash: "linkhash"}, nil)
+	sharedLinkRepository.On("GetByToken", "revoked").Return(&model.SharedLink{Id: linkId, ExpirationDate: tomorrow, PasswordProtected: true, PasswordHash: "linkhash", Revoked: true}, nil)
+	sharedLinkRepository.On("GetByToken", "unknown").Return((*model.SharedLink)(nil), mongo.ErrNoDocuments)
+	// Another visitor took the last use in the meantime
+	sharedLinkRepository.On("GetByToken", "usedup").Return(&model.SharedLink{Id: linkId, ExpirationDate: tomorrow, PasswordProtected: true, PasswordHash: "linkhash", MaxUses: 1}, nil)
+	sharedLinkRepository.On("Use", "protected", mock.Anything).Return(&model.SharedLink{Id: linkId, ExpirationDate: tomorrow, PasswordProtected: true, Uses: 1}, nil)
+	sharedLinkRepository.On("Use", "usedup", mock.Anything).Return((*model.SharedLink)(nil), mongo.ErrNoDocuments)
+	hashModule := &mocks.HashModule{}
+	hashModule.On("VerifyPassword", "linkpassword", "linkhash").Return(true)
+	hashModule.On("VerifyPassword", mock.Anything, "linkhash").Return(false)
+	tokenModule := &mocks.TokenModule{}
+	tokenModule.On("CreateLinkSessionToken", &linkId).Return("linksession", nil)
+	svc := services.NewSharedLinkService(sharedLinkRepository, nil, hashModule, tokenModule)
+
+	testCases := []struct {
+		name              string
+		token             string
+		password          string
+		expectedErrorCode *int
+	}{
+		{"Valid password", "protected", "linkpassword", nil},
+		{"Invalid password", "protected", "guessed", utils.IntPtr(401)},
+		{"Revoked link", "revoked", "linkpassword", utils.IntPtr(410)},
+		{"Unknown link", "unknown", "linkpassword", utils.IntPtr(404)},
+		{"Used up link", "usedup", "linkpassword", utils.IntPtr(410)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			link, sessionToken, err := svc.Unlock(tc.token, tc.password)
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				assert.Empty(t, sessionToken)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, linkId, link.Id)
+			assert.Equal(t, "linksession", sessionToken)
+		})
+	}
+	// Only a successful unlock counts a use
+	sharedLinkRepository.AssertNumberOfCalls(t, "Use", 2)
+}
+
+func TestSharedLinkIsUsable(t *testing.T) {
+	now := time.Now()
+	tomorrow := now.Add(24 * time.Hour)
+	assert.True(t, (&model.SharedLink{ExpirationDate: tomorrow}).IsUsable(now))
+	assert.True(t, (&model.SharedLink{ExpirationDate: tomorrow, MaxUses: 2, Uses: 1}).IsUsable(now))
+	assert.False(t, (&model.SharedLink{ExpirationDate: tomorrow, MaxUses: 2, Uses: 2}).IsUsable(now))
+	assert.False(t, (&model.SharedLink{ExpirationDate: tomorrow, Revoked: true}).IsUsable(now))
+	assert.False(t, (&model.SharedLink{ExpirationDate: now.Add(-time.Second)}).IsUsable(now))
+}
+
+func TestSharedLinkHasScope(t *testing.T) {
+	dropBox := model.SharedLink{Scopes: []model.SharedLinkScope{model.SHARED_LINK_SCOPE_UPLOAD}}
+	assert.True(t, dropBox.HasScope(model.SHARED_LINK_SCOPE_UPLOAD))
+	assert.False(t, dropBox.HasScope(model.SHARED_LINK_SCOPE_VIEW))
+
+	// Links created before scopes keep what they could do
+	legacy := model.SharedLink{}
+	assert.True(t, legacy.HasScope(model.SHARED_LINK_SCOPE_VIEW))
+	assert.True(t, legacy.HasScope(model.SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS))
+	assert.False(t, legacy.HasScope(model.SHARED_LINK_SCOPE_UPLOAD))
+	legacyEdit := model.SharedLink{CanEdit: true}
+	assert.True(t, legacyEdit.HasScope(model.SHARED_LINK_SCOPE_UPLOAD))
+	assert.True(t, legacyEdit.HasScope(model.SHARED_LINK_SCOPE_REMOVE))
+}
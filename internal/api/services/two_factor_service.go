@@ -0,0 +1,193 @@
+package services
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/ratelimit"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TwoFactorService interface {
+	// Start a TOTP enrollment, the secret is only enabled once confirmed with a code
+	StartTotpEnrollment(userId *primitive.ObjectID) (*security.TotpEnrollment, utils.ServiceError)
+	// Confirm a TOTP enrollment with a code from the authenticator app, returns the recovery codes
+	ConfirmTotpEnrollment(userId *primitive.ObjectID, code string) ([]string, utils.ServiceError)
+	// Disable TOTP, a code or a recovery code is required
+	DisableTotp(userId *primitive.ObjectID, code string) utils.ServiceError
+	// Replace all recovery codes, a code or a recovery code is required
+	RegenerateRecoveryCodes(userId *primitive.ObjectID, code string) ([]string, utils.ServiceError)
+	// Second login step, check the code of the user of a MFA token and open a session
+	CompleteLogin(mfaToken string, code string, userAgent string, ip string) (*model.User, *model.SessionTokens, utils.ServiceError)
+}
+
+type twoFactorService struct {
+	// Repository dependencies
+	userRepository repository.UserRepository
+	tokenModule    security.TokenModule
+	// Service dependencies
+	sessionService SessionService
+	loginGuard     ratelimit.LoginGuard
+}
+
+func NewTwoFactorService(userRepository repository.UserRepository, tokenModule security.TokenModule, sessionService SessionService, loginGuard ratelimit.LoginGuard) twoFactorService {
+	return twoFactorService{userRepository, tokenModule, sessionService, loginGuard}
+}
+
+func (s twoFactorService) StartTotpEnrollment(userId *primitive.ObjectID) (*security.TotpEnrollment, utils.ServiceError) {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if user.HasTwoFactor() {
+		return nil, utils.NewServiceError(http.StatusConflict, "two-factor authentication is already enabled")
+	}
+	enrollment, err := security.GenerateTotp(user.Email)
+	if err != nil {
+		slog.Error("couldn't generate totp secret", "error", err)
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't start enrollment")
+	}
+	// Restarting an enrollment replaces the pending secret
+	totp := model.TotpSettings{Secret: enrollment.Secret, Enabled: false, RecoveryCodeHashes: []string{}}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"totp": totp}}); err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't start enrollment")
+	}
+	return enrollment, nil
+}
+
+func (s twoFactorService) ConfirmTotpEnrollment(userId *primitive.ObjectID, code string) ([]string, utils.ServiceError) {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if user.Totp == nil {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "no enrollment in progress")
+	}
+	if user.Totp.Enabled {
+		return nil, utils.NewServiceError(http.StatusConflict, "two-factor authentication is already enabled")
+	}
+	step, valid := security.ValidateTotp(user.Totp.Secret, code, time.Now(), user.Totp.LastUsedStep)
+	if !valid {
+		return nil, utils.NewServiceError(http.StatusUnauthorized, "invalid code")
+	}
+	recoveryCodes, recoveryCodeHashes, svcErr := newRecoveryCodes()
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	err = s.userRepository.Update(userId, bson.M{"$set": bson.M{
+		"totp.enabled":            true,
+		"totp.enrolledAt":         time.Now(),
+		"totp.lastUsedStep":       step,
+		"totp.recoveryCodeHashes": recoveryCodeHashes,
+	}})
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't enable two-factor authentication")
+	}
+	return recoveryCodes, nil
+}
+
+func (s twoFactorService) DisableTotp(userId *primitive.ObjectID, code string) utils.ServiceError {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if user.HasTwoFactor() {
+		if svcErr := s.verifySecondFactor(user, code); svcErr != nil {
+			return svcErr
+		}
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$unset": bson.M{"totp": ""}}); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't disable two-factor authentication")
+	}
+	return nil
+}
+
+func (s twoFactorService) RegenerateRecoveryCodes(userId *primitive.ObjectID, code string) ([]string, utils.ServiceError) {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if !user.HasTwoFactor() {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "two-factor authentication is not enabled")
+	}
+	if svcErr := s.verifySecondFactor(user, code); svcErr != nil {
+		return nil, svcErr
+	}
+	recoveryCodes, recoveryCodeHashes, svcErr := newRecoveryCodes()
+	if svcErr != nil {
+		return nil, svcErr
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"totp.recoveryCodeHashes": recoveryCodeHashes}}); err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't regenerate recovery codes")
+	}
+	return recoveryCodes, nil
+}
+
+func (s twoFactorService) CompleteLogin(mfaToken string, code string, userAgent string, ip string) (*model.User, *model.SessionTokens, utils.ServiceError) {
+	userId, err := s.tokenModule.ParseMfaToken(mfaToken)
+	if err != nil {
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "login expired, please try again")
+	}
+	user, err := s.userRepository.GetById(userId)
+	if err != nil || !user.HasTwoFactor() {
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+	}
+	// Codes are short, guessing them counts as failed logins of the account
+	if allowed, retryAfter := s.loginGuard.Allow(user.Email); !allowed {
+		return nil, nil, tooManyAttempts(retryAfter)
+	}
+	if svcErr := s.verifySecondFactor(user, code); svcErr != nil {
+		if svcErr.GetCode() == http.StatusUnauthorized {
+			s.loginGuard.Failure(user.Email)
+		}
+		return nil, nil, svcErr
+	}
+	s.loginGuard.Success(user.Email)
+	tokens, svcErr := s.sessionService.Create(user, userAgent, ip)
+	if svcErr != nil {
+		return nil, nil, svcErr
+	}
+	if s.userRepository.Update(&user.Id, bson.M{"$set": bson.M{"lastLogin": time.Now()}}) != nil {
+		slog.Error("couldn't update last login date for user", "userId", user.Id.Hex())
+	}
+	return user, tokens, nil
+}
+
+// Check a TOTP code or a recovery code, then mark it used
+func (s twoFactorService) verifySecondFactor(user *model.User, code string) utils.ServiceError {
+	if step, valid := security.ValidateTotp(user.Totp.Secret, code, time.Now(), user.Totp.LastUsedStep); valid {
+		if err := s.userRepository.Update(&user.Id, bson.M{"$set": bson.M{"totp.lastUsedStep": step}}); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't check code")
+		}
+		return nil
+	}
+	recoveryCodeHash := security.HashRecoveryCode(code)
+	if slices.Contains(user.Totp.RecoveryCodeHashes, recoveryCodeHash) {
+		if err := s.userRepository.Update(&user.Id, bson.M{"$pull": bson.M{"totp.recoveryCodeHashes": recoveryCodeHash}}); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't check code")
+		}
+		slog.Info("recovery code used", "userId", user.Id.Hex(), "remaining", len(user.Totp.RecoveryCodeHashes)-1)
+		return nil
+	}
+	return utils.NewServiceError(http.StatusUnauthorized, "invalid code")
+}
+
+func newRecoveryCodes() ([]string, []string, utils.ServiceError) {
+	recoveryCodes, err := security.GenerateRecoveryCodes()
+	if err != nil {
+		slog.Error("couldn't generate recovery codes", "error", err)
+		return nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't generate recovery codes")
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = security.HashRecoveryCode(code)
+	}
+	return recoveryCodes, hashes, nil
+}
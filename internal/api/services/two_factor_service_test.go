@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCompleteLogin(t *testing.T) {
+	userId := primitive.NewObjectID()
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	validCode, _ := totp.GenerateCode(secret, time.Now())
+	currentStep := time.Now().Unix() / security.TOTP_PERIOD
+
+	testCases := []struct {
+		name              string
+		mfaToken          string
+		code              string
+		lastUsedStep      int64
+		expectedErrorCode *int
+		expectedUpdate    string
+	}{
+		{
+			name:              "Invalid MFA token",
+			mfaToken:          "expired",
+			code:              validCode,
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:           "Valid code",
+			mfaToken:       "valid",
+			code:           validCode,
+			expectedUpdate: "$set",
+		},
+		{
+			name:              "Replayed code",
+			mfaToken:          "valid",
+			code:              validCode,
+			lastUsedStep:      currentStep + 1,
+			expectedErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:           "Recovery code",
+			mfaToken:       "valid",
+			code:           "ABCDE-FGHIJ",
+			expectedUpdate: "$pull",
+		},
+		{
+			name:              "Invalid code",
+			mfaToken:          "valid",
+			code:              "000000",
+			expectedErrorCode: utils.IntPtr(401),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenModuleMock := &mocks.TokenModule{}
+			tokenModuleMock.On("ParseMfaToken", "valid").Return(&userId, nil)
+			tokenModuleMock.On("ParseMfaToken", "expired").Return((*primitive.ObjectID)(nil), errors.New("token is expired"))
+			userRepositoryMock := &mocks.UserRepository{}
+			userRepositoryMock.On("GetById", &userId).Return(&model.User{Id: userId, Email: "test@test.fr", Totp: &model.TotpSettings{
+				Secret:             secret,
+				Enabled:            true,
+				LastUsedStep:       tc.lastUsedStep,
+				RecoveryCodeHashes: []string{security.HashRecoveryCode("abcde-fghij")},
+			}}, nil)
+			userRepositoryMock.On("Update", &userId, mock.Anything).Return(nil)
+			sessionServiceMock := &mocks.SessionService{}
+			sessionServiceMock.On("Create", mock.Anything, "browser", "127.0.0.1").Return(&model.SessionTokens{AccessToken: "jwt"}, nil)
+
+			svc := services.NewTwoFactorService(userRepositoryMock, tokenModuleMock, sessionServiceMock, allowingLoginGuard())
+			user, tokens, err := svc.CompleteLogin(tc.mfaToken, tc.code, "browser", "127.0.0.1")
+			if tc.expectedErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectedErrorCode, err.GetCode())
+				sessionServiceMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, userId, user.Id)
+			assert.Equal(t, "jwt", tokens.AccessToken)
+			// The code is marked used
+			userRepositoryMock.AssertCalled(t, "Update", &userId, mock.MatchedBy(func(update bson.M) bool {
+				_, ok := update[tc.expectedUpdate]
+				return ok
+			}))
+		})
+	}
+}
+
+func TestConfirmTotpEnrollment(t *testing.T) {
+	userId := primitive.NewObjectID()
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	validCode, _ := totp.GenerateCode(secret, time.Now())
+
+	userRepositoryMock := &mocks.UserRepository{}
+	userRepositoryMock.On("GetById", &userId).Return(&model.User{Id: userId, Totp: &model.TotpSettings{Secret: secret}}, nil)
+	userRepositoryMock.On("Update", &userId, mock.Anything).Return(nil)
+
+	svc := services.NewTwoFactorService(userRepositoryMock, nil, nil, nil)
+
+	_, err := svc.ConfirmTotpEnrollment(&userId, "000000")
+	assert.NotNil(t, err)
+	assert.Equal(t, 401, err.GetCode())
+
+	recoveryCodes, err := svc.ConfirmTotpEnrollment(&userId, validCode)
+	assert.Nil(t, err)
+	assert.Len(t, recoveryCodes, security.RECOVERY_CODES_COUNT)
+	// Only hashes of the recovery codes are stored
+	userRepositoryMock.AssertCalled(t, "Update", &userId, mock.MatchedBy(func(update bson.M) bool {
+		set := update["$set"].(bson.M)
+		hashes, ok := set["totp.recoveryCodeHashes"].([]string)
+		return ok && set["totp.enabled"] == true && hashes[0] == security.HashRecoveryCode(recoveryCodes[0]) && hashes[0] != recoveryCodes[0]
+	}))
+}
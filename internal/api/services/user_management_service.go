@@ -0,0 +1,308 @@
+package services
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/utils"
+	"log/slog"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserManagementService interface {
+	// Disable or enable a user, disabling a user revokes all their sessions
+	SetDisabled(userId *primitive.ObjectID, disabled bool) utils.ServiceError
+	// Grant or revoke the admin role
+	SetAdmin(userId *primitive.ObjectID, isAdmin bool) utils.ServiceError
+	// Set the password of a user, a random password is generated when none is given. All their sessions are revoked.
+	ResetPassword(userId *primitive.ObjectID, newPassword string) (*string, utils.ServiceError)
+	// Delete a user. Their albums and medias are transferred to another user, or deleted when transferTo is nil.
+	Delete(userId *primitive.ObjectID, transferTo *primitive.ObjectID) utils.ServiceError
+}
+
+type userManagementService struct {
+	// Repository dependencies
+	userRepository         repository.UserRepository
+	hashModule             security.HashModule
+	passwordPolicy         security.PasswordPolicy
+	albumRepository        repository.AlbumRepository
+	albumAccessRepository  repository.AlbumAccessRepository
+	mediaRepository        repository.MediaRepository
+	mediaInAlbumRepository repository.MediaInAlbumRepository
+	mediaAccessRepository  repository.MediaAccessRepository
+	sharedLinkRepository   repository.SharedLinkRepository
+	apiKeyRepository       repository.ApiKeyRepository
+	groupRepository        repository.GroupRepository
+	// Pending transfers from or to the user cannot be answered anymore
+	ownershipTransferRepository repository.OwnershipTransferRepository
+	// Service dependencies
+	sessionService SessionService
+	albumService   AlbumService
+	mediaService   MediaService
+	quotaService   QuotaService
+}
+
+func NewUserManagementService(
+	userRepository repository.UserRepository,
+	hashModule security.HashModule,
+	passwordPolicy security.PasswordPolicy,
+	albumRepository repository.AlbumRepository,
+	albumAccessRepository repository.AlbumAccessRepository,
+	mediaRepository repository.MediaRepository,
+	mediaInAlbumRepository repository.MediaInAlbumRepository,
+	mediaAccessRepository repository.MediaAccessRepository,
+	sharedLinkRepository repository.SharedLinkRepository,
+	apiKeyRepository repository.ApiKeyRepository,
+	groupRepository repository.GroupRepository,
+	ownershipTransferRepository repository.OwnershipTransferRepository,
+	sessionService SessionService,
+	albumService AlbumService,
+	mediaService MediaService,
+	quotaService QuotaService,
+) userManagementService {
+	return userManagementService{userRepository, hashModule, passwordPolicy, albumRepository, albumAccessRepository, mediaRepository, mediaInAlbumRepository, mediaAccessRepository, sharedLinkRepository, apiKeyRepository, groupRepository, ownershipTransferRepository, sessionService, albumService, mediaService, quotaService}
+}
+
+func (s userManagementService) SetDisabled(userId *primitive.ObjectID, disabled bool) utils.ServiceError {
+	if _, err := s.userRepository.GetById(userId); err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"disabled": disabled}}); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't update user")
+	}
+	if disabled {
+		// API keys are checked against the user on each request, sessions must go
+		return s.sessionService.RevokeAll(userId)
+	}
+	return nil
+}
+
+func (s userManagementService) SetAdmin(userId *primitive.ObjectID, isAdmin bool) utils.ServiceError {
+	if _, err := s.userRepository.GetById(userId); err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"isAdmin": isAdmin}}); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't update user")
+	}
+	return nil
+}
+
+func (s userManagementService) ResetPassword(userId *primitive.ObjectID, newPassword string) (*string, utils.ServiceError) {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if newPassword == "" {
+		// Random passwords are not checked against the policy, which is meant for passwords chosen by people
+		generated, err := security.GeneratePassword()
+		if err != nil {
+			return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't reset password")
+		}
+		newPassword = generated
+	} else if svcErr := checkPassword(s.passwordPolicy, newPassword, user.Email); svcErr != nil {
+		return nil, svcErr
+	}
+	hash, err := s.hashModule.HashPassword(newPassword)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't reset password")
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"passwordHash": hash}}); err != nil {
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't reset password")
+	}
+	if svcErr := s.sessionService.RevokeAll(userId); svcErr != nil {
+		return nil, svcErr
+	}
+	return &newPassword, nil
+}
+
+func (s userManagementService) Delete(userId *primitive.ObjectID, transferTo *primitive.ObjectID) utils.ServiceError {
+	if _, err := s.userRepository.GetById(userId); err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	var svcErr utils.ServiceError
+	if transferTo != nil {
+		if *transferTo == *userId {
+			return utils.NewServiceError(http.StatusBadRequest, "cannot transfer data to the deleted user")
+		}
+		if _, err := s.userRepository.GetById(transferTo); err != nil {
+			return utils.NewServiceError(http.StatusBadRequest, "couldn't find user to transfer data to")
+		}
+		svcErr = s.transfer(userId, transferTo)
+	} else {
+		svcErr = s.purge(userId)
+	}
+	if svcErr != nil {
+		return svcErr
+	}
+
+	// Nothing belongs to the user anymore, remove what gave them access to other users' data
+	albumAccesses, err := s.albumAccessRepository.GetAllByUser(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete user")
+	}
+	for _, access := range albumAccesses {
+		s.albumAccessRepository.Remove(userId, access.AlbumId)
+	}
+	mediaAccesses, err := s.mediaAccessRepository.GetAllForUser(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete user")
+	}
+	for _, access := range mediaAccesses {
+		s.mediaAccessRepository.Remove(userId, access.MediaId)
+	}
+	if err := s.groupRepository.RemoveMemberFromAll(userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete user")
+	}
+	if err := s.ownershipTransferRepository.CancelAllPendingForUser(userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete user")
+	}
+	if err := s.apiKeyRepository.DeleteAllForUser(userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete user")
+	}
+	if svcErr := s.sessionService.RevokeAll(userId); svcErr != nil {
+		return svcErr
+	}
+	if err := s.userRepository.Delete(userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete user")
+	}
+	return nil
+}
+
+// Give the albums, medias and shared links of a user to another user
+func (s userManagementService) transfer(userId *primitive.ObjectID, transferTo *primitive.ObjectID) utils.ServiceError {
+	albums, err := s.albumRepository.GetAllByAuthor(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer albums")
+	}
+	for _, album := range albums {
+		if err := s.albumRepository.SetAuthor(album.Id, userId, transferTo); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer albums")
+		}
+		if err := s.albumAccessRepository.Create(transferTo, album.Id, model.ALBUM_ROLE_OWNER); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer albums")
+		}
+	}
+
+	medias, err := s.mediaRepository.GetAllUploadedBy(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+	}
+	for _, media := range medias {
+		if media.Hash != nil {
+			if existing, err := s.mediaRepository.GetByHash(*media.Hash, transferTo); err == nil {
+				// The other user already uploaded the same content, keep their media
+				if svcErr := s.replaceMedia(&media, existing); svcErr != nil {
+					return svcErr
+				}
+				continue
+			}
+		}
+		if err := s.mediaRepository.Update(&media.Id, bson.M{"uploadedBy": transferTo}); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+		}
+	}
+
+	// Medias uploaded by the user, and via their shared links, now count against the other user's quota
+	charged, err := s.mediaRepository.GetAllChargedTo(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+	}
+	for _, media := range charged {
+		if err := s.mediaRepository.Update(&media.Id, bson.M{"chargedTo": transferTo}); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+		}
+		s.quotaService.Charge(userId, -media.ChargedBytes, -1)
+		s.quotaService.Charge(transferTo, media.ChargedBytes, 1)
+	}
+
+	if err := s.sharedLinkRepository.ReassignCreator(userId, transferTo); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer shared links")
+	}
+
+	groups, err := s.groupRepository.GetAllByOwner(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer groups")
+	}
+	for _, group := range groups {
+		if err := s.groupRepository.SetOwner(&group.Id, transferTo); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer groups")
+		}
+	}
+	return nil
+}
+
+// Put an existing media in place of a media in all albums, then delete the media
+func (s userManagementService) replaceMedia(media *model.Media, replacement *model.Media) utils.ServiceError {
+	mediaInAlbums, err := s.mediaInAlbumRepository.ListAllAlbums(&media.Id)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+	}
+	for _, mediaInAlbum := range mediaInAlbums {
+		if s.mediaInAlbumRepository.IsInAlbum(&replacement.Id, mediaInAlbum.AlbumId) {
+			continue
+		}
+		mediaInAlbum.LinkId = nil
+		mediaInAlbum.MediaId = &replacement.Id
+		if err := s.mediaInAlbumRepository.AddMediaToAlbum(&mediaInAlbum); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't transfer medias")
+		}
+	}
+	return s.mediaService.Delete(&media.Id)
+}
+
+// Delete the albums and medias of a user
+func (s userManagementService) purge(userId *primitive.ObjectID) utils.ServiceError {
+	albums, err := s.albumRepository.GetAllByAuthor(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete albums")
+	}
+	for _, album := range albums {
+		if svcErr := s.albumService.Delete(album.Id); svcErr != nil {
+			return svcErr
+		}
+	}
+
+	medias, err := s.mediaRepository.GetAllUploadedBy(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete medias")
+	}
+	// Medias uploaded via the user's shared links count against their quota, they go as well
+	charged, err := s.mediaRepository.GetAllChargedTo(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete medias")
+	}
+	deleted := map[primitive.ObjectID]bool{}
+	for _, media := range append(medias, charged...) {
+		if deleted[media.Id] {
+			continue
+		}
+		deleted[media.Id] = true
+		if svcErr := s.mediaService.Delete(&media.Id); svcErr != nil {
+			return svcErr
+		}
+	}
+
+	// Links left are on albums of other users
+	if err := s.sharedLinkRepository.DeleteAllCreatedBy(userId); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete shared links")
+	}
+
+	// Groups of the user are deleted, with the album accesses granted to them
+	groups, err := s.groupRepository.GetAllByOwner(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete groups")
+	}
+	for _, group := range groups {
+		if err := s.albumAccessRepository.RemoveAllForGroup(&group.Id); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete groups")
+		}
+		if err := s.groupRepository.Delete(&group.Id); err != nil {
+			return utils.NewServiceError(http.StatusInternalServerError, "couldn't delete groups")
+		}
+	}
+	slog.Info("Purged user data", "userId", userId.Hex(), "albums", len(albums), "medias", len(deleted))
+	return nil
+}
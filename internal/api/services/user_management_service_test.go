@@ -0,0 +1,186 @@
+package services_test
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/mocks"
+	"data-storage-svc/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type userManagementMocks struct {
+	userRepository         *mocks.UserRepository
+	hashModule             *mocks.HashModule
+	albumRepository        *mocks.AlbumRepository
+	albumAccessRepository  *mocks.AlbumAccessRepository
+	mediaRepository        *mocks.MediaRepository
+	mediaInAlbumRepository *mocks.MediaInAlbumRepository
+	mediaAccessRepository  *mocks.MediaAccessRepository
+	sharedLinkRepository   *mocks.SharedLinkRepository
+	apiKeyRepository       *mocks.ApiKeyRepository
+	groupRepository        *mocks.GroupRepository
+	transferRepository     *mocks.OwnershipTransferRepository
+	sessionService         *mocks.SessionService
+	albumService           *mocks.AlbumService
+	mediaService           *mocks.MediaService
+	quotaService           *mocks.QuotaService
+}
+
+func newUserManagementService() (services.UserManagementService, userManagementMocks) {
+	m := userManagementMocks{
+		&mocks.UserRepository{}, &mocks.HashModule{}, &mocks.AlbumRepository{}, &mocks.AlbumAccessRepository{}, &mocks.MediaRepository{},
+		&mocks.MediaInAlbumRepository{}, &mocks.MediaAccessRepository{}, &mocks.SharedLinkRepository{}, &mocks.ApiKeyRepository{},
+		&mocks.GroupRepository{}, &mocks.OwnershipTransferRepository{}, &mocks.SessionService{}, &mocks.AlbumService{}, &mocks.MediaService{}, &mocks.QuotaService{},
+	}
+	svc := services.NewUserManagementService(m.userRepository, m.hashModule, testPasswordPolicy(), m.albumRepository, m.albumAccessRepository, m.mediaRepository,
+		m.mediaInAlbumRepository, m.mediaAccessRepository, m.sharedLinkRepository, m.apiKeyRepository, m.groupRepository, m.transferRepository, m.sessionService,
+		m.albumService, m.mediaService, m.quotaService)
+	return svc, m
+}
+
+// Expectations shared by transfer and purge, once the user data has been handled
+func expectUserCleanup(m userManagementMocks, userId *primitive.ObjectID, sharedAlbumId *primitive.ObjectID) {
+	m.albumAccessRepository.On("GetAllByUser", userId).Return([]model.UserAlbumAccess{{UserId: userId, AlbumId: sharedAlbumId}}, nil)
+	m.albumAccessRepository.On("Remove", userId, sharedAlbumId).Return(nil)
+	m.mediaAccessRepository.On("GetAllForUser", userId).Return([]model.UserMediaAccess{}, nil)
+	m.groupRepository.On("RemoveMemberFromAll", userId).Return(nil)
+	m.transferRepository.On("CancelAllPendingForUser", userId).Return(nil)
+	m.apiKeyRepository.On("DeleteAllForUser", userId).Return(nil)
+	m.sessionService.On("RevokeAll", userId).Return(nil)
+	m.userRepository.On("Delete", userId).Return(nil)
+}
+
+func TestDeleteUserTransfer(t *testing.T) {
+	svc, m := newUserManagementService()
+
+	userId := primitive.NewObjectID()
+	targetId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+	sharedAlbumId := primitive.NewObjectID()
+	hash, duplicateHash := "hash", "duplicateHash"
+	media := model.Media{Id: primitive.NewObjectID(), Hash: &hash, UploadedBy: &userId, ChargedTo: &userId, ChargedBytes: 100}
+	duplicate := model.Media{Id: primitive.NewObjectID(), Hash: &duplicateHash, UploadedBy: &userId, ChargedTo: &userId, ChargedBytes: 50}
+	existing := model.Media{Id: primitive.NewObjectID(), Hash: &duplicateHash, UploadedBy: &targetId}
+
+	m.userRepository.On("GetById", &userId).Return(&model.User{Id: userId}, nil)
+	m.userRepository.On("GetById", &targetId).Return(&model.User{Id: targetId}, nil)
+
+	// Albums change author, the new author can edit them
+	m.albumRepository.On("GetAllByAuthor", &userId).Return([]model.Album{{Id: &albumId, AuthorId: &userId}}, nil)
+	m.albumRepository.On("SetAuthor", &albumId, &userId, &targetId).Return(nil)
+	m.albumAccessRepository.On("Create", &targetId, &albumId, model.ALBUM_ROLE_OWNER).Return(nil)
+
+	// The target already has the duplicate content, albums point to their media and the duplicate is deleted
+	m.mediaRepository.On("GetAllUploadedBy", &userId).Return([]model.Media{media, duplicate}, nil)
+	m.mediaRepository.On("GetByHash", hash, &targetId).Return((*model.Media)(nil), mongo.ErrNoDocuments)
+	m.mediaRepository.On("GetByHash", duplicateHash, &targetId).Return(&existing, nil)
+	m.mediaInAlbumRepository.On("ListAllAlbums", &duplicate.Id).Return([]model.MediaInAlbum{{MediaId: &duplicate.Id, AlbumId: &albumId}}, nil)
+	m.mediaInAlbumRepository.On("IsInAlbum", &existing.Id, &albumId).Return(false)
+	m.mediaInAlbumRepository.On("AddMediaToAlbum", mock.MatchedBy(func(mediaInAlbum *model.MediaInAlbum) bool {
+		return *mediaInAlbum.MediaId == existing.Id && *mediaInAlbum.AlbumId == albumId
+	})).Return(nil)
+	m.mediaService.On("Delete", &duplicate.Id).Return(nil)
+	m.mediaRepository.On("Update", &media.Id, bson.M{"uploadedBy": &targetId}).Return(nil)
+
+	// Remaining medias count against the target quota
+	m.mediaRepository.On("GetAllChargedTo", &userId).Return([]model.Media{media}, nil)
+	m.mediaRepository.On("Update", &media.Id, bson.M{"chargedTo": &targetId}).Return(nil)
+	m.quotaService.On("Charge", &userId, int64(-100), int64(-1)).Return(nil)
+	m.quotaService.On("Charge", &targetId, int64(100), int64(1)).Return(nil)
+	m.sharedLinkRepository.On("ReassignCreator", &userId, &targetId).Return(nil)
+
+	// Groups change owner
+	groupId := primitive.NewObjectID()
+	m.groupRepository.On("GetAllByOwner", &userId).Return([]model.Group{{Id: groupId, OwnerId: userId}}, nil)
+	m.groupRepository.On("SetOwner", &groupId, &targetId).Return(nil)
+
+	expectUserCleanup(m, &userId, &sharedAlbumId)
+
+	assert.Nil(t, svc.Delete(&userId, &targetId))
+	m.albumRepository.AssertExpectations(t)
+	m.mediaRepository.AssertExpectations(t)
+	m.mediaInAlbumRepository.AssertExpectations(t)
+	m.mediaService.AssertExpectations(t)
+	m.quotaService.AssertExpectations(t)
+	m.sharedLinkRepository.AssertExpectations(t)
+	m.albumAccessRepository.AssertExpectations(t)
+	m.groupRepository.AssertExpectations(t)
+	m.userRepository.AssertCalled(t, "Delete", &userId)
+	m.albumService.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestDeleteUserPurge(t *testing.T) {
+	svc, m := newUserManagementService()
+
+	userId := primitive.NewObjectID()
+	albumId := primitive.NewObjectID()
+	sharedAlbumId := primitive.NewObjectID()
+	uploaded := model.Media{Id: primitive.NewObjectID(), UploadedBy: &userId, ChargedTo: &userId}
+	// Uploaded via a shared link of the user
+	viaLink := model.Media{Id: primitive.NewObjectID(), ChargedTo: &userId}
+
+	m.userRepository.On("GetById", &userId).Return(&model.User{Id: userId}, nil)
+	m.albumRepository.On("GetAllByAuthor", &userId).Return([]model.Album{{Id: &albumId, AuthorId: &userId}}, nil)
+	m.albumService.On("Delete", &albumId).Return(nil)
+	m.mediaRepository.On("GetAllUploadedBy", &userId).Return([]model.Media{uploaded}, nil)
+	m.mediaRepository.On("GetAllChargedTo", &userId).Return([]model.Media{uploaded, viaLink}, nil)
+	m.mediaService.On("Delete", &uploaded.Id).Return(nil)
+	m.mediaService.On("Delete", &viaLink.Id).Return(nil)
+	m.sharedLinkRepository.On("DeleteAllCreatedBy", &userId).Return(nil)
+	groupId := primitive.NewObjectID()
+	m.groupRepository.On("GetAllByOwner", &userId).Return([]model.Group{{Id: groupId, OwnerId: userId}}, nil)
+	m.albumAccessRepository.On("RemoveAllForGroup", &groupId).Return(nil)
+	m.groupRepository.On("Delete", &groupId).Return(nil)
+
+	expectUserCleanup(m, &userId, &sharedAlbumId)
+
+	assert.Nil(t, svc.Delete(&userId, nil))
+	m.albumService.AssertExpectations(t)
+	// Each media is deleted once
+	m.mediaService.AssertNumberOfCalls(t, "Delete", 2)
+	m.sharedLinkRepository.AssertExpectations(t)
+	// Groups are deleted with their accesses
+	m.groupRepository.AssertExpectations(t)
+	m.albumAccessRepository.AssertCalled(t, "RemoveAllForGroup", &groupId)
+	m.albumRepository.AssertNotCalled(t, "Update", mock.Anything)
+	m.userRepository.AssertCalled(t, "Delete", &userId)
+}
+
+func TestDeleteUserTransferToThemselves(t *testing.T) {
+	svc, m := newUserManagementService()
+
+	userId := primitive.NewObjectID()
+	m.userRepository.On("GetById", &userId).Return(&model.User{Id: userId}, nil)
+
+	err := svc.Delete(&userId, &userId)
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.GetCode())
+	m.userRepository.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestResetPassword(t *testing.T) {
+	svc, m := newUserManagementService()
+
+	userId := primitive.NewObjectID()
+	m.userRepository.On("GetById", &userId).Return(&model.User{Id: userId}, nil)
+	m.userRepository.On("Update", &userId, mock.Anything).Return(nil)
+	m.hashModule.On("HashPassword", mock.Anything).Return("newhash", nil)
+	m.sessionService.On("RevokeAll", &userId).Return(nil)
+
+	// Given password
+	password, err := svc.ResetPassword(&userId, "anewpassword")
+	assert.Nil(t, err)
+	assert.Equal(t, "anewpassword", *password)
+
+	// Generated password
+	password, err = svc.ResetPassword(&userId, "")
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, len(*password), 16)
+	m.hashModule.AssertCalled(t, "HashPassword", *password)
+	m.sessionService.AssertNumberOfCalls(t, "RevokeAll", 2)
+}
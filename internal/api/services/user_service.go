@@ -3,9 +3,12 @@ package services
 import (
 	"data-storage-svc/internal/api/security"
 	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/ratelimit"
 	"data-storage-svc/internal/repository"
 	"data-storage-svc/internal/utils"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"time"
 
@@ -15,37 +18,46 @@ import (
 )
 
 type UserService interface {
-	// Create a new user (registration)
-	Create(email string, password string) (*primitive.ObjectID, utils.ServiceError)
+	// Create a new user (registration), as an admin if isAdmin is set
+	Create(email string, password string, isAdmin bool) (*primitive.ObjectID, utils.ServiceError)
 	// Get by email
 	GetByEmail(email string) (*model.User, utils.ServiceError)
 	// Get by id
 	GetById(userId primitive.ObjectID) (*model.User, utils.ServiceError)
 	// Get all
 	GetAll() ([]model.User, utils.ServiceError)
-	// Generates a JWT token for the given user
-	GenerateToken(email string, password string) (*string, utils.ServiceError)
+	// Authenticate a user and open a new session from the given device. Users with a second factor get a MFA token
+	// to complete the login with their code instead.
+	Login(email string, password string, userAgent string, ip string) (*model.SessionTokens, *string, utils.ServiceError)
+	// Change the password of a user, all their sessions are revoked
+	ChangePassword(userId *primitive.ObjectID, currentPassword string, newPassword string) utils.ServiceError
+	// Change the email of a user, the current password is required
+	ChangeEmail(userId *primitive.ObjectID, currentPassword string, email string) (*model.User, utils.ServiceError)
 }
 
 type userService struct {
 	// Repository dependencies
 	userRepository repository.UserRepository
 	hashModule     security.HashModule
+	passwordPolicy security.PasswordPolicy
 	tokenModule    security.TokenModule
+	// Service dependencies
+	sessionService SessionService
+	loginGuard     ratelimit.LoginGuard
 }
 
-func NewUserService(userRepository repository.UserRepository, hashModule security.HashModule, tokenModule security.TokenModule) userService {
-	return userService{userRepository, hashModule, tokenModule}
+func NewUserService(userRepository repository.UserRepository, hashModule security.HashModule, passwordPolicy security.PasswordPolicy, tokenModule security.TokenModule, sessionService SessionService, loginGuard ratelimit.LoginGuard) userService {
+	return userService{userRepository, hashModule, passwordPolicy, tokenModule, sessionService, loginGuard}
 }
 
-func (s userService) Create(email string, password string) (*primitive.ObjectID, utils.ServiceError) {
+func (s userService) Create(email string, password string, isAdmin bool) (*primitive.ObjectID, utils.ServiceError) {
 	// Check email
 	if !utils.IsValidEmail(email) {
 		return nil, utils.NewServiceError(http.StatusBadRequest, "invalid email address")
 	}
 	// Check password
-	if len(password) < 6 {
-		return nil, utils.NewServiceError(http.StatusBadRequest, "password should be at least 6 characters long")
+	if svcErr := checkPassword(s.passwordPolicy, password, email); svcErr != nil {
+		return nil, svcErr
 	}
 	// Check if user already exists
 	_, err := s.userRepository.GetByEmail(email)
@@ -58,7 +70,7 @@ func (s userService) Create(email string, password string) (*primitive.ObjectID,
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create new user")
 	}
 	// Finally store the user in DB
-	createdId, err := s.userRepository.Create(&model.User{Email: email, PasswordHash: hash, IsAdmin: false, JoinDate: time.Now()})
+	createdId, err := s.userRepository.Create(&model.User{Email: email, PasswordHash: hash, IsAdmin: isAdmin, JoinDate: time.Now()})
 	if err != nil {
 		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't create new user")
 	}
@@ -73,28 +85,143 @@ func (s userService) GetByEmail(email string) (*model.User, utils.ServiceError)
 	return user, nil
 }
 
-func (s userService) GenerateToken(email string, password string) (*string, utils.ServiceError) {
+func tooManyAttempts(retryAfter time.Duration) utils.ServiceError {
+	return utils.NewServiceError(http.StatusTooManyRequests, fmt.Sprintf("too many attempts, try again in %d seconds", int(math.Ceil(retryAfter.Seconds()))))
+}
+
+func checkPassword(passwordPolicy security.PasswordPolicy, password string, email string) utils.ServiceError {
+	if err := passwordPolicy.Check(password, email); err != nil {
+		return utils.NewServiceError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+func (s userService) Login(email string, password string, userAgent string, ip string) (*model.SessionTokens, *string, utils.ServiceError) {
 	if len(email) == 0 || len(password) == 0 {
-		return nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+	}
+	// Checked before hashing anything, hashing is what makes guessing expensive for the server
+	if allowed, retryAfter := s.loginGuard.Allow(email); !allowed {
+		return nil, nil, tooManyAttempts(retryAfter)
 	}
 
 	user, err := s.userRepository.GetByEmail(email)
 	if err != nil {
-		return nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+		// Unknown accounts are locked as well, so locking does not tell which accounts exist
+		s.loginGuard.Failure(email)
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
 	}
 	if !s.hashModule.VerifyPassword(password, user.PasswordHash) {
-		return nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
+		s.loginGuard.Failure(email)
+		return nil, nil, utils.NewServiceError(http.StatusUnauthorized, "unable to authenticate user")
 	}
-	// User is authenticated, generate a new token
-	jwt, err := s.tokenModule.CreateToken(&user.Email)
-	if err != nil {
-		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't generate token")
+	if user.Disabled {
+		return nil, nil, utils.NewServiceError(http.StatusForbidden, "account is disabled")
+	}
+	s.rehashPassword(user, password)
+	if user.HasTwoFactor() {
+		// Password is valid, the second factor is still needed
+		mfaToken, err := s.tokenModule.CreateMfaToken(&user.Id)
+		if err != nil {
+			return nil, nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't generate token")
+		}
+		// Failures are only forgotten once the second factor is checked too
+		return nil, &mfaToken, nil
+	}
+	s.loginGuard.Success(email)
+	// User is authenticated, open a new session
+	tokens, svcErr := s.sessionService.Create(user, userAgent, ip)
+	if svcErr != nil {
+		return nil, nil, svcErr
 	}
 	// Update the last login date
 	if s.userRepository.Update(&user.Id, bson.M{"$set": bson.M{"lastLogin": time.Now()}}) != nil {
 		slog.Error("couldn't update join date for user", "userId", user.Id.Hex())
 	}
-	return &jwt, nil
+	return tokens, nil, nil
+}
+
+// The password is only known at login, hash it again if the hashing algorithm or cost changed since it was set
+func (s userService) rehashPassword(user *model.User, password string) {
+	if !s.hashModule.NeedsRehash(user.PasswordHash) {
+		return
+	}
+	hash, err := s.hashModule.HashPassword(password)
+	if err != nil {
+		slog.Error("couldn't rehash password", "userId", user.Id.Hex(), "error", err)
+		return
+	}
+	if err := s.userRepository.Update(&user.Id, bson.M{"$set": bson.M{"passwordHash": hash}}); err != nil {
+		slog.Error("couldn't rehash password", "userId", user.Id.Hex(), "error", err)
+		return
+	}
+	user.PasswordHash = hash
+}
+
+// Check the password of a logged in user before a sensitive change. Attempts count along with the logins of the
+// account, so a stolen session cannot be used to guess the password faster than the login page allows.
+func (s userService) checkCurrentPassword(user *model.User, password string) utils.ServiceError {
+	if allowed, retryAfter := s.loginGuard.Allow(user.Email); !allowed {
+		return tooManyAttempts(retryAfter)
+	}
+	if !s.hashModule.VerifyPassword(password, user.PasswordHash) {
+		s.loginGuard.Failure(user.Email)
+		return utils.NewServiceError(http.StatusUnauthorized, "invalid current password")
+	}
+	// Like at login, the password alone does not forget the failures of an account having a second factor
+	if !user.HasTwoFactor() {
+		s.loginGuard.Success(user.Email)
+	}
+	return nil
+}
+
+func (s userService) ChangePassword(userId *primitive.ObjectID, currentPassword string, newPassword string) utils.ServiceError {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if svcErr := s.checkCurrentPassword(user, currentPassword); svcErr != nil {
+		return svcErr
+	}
+	if svcErr := checkPassword(s.passwordPolicy, newPassword, user.Email); svcErr != nil {
+		return svcErr
+	}
+	hash, err := s.hashModule.HashPassword(newPassword)
+	if err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't change password")
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"passwordHash": hash}}); err != nil {
+		return utils.NewServiceError(http.StatusInternalServerError, "couldn't change password")
+	}
+	// Whoever knew the old password must not stay logged in
+	return s.sessionService.RevokeAll(userId)
+}
+
+func (s userService) ChangeEmail(userId *primitive.ObjectID, currentPassword string, email string) (*model.User, utils.ServiceError) {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return nil, utils.NewServiceError(http.StatusNotFound, "couldn't find user")
+	}
+	if user.Email == email {
+		return user, nil
+	}
+	if !utils.IsValidEmail(email) {
+		return nil, utils.NewServiceError(http.StatusBadRequest, "invalid email address")
+	}
+	if svcErr := s.checkCurrentPassword(user, currentPassword); svcErr != nil {
+		return nil, svcErr
+	}
+	if _, err := s.userRepository.GetByEmail(email); err != mongo.ErrNoDocuments {
+		return nil, utils.NewServiceError(http.StatusConflict, "email address already in use")
+	}
+	if err := s.userRepository.Update(userId, bson.M{"$set": bson.M{"email": email}}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, utils.NewServiceError(http.StatusConflict, "email address already in use")
+		}
+		return nil, utils.NewServiceError(http.StatusInternalServerError, "couldn't change email address")
+	}
+	user.Email = email
+	return user, nil
 }
 
 func (s userService) GetById(userId primitive.ObjectID) (*model.User, utils.ServiceError) {
@@ -1,18 +1,35 @@
 package services_test
 
 import (
+	"data-storage-svc/internal/api/security"
 	"data-storage-svc/internal/api/services"
 	"data-storage-svc/internal/mocks"
 	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/utils"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+func testPasswordPolicy() security.PasswordPolicy {
+	passwordPolicy, _ := security.NewPasswordPolicy(security.PasswordPolicyConfig{MinLength: 6, ForbidEmail: true})
+	return passwordPolicy
+}
+
+// Login guard letting every attempt through
+func allowingLoginGuard() *mocks.LoginGuard {
+	loginGuard := &mocks.LoginGuard{}
+	loginGuard.On("Allow", mock.Anything).Return(true, time.Duration(0))
+	loginGuard.On("Failure", mock.Anything).Return()
+	loginGuard.On("Success", mock.Anything).Return()
+	return loginGuard
+}
+
 func TestCreateUser(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -49,7 +66,8 @@ func TestCreateUser(t *testing.T) {
 	mockRepository := &mocks.UserRepository{}
 	hashModule := &mocks.HashModule{}
 	tokenModule := &mocks.TokenModule{}
-	svc := services.NewUserService(mockRepository, hashModule, tokenModule)
+	sessionService := &mocks.SessionService{}
+	svc := services.NewUserService(mockRepository, hashModule, testPasswordPolicy(), tokenModule, sessionService, nil)
 
 	newObjId := primitive.NewObjectID()
 	mockRepository.On("Create", mock.Anything).Return(&newObjId, nil)
@@ -60,7 +78,7 @@ func TestCreateUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			createdId, err := svc.Create(tc.email, tc.password)
+			createdId, err := svc.Create(tc.email, tc.password, false)
 			if tc.expectError {
 				assert.Nil(t, createdId)
 				assert.NotNil(t, err)
@@ -101,7 +119,7 @@ func TestGetByEmail(t *testing.T) {
 	mockRepository.On("GetByEmail", "unexisting@test.fr").Return((*model.User)(nil), mongo.ErrNoDocuments)
 	mockRepository.On("GetByEmail", "test@test.fr").Return(&model.User{Email: "test@test.fr"}, nil)
 
-	svc := services.NewUserService(mockRepository, nil, nil)
+	svc := services.NewUserService(mockRepository, nil, nil, nil, nil, nil)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -117,12 +135,13 @@ func TestGetByEmail(t *testing.T) {
 	}
 }
 
-func TestGenerateToken(t *testing.T) {
+func TestLogin(t *testing.T) {
 	testCases := []struct {
 		name            string
 		email           string
 		password        string
 		expectErrorCode *int
+		expectMfa       bool
 	}{
 		{
 			name:            "Unexisting user",
@@ -142,6 +161,25 @@ func TestGenerateToken(t *testing.T) {
 			password:        "azertyuiop",
 			expectErrorCode: nil,
 		},
+		{
+			name:            "Valid password, second factor needed",
+			email:           "2fa@test.fr",
+			password:        "azertyuiop",
+			expectErrorCode: nil,
+			expectMfa:       true,
+		},
+		{
+			name:            "Disabled user",
+			email:           "disabled@test.fr",
+			password:        "azertyuiop",
+			expectErrorCode: utils.IntPtr(403),
+		},
+		{
+			name:            "Locked account",
+			email:           "locked@test.fr",
+			password:        "azertyuiop",
+			expectErrorCode: utils.IntPtr(429),
+		},
 	}
 	hash := "$2a$14$RUahhb6.L8oVMq91f3.HQ.37SKrtcmAkFwp8lW.eb7WFJy9G6ZayK"
 
@@ -149,27 +187,226 @@ func TestGenerateToken(t *testing.T) {
 	mockRepository.On("GetByEmail", "unexisting@test.fr").Return((*model.User)(nil), mongo.ErrNoDocuments)
 	userId := primitive.NewObjectID()
 	mockRepository.On("GetByEmail", "test@test.fr").Return(&model.User{Id: userId, Email: "test@test.fr", PasswordHash: hash}, nil)
+	mfaUserId := primitive.NewObjectID()
+	mockRepository.On("GetByEmail", "2fa@test.fr").Return(&model.User{Id: mfaUserId, Email: "2fa@test.fr", PasswordHash: hash, Totp: &model.TotpSettings{Secret: "secret", Enabled: true}}, nil)
+	mockRepository.On("GetByEmail", "disabled@test.fr").Return(&model.User{Id: primitive.NewObjectID(), Email: "disabled@test.fr", PasswordHash: hash, Disabled: true}, nil)
 	mockRepository.On("Update", &userId, mock.Anything).Return(nil)
 
 	hashModule := &mocks.HashModule{}
 	hashModule.On("VerifyPassword", "invalidpassword", hash).Return(false)
 	hashModule.On("VerifyPassword", "azertyuiop", hash).Return(true)
+	hashModule.On("NeedsRehash", hash).Return(false)
+
+	sessionService := &mocks.SessionService{}
+	sessionService.On("Create", mock.MatchedBy(func(user *model.User) bool { return user.Id == userId }), "curl/8.0", "127.0.0.1").
+		Return(&model.SessionTokens{AccessToken: "asecretgeneratedtoken", RefreshToken: "arefreshtoken"}, nil)
 
 	tokenModule := &mocks.TokenModule{}
-	tokenModule.On("CreateToken", utils.StrPtr("test@test.fr")).Return("asecretgeneratedtoken", nil)
+	tokenModule.On("CreateMfaToken", &mfaUserId).Return("amfatoken", nil)
+
+	loginGuard := &mocks.LoginGuard{}
+	loginGuard.On("Allow", "locked@test.fr").Return(false, 30*time.Second)
+	loginGuard.On("Allow", mock.Anything).Return(true, time.Duration(0))
+	loginGuard.On("Failure", mock.Anything).Return()
+	loginGuard.On("Success", mock.Anything).Return()
+
+	svc := services.NewUserService(mockRepository, hashModule, nil, tokenModule, sessionService, loginGuard)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, mfaToken, err := svc.Login(tc.email, tc.password, "curl/8.0", "127.0.0.1")
+			if tc.expectErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectErrorCode, err.GetCode())
+			} else if tc.expectMfa {
+				// No session until the second factor is checked
+				assert.Nil(t, err)
+				assert.Nil(t, tokens)
+				assert.Equal(t, "amfatoken", *mfaToken)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "asecretgeneratedtoken", tokens.AccessToken)
+				assert.Equal(t, "arefreshtoken", tokens.RefreshToken)
+			}
+		})
+	}
+	// Locked accounts are rejected before the password is checked
+	mockRepository.AssertNotCalled(t, "GetByEmail", "locked@test.fr")
+	loginGuard.AssertCalled(t, "Failure", "test@test.fr")
+	loginGuard.AssertCalled(t, "Failure", "unexisting@test.fr")
+	// The account is unlocked only once the second factor is checked
+	loginGuard.AssertCalled(t, "Success", "test@test.fr")
+	loginGuard.AssertNotCalled(t, "Success", "2fa@test.fr")
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	userId := primitive.NewObjectID()
+	mockRepository := &mocks.UserRepository{}
+	mockRepository.On("GetByEmail", "test@test.fr").Return(&model.User{Id: userId, Email: "test@test.fr", PasswordHash: "oldhash"}, nil)
+	mockRepository.On("Update", &userId, mock.Anything).Return(nil)
+
+	hashModule := &mocks.HashModule{}
+	hashModule.On("VerifyPassword", "azertyuiop", "oldhash").Return(true)
+	hashModule.On("NeedsRehash", "oldhash").Return(true)
+	hashModule.On("HashPassword", "azertyuiop").Return("newhash", nil)
+
+	sessionService := &mocks.SessionService{}
+	sessionService.On("Create", mock.MatchedBy(func(user *model.User) bool { return user.PasswordHash == "newhash" }), "curl/8.0", "127.0.0.1").
+		Return(&model.SessionTokens{AccessToken: "asecretgeneratedtoken"}, nil)
+
+	svc := services.NewUserService(mockRepository, hashModule, nil, nil, sessionService, allowingLoginGuard())
+	_, _, err := svc.Login("test@test.fr", "azertyuiop", "curl/8.0", "127.0.0.1")
+	assert.Nil(t, err)
+	mockRepository.AssertCalled(t, "Update", &userId, bson.M{"$set": bson.M{"passwordHash": "newhash"}})
+}
+
+func TestChangePassword(t *testing.T) {
+	testCases := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		expectErrorCode *int
+	}{
+		{
+			name:            "Invalid current password",
+			currentPassword: "invalidpassword",
+			newPassword:     "anewpassword",
+			expectErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:            "Too short new password",
+			currentPassword: "azertyuiop",
+			newPassword:     "a",
+			expectErrorCode: utils.IntPtr(400),
+		},
+		{
+			name:            "Valid change",
+			currentPassword: "azertyuiop",
+			newPassword:     "anewpassword",
+			expectErrorCode: nil,
+		},
+	}
+	hash := "$2a$14$RUahhb6.L8oVMq91f3.HQ.37SKrtcmAkFwp8lW.eb7WFJy9G6ZayK"
+
+	userId := primitive.NewObjectID()
+	mockRepository := &mocks.UserRepository{}
+	mockRepository.On("GetById", &userId).Return(&model.User{Id: userId, Email: "test@test.fr", PasswordHash: hash}, nil)
+	mockRepository.On("Update", &userId, mock.Anything).Return(nil)
+
+	hashModule := &mocks.HashModule{}
+	hashModule.On("VerifyPassword", "invalidpassword", hash).Return(false)
+	hashModule.On("VerifyPassword", "azertyuiop", hash).Return(true)
+	hashModule.On("HashPassword", "anewpassword").Return("newhash", nil)
+
+	sessionService := &mocks.SessionService{}
+	sessionService.On("RevokeAll", &userId).Return(nil)
+
+	svc := services.NewUserService(mockRepository, hashModule, testPasswordPolicy(), nil, sessionService, allowingLoginGuard())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.ChangePassword(&userId, tc.currentPassword, tc.newPassword)
+			if tc.expectErrorCode != nil {
+				assert.NotNil(t, err)
+				assert.Equal(t, *tc.expectErrorCode, err.GetCode())
+				sessionService.AssertNotCalled(t, "RevokeAll", &userId)
+			} else {
+				assert.Nil(t, err)
+				// Every session is revoked
+				sessionService.AssertCalled(t, "RevokeAll", &userId)
+			}
+		})
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	testCases := []struct {
+		name            string
+		currentPassword string
+		email           string
+		expectErrorCode *int
+	}{
+		{
+			name:            "Invalid email",
+			currentPassword: "azertyuiop",
+			email:           "invalidEmail",
+			expectErrorCode: utils.IntPtr(400),
+		},
+		{
+			name:            "Invalid current password",
+			currentPassword: "invalidpassword",
+			email:           "new@test.fr",
+			expectErrorCode: utils.IntPtr(401),
+		},
+		{
+			name:            "Email already in use",
+			currentPassword: "azertyuiop",
+			email:           "taken@test.fr",
+			expectErrorCode: utils.IntPtr(409),
+		},
+		{
+			name:            "Same email",
+			currentPassword: "",
+			email:           "test@test.fr",
+			expectErrorCode: nil,
+		},
+		{
+			name:            "Valid change",
+			currentPassword: "azertyuiop",
+			email:           "new@test.fr",
+			expectErrorCode: nil,
+		},
+	}
+	hash := "$2a$14$RUahhb6.L8oVMq91f3.HQ.37SKrtcmAkFwp8lW.eb7WFJy9G6ZayK"
 
-	svc := services.NewUserService(mockRepository, hashModule, tokenModule)
+	userId := primitive.NewObjectID()
+	mockRepository := &mocks.UserRepository{}
+	mockRepository.On("GetById", &userId).Return(func(*primitive.ObjectID) (*model.User, error) {
+		return &model.User{Id: userId, Email: "test@test.fr", PasswordHash: hash}, nil
+	})
+	mockRepository.On("GetByEmail", "taken@test.fr").Return(&model.User{Email: "taken@test.fr"}, nil)
+	mockRepository.On("GetByEmail", "new@test.fr").Return((*model.User)(nil), mongo.ErrNoDocuments)
+	mockRepository.On("Update", &userId, mock.Anything).Return(nil)
+
+	hashModule := &mocks.HashModule{}
+	hashModule.On("VerifyPassword", "invalidpassword", hash).Return(false)
+	hashModule.On("VerifyPassword", "azertyuiop", hash).Return(true)
+
+	svc := services.NewUserService(mockRepository, hashModule, nil, nil, nil, allowingLoginGuard())
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			token, err := svc.GenerateToken(tc.email, tc.password)
+			user, err := svc.ChangeEmail(&userId, tc.currentPassword, tc.email)
 			if tc.expectErrorCode != nil {
 				assert.NotNil(t, err)
 				assert.Equal(t, *tc.expectErrorCode, err.GetCode())
 			} else {
 				assert.Nil(t, err)
-				assert.Equal(t, "asecretgeneratedtoken", *token)
+				assert.Equal(t, tc.email, user.Email)
 			}
 		})
 	}
+	mockRepository.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestChangesNeedingPasswordAreGuarded(t *testing.T) {
+	hash := "hash"
+	userId := primitive.NewObjectID()
+	mockRepository := &mocks.UserRepository{}
+	mockRepository.On("GetById", &userId).Return(&model.User{Id: userId, Email: "test@test.fr", PasswordHash: hash}, nil)
+	hashModule := &mocks.HashModule{}
+	hashModule.On("VerifyPassword", "guess", hash).Return(false)
+	loginGuard := &mocks.LoginGuard{}
+	loginGuard.On("Allow", "test@test.fr").Return(true, time.Duration(0)).Once()
+	loginGuard.On("Allow", "test@test.fr").Return(false, 30*time.Second)
+	loginGuard.On("Failure", "test@test.fr").Return()
+	svc := services.NewUserService(mockRepository, hashModule, testPasswordPolicy(), nil, nil, loginGuard)
+
+	// Wrong guesses count as failed logins, the account is then locked for these changes too
+	assert.Equal(t, 401, svc.ChangePassword(&userId, "guess", "anewpassword").GetCode())
+	loginGuard.AssertCalled(t, "Failure", "test@test.fr")
+	assert.Equal(t, 429, svc.ChangePassword(&userId, "guess", "anewpassword").GetCode())
+	_, err := svc.ChangeEmail(&userId, "guess", "new@test.fr")
+	assert.Equal(t, 429, err.GetCode())
+	hashModule.AssertNumberOfCalls(t, "VerifyPassword", 1)
 }
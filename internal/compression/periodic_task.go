@@ -1,10 +1,13 @@
 package compression
 
 import (
+	"data-storage-svc/internal"
 	"data-storage-svc/internal/api/common"
 	"data-storage-svc/internal/repository"
-	"data-storage-svc/internal/utils"
+	"data-storage-svc/internal/storage"
 	"log/slog"
+	"os"
+	"path"
 	"path/filepath"
 	"time"
 
@@ -17,17 +20,7 @@ var compressionQueue = make(chan primitive.ObjectID, 15)
 
 // Periodic task to compress files after they have been uploaded. It will run every delaySeconds, check if there are files
 // that have not been compressed yet, and if yes, compress them.
-func CompressionTask(delaySeconds int64, mediaRepository repository.MediaRepository) {
-	originalDir, errOri := utils.GetDataDir(common.ORIGINAL_MEDIA_DIRECTORY)
-	if errOri != nil {
-		slog.Error("couldn't open original media directory", "error", errOri)
-		return
-	}
-	compressedDir, errComp := utils.GetDataDir(common.COMPRESSED_DIRECTORY)
-	if errComp != nil {
-		slog.Error("couldn't open compressed media directory", "error", errComp)
-		return
-	}
+func CompressionTask(delaySeconds int64, mediaRepository repository.MediaRepository, userRepository repository.UserRepository, backend storage.Backend) {
 	ticker := time.NewTicker(time.Duration(delaySeconds) * time.Second)
 	for range ticker.C {
 		slog.Debug("Executing compression task")
@@ -58,32 +51,99 @@ func CompressionTask(delaySeconds int64, mediaRepository repository.MediaReposit
 				slog.Error("Couldn't fetch media to compress, skipping", "error", err)
 				continue
 			}
-			if name, err := CompressMedia(filepath.Join(originalDir, *media.StorageFileName), compressedDir); err != nil {
+			// The original must be restored before a rendition can be built
+			if media.IsArchived() {
+				slog.Debug("Original is archived, skipping", "mediaId", mediaId.Hex())
+				continue
+			}
+			if name, err := compressStoredMedia(*media.StorageFileName, backend); err != nil {
 				slog.Error("Couldn't compress file", "filename", *media.OriginalFileName, "error", err)
 				nbrFailed += 1
 			} else {
 				nbrCompressedFile += 1
-				setCompressedFileName(&mediaId, name, mediaRepository)
+				setCompressedFileName(*media.StorageFileName, name, mediaRepository)
+				if internal.QUOTA_INCLUDE_RENDITIONS {
+					chargeRendition(*media.StorageFileName, *name, mediaRepository, userRepository, backend)
+				}
 			}
 		}
 		slog.Debug("Compression results", "successes", nbrCompressedFile, "failures", nbrFailed)
 	}
 }
 
-func setCompressedFileName(mediaId *primitive.ObjectID, compressedFileName *string, mediaRepository repository.MediaRepository) {
+// Compress an original media from the storage backend, and store the compressed version next to it
+func compressStoredMedia(storageFileName string, backend storage.Backend) (*string, error) {
+	// ffmpeg works on local files only
+	originalPath, cleanup, err := storage.LocalFile(backend, path.Join(common.ORIGINAL_MEDIA_DIRECTORY, storageFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	compressedDir, err := os.MkdirTemp("", "compressed-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(compressedDir)
+
+	name, err := CompressMedia(originalPath, compressedDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.MoveFile(backend, path.Join(common.COMPRESSED_DIRECTORY, *name), filepath.Join(compressedDir, *name)); err != nil {
+		return nil, err
+	}
+	return name, nil
+}
+
+// Set the rendition of all the medias sharing the given original
+func setCompressedFileName(storageFileName string, compressedFileName *string, mediaRepository repository.MediaRepository) {
 	update := bson.M{}
 	if compressedFileName != nil {
 		update["compressedFileName"] = *compressedFileName
 	} else {
 		update["compressedFileName"] = nil
 	}
-	if err := mediaRepository.Update(mediaId, update); err != nil {
+	if err := mediaRepository.UpdateAllByStorageFileName(storageFileName, update); err != nil {
 		slog.Error("error setting compressed file name", "error", err)
 	}
 }
 
+// Count the rendition size against the quota of the users owning the medias sharing the given original
+func chargeRendition(storageFileName string, compressedFileName string, mediaRepository repository.MediaRepository, userRepository repository.UserRepository, backend storage.Backend) {
+	info, err := backend.Stat(path.Join(common.COMPRESSED_DIRECTORY, compressedFileName))
+	if err != nil {
+		slog.Error("couldn't get rendition size", "file", compressedFileName, "error", err)
+		return
+	}
+	medias, err := mediaRepository.GetAllByStorageFileName(storageFileName)
+	if err != nil {
+		slog.Error("couldn't get medias to charge rendition to", "error", err)
+		return
+	}
+	for _, media := range medias {
+		// Renditions already counted (e.g. regenerated one) are not counted twice
+		if media.ChargedTo == nil || media.ChargedBytes != media.Size {
+			continue
+		}
+		if err := mediaRepository.Update(&media.Id, bson.M{"chargedBytes": media.Size + info.Size}); err != nil {
+			slog.Error("couldn't update media charged bytes", "mediaId", media.Id.Hex(), "error", err)
+			continue
+		}
+		if err := userRepository.Update(media.ChargedTo, bson.M{"$inc": bson.M{"usage.bytes": info.Size}}); err != nil {
+			slog.Error("couldn't update user storage usage", "userId", media.ChargedTo.Hex(), "error", err)
+		}
+	}
+}
+
+// Queue a media for compression. Never blocks: when the queue is full the media is dropped, it will be queued again
+// the next time its compressed version is requested.
 func AddToCompressQueue(mediaId *primitive.ObjectID) {
-	if mediaId != nil {
-		compressionQueue <- *mediaId
+	if mediaId == nil {
+		return
+	}
+	select {
+	case compressionQueue <- *mediaId:
+	default:
+		slog.Debug("Compression queue is full, media not queued", "mediaId", mediaId.Hex())
 	}
 }
@@ -8,4 +8,57 @@ var DEBUG bool
 var DATA_DIRECTORY string
 var API_DOMAIN string
 var JWT_KEY string
+var JWT_KEYS_DIRECTORY string
+var JWT_SIGNING_KEY_ID string
 var COMPRESSION_TASK_PERIOD int64
+var STORAGE_BACKEND string
+var S3_ENDPOINT string
+var S3_BUCKET string
+var S3_ACCESS_KEY string
+var S3_SECRET_KEY string
+var S3_REGION string
+var S3_USE_SSL bool
+var ARCHIVE_BACKEND string
+var ARCHIVE_DIRECTORY string
+var ARCHIVE_S3_BUCKET string
+var ARCHIVE_AFTER_DAYS int64
+var ARCHIVE_IDLE_DAYS int64
+var TIERING_TASK_PERIOD int64
+var QUOTA_INCLUDE_RENDITIONS bool
+var OIDC_ISSUER string
+var OIDC_CLIENT_ID string
+var OIDC_CLIENT_SECRET string
+var OIDC_REDIRECT_URL string
+var OIDC_SCOPES []string
+var OIDC_PROVISIONING bool
+var OIDC_GROUPS_CLAIM string
+var OIDC_ADMIN_GROUP string
+var OIDC_POST_LOGIN_URL string
+var APP_URL string
+var MAILER string
+var MAIL_DIRECTORY string
+var MAIL_FROM string
+var SMTP_HOST string
+var SMTP_PORT int64
+var SMTP_USERNAME string
+var SMTP_PASSWORD string
+var TRUSTED_PROXIES []string
+var LOGIN_IP_RATE_LIMIT int64
+var LOGIN_ACCOUNT_RATE_LIMIT int64
+var SHARED_LINK_RATE_LIMIT int64
+var LOCKOUT_THRESHOLD int64
+var LOCKOUT_DURATION int64
+var LOCKOUT_MAX_DURATION int64
+var SETUP_ENDPOINT bool
+var SETUP_TOKEN string
+var PASSWORD_HASH string
+var BCRYPT_COST int64
+var ARGON2_MEMORY int64
+var ARGON2_ITERATIONS int64
+var ARGON2_PARALLELISM int64
+var PASSWORD_MIN_LENGTH int64
+var PASSWORD_FORBID_EMAIL bool
+var PASSWORD_BREACH_LIST string
+var MEDIA_URL_KEY string
+var MEDIA_URL_TTL int64
+var PUBLIC_URL string
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Bring the documents written by older versions up to date. Migrations only touch documents left in an old format, so
+// they run on every start.
+func migrateMongoDb(db *mongo.Database) {
+	if err := migrateAlbumAccessRoles(db); err != nil {
+		slog.Error("couldn't migrate album accesses to roles", "error", err)
+		panic(err)
+	}
+	if err := migrateMediaCharges(db); err != nil {
+		slog.Error("couldn't count the medias uploaded before quotas", "error", err)
+		panic(err)
+	}
+	if err := migrateOwnMediaAccesses(db); err != nil {
+		slog.Error("couldn't remove the accesses of users to their own medias", "error", err)
+		panic(err)
+	}
+}
+
+// Album accesses and invitations had an edit flag before roles: those who could edit become editors, the others
+// viewers, and authors own their albums
+func migrateAlbumAccessRoles(db *mongo.Database) error {
+	ctx := context.Background()
+	migrated := int64(0)
+	for _, collection := range []string{repository.USER_ALBUM_ACCESS_COLLECTION, repository.GROUP_ALBUM_ACCESS_COLLECTION, repository.USER_TOKEN_COLLECTION} {
+		for _, canEdit := range []bool{true, false} {
+			filter := bson.M{"role": bson.M{"$exists": false}, "albumId": bson.M{"$exists": true}}
+			if canEdit {
+				filter["canEdit"] = true
+			}
+			update := bson.M{"$set": bson.M{"role": model.AlbumRoleFromCanEdit(canEdit)}, "$unset": bson.M{"canEdit": ""}}
+			result, err := db.Collection(collection).UpdateMany(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			migrated += result.ModifiedCount
+		}
+	}
+	if migrated == 0 {
+		return nil
+	}
+
+	cursor, err := db.Collection(repository.ALBUM_COLLECTION).Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"authorId": 1}))
+	if err != nil {
+		return err
+	}
+	var albums []model.Album
+	if err := cursor.All(ctx, &albums); err != nil {
+		return err
+	}
+	for _, album := range albums {
+		filter := bson.M{"userId": album.AuthorId, "albumId": album.Id}
+		if _, err := db.Collection(repository.USER_ALBUM_ACCESS_COLLECTION).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"role": model.ALBUM_ROLE_OWNER}}); err != nil {
+			return err
+		}
+	}
+	slog.Info("Migrated album accesses to roles", "accesses", migrated, "albums", len(albums))
+	return nil
+}
+
+// Transfers used to give the new owner an access to the medias they received, which then showed up as shared with them
+func migrateOwnMediaAccesses(db *mongo.Database) error {
+	ctx := context.Background()
+	pipeline := mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{"from": repository.MEDIA_COLLECTION, "localField": "mediaId", "foreignField": "_id", "as": "media"}}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$in": bson.A{"$userId", "$media.uploadedBy"}}}}},
+		{{Key: "$project", Value: bson.M{"_id": 1}}},
+	}
+	cursor, err := db.Collection(repository.USER_MEDIA_ACCESS_COLLECTION).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var accesses []model.UserMediaAccess
+	if err := cursor.All(ctx, &accesses); err != nil {
+		return err
+	}
+	if len(accesses) == 0 {
+		return nil
+	}
+
+	accessIds := make([]*primitive.ObjectID, 0, len(accesses))
+	for _, access := range accesses {
+		accessIds = append(accessIds, access.AccessId)
+	}
+	result, err := db.Collection(repository.USER_MEDIA_ACCESS_COLLECTION).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": accessIds}})
+	if err != nil {
+		return err
+	}
+	slog.Info("Removed the accesses of users to their own medias", "accesses", result.DeletedCount)
+	return nil
+}
+
+// Medias uploaded before quotas are charged to their uploader, for the size of their original. The size of medias
+// predating both quotas and deduplication is only known once fsck tracked their blob, they are charged on a later start.
+func migrateMediaCharges(db *mongo.Database) error {
+	ctx := context.Background()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"chargedTo": bson.M{"$exists": false}, "uploadedBy": bson.M{"$ne": nil}}}},
+		{{Key: "$lookup", Value: bson.M{"from": repository.BLOB_COLLECTION, "localField": "storageFileName", "foreignField": "storageFileName", "as": "blob"}}},
+		{{Key: "$project", Value: bson.M{"uploadedBy": 1, "size": 1, "blobSize": bson.M{"$arrayElemAt": bson.A{"$blob.size", 0}}}}},
+	}
+	cursor, err := db.Collection(repository.MEDIA_COLLECTION).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var medias []struct {
+		Id         primitive.ObjectID `bson:"_id"`
+		UploadedBy primitive.ObjectID `bson:"uploadedBy"`
+		Size       int64              `bson:"size"`
+		BlobSize   int64              `bson:"blobSize"`
+	}
+	if err := cursor.All(ctx, &medias); err != nil {
+		return err
+	}
+
+	usages := map[primitive.ObjectID]*model.StorageUsage{}
+	for _, media := range medias {
+		size := media.Size
+		if size == 0 {
+			size = media.BlobSize
+		}
+		if size == 0 {
+			continue
+		}
+		// Only stamped once, the usage of its uploader is counted for the stamped medias only
+		filter := bson.M{"_id": media.Id, "chargedTo": bson.M{"$exists": false}}
+		update := bson.M{"$set": bson.M{"chargedTo": media.UploadedBy, "chargedBytes": size, "size": size}}
+		result, err := db.Collection(repository.MEDIA_COLLECTION).UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+		usage, ok := usages[media.UploadedBy]
+		if !ok {
+			usage = &model.StorageUsage{}
+			usages[media.UploadedBy] = usage
+		}
+		usage.Bytes += size
+		usage.Files += 1
+	}
+
+	for userId, usage := range usages {
+		update := bson.M{"$inc": bson.M{"usage.bytes": usage.Bytes, "usage.files": usage.Files}}
+		if _, err := db.Collection(repository.USER_COLLECTION).UpdateOne(ctx, bson.M{"_id": userId}, update); err != nil {
+			return err
+		}
+	}
+	if len(usages) > 0 {
+		slog.Info("Charged the medias uploaded before quotas", "users", len(usages))
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"data-storage-svc/internal"
+	"data-storage-svc/internal/model"
 	"data-storage-svc/internal/repository"
 	"log/slog"
 
@@ -25,6 +26,7 @@ func Mongo() *mongo.Database {
 			panic(err)
 		}
 		configureMongoDb(client, DB_NAME)
+		migrateMongoDb(client.Database(DB_NAME))
 		mongoClient = client
 	}
 
@@ -49,6 +51,11 @@ func configureMongoDb(client *mongo.Client, dbName string) {
 		Options: options.Index().SetUnique(true),
 	}
 	client.Database(dbName).Collection(repository.USER_MEDIA_ACCESS_COLLECTION).Indexes().CreateOne(context.Background(), indexUserMediaId)
+	// Create a media id index to list the users a media is shared with
+	indexMediaId := mongo.IndexModel{
+		Keys: bson.D{{Key: "mediaId", Value: 1}},
+	}
+	client.Database(dbName).Collection(repository.USER_MEDIA_ACCESS_COLLECTION).Indexes().CreateOne(context.Background(), indexMediaId)
 
 	// Create an index to quicly get all albums accessible to a user
 	indexUserAlbumId := mongo.IndexModel{
@@ -82,4 +89,84 @@ func configureMongoDb(client *mongo.Client, dbName string) {
 		Options: options.Index().SetUnique(true),
 	}
 	client.Database(dbName).Collection(repository.MEDIA_COLLECTION).Indexes().CreateOne(context.Background(), mediaHashIndex)
+
+	// Create an index to authenticate API keys from their hash
+	apiKeyHashIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "keyHash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	client.Database(dbName).Collection(repository.API_KEY_COLLECTION).Indexes().CreateOne(context.Background(), apiKeyHashIndex)
+
+	// Let Mongo purge expired sessions
+	sessionExpiryIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	client.Database(dbName).Collection(repository.SESSION_COLLECTION).Indexes().CreateOne(context.Background(), sessionExpiryIndex)
+
+	// Create an index to quickly list the sessions of a user
+	sessionUserIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}},
+	}
+	client.Database(dbName).Collection(repository.SESSION_COLLECTION).Indexes().CreateOne(context.Background(), sessionUserIndex)
+
+	// An OpenID Connect identity is linked to a single user
+	oidcSubjectIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "oidcSubject", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	}
+	client.Database(dbName).Collection(repository.USER_COLLECTION).Indexes().CreateOne(context.Background(), oidcSubjectIndex)
+
+	// Tokens sent by email are found from their hash, and purged by Mongo once expired
+	userTokenHashIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	client.Database(dbName).Collection(repository.USER_TOKEN_COLLECTION).Indexes().CreateOne(context.Background(), userTokenHashIndex)
+	userTokenExpiryIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	client.Database(dbName).Collection(repository.USER_TOKEN_COLLECTION).Indexes().CreateOne(context.Background(), userTokenExpiryIndex)
+
+	// Shared links are resolved from their token on each request, and purged by Mongo some time after they expired
+	sharedLinkTokenIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "token", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	client.Database(dbName).Collection(repository.SHARED_LINK_COLLECTION).Indexes().CreateOne(context.Background(), sharedLinkTokenIndex)
+	sharedLinkExpiryIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiration", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(repository.SHARED_LINK_RETENTION.Seconds())),
+	}
+	client.Database(dbName).Collection(repository.SHARED_LINK_COLLECTION).Indexes().CreateOne(context.Background(), sharedLinkExpiryIndex)
+
+	// Public albums are found by their slug, albums never made public have none
+	albumSlugIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"slug": bson.M{"$exists": true}}),
+	}
+	client.Database(dbName).Collection(repository.ALBUM_COLLECTION).Indexes().CreateOne(context.Background(), albumSlugIndex)
+
+	// Access checks look up the groups of a user, and the accesses of the groups to an album
+	groupMemberIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "memberIds", Value: 1}},
+	}
+	client.Database(dbName).Collection(repository.GROUP_COLLECTION).Indexes().CreateOne(context.Background(), groupMemberIndex)
+	groupAlbumAccessKey := mongo.IndexModel{
+		Keys:    bson.D{{Key: "groupId", Value: 1}, {Key: "albumId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	client.Database(dbName).Collection(repository.GROUP_ALBUM_ACCESS_COLLECTION).Indexes().CreateOne(context.Background(), groupAlbumAccessKey)
+
+	// Transfers are listed by album, which has at most one pending transfer
+	transferAlbumIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "albumId", Value: 1}},
+	}
+	client.Database(dbName).Collection(repository.OWNERSHIP_TRANSFER_COLLECTION).Indexes().CreateOne(context.Background(), transferAlbumIndex)
+	pendingTransferIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "albumId", Value: 1}, {Key: "status", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": model.TRANSFER_STATUS_PENDING}),
+	}
+	client.Database(dbName).Collection(repository.OWNERSHIP_TRANSFER_COLLECTION).Indexes().CreateOne(context.Background(), pendingTransferIndex)
 }
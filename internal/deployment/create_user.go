@@ -0,0 +1,58 @@
+package deployment
+
+import (
+	"data-storage-svc/internal/api/security"
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/database"
+	"data-storage-svc/internal/repository"
+	"fmt"
+)
+
+// Create a user from the command line, a password is generated and printed when none is given
+func CreateUser(email string, password string, isAdmin bool) error {
+	db := database.Mongo()
+	generated := password == ""
+	if generated {
+		var err error
+		if password, err = security.GeneratePassword(); err != nil {
+			return fmt.Errorf("couldn't generate password: %s", err)
+		}
+	}
+
+	hashModule, passwordPolicy, err := passwordModules()
+	if err != nil {
+		return fmt.Errorf("couldn't set up password hashing: %s", err)
+	}
+	userService := services.NewUserService(repository.NewUserRepository(db), hashModule, passwordPolicy, nil, nil, nil)
+	userId, svcErr := userService.Create(email, password, isAdmin)
+	if svcErr != nil {
+		return fmt.Errorf("couldn't create user: %s", svcErr.GetMessage())
+	}
+
+	fmt.Printf("Created user %s (%s)\n", email, userId.Hex())
+	if generated {
+		fmt.Printf("Password: %s\n", password)
+	}
+	return nil
+}
+
+// Token required by the setup endpoint, only while there is no user. The configured token is used if any, otherwise
+// a new one is generated on each start. Returns an empty token when the setup is over or disabled.
+func setupToken(userRepository repository.UserRepository, enabled bool, configured string) (string, error) {
+	if !enabled {
+		return "", nil
+	}
+	count, err := userRepository.Count()
+	if err != nil || count > 0 {
+		return "", err
+	}
+	token := configured
+	if token == "" {
+		if token, err = security.GenerateSetupToken(); err != nil {
+			return "", err
+		}
+	}
+	// Printed whatever the log level, this is the only way to get the first admin from a browser
+	fmt.Printf("No user yet, create the first admin with POST /setup and the setup token: %s\n", token)
+	return token, nil
+}
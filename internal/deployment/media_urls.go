@@ -0,0 +1,34 @@
+package deployment
+
+import (
+	"crypto/rand"
+	"data-storage-svc/internal"
+	"data-storage-svc/internal/api/security"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Signer of the media URLs. Its secret is created on first start, instances serving the same medias must share it.
+func mediaUrlSigner() (security.MediaUrlSigner, error) {
+	if internal.MEDIA_URL_TTL <= 0 {
+		return nil, errors.New("media URL ttl must be positive")
+	}
+	keyPath := filepath.Join(internal.DATA_DIRECTORY, internal.MEDIA_URL_KEY)
+	secret, err := os.ReadFile(keyPath)
+	if os.IsNotExist(err) {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(keyPath, secret, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return security.NewMediaUrlSigner(secret, time.Duration(internal.MEDIA_URL_TTL)*time.Second), nil
+}
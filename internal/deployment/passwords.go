@@ -0,0 +1,29 @@
+package deployment
+
+import (
+	"data-storage-svc/internal"
+	"data-storage-svc/internal/api/security"
+)
+
+// Hash module and password policy from the configuration, shared by the API and the user commands
+func passwordModules() (security.HashModule, security.PasswordPolicy, error) {
+	hashModule, err := security.NewHashModule(security.HashConfig{
+		Algorithm:         internal.PASSWORD_HASH,
+		BcryptCost:        int(internal.BCRYPT_COST),
+		Argon2Memory:      uint32(internal.ARGON2_MEMORY),
+		Argon2Iterations:  uint32(internal.ARGON2_ITERATIONS),
+		Argon2Parallelism: uint8(internal.ARGON2_PARALLELISM),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	passwordPolicy, err := security.NewPasswordPolicy(security.PasswordPolicyConfig{
+		MinLength:      internal.PASSWORD_MIN_LENGTH,
+		ForbidEmail:    internal.PASSWORD_FORBID_EMAIL,
+		BreachListFile: internal.PASSWORD_BREACH_LIST,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return hashModule, passwordPolicy, nil
+}
@@ -0,0 +1,56 @@
+package deployment
+
+import (
+	"data-storage-svc/internal/api/services"
+	"data-storage-svc/internal/database"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/storage"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Check the consistency between the data directory and the database, print the report on stdout
+func RunFsck(repair bool, gracePeriod time.Duration) error {
+	slog.Debug("Getting mongo client")
+	db := database.Mongo()
+	storageBackend, err := storage.New()
+	if err != nil {
+		return fmt.Errorf("couldn't open storage backend: %s", err)
+	}
+	archiveBackend, err := storage.NewArchive()
+	if err != nil {
+		return fmt.Errorf("couldn't open archive backend: %s", err)
+	}
+
+	// Removed media documents release the usage they were charged for
+	quotaService := services.NewQuotaService(repository.NewUserRepository(db), repository.NewSettingsRepository(db))
+	fsckService := services.NewFsckService(
+		repository.NewMediaRepository(db),
+		repository.NewDownloadRepository(db),
+		repository.NewMediaInAlbumRepository(db),
+		repository.NewMediaAccessRepository(db),
+		repository.NewBlobRepository(db),
+		quotaService,
+		storageBackend,
+		archiveBackend,
+	)
+
+	report, svcErr := fsckService.Run(repair, gracePeriod)
+	if svcErr != nil {
+		return fmt.Errorf("storage check failed: %s", svcErr.GetMessage())
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if unrepaired := report.Unrepaired(); unrepaired > 0 {
+		return fmt.Errorf("%d storage issue(s) left unrepaired", unrepaired)
+	}
+	return nil
+}
@@ -9,10 +9,16 @@ import (
 	"data-storage-svc/internal/api/services"
 	"data-storage-svc/internal/compression"
 	"data-storage-svc/internal/database"
+	"data-storage-svc/internal/mail"
+	"data-storage-svc/internal/ratelimit"
 	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/storage"
+	"data-storage-svc/internal/tiering"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,17 +26,66 @@ import (
 func StartApi() {
 	router := gin.Default()
 	router.Use(middlewares.CORSMiddleware())
+	// Client IPs are taken from X-Forwarded-For only when the request comes from a trusted proxy
+	if err := router.SetTrustedProxies(internal.TRUSTED_PROXIES); err != nil {
+		slog.Error("invalid trusted proxies", "error", err)
+		panic(err)
+	}
 
 	slog.Debug("Getting mongo client")
 	db := database.Mongo()
 
+	slog.Debug("Opening storage backend")
+	storageBackend, err := storage.New()
+	if err != nil {
+		slog.Error("couldn't open storage backend", "error", err)
+		panic(err)
+	}
+	archiveBackend, err := storage.NewArchive()
+	if err != nil {
+		slog.Error("couldn't open archive backend", "error", err)
+		panic(err)
+	}
+
 	slog.Debug("Creating security modules")
-	hashModule := security.NewHashModule()
-	tokenModule := security.NewTokenModule()
+	hashModule, passwordPolicy, err := passwordModules()
+	if err != nil {
+		slog.Error("couldn't set up password hashing", "error", err)
+		panic(err)
+	}
+	keyring, err := security.LoadKeyring(filepath.Join(internal.DATA_DIRECTORY, internal.JWT_KEY), internal.JWT_KEYS_DIRECTORY, internal.JWT_SIGNING_KEY_ID)
+	if err != nil {
+		slog.Error("couldn't load JWT keys", "error", err)
+		panic(err)
+	}
+	tokenModule := security.NewTokenModule(keyring)
+	mediaUrlSigner, err := mediaUrlSigner()
+	if err != nil {
+		slog.Error("couldn't set up media URL signing", "error", err)
+		panic(err)
+	}
+
+	slog.Debug("Creating rate limits")
+	rateLimitStore := ratelimit.NewMemoryStore(time.Now)
+	loginGuard := ratelimit.NewLoginGuard(rateLimitStore, ratelimit.PerMinute(internal.LOGIN_ACCOUNT_RATE_LIMIT), ratelimit.LockoutPolicy{
+		Threshold:   internal.LOCKOUT_THRESHOLD,
+		Duration:    time.Duration(internal.LOCKOUT_DURATION) * time.Second,
+		MaxDuration: time.Duration(internal.LOCKOUT_MAX_DURATION) * time.Second,
+		ForgetAfter: 24 * time.Hour,
+	}, time.Now)
+	authRateLimit := middlewares.RateLimitMiddleware(rateLimitStore, "auth", ratelimit.PerMinute(internal.LOGIN_IP_RATE_LIMIT))
+
+	slog.Debug("Creating mailer")
+	mailer, err := mail.New()
+	if err != nil {
+		slog.Error("couldn't create mailer", "error", err)
+		panic(err)
+	}
 
 	slog.Debug("Creating repositories")
 	// Create repositories
 	albumAccessRepository := repository.NewAlbumAccessRepository(db)
+	groupRepository := repository.NewGroupRepository(db)
 	albumRepository := repository.NewAlbumRepository(db)
 	mediaAccessRepository := repository.NewMediaAccessRepository(db)
 	mediaInAlbumRepository := repository.NewMediaInAlbumRepository(db)
@@ -38,28 +93,66 @@ func StartApi() {
 	userRepository := repository.NewUserRepository(db)
 	downloadRepository := repository.NewDownloadRepository(db)
 	sharedLinkRepository := repository.NewSharedLinkRepository(db)
+	blobRepository := repository.NewBlobRepository(db)
+	settingsRepository := repository.NewSettingsRepository(db)
+	apiKeyRepository := repository.NewApiKeyRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	userTokenRepository := repository.NewUserTokenRepository(db)
+	ownershipTransferRepository := repository.NewOwnershipTransferRepository(db)
 
 	// Create services
 	albumAccessService := services.NewAlbumAccessService(albumAccessRepository)
 	albumService := services.NewAlbumService(albumRepository, mediaInAlbumRepository, albumAccessService, sharedLinkRepository, mediaRepository)
 	mediaAccessService := services.NewMediaAccessService(mediaAccessRepository)
-	mediaService := services.NewMediaService(mediaRepository, mediaInAlbumRepository, mediaAccessService, albumService)
-	userService := services.NewUserService(userRepository, hashModule, tokenModule)
-	downloadService := services.NewDownloadService(albumRepository, downloadRepository, mediaRepository, mediaInAlbumRepository)
-	sharedLinkService := services.NewSharedLinkService(sharedLinkRepository, albumAccessRepository)
+	quotaService := services.NewQuotaService(userRepository, settingsRepository)
+	mediaService := services.NewMediaService(mediaRepository, mediaInAlbumRepository, blobRepository, mediaAccessService, albumService, quotaService, storageBackend, archiveBackend)
+	sessionService := services.NewSessionService(sessionRepository, userRepository, tokenModule)
+	userService := services.NewUserService(userRepository, hashModule, passwordPolicy, tokenModule, sessionService, loginGuard)
+	twoFactorService := services.NewTwoFactorService(userRepository, tokenModule, sessionService, loginGuard)
+	oidcService := services.NewOidcService(services.OidcConfig{
+		Issuer:       internal.OIDC_ISSUER,
+		ClientId:     internal.OIDC_CLIENT_ID,
+		ClientSecret: internal.OIDC_CLIENT_SECRET,
+		RedirectUrl:  internal.OIDC_REDIRECT_URL,
+		Scopes:       internal.OIDC_SCOPES,
+		Provisioning: internal.OIDC_PROVISIONING,
+		GroupsClaim:  internal.OIDC_GROUPS_CLAIM,
+		AdminGroup:   internal.OIDC_ADMIN_GROUP,
+	}, userRepository, sessionService)
+	apiKeyService := services.NewApiKeyService(apiKeyRepository)
+	invitationService := services.NewInvitationService(userTokenRepository, userRepository, albumRepository, hashModule, passwordPolicy, albumAccessService, mailer, internal.APP_URL)
+	passwordResetService := services.NewPasswordResetService(userTokenRepository, userRepository, hashModule, passwordPolicy, sessionService, mailer, internal.APP_URL)
+	downloadService := services.NewDownloadService(albumRepository, downloadRepository, mediaRepository, mediaInAlbumRepository, storageBackend, archiveBackend)
+	groupService := services.NewGroupService(groupRepository, albumAccessRepository)
+	ownershipTransferService := services.NewOwnershipTransferService(ownershipTransferRepository, albumRepository, albumAccessRepository, mediaRepository, mediaInAlbumRepository, quotaService)
+	sharedLinkService := services.NewSharedLinkService(sharedLinkRepository, albumAccessRepository, hashModule, tokenModule)
+	fsckService := services.NewFsckService(mediaRepository, downloadRepository, mediaInAlbumRepository, mediaAccessRepository, blobRepository, quotaService, storageBackend, archiveBackend)
+	setupToken, err := setupToken(userRepository, internal.SETUP_ENDPOINT, internal.SETUP_TOKEN)
+	if err != nil {
+		slog.Error("couldn't check if setup is needed", "error", err)
+		panic(err)
+	}
+	setupService := services.NewSetupService(userRepository, userService, setupToken)
+	userManagementService := services.NewUserManagementService(userRepository, hashModule, passwordPolicy, albumRepository, albumAccessRepository, mediaRepository, mediaInAlbumRepository, mediaAccessRepository, sharedLinkRepository, apiKeyRepository, groupRepository, ownershipTransferRepository, sessionService, albumService, mediaService, quotaService)
 
 	// Create middlewares
-	userMiddleware := middlewares.UserMiddleware(userRepository)
-	sharedLinkMiddleware := middlewares.SharedLinkMiddleware(sharedLinkRepository)
+	userMiddleware := middlewares.UserMiddleware(userRepository, apiKeyRepository, sessionRepository, tokenModule)
+	sharedLinkMiddleware := middlewares.SharedLinkMiddleware(sharedLinkRepository, tokenModule, rateLimitStore, ratelimit.PerMinute(internal.SHARED_LINK_RATE_LIMIT))
 
-	permissionManager := common.NewPermissionsManager(albumAccessRepository, albumRepository, downloadRepository, mediaAccessRepository, mediaInAlbumRepository, mediaRepository)
+	permissionManager := common.NewPermissionsManager(albumAccessRepository, albumRepository, downloadRepository, groupRepository, mediaAccessRepository, mediaInAlbumRepository, mediaRepository)
 
 	// Create endpoints
-	albumEndpoint := endpoints.NewAlbumEndpoint([]gin.HandlerFunc{}, permissionManager, albumService, albumAccessService, mediaService, userService)
-	mediaEndpoint := endpoints.NewMediaEndpoint([]gin.HandlerFunc{}, permissionManager, mediaService, mediaAccessService)
-	userEndpoint := endpoints.NewUserEndpoint([]gin.HandlerFunc{}, permissionManager, userService)
+	albumEndpoint := endpoints.NewAlbumEndpoint([]gin.HandlerFunc{}, permissionManager, albumService, albumAccessService, groupService, mediaService, userService)
+	mediaEndpoint := endpoints.NewMediaEndpoint([]gin.HandlerFunc{}, permissionManager, mediaUrlSigner, mediaService, mediaAccessService, quotaService, userService)
+	userEndpoint := endpoints.NewUserEndpoint([]gin.HandlerFunc{}, permissionManager, authRateLimit, userService, quotaService, apiKeyService, sessionService, oidcService, twoFactorService, invitationService, passwordResetService)
 	downloadEndpoint := endpoints.NewDownloadEndpoint([]gin.HandlerFunc{}, permissionManager, downloadService, albumAccessService)
-	sharedLinkEndpoint := endpoints.NewSharedLinkEndpoint([]gin.HandlerFunc{}, permissionManager, sharedLinkService, albumService)
+	sharedLinkEndpoint := endpoints.NewSharedLinkEndpoint([]gin.HandlerFunc{}, permissionManager, authRateLimit, sharedLinkService, albumService)
+	adminEndpoint := endpoints.NewAdminEndpoint([]gin.HandlerFunc{}, permissionManager, fsckService, quotaService, userManagementService)
+	setupEndpoint := endpoints.NewSetupEndpoint([]gin.HandlerFunc{}, permissionManager, authRateLimit, setupService)
+	groupEndpoint := endpoints.NewGroupEndpoint([]gin.HandlerFunc{}, permissionManager, groupService, userService)
+	transferEndpoint := endpoints.NewTransferEndpoint([]gin.HandlerFunc{}, permissionManager, ownershipTransferService, userService)
+	signedMediaEndpoint := endpoints.NewSignedMediaEndpoint([]gin.HandlerFunc{}, mediaUrlSigner, storageBackend)
+	publicEndpoint := endpoints.NewPublicEndpoint([]gin.HandlerFunc{}, mediaUrlSigner, internal.PUBLIC_URL, albumService, mediaService)
 
 	endpointGroupsList := []common.EndpointGroup{
 		albumEndpoint,
@@ -67,23 +160,45 @@ func StartApi() {
 		userEndpoint,
 		downloadEndpoint,
 		sharedLinkEndpoint,
+		adminEndpoint,
+		setupEndpoint,
+		groupEndpoint,
+		transferEndpoint,
 	}
 
 	router.RedirectTrailingSlash = false
-	api := router.Group("", userMiddleware, sharedLinkMiddleware)
-	{
-		for _, endpoint := range endpointGroupsList {
-			endpointGroup := api.Group(endpoint.GetGroupUrl(), endpoint.GetCommonMiddlewares()...)
-			for methodAndPath, finalEndpoint := range endpoint.GetEndpointsList() {
-				pathNoSlash := strings.TrimSuffix(methodAndPath.Path, "/")
-				endpointGroup.Handle(methodAndPath.Method, pathNoSlash, finalEndpoint...)
-				endpointGroup.Handle(methodAndPath.Method, pathNoSlash+"/", finalEndpoint...)
-			}
-		}
-	}
+	registerEndpoints(router.Group("", userMiddleware, sharedLinkMiddleware), endpointGroupsList, nil)
+	// The same routes scoped to a shared link, requests under /s/:shareToken only act as the link. Chunk uploads are
+	// left out: tus builds the upload URLs from a fixed base path, uploads through a link send its token in a header.
+	registerEndpoints(router.Group("/s/:"+middlewares.SHARE_TOKEN_PARAM, sharedLinkMiddleware), endpointGroupsList, isChunkUpload)
+	// Signed media URLs are checked without any user nor link, so that serving them never hits the database,
+	// public albums are seen anonymously
+	registerEndpoints(router.Group(""), []common.EndpointGroup{signedMediaEndpoint, publicEndpoint}, nil)
 
 	// Start the compression task
-	go compression.CompressionTask(internal.COMPRESSION_TASK_PERIOD, mediaRepository)
+	go compression.CompressionTask(internal.COMPRESSION_TASK_PERIOD, mediaRepository, userRepository, storageBackend)
+	if archiveBackend != nil {
+		go tiering.TieringTask(internal.TIERING_TASK_PERIOD, internal.ARCHIVE_AFTER_DAYS, internal.ARCHIVE_IDLE_DAYS, mediaRepository, storageBackend, archiveBackend)
+	}
 
 	router.Run(fmt.Sprintf("%s:%d", internal.API_IP, internal.API_PORT))
 }
+
+// Register the routes of the endpoint groups, except those the skip function matches when given
+func registerEndpoints(api *gin.RouterGroup, endpointGroupsList []common.EndpointGroup, skip func(groupUrl string, methodAndPath common.MethodPath) bool) {
+	for _, endpoint := range endpointGroupsList {
+		endpointGroup := api.Group(endpoint.GetGroupUrl(), endpoint.GetCommonMiddlewares()...)
+		for methodAndPath, finalEndpoint := range endpoint.GetEndpointsList() {
+			if skip != nil && skip(endpoint.GetGroupUrl(), methodAndPath) {
+				continue
+			}
+			pathNoSlash := strings.TrimSuffix(methodAndPath.Path, "/")
+			endpointGroup.Handle(methodAndPath.Method, pathNoSlash, finalEndpoint...)
+			endpointGroup.Handle(methodAndPath.Method, pathNoSlash+"/", finalEndpoint...)
+		}
+	}
+}
+
+func isChunkUpload(groupUrl string, methodAndPath common.MethodPath) bool {
+	return strings.HasPrefix(groupUrl+methodAndPath.Path, "/media/chunkupload")
+}
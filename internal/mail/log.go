@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Keep emails local instead of sending them, for development and tests. Each email is written to a file of the
+// directory, or logged when no directory is given.
+type logMailer struct {
+	directory string
+}
+
+func NewLogMailer(directory string) Mailer {
+	return logMailer{directory}
+}
+
+func (m logMailer) Send(message Message) error {
+	if m.directory == "" {
+		slog.Info("Email not sent, no mailer configured", "to", message.To, "subject", message.Subject, "body", message.Body)
+		return nil
+	}
+	if err := os.MkdirAll(m.directory, 0755); err != nil {
+		return err
+	}
+	// Recipient is part of the name so that tests and developers can find the email
+	recipient := strings.NewReplacer("/", "_", "\\", "_").Replace(message.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), recipient)
+	return os.WriteFile(filepath.Join(m.directory, name), format("", message, time.Now()), 0644)
+}
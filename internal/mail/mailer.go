@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"data-storage-svc/internal"
+	"fmt"
+)
+
+// An email sent to a single recipient, the body is plain text
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sends the emails of the application (invitations, password resets...)
+type Mailer interface {
+	// Send an email, returns once it has been handed to the mail server
+	Send(message Message) error
+}
+
+// Create the mailer selected in the configuration
+func New() (Mailer, error) {
+	switch internal.MAILER {
+	case "", "log":
+		return NewLogMailer(internal.MAIL_DIRECTORY), nil
+	case "smtp":
+		if internal.SMTP_HOST == "" || internal.MAIL_FROM == "" {
+			return nil, fmt.Errorf("an SMTP host and a sender address are needed for the smtp mailer")
+		}
+		return NewSmtpMailer(SmtpConfig{
+			Host:     internal.SMTP_HOST,
+			Port:     internal.SMTP_PORT,
+			Username: internal.SMTP_USERNAME,
+			Password: internal.SMTP_PASSWORD,
+			From:     internal.MAIL_FROM,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer [%s]", internal.MAILER)
+	}
+}
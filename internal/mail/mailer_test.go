@@ -0,0 +1,97 @@
+package mail_test
+
+import (
+	"bufio"
+	"data-storage-svc/internal/mail"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMailer(t *testing.T) {
+	directory := t.TempDir()
+	mailer := mail.NewLogMailer(directory)
+	assert.NoError(t, mailer.Send(mail.Message{To: "test@test.fr", Subject: "Invitation à l'album", Body: "Hello\nWorld"}))
+
+	files, err := filepath.Glob(filepath.Join(directory, "*-test@test.fr.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: test@test.fr\r\n")
+	assert.Contains(t, string(content), "Subject: =?utf-8?q?Invitation_=C3=A0_l'album?=\r\n")
+	assert.True(t, strings.HasSuffix(string(content), "\r\n\r\nHello\r\nWorld"))
+
+	// Without directory, emails are only logged
+	assert.NoError(t, mail.NewLogMailer("").Send(mail.Message{To: "test@test.fr"}))
+}
+
+// Minimal SMTP server accepting a single email, returns what the client sent
+func fakeSmtpServer(t *testing.T) (string, int64, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't listen: %s", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var transcript strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			transcript.WriteString(line)
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					transcript.WriteString(dataLine)
+				}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				received <- transcript.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.ParseInt(port, 10, 64)
+	return host, portNumber, received
+}
+
+func TestSmtpMailer(t *testing.T) {
+	host, port, received := fakeSmtpServer(t)
+	mailer := mail.NewSmtpMailer(mail.SmtpConfig{Host: host, Port: port, From: "album@test.fr"})
+	assert.NoError(t, mailer.Send(mail.Message{To: "test@test.fr", Subject: "Reset your password", Body: "Click the link"}))
+
+	transcript := <-received
+	assert.Contains(t, transcript, "MAIL FROM:<album@test.fr>")
+	assert.Contains(t, transcript, "RCPT TO:<test@test.fr>")
+	assert.Contains(t, transcript, "From: album@test.fr\r\n")
+	assert.Contains(t, transcript, "Subject: Reset your password\r\n")
+	assert.Contains(t, transcript, "Click the link")
+}
@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SmtpConfig struct {
+	Host string
+	Port int64
+	// Leave empty when the server does not require authentication
+	Username string
+	Password string
+	// Sender address
+	From string
+}
+
+// Send emails through an SMTP server, STARTTLS is used when the server offers it
+type smtpMailer struct {
+	config SmtpConfig
+}
+
+func NewSmtpMailer(config SmtpConfig) Mailer {
+	return smtpMailer{config}
+}
+
+func (m smtpMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	address := net.JoinHostPort(m.config.Host, strconv.FormatInt(m.config.Port, 10))
+	return smtp.SendMail(address, auth, m.config.From, []string{message.To}, format(m.config.From, message, time.Now()))
+}
+
+// Build the raw email, headers are encoded so that non ASCII subjects are valid
+func format(from string, message Message, date time.Time) []byte {
+	var buffer bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&buffer, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&buffer, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buffer.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buffer.Bytes()
+}
@@ -0,0 +1,171 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	common "data-storage-svc/internal/api/common"
+
+	gin "github.com/gin-gonic/gin"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AdminEndpoint is an autogenerated mock type for the AdminEndpoint type
+type AdminEndpoint struct {
+	mock.Mock
+}
+
+// DeleteUser provides a mock function with given fields: c
+func (_m *AdminEndpoint) DeleteUser(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Fsck provides a mock function with given fields: c
+func (_m *AdminEndpoint) Fsck(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetCommonMiddlewares provides a mock function with no fields
+func (_m *AdminEndpoint) GetCommonMiddlewares() []gin.HandlerFunc {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommonMiddlewares")
+	}
+
+	var r0 []gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func() []gin.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetDefaultQuota provides a mock function with given fields: c
+func (_m *AdminEndpoint) GetDefaultQuota(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetEndpointName provides a mock function with no fields
+func (_m *AdminEndpoint) GetEndpointName() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointName")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetEndpointsList provides a mock function with no fields
+func (_m *AdminEndpoint) GetEndpointsList() map[common.MethodPath][]gin.HandlerFunc {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointsList")
+	}
+
+	var r0 map[common.MethodPath][]gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func() map[common.MethodPath][]gin.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[common.MethodPath][]gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetGroupUrl provides a mock function with no fields
+func (_m *AdminEndpoint) GetGroupUrl() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroupUrl")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetPermissionsManager provides a mock function with no fields
+func (_m *AdminEndpoint) GetPermissionsManager() common.PermissionsManager {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermissionsManager")
+	}
+
+	var r0 common.PermissionsManager
+	if rf, ok := ret.Get(0).(func() common.PermissionsManager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(common.PermissionsManager)
+		}
+	}
+
+	return r0
+}
+
+// GetUserUsage provides a mock function with given fields: c
+func (_m *AdminEndpoint) GetUserUsage(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ResetUserPassword provides a mock function with given fields: c
+func (_m *AdminEndpoint) ResetUserPassword(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ResetUserQuota provides a mock function with given fields: c
+func (_m *AdminEndpoint) ResetUserQuota(c *gin.Context) {
+	_m.Called(c)
+}
+
+// SetDefaultQuota provides a mock function with given fields: c
+func (_m *AdminEndpoint) SetDefaultQuota(c *gin.Context) {
+	_m.Called(c)
+}
+
+// SetUserQuota provides a mock function with given fields: c
+func (_m *AdminEndpoint) SetUserQuota(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UpdateUser provides a mock function with given fields: c
+func (_m *AdminEndpoint) UpdateUser(c *gin.Context) {
+	_m.Called(c)
+}
+
+// NewAdminEndpoint creates a new instance of AdminEndpoint. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAdminEndpoint(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AdminEndpoint {
+	mock := &AdminEndpoint{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
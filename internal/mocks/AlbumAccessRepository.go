@@ -15,17 +15,35 @@ type AlbumAccessRepository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: userId, albumId, canEdit
-func (_m *AlbumAccessRepository) Create(userId *primitive.ObjectID, albumId *primitive.ObjectID, canEdit bool) error {
-	ret := _m.Called(userId, albumId, canEdit)
+// Create provides a mock function with given fields: userId, albumId, role
+func (_m *AlbumAccessRepository) Create(userId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) error {
+	ret := _m.Called(userId, albumId, role)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, bool) error); ok {
-		r0 = rf(userId, albumId, canEdit)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, model.AlbumRole) error); ok {
+		r0 = rf(userId, albumId, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateForGroup provides a mock function with given fields: groupId, albumId, role
+func (_m *AlbumAccessRepository) CreateForGroup(groupId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) error {
+	ret := _m.Called(groupId, albumId, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateForGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, model.AlbumRole) error); ok {
+		r0 = rf(groupId, albumId, role)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -123,6 +141,96 @@ func (_m *AlbumAccessRepository) GetAllByUser(userId *primitive.ObjectID) ([]mod
 	return r0, r1
 }
 
+// GetAllEffectiveByUser provides a mock function with given fields: userId
+func (_m *AlbumAccessRepository) GetAllEffectiveByUser(userId *primitive.ObjectID) ([]model.UserAlbumAccess, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllEffectiveByUser")
+	}
+
+	var r0 []model.UserAlbumAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.UserAlbumAccess, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.UserAlbumAccess); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserAlbumAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllGroupsByAlbum provides a mock function with given fields: albumId
+func (_m *AlbumAccessRepository) GetAllGroupsByAlbum(albumId *primitive.ObjectID) ([]model.GroupAlbumAccess, error) {
+	ret := _m.Called(albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllGroupsByAlbum")
+	}
+
+	var r0 []model.GroupAlbumAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.GroupAlbumAccess, error)); ok {
+		return rf(albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.GroupAlbumAccess); ok {
+		r0 = rf(albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.GroupAlbumAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(albumId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEffective provides a mock function with given fields: userId, albumId
+func (_m *AlbumAccessRepository) GetEffective(userId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.UserAlbumAccess, error) {
+	ret := _m.Called(userId, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEffective")
+	}
+
+	var r0 *model.UserAlbumAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) (*model.UserAlbumAccess, error)); ok {
+		return rf(userId, albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) *model.UserAlbumAccess); ok {
+		r0 = rf(userId, albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserAlbumAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r1 = rf(userId, albumId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Remove provides a mock function with given fields: userId, albumId
 func (_m *AlbumAccessRepository) Remove(userId *primitive.ObjectID, albumId *primitive.ObjectID) error {
 	ret := _m.Called(userId, albumId)
@@ -159,6 +267,42 @@ func (_m *AlbumAccessRepository) RemoveAllAccesses(albumId *primitive.ObjectID)
 	return r0
 }
 
+// RemoveAllForGroup provides a mock function with given fields: groupId
+func (_m *AlbumAccessRepository) RemoveAllForGroup(groupId *primitive.ObjectID) error {
+	ret := _m.Called(groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveAllForGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(groupId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveForGroup provides a mock function with given fields: groupId, albumId
+func (_m *AlbumAccessRepository) RemoveForGroup(groupId *primitive.ObjectID, albumId *primitive.ObjectID) error {
+	ret := _m.Called(groupId, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveForGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(groupId, albumId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewAlbumAccessRepository creates a new instance of AlbumAccessRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAlbumAccessRepository(t interface {
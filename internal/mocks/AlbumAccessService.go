@@ -79,17 +79,69 @@ func (_m *AlbumAccessService) GetAllForUser(userId *primitive.ObjectID) ([]model
 	return r0, r1
 }
 
-// GrantAccess provides a mock function with given fields: userId, albumId, canEdit
-func (_m *AlbumAccessService) GrantAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID, canEdit bool) utils.ServiceError {
-	ret := _m.Called(userId, albumId, canEdit)
+// GetAllGroupAccesses provides a mock function with given fields: albumId
+func (_m *AlbumAccessService) GetAllGroupAccesses(albumId *primitive.ObjectID) ([]model.GroupAlbumAccess, utils.ServiceError) {
+	ret := _m.Called(albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllGroupAccesses")
+	}
+
+	var r0 []model.GroupAlbumAccess
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.GroupAlbumAccess, utils.ServiceError)); ok {
+		return rf(albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.GroupAlbumAccess); ok {
+		r0 = rf(albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.GroupAlbumAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(albumId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GrantAccess provides a mock function with given fields: userId, albumId, role
+func (_m *AlbumAccessService) GrantAccess(userId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError {
+	ret := _m.Called(userId, albumId, role)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GrantAccess")
 	}
 
 	var r0 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, bool) utils.ServiceError); ok {
-		r0 = rf(userId, albumId, canEdit)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, model.AlbumRole) utils.ServiceError); ok {
+		r0 = rf(userId, albumId, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// GrantGroupAccess provides a mock function with given fields: groupId, albumId, role
+func (_m *AlbumAccessService) GrantGroupAccess(groupId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError {
+	ret := _m.Called(groupId, albumId, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantGroupAccess")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, model.AlbumRole) utils.ServiceError); ok {
+		r0 = rf(groupId, albumId, role)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(utils.ServiceError)
@@ -137,6 +189,26 @@ func (_m *AlbumAccessService) RevokeAllAccesses(albumId *primitive.ObjectID) err
 	return r0
 }
 
+// RevokeGroupAccess provides a mock function with given fields: groupId, albumId
+func (_m *AlbumAccessService) RevokeGroupAccess(groupId *primitive.ObjectID, albumId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(groupId, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeGroupAccess")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(groupId, albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
 // NewAlbumAccessService creates a new instance of AlbumAccessService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAlbumAccessService(t interface {
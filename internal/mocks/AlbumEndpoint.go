@@ -166,6 +166,11 @@ func (_m *AlbumEndpoint) GetPermissionsManager() common.PermissionsManager {
 	return r0
 }
 
+// SetVisibility provides a mock function with given fields: c
+func (_m *AlbumEndpoint) SetVisibility(c *gin.Context) {
+	_m.Called(c)
+}
+
 // NewAlbumEndpoint creates a new instance of AlbumEndpoint. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAlbumEndpoint(t interface {
@@ -63,6 +63,66 @@ func (_m *AlbumRepository) Delete(albumId *primitive.ObjectID) error {
 	return r0
 }
 
+// GetAllByAuthor provides a mock function with given fields: authorId
+func (_m *AlbumRepository) GetAllByAuthor(authorId *primitive.ObjectID) ([]model.Album, error) {
+	ret := _m.Called(authorId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllByAuthor")
+	}
+
+	var r0 []model.Album
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Album, error)); ok {
+		return rf(authorId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Album); ok {
+		r0 = rf(authorId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Album)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(authorId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllListed provides a mock function with no fields
+func (_m *AlbumRepository) GetAllListed() ([]model.Album, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllListed")
+	}
+
+	var r0 []model.Album
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Album, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Album); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Album)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetById provides a mock function with given fields: id
 func (_m *AlbumRepository) GetById(id primitive.ObjectID) (*model.Album, error) {
 	ret := _m.Called(id)
@@ -93,6 +153,54 @@ func (_m *AlbumRepository) GetById(id primitive.ObjectID) (*model.Album, error)
 	return r0, r1
 }
 
+// GetBySlug provides a mock function with given fields: slug
+func (_m *AlbumRepository) GetBySlug(slug string) (*model.Album, error) {
+	ret := _m.Called(slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySlug")
+	}
+
+	var r0 *model.Album
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.Album, error)); ok {
+		return rf(slug)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.Album); ok {
+		r0 = rf(slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Album)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetAuthor provides a mock function with given fields: albumId, previousAuthorId, authorId
+func (_m *AlbumRepository) SetAuthor(albumId *primitive.ObjectID, previousAuthorId *primitive.ObjectID, authorId *primitive.ObjectID) error {
+	ret := _m.Called(albumId, previousAuthorId, authorId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAuthor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(albumId, previousAuthorId, authorId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: album
 func (_m *AlbumRepository) Update(album *model.Album) error {
 	ret := _m.Called(album)
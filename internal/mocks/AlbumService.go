@@ -9,6 +9,8 @@ import (
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 
+	services "data-storage-svc/internal/api/services"
+
 	utils "data-storage-svc/internal/utils"
 )
 
@@ -257,6 +259,102 @@ func (_m *AlbumService) GetMedias(albumId *primitive.ObjectID) ([]model.MediaInA
 	return r0, r1
 }
 
+// GetPublicAlbum provides a mock function with given fields: slug
+func (_m *AlbumService) GetPublicAlbum(slug string) (*model.Album, utils.ServiceError) {
+	ret := _m.Called(slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPublicAlbum")
+	}
+
+	var r0 *model.Album
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string) (*model.Album, utils.ServiceError)); ok {
+		return rf(slug)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.Album); ok {
+		r0 = rf(slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Album)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) utils.ServiceError); ok {
+		r1 = rf(slug)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetPublicDirectory provides a mock function with no fields
+func (_m *AlbumService) GetPublicDirectory() ([]model.Album, utils.ServiceError) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPublicDirectory")
+	}
+
+	var r0 []model.Album
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func() ([]model.Album, utils.ServiceError)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Album); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Album)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() utils.ServiceError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// SetVisibility provides a mock function with given fields: albumId, visibility
+func (_m *AlbumService) SetVisibility(albumId *primitive.ObjectID, visibility services.AlbumVisibility) (*model.Album, utils.ServiceError) {
+	ret := _m.Called(albumId, visibility)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetVisibility")
+	}
+
+	var r0 *model.Album
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, services.AlbumVisibility) (*model.Album, utils.ServiceError)); ok {
+		return rf(albumId, visibility)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, services.AlbumVisibility) *model.Album); ok {
+		r0 = rf(albumId, visibility)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Album)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, services.AlbumVisibility) utils.ServiceError); ok {
+		r1 = rf(albumId, visibility)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
 // NewAlbumService creates a new instance of AlbumService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAlbumService(t interface {
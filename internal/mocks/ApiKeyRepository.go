@@ -0,0 +1,176 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	time "time"
+)
+
+// ApiKeyRepository is an autogenerated mock type for the ApiKeyRepository type
+type ApiKeyRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: apiKey
+func (_m *ApiKeyRepository) Create(apiKey *model.ApiKey) (*primitive.ObjectID, error) {
+	ret := _m.Called(apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.ApiKey) (*primitive.ObjectID, error)); ok {
+		return rf(apiKey)
+	}
+	if rf, ok := ret.Get(0).(func(*model.ApiKey) *primitive.ObjectID); ok {
+		r0 = rf(apiKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.ApiKey) error); ok {
+		r1 = rf(apiKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAllForUser provides a mock function with given fields: userId
+func (_m *ApiKeyRepository) DeleteAllForUser(userId *primitive.ObjectID) error {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllForUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllForUser provides a mock function with given fields: userId
+func (_m *ApiKeyRepository) GetAllForUser(userId *primitive.ObjectID) ([]model.ApiKey, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForUser")
+	}
+
+	var r0 []model.ApiKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.ApiKey, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.ApiKey); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByHash provides a mock function with given fields: keyHash
+func (_m *ApiKeyRepository) GetByHash(keyHash string) (*model.ApiKey, error) {
+	ret := _m.Called(keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.ApiKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.ApiKey, error)); ok {
+		return rf(keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.ApiKey); ok {
+		r0 = rf(keyHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: userId, apiKeyId
+func (_m *ApiKeyRepository) Revoke(userId *primitive.ObjectID, apiKeyId *primitive.ObjectID) error {
+	ret := _m.Called(userId, apiKeyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(userId, apiKeyId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetLastUsed provides a mock function with given fields: apiKeyId, lastUsed
+func (_m *ApiKeyRepository) SetLastUsed(apiKeyId *primitive.ObjectID, lastUsed time.Time) error {
+	ret := _m.Called(apiKeyId, lastUsed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLastUsed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, time.Time) error); ok {
+		r0 = rf(apiKeyId, lastUsed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewApiKeyRepository creates a new instance of ApiKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewApiKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ApiKeyRepository {
+	mock := &ApiKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,125 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// ApiKeyService is an autogenerated mock type for the ApiKeyService type
+type ApiKeyService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: userId, name, scopes
+func (_m *ApiKeyService) Create(userId *primitive.ObjectID, name string, scopes []model.ApiKeyScope) (*model.ApiKey, *string, utils.ServiceError) {
+	ret := _m.Called(userId, name, scopes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *model.ApiKey
+	var r1 *string
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, []model.ApiKeyScope) (*model.ApiKey, *string, utils.ServiceError)); ok {
+		return rf(userId, name, scopes)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, []model.ApiKeyScope) *model.ApiKey); ok {
+		r0 = rf(userId, name, scopes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string, []model.ApiKeyScope) *string); ok {
+		r1 = rf(userId, name, scopes)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(*primitive.ObjectID, string, []model.ApiKeyScope) utils.ServiceError); ok {
+		r2 = rf(userId, name, scopes)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// GetAllForUser provides a mock function with given fields: userId
+func (_m *ApiKeyService) GetAllForUser(userId *primitive.ObjectID) ([]model.ApiKey, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForUser")
+	}
+
+	var r0 []model.ApiKey
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.ApiKey, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.ApiKey); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ApiKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: userId, apiKeyId
+func (_m *ApiKeyService) Revoke(userId *primitive.ObjectID, apiKeyId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(userId, apiKeyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(userId, apiKeyId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewApiKeyService creates a new instance of ApiKeyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewApiKeyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ApiKeyService {
+	mock := &ApiKeyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,155 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "data-storage-svc/internal/storage"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Backend is an autogenerated mock type for the Backend type
+type Backend struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: key
+func (_m *Backend) Delete(key string) error {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: key, offset, length
+func (_m *Backend) Get(key string, offset int64, length int64) (io.ReadCloser, error) {
+	ret := _m.Called(key, offset, length)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64, int64) (io.ReadCloser, error)); ok {
+		return rf(key, offset, length)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, int64) io.ReadCloser); ok {
+		r0 = rf(key, offset, length)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, int64) error); ok {
+		r1 = rf(key, offset, length)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: prefix
+func (_m *Backend) List(prefix string) ([]storage.ObjectInfo, error) {
+	ret := _m.Called(prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []storage.ObjectInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]storage.ObjectInfo, error)); ok {
+		return rf(prefix)
+	}
+	if rf, ok := ret.Get(0).(func(string) []storage.ObjectInfo); ok {
+		r0 = rf(prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.ObjectInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Put provides a mock function with given fields: key, data, size
+func (_m *Backend) Put(key string, data io.Reader, size int64) error {
+	ret := _m.Called(key, data, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Put")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, io.Reader, int64) error); ok {
+		r0 = rf(key, data, size)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stat provides a mock function with given fields: key
+func (_m *Backend) Stat(key string) (*storage.ObjectInfo, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stat")
+	}
+
+	var r0 *storage.ObjectInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*storage.ObjectInfo, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) *storage.ObjectInfo); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.ObjectInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewBackend creates a new instance of Backend. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBackend(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Backend {
+	mock := &Backend{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
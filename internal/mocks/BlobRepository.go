@@ -0,0 +1,152 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BlobRepository is an autogenerated mock type for the BlobRepository type
+type BlobRepository struct {
+	mock.Mock
+}
+
+// Acquire provides a mock function with given fields: hash, storageFileName, size
+func (_m *BlobRepository) Acquire(hash string, storageFileName string, size int64) (*model.Blob, error) {
+	ret := _m.Called(hash, storageFileName, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Acquire")
+	}
+
+	var r0 *model.Blob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) (*model.Blob, error)); ok {
+		return rf(hash, storageFileName, size)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int64) *model.Blob); ok {
+		r0 = rf(hash, storageFileName, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Blob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int64) error); ok {
+		r1 = rf(hash, storageFileName, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: hash
+func (_m *BlobRepository) Delete(hash string) error {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with no fields
+func (_m *BlobRepository) GetAll() ([]model.Blob, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []model.Blob
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Blob, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Blob); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Blob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: hash, storageFileName
+func (_m *BlobRepository) Release(hash string, storageFileName string) (bool, error) {
+	ret := _m.Called(hash, storageFileName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (bool, error)); ok {
+		return rf(hash, storageFileName)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(hash, storageFileName)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(hash, storageFileName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: blob
+func (_m *BlobRepository) Save(blob *model.Blob) error {
+	ret := _m.Called(blob)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Blob) error); ok {
+		r0 = rf(blob)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewBlobRepository creates a new instance of BlobRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBlobRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *BlobRepository {
+	mock := &BlobRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -45,6 +45,24 @@ func (_m *DownloadRepository) Create(download *model.Download) (*primitive.Objec
 	return r0, r1
 }
 
+// Delete provides a mock function with given fields: downloadId
+func (_m *DownloadRepository) Delete(downloadId *primitive.ObjectID) error {
+	ret := _m.Called(downloadId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(downloadId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: downloadId
 func (_m *DownloadRepository) Get(downloadId *primitive.ObjectID) (*model.Download, error) {
 	ret := _m.Called(downloadId)
@@ -75,6 +93,36 @@ func (_m *DownloadRepository) Get(downloadId *primitive.ObjectID) (*model.Downlo
 	return r0, r1
 }
 
+// GetAll provides a mock function with no fields
+func (_m *DownloadRepository) GetAll() ([]model.Download, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []model.Download
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Download, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Download); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Download)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MarkAsReady provides a mock function with given fields: downloadId
 func (_m *DownloadRepository) MarkAsReady(downloadId *primitive.ObjectID) error {
 	ret := _m.Called(downloadId)
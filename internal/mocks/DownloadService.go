@@ -4,11 +4,14 @@ package mocks
 
 import (
 	model "data-storage-svc/internal/model"
+	io "io"
 
 	mock "github.com/stretchr/testify/mock"
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 
+	time "time"
+
 	utils "data-storage-svc/internal/utils"
 )
 
@@ -50,40 +53,49 @@ func (_m *DownloadService) Get(downloadId *primitive.ObjectID) (*model.Download,
 }
 
 // GetData provides a mock function with given fields: downloadId
-func (_m *DownloadService) GetData(downloadId *primitive.ObjectID) ([]byte, utils.ServiceError) {
+func (_m *DownloadService) GetData(downloadId *primitive.ObjectID) (io.ReadSeekCloser, *time.Time, utils.ServiceError) {
 	ret := _m.Called(downloadId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetData")
 	}
 
-	var r0 []byte
-	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]byte, utils.ServiceError)); ok {
+	var r0 io.ReadSeekCloser
+	var r1 *time.Time
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (io.ReadSeekCloser, *time.Time, utils.ServiceError)); ok {
 		return rf(downloadId)
 	}
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []byte); ok {
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) io.ReadSeekCloser); ok {
 		r0 = rf(downloadId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(io.ReadSeekCloser)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) *time.Time); ok {
 		r1 = rf(downloadId)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(utils.ServiceError)
+			r1 = ret.Get(1).(*time.Time)
 		}
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r2 = rf(downloadId)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
 }
 
-// InitDownload provides a mock function with given fields: albumId, initiator, isInitatedBySharedLink
-func (_m *DownloadService) InitDownload(albumId *primitive.ObjectID, initiator *primitive.ObjectID, isInitatedBySharedLink bool) (*primitive.ObjectID, utils.ServiceError) {
-	ret := _m.Called(albumId, initiator, isInitatedBySharedLink)
+// InitDownload provides a mock function with given fields: albumId, initiator, isInitatedBySharedLink, renditions
+func (_m *DownloadService) InitDownload(albumId *primitive.ObjectID, initiator *primitive.ObjectID, isInitatedBySharedLink bool, renditions bool) (*primitive.ObjectID, utils.ServiceError) {
+	ret := _m.Called(albumId, initiator, isInitatedBySharedLink, renditions)
 
 	if len(ret) == 0 {
 		panic("no return value specified for InitDownload")
@@ -91,19 +103,19 @@ func (_m *DownloadService) InitDownload(albumId *primitive.ObjectID, initiator *
 
 	var r0 *primitive.ObjectID
 	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, bool) (*primitive.ObjectID, utils.ServiceError)); ok {
-		return rf(albumId, initiator, isInitatedBySharedLink)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, bool, bool) (*primitive.ObjectID, utils.ServiceError)); ok {
+		return rf(albumId, initiator, isInitatedBySharedLink, renditions)
 	}
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, bool) *primitive.ObjectID); ok {
-		r0 = rf(albumId, initiator, isInitatedBySharedLink)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, bool, bool) *primitive.ObjectID); ok {
+		r0 = rf(albumId, initiator, isInitatedBySharedLink, renditions)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*primitive.ObjectID)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID, bool) utils.ServiceError); ok {
-		r1 = rf(albumId, initiator, isInitatedBySharedLink)
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID, bool, bool) utils.ServiceError); ok {
+		r1 = rf(albumId, initiator, isInitatedBySharedLink, renditions)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(utils.ServiceError)
@@ -0,0 +1,64 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// FsckService is an autogenerated mock type for the FsckService type
+type FsckService struct {
+	mock.Mock
+}
+
+// Run provides a mock function with given fields: repair, gracePeriod
+func (_m *FsckService) Run(repair bool, gracePeriod time.Duration) (*model.FsckReport, utils.ServiceError) {
+	ret := _m.Called(repair, gracePeriod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Run")
+	}
+
+	var r0 *model.FsckReport
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(bool, time.Duration) (*model.FsckReport, utils.ServiceError)); ok {
+		return rf(repair, gracePeriod)
+	}
+	if rf, ok := ret.Get(0).(func(bool, time.Duration) *model.FsckReport); ok {
+		r0 = rf(repair, gracePeriod)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.FsckReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bool, time.Duration) utils.ServiceError); ok {
+		r1 = rf(repair, gracePeriod)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewFsckService creates a new instance of FsckService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFsckService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FsckService {
+	mock := &FsckService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
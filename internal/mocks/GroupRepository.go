@@ -0,0 +1,240 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupRepository is an autogenerated mock type for the GroupRepository type
+type GroupRepository struct {
+	mock.Mock
+}
+
+// AddMember provides a mock function with given fields: groupId, userId
+func (_m *GroupRepository) AddMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) error {
+	ret := _m.Called(groupId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(groupId, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: group
+func (_m *GroupRepository) Create(group *model.Group) (*primitive.ObjectID, error) {
+	ret := _m.Called(group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.Group) (*primitive.ObjectID, error)); ok {
+		return rf(group)
+	}
+	if rf, ok := ret.Get(0).(func(*model.Group) *primitive.ObjectID); ok {
+		r0 = rf(group)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.Group) error); ok {
+		r1 = rf(group)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: groupId
+func (_m *GroupRepository) Delete(groupId *primitive.ObjectID) error {
+	ret := _m.Called(groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(groupId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: groupId
+func (_m *GroupRepository) Get(groupId *primitive.ObjectID) (*model.Group, error) {
+	ret := _m.Called(groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.Group, error)); ok {
+		return rf(groupId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.Group); ok {
+		r0 = rf(groupId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(groupId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllByMember provides a mock function with given fields: userId
+func (_m *GroupRepository) GetAllByMember(userId *primitive.ObjectID) ([]model.Group, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllByMember")
+	}
+
+	var r0 []model.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Group, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Group); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllByOwner provides a mock function with given fields: userId
+func (_m *GroupRepository) GetAllByOwner(userId *primitive.ObjectID) ([]model.Group, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllByOwner")
+	}
+
+	var r0 []model.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Group, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Group); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: groupId, userId
+func (_m *GroupRepository) RemoveMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) error {
+	ret := _m.Called(groupId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(groupId, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveMemberFromAll provides a mock function with given fields: userId
+func (_m *GroupRepository) RemoveMemberFromAll(userId *primitive.ObjectID) error {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMemberFromAll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetOwner provides a mock function with given fields: groupId, ownerId
+func (_m *GroupRepository) SetOwner(groupId *primitive.ObjectID, ownerId *primitive.ObjectID) error {
+	ret := _m.Called(groupId, ownerId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOwner")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(groupId, ownerId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewGroupRepository creates a new instance of GroupRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGroupRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *GroupRepository {
+	mock := &GroupRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,188 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// GroupService is an autogenerated mock type for the GroupService type
+type GroupService struct {
+	mock.Mock
+}
+
+// AddMember provides a mock function with given fields: groupId, userId
+func (_m *GroupService) AddMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(groupId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(groupId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: ownerId, name
+func (_m *GroupService) Create(ownerId *primitive.ObjectID, name string) (*model.Group, utils.ServiceError) {
+	ret := _m.Called(ownerId, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *model.Group
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) (*model.Group, utils.ServiceError)); ok {
+		return rf(ownerId, name)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) *model.Group); ok {
+		r0 = rf(ownerId, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string) utils.ServiceError); ok {
+		r1 = rf(ownerId, name)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: groupId
+func (_m *GroupService) Delete(groupId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(groupId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// GetAllForUser provides a mock function with given fields: userId
+func (_m *GroupService) GetAllForUser(userId *primitive.ObjectID) ([]model.Group, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForUser")
+	}
+
+	var r0 []model.Group
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Group, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Group); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: groupId
+func (_m *GroupService) GetById(groupId *primitive.ObjectID) (*model.Group, utils.ServiceError) {
+	ret := _m.Called(groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *model.Group
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.Group, utils.ServiceError)); ok {
+		return rf(groupId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.Group); ok {
+		r0 = rf(groupId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(groupId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: groupId, userId
+func (_m *GroupService) RemoveMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(groupId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(groupId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewGroupService creates a new instance of GroupService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGroupService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *GroupService {
+	mock := &GroupService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -37,6 +37,24 @@ func (_m *HashModule) HashPassword(password string) (string, error) {
 	return r0, r1
 }
 
+// NeedsRehash provides a mock function with given fields: hash
+func (_m *HashModule) NeedsRehash(hash string) bool {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NeedsRehash")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // VerifyPassword provides a mock function with given fields: password, hash
 func (_m *HashModule) VerifyPassword(password string, hash string) bool {
 	ret := _m.Called(password, hash)
@@ -0,0 +1,84 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// InvitationService is an autogenerated mock type for the InvitationService type
+type InvitationService struct {
+	mock.Mock
+}
+
+// Accept provides a mock function with given fields: token, password
+func (_m *InvitationService) Accept(token string, password string) (*primitive.ObjectID, utils.ServiceError) {
+	ret := _m.Called(token, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Accept")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string) (*primitive.ObjectID, utils.ServiceError)); ok {
+		return rf(token, password)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *primitive.ObjectID); ok {
+		r0 = rf(token, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) utils.ServiceError); ok {
+		r1 = rf(token, password)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Invite provides a mock function with given fields: inviter, email, albumId, role
+func (_m *InvitationService) Invite(inviter *model.User, email string, albumId *primitive.ObjectID, role model.AlbumRole) utils.ServiceError {
+	ret := _m.Called(inviter, email, albumId, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Invite")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*model.User, string, *primitive.ObjectID, model.AlbumRole) utils.ServiceError); ok {
+		r0 = rf(inviter, email, albumId, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewInvitationService creates a new instance of InvitationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewInvitationService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *InvitationService {
+	mock := &InvitationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
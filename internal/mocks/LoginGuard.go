@@ -0,0 +1,66 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LoginGuard is an autogenerated mock type for the LoginGuard type
+type LoginGuard struct {
+	mock.Mock
+}
+
+// Allow provides a mock function with given fields: account
+func (_m *LoginGuard) Allow(account string) (bool, time.Duration) {
+	ret := _m.Called(account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Allow")
+	}
+
+	var r0 bool
+	var r1 time.Duration
+	if rf, ok := ret.Get(0).(func(string) (bool, time.Duration)); ok {
+		return rf(account)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(account)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) time.Duration); ok {
+		r1 = rf(account)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	return r0, r1
+}
+
+// Failure provides a mock function with given fields: account
+func (_m *LoginGuard) Failure(account string) {
+	_m.Called(account)
+}
+
+// Success provides a mock function with given fields: account
+func (_m *LoginGuard) Success(account string) {
+	_m.Called(account)
+}
+
+// NewLoginGuard creates a new instance of LoginGuard. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginGuard(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginGuard {
+	mock := &LoginGuard{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
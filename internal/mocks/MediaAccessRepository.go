@@ -63,6 +63,36 @@ func (_m *MediaAccessRepository) Get(userId *primitive.ObjectID, mediaId *primit
 	return r0, r1
 }
 
+// GetAllForMedia provides a mock function with given fields: mediaId
+func (_m *MediaAccessRepository) GetAllForMedia(mediaId *primitive.ObjectID) ([]model.UserMediaAccess, error) {
+	ret := _m.Called(mediaId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForMedia")
+	}
+
+	var r0 []model.UserMediaAccess
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.UserMediaAccess, error)); ok {
+		return rf(mediaId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.UserMediaAccess); ok {
+		r0 = rf(mediaId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserMediaAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(mediaId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllForUser provides a mock function with given fields: userId
 func (_m *MediaAccessRepository) GetAllForUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, error) {
 	ret := _m.Called(userId)
@@ -3,7 +3,10 @@
 package mocks
 
 import (
+	model "data-storage-svc/internal/model"
+
 	mock "github.com/stretchr/testify/mock"
+
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 
 	utils "data-storage-svc/internal/utils"
@@ -32,6 +35,70 @@ func (_m *MediaAccessService) CanView(userId *primitive.ObjectID, mediaId *primi
 	return r0
 }
 
+// GetAllForMedia provides a mock function with given fields: mediaId
+func (_m *MediaAccessService) GetAllForMedia(mediaId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError) {
+	ret := _m.Called(mediaId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForMedia")
+	}
+
+	var r0 []model.UserMediaAccess
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError)); ok {
+		return rf(mediaId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.UserMediaAccess); ok {
+		r0 = rf(mediaId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserMediaAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(mediaId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllForUser provides a mock function with given fields: userId
+func (_m *MediaAccessService) GetAllForUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForUser")
+	}
+
+	var r0 []model.UserMediaAccess
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.UserMediaAccess); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserMediaAccess)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GrantAccess provides a mock function with given fields: userId, mediaId
 func (_m *MediaAccessService) GrantAccess(userId *primitive.ObjectID, mediaId *primitive.ObjectID) utils.ServiceError {
 	ret := _m.Called(userId, mediaId)
@@ -52,6 +119,26 @@ func (_m *MediaAccessService) GrantAccess(userId *primitive.ObjectID, mediaId *p
 	return r0
 }
 
+// RevokeAccess provides a mock function with given fields: userId, mediaId
+func (_m *MediaAccessService) RevokeAccess(userId *primitive.ObjectID, mediaId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(userId, mediaId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAccess")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(userId, mediaId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
 // RevokeAll provides a mock function with given fields: mediaId
 func (_m *MediaAccessService) RevokeAll(mediaId *primitive.ObjectID) utils.ServiceError {
 	ret := _m.Called(mediaId)
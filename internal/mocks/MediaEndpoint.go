@@ -17,16 +17,31 @@ type MediaEndpoint struct {
 	mock.Mock
 }
 
+// CreateAccess provides a mock function with given fields: c
+func (_m *MediaEndpoint) CreateAccess(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Delete provides a mock function with given fields: c
 func (_m *MediaEndpoint) Delete(c *gin.Context) {
 	_m.Called(c)
 }
 
+// DeleteAccess provides a mock function with given fields: c
+func (_m *MediaEndpoint) DeleteAccess(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Get provides a mock function with given fields: c
 func (_m *MediaEndpoint) Get(c *gin.Context) {
 	_m.Called(c)
 }
 
+// GetAllAccesses provides a mock function with given fields: c
+func (_m *MediaEndpoint) GetAllAccesses(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetCommonMiddlewares provides a mock function with no fields
 func (_m *MediaEndpoint) GetCommonMiddlewares() []gin.HandlerFunc {
 	ret := _m.Called()
@@ -128,11 +143,21 @@ func (_m *MediaEndpoint) GetPermissionsManager() common.PermissionsManager {
 	return r0
 }
 
+// GetSignedUrl provides a mock function with given fields: c
+func (_m *MediaEndpoint) GetSignedUrl(c *gin.Context) {
+	_m.Called(c)
+}
+
 // List provides a mock function with given fields: c
 func (_m *MediaEndpoint) List(c *gin.Context) {
 	_m.Called(c)
 }
 
+// ListShared provides a mock function with given fields: c
+func (_m *MediaEndpoint) ListShared(c *gin.Context) {
+	_m.Called(c)
+}
+
 // PreCreate provides a mock function with given fields: hook
 func (_m *MediaEndpoint) PreCreate(hook handler.HookEvent) (handler.HTTPResponse, handler.FileInfoChanges, error) {
 	ret := _m.Called(hook)
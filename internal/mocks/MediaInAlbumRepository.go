@@ -33,6 +33,36 @@ func (_m *MediaInAlbumRepository) AddMediaToAlbum(mediaInAlbum *model.MediaInAlb
 	return r0
 }
 
+// Get provides a mock function with given fields: mediaId, albumId
+func (_m *MediaInAlbumRepository) Get(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.MediaInAlbum, error) {
+	ret := _m.Called(mediaId, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.MediaInAlbum
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) (*model.MediaInAlbum, error)); ok {
+		return rf(mediaId, albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) *model.MediaInAlbum); ok {
+		r0 = rf(mediaId, albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.MediaInAlbum)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r1 = rf(mediaId, albumId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IsInAlbum provides a mock function with given fields: mediaId, albumId
 func (_m *MediaInAlbumRepository) IsInAlbum(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) bool {
 	ret := _m.Called(mediaId, albumId)
@@ -51,6 +81,36 @@ func (_m *MediaInAlbumRepository) IsInAlbum(mediaId *primitive.ObjectID, albumId
 	return r0
 }
 
+// ListAllAlbums provides a mock function with given fields: mediaId
+func (_m *MediaInAlbumRepository) ListAllAlbums(mediaId *primitive.ObjectID) ([]model.MediaInAlbum, error) {
+	ret := _m.Called(mediaId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAllAlbums")
+	}
+
+	var r0 []model.MediaInAlbum
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.MediaInAlbum, error)); ok {
+		return rf(mediaId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.MediaInAlbum); ok {
+		r0 = rf(mediaId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MediaInAlbum)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(mediaId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListAllMedias provides a mock function with given fields: albumId
 func (_m *MediaInAlbumRepository) ListAllMedias(albumId *primitive.ObjectID) ([]model.MediaInAlbum, error) {
 	ret := _m.Called(albumId)
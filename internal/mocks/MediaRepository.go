@@ -93,6 +93,126 @@ func (_m *MediaRepository) Get(mediaId *primitive.ObjectID) (*model.Media, error
 	return r0, r1
 }
 
+// GetAll provides a mock function with no fields
+func (_m *MediaRepository) GetAll() ([]model.Media, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []model.Media
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Media, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Media); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Media)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllByStorageFileName provides a mock function with given fields: storageFileName
+func (_m *MediaRepository) GetAllByStorageFileName(storageFileName string) ([]model.Media, error) {
+	ret := _m.Called(storageFileName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllByStorageFileName")
+	}
+
+	var r0 []model.Media
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]model.Media, error)); ok {
+		return rf(storageFileName)
+	}
+	if rf, ok := ret.Get(0).(func(string) []model.Media); ok {
+		r0 = rf(storageFileName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Media)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(storageFileName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllChargedTo provides a mock function with given fields: userId
+func (_m *MediaRepository) GetAllChargedTo(userId *primitive.ObjectID) ([]model.Media, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllChargedTo")
+	}
+
+	var r0 []model.Media
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Media, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Media); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Media)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllInTier provides a mock function with given fields: tier
+func (_m *MediaRepository) GetAllInTier(tier model.StorageTier) ([]model.Media, error) {
+	ret := _m.Called(tier)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllInTier")
+	}
+
+	var r0 []model.Media
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.StorageTier) ([]model.Media, error)); ok {
+		return rf(tier)
+	}
+	if rf, ok := ret.Get(0).(func(model.StorageTier) []model.Media); ok {
+		r0 = rf(tier)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Media)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(model.StorageTier) error); ok {
+		r1 = rf(tier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllUploadedBy provides a mock function with given fields: userId
 func (_m *MediaRepository) GetAllUploadedBy(userId *primitive.ObjectID) ([]model.Media, error) {
 	ret := _m.Called(userId)
@@ -123,6 +243,66 @@ func (_m *MediaRepository) GetAllUploadedBy(userId *primitive.ObjectID) ([]model
 	return r0, r1
 }
 
+// GetByHash provides a mock function with given fields: hash, uploader
+func (_m *MediaRepository) GetByHash(hash string, uploader *primitive.ObjectID) (*model.Media, error) {
+	ret := _m.Called(hash, uploader)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.Media
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *primitive.ObjectID) (*model.Media, error)); ok {
+		return rf(hash, uploader)
+	}
+	if rf, ok := ret.Get(0).(func(string, *primitive.ObjectID) *model.Media); ok {
+		r0 = rf(hash, uploader)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Media)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *primitive.ObjectID) error); ok {
+		r1 = rf(hash, uploader)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOneByStorageFileName provides a mock function with given fields: storageFileName
+func (_m *MediaRepository) GetOneByStorageFileName(storageFileName string) (*model.Media, error) {
+	ret := _m.Called(storageFileName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOneByStorageFileName")
+	}
+
+	var r0 *model.Media
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.Media, error)); ok {
+		return rf(storageFileName)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.Media); ok {
+		r0 = rf(storageFileName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Media)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(storageFileName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: mediaId, update
 func (_m *MediaRepository) Update(mediaId *primitive.ObjectID, update primitive.M) error {
 	ret := _m.Called(mediaId, update)
@@ -141,6 +321,24 @@ func (_m *MediaRepository) Update(mediaId *primitive.ObjectID, update primitive.
 	return r0
 }
 
+// UpdateAllByStorageFileName provides a mock function with given fields: storageFileName, update
+func (_m *MediaRepository) UpdateAllByStorageFileName(storageFileName string, update primitive.M) error {
+	ret := _m.Called(storageFileName, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAllByStorageFileName")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, primitive.M) error); ok {
+		r0 = rf(storageFileName, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewMediaRepository creates a new instance of MediaRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMediaRepository(t interface {
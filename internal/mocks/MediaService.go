@@ -4,11 +4,10 @@ package mocks
 
 import (
 	model "data-storage-svc/internal/model"
+	io "io"
 
 	mock "github.com/stretchr/testify/mock"
 
-	os "os"
-
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 
 	time "time"
@@ -21,9 +20,9 @@ type MediaService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: originalFilename, storageFilename, uploader, uploadedViaSharedLink
-func (_m *MediaService) Create(originalFilename string, storageFilename string, uploader *primitive.ObjectID, uploadedViaSharedLink bool) (*primitive.ObjectID, utils.ServiceError) {
-	ret := _m.Called(originalFilename, storageFilename, uploader, uploadedViaSharedLink)
+// Create provides a mock function with given fields: originalFilename, uploader, uploadedViaSharedLink, chargedTo, data
+func (_m *MediaService) Create(originalFilename string, uploader *primitive.ObjectID, uploadedViaSharedLink bool, chargedTo *primitive.ObjectID, data io.ReadCloser) (*primitive.ObjectID, utils.ServiceError) {
+	ret := _m.Called(originalFilename, uploader, uploadedViaSharedLink, chargedTo, data)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -31,19 +30,19 @@ func (_m *MediaService) Create(originalFilename string, storageFilename string,
 
 	var r0 *primitive.ObjectID
 	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(string, string, *primitive.ObjectID, bool) (*primitive.ObjectID, utils.ServiceError)); ok {
-		return rf(originalFilename, storageFilename, uploader, uploadedViaSharedLink)
+	if rf, ok := ret.Get(0).(func(string, *primitive.ObjectID, bool, *primitive.ObjectID, io.ReadCloser) (*primitive.ObjectID, utils.ServiceError)); ok {
+		return rf(originalFilename, uploader, uploadedViaSharedLink, chargedTo, data)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, *primitive.ObjectID, bool) *primitive.ObjectID); ok {
-		r0 = rf(originalFilename, storageFilename, uploader, uploadedViaSharedLink)
+	if rf, ok := ret.Get(0).(func(string, *primitive.ObjectID, bool, *primitive.ObjectID, io.ReadCloser) *primitive.ObjectID); ok {
+		r0 = rf(originalFilename, uploader, uploadedViaSharedLink, chargedTo, data)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*primitive.ObjectID)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, *primitive.ObjectID, bool) utils.ServiceError); ok {
-		r1 = rf(originalFilename, storageFilename, uploader, uploadedViaSharedLink)
+	if rf, ok := ret.Get(1).(func(string, *primitive.ObjectID, bool, *primitive.ObjectID, io.ReadCloser) utils.ServiceError); ok {
+		r1 = rf(originalFilename, uploader, uploadedViaSharedLink, chargedTo, data)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(utils.ServiceError)
@@ -74,23 +73,23 @@ func (_m *MediaService) Delete(mediaId *primitive.ObjectID) utils.ServiceError {
 }
 
 // GetAllSharedWithUser provides a mock function with given fields: userId
-func (_m *MediaService) GetAllSharedWithUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError) {
+func (_m *MediaService) GetAllSharedWithUser(userId *primitive.ObjectID) ([]model.Media, utils.ServiceError) {
 	ret := _m.Called(userId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllSharedWithUser")
 	}
 
-	var r0 []model.UserMediaAccess
+	var r0 []model.Media
 	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.UserMediaAccess, utils.ServiceError)); ok {
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Media, utils.ServiceError)); ok {
 		return rf(userId)
 	}
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.UserMediaAccess); ok {
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Media); ok {
 		r0 = rf(userId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.UserMediaAccess)
+			r0 = ret.Get(0).([]model.Media)
 		}
 	}
 
@@ -169,47 +168,47 @@ func (_m *MediaService) GetById(mediaId *primitive.ObjectID) (*model.Media, util
 	return r0, r1
 }
 
-// GetData provides a mock function with given fields: mediaId, storageFileName, compressed
-func (_m *MediaService) GetData(mediaId *primitive.ObjectID, storageFileName string, compressed bool) (*string, *os.File, *time.Time, utils.ServiceError) {
-	ret := _m.Called(mediaId, storageFileName, compressed)
+// GetData provides a mock function with given fields: media, compressed
+func (_m *MediaService) GetData(media *model.Media, compressed bool) (*string, io.ReadSeekCloser, *time.Time, utils.ServiceError) {
+	ret := _m.Called(media, compressed)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetData")
 	}
 
 	var r0 *string
-	var r1 *os.File
+	var r1 io.ReadSeekCloser
 	var r2 *time.Time
 	var r3 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, bool) (*string, *os.File, *time.Time, utils.ServiceError)); ok {
-		return rf(mediaId, storageFileName, compressed)
+	if rf, ok := ret.Get(0).(func(*model.Media, bool) (*string, io.ReadSeekCloser, *time.Time, utils.ServiceError)); ok {
+		return rf(media, compressed)
 	}
-	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, bool) *string); ok {
-		r0 = rf(mediaId, storageFileName, compressed)
+	if rf, ok := ret.Get(0).(func(*model.Media, bool) *string); ok {
+		r0 = rf(media, compressed)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string, bool) *os.File); ok {
-		r1 = rf(mediaId, storageFileName, compressed)
+	if rf, ok := ret.Get(1).(func(*model.Media, bool) io.ReadSeekCloser); ok {
+		r1 = rf(media, compressed)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*os.File)
+			r1 = ret.Get(1).(io.ReadSeekCloser)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(*primitive.ObjectID, string, bool) *time.Time); ok {
-		r2 = rf(mediaId, storageFileName, compressed)
+	if rf, ok := ret.Get(2).(func(*model.Media, bool) *time.Time); ok {
+		r2 = rf(media, compressed)
 	} else {
 		if ret.Get(2) != nil {
 			r2 = ret.Get(2).(*time.Time)
 		}
 	}
 
-	if rf, ok := ret.Get(3).(func(*primitive.ObjectID, string, bool) utils.ServiceError); ok {
-		r3 = rf(mediaId, storageFileName, compressed)
+	if rf, ok := ret.Get(3).(func(*model.Media, bool) utils.ServiceError); ok {
+		r3 = rf(media, compressed)
 	} else {
 		if ret.Get(3) != nil {
 			r3 = ret.Get(3).(utils.ServiceError)
@@ -251,6 +250,38 @@ func (_m *MediaService) GetMetaData(mediaId *primitive.ObjectID) (*model.MetaDat
 	return r0, r1
 }
 
+// GetStoredFileName provides a mock function with given fields: media, compressed
+func (_m *MediaService) GetStoredFileName(media *model.Media, compressed bool) (*string, utils.ServiceError) {
+	ret := _m.Called(media, compressed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStoredFileName")
+	}
+
+	var r0 *string
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*model.Media, bool) (*string, utils.ServiceError)); ok {
+		return rf(media, compressed)
+	}
+	if rf, ok := ret.Get(0).(func(*model.Media, bool) *string); ok {
+		r0 = rf(media, compressed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.Media, bool) utils.ServiceError); ok {
+		r1 = rf(media, compressed)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
 // IsInAlbum provides a mock function with given fields: mediaId, albumId
 func (_m *MediaService) IsInAlbum(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) bool {
 	ret := _m.Called(mediaId, albumId)
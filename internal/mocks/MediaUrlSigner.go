@@ -0,0 +1,82 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MediaUrlSigner is an autogenerated mock type for the MediaUrlSigner type
+type MediaUrlSigner struct {
+	mock.Mock
+}
+
+// Expiry provides a mock function with given fields: now
+func (_m *MediaUrlSigner) Expiry(now time.Time) time.Time {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Expiry")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(time.Time) time.Time); ok {
+		r0 = rf(now)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// Sign provides a mock function with given fields: mediaId, rendition, fileName, expiry
+func (_m *MediaUrlSigner) Sign(mediaId string, rendition string, fileName string, expiry time.Time) string {
+	ret := _m.Called(mediaId, rendition, fileName, expiry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sign")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time) string); ok {
+		r0 = rf(mediaId, rendition, fileName, expiry)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Verify provides a mock function with given fields: mediaId, rendition, fileName, expiry, signature, now
+func (_m *MediaUrlSigner) Verify(mediaId string, rendition string, fileName string, expiry time.Time, signature string, now time.Time) bool {
+	ret := _m.Called(mediaId, rendition, fileName, expiry, signature, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Verify")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time, string, time.Time) bool); ok {
+		r0 = rf(mediaId, rendition, fileName, expiry, signature, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// NewMediaUrlSigner creates a new instance of MediaUrlSigner. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMediaUrlSigner(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MediaUrlSigner {
+	mock := &MediaUrlSigner{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
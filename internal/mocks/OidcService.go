@@ -0,0 +1,132 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	services "data-storage-svc/internal/api/services"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// OidcService is an autogenerated mock type for the OidcService type
+type OidcService struct {
+	mock.Mock
+}
+
+// Enabled provides a mock function with no fields
+func (_m *OidcService) Enabled() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enabled")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// FinishLogin provides a mock function with given fields: code, state, userAgent, ip
+func (_m *OidcService) FinishLogin(code string, state *services.OidcLoginState, userAgent string, ip string) (*model.User, *model.SessionTokens, utils.ServiceError) {
+	ret := _m.Called(code, state, userAgent, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishLogin")
+	}
+
+	var r0 *model.User
+	var r1 *model.SessionTokens
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, *services.OidcLoginState, string, string) (*model.User, *model.SessionTokens, utils.ServiceError)); ok {
+		return rf(code, state, userAgent, ip)
+	}
+	if rf, ok := ret.Get(0).(func(string, *services.OidcLoginState, string, string) *model.User); ok {
+		r0 = rf(code, state, userAgent, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *services.OidcLoginState, string, string) *model.SessionTokens); ok {
+		r1 = rf(code, state, userAgent, ip)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.SessionTokens)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string, *services.OidcLoginState, string, string) utils.ServiceError); ok {
+		r2 = rf(code, state, userAgent, ip)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// StartLogin provides a mock function with no fields
+func (_m *OidcService) StartLogin() (*string, *services.OidcLoginState, utils.ServiceError) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartLogin")
+	}
+
+	var r0 *string
+	var r1 *services.OidcLoginState
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func() (*string, *services.OidcLoginState, utils.ServiceError)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *services.OidcLoginState); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*services.OidcLoginState)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func() utils.ServiceError); ok {
+		r2 = rf()
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// NewOidcService creates a new instance of OidcService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOidcService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OidcService {
+	mock := &OidcService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
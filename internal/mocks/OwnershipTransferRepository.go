@@ -0,0 +1,234 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OwnershipTransferRepository is an autogenerated mock type for the OwnershipTransferRepository type
+type OwnershipTransferRepository struct {
+	mock.Mock
+}
+
+// CancelAllPendingForUser provides a mock function with given fields: userId
+func (_m *OwnershipTransferRepository) CancelAllPendingForUser(userId *primitive.ObjectID) error {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelAllPendingForUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: transfer
+func (_m *OwnershipTransferRepository) Create(transfer *model.OwnershipTransfer) (*primitive.ObjectID, error) {
+	ret := _m.Called(transfer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.OwnershipTransfer) (*primitive.ObjectID, error)); ok {
+		return rf(transfer)
+	}
+	if rf, ok := ret.Get(0).(func(*model.OwnershipTransfer) *primitive.ObjectID); ok {
+		r0 = rf(transfer)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.OwnershipTransfer) error); ok {
+		r1 = rf(transfer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: transferId
+func (_m *OwnershipTransferRepository) Get(transferId *primitive.ObjectID) (*model.OwnershipTransfer, error) {
+	ret := _m.Called(transferId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.OwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.OwnershipTransfer, error)); ok {
+		return rf(transferId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.OwnershipTransfer); ok {
+		r0 = rf(transferId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(transferId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllByAlbum provides a mock function with given fields: albumId
+func (_m *OwnershipTransferRepository) GetAllByAlbum(albumId *primitive.ObjectID) ([]model.OwnershipTransfer, error) {
+	ret := _m.Called(albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllByAlbum")
+	}
+
+	var r0 []model.OwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.OwnershipTransfer, error)); ok {
+		return rf(albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.OwnershipTransfer); ok {
+		r0 = rf(albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(albumId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllPendingForUser provides a mock function with given fields: userId
+func (_m *OwnershipTransferRepository) GetAllPendingForUser(userId *primitive.ObjectID) ([]model.OwnershipTransfer, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllPendingForUser")
+	}
+
+	var r0 []model.OwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.OwnershipTransfer, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.OwnershipTransfer); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingForAlbum provides a mock function with given fields: albumId
+func (_m *OwnershipTransferRepository) GetPendingForAlbum(albumId *primitive.ObjectID) (*model.OwnershipTransfer, error) {
+	ret := _m.Called(albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingForAlbum")
+	}
+
+	var r0 *model.OwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.OwnershipTransfer, error)); ok {
+		return rf(albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.OwnershipTransfer); ok {
+		r0 = rf(albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(albumId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reopen provides a mock function with given fields: transferId
+func (_m *OwnershipTransferRepository) Reopen(transferId *primitive.ObjectID) error {
+	ret := _m.Called(transferId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reopen")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(transferId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Resolve provides a mock function with given fields: transferId, status, resolvedBy, transferredMedias
+func (_m *OwnershipTransferRepository) Resolve(transferId *primitive.ObjectID, status model.OwnershipTransferStatus, resolvedBy *primitive.ObjectID, transferredMedias int) error {
+	ret := _m.Called(transferId, status, resolvedBy, transferredMedias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resolve")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, model.OwnershipTransferStatus, *primitive.ObjectID, int) error); ok {
+		r0 = rf(transferId, status, resolvedBy, transferredMedias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOwnershipTransferRepository creates a new instance of OwnershipTransferRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOwnershipTransferRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OwnershipTransferRepository {
+	mock := &OwnershipTransferRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
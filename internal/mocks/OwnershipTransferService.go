@@ -0,0 +1,232 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// OwnershipTransferService is an autogenerated mock type for the OwnershipTransferService type
+type OwnershipTransferService struct {
+	mock.Mock
+}
+
+// Accept provides a mock function with given fields: transferId, userId
+func (_m *OwnershipTransferService) Accept(transferId *primitive.ObjectID, userId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError) {
+	ret := _m.Called(transferId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Accept")
+	}
+
+	var r0 *model.OwnershipTransfer
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError)); ok {
+		return rf(transferId, userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) *model.OwnershipTransfer); ok {
+		r0 = rf(transferId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(transferId, userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Cancel provides a mock function with given fields: transferId, userId
+func (_m *OwnershipTransferService) Cancel(transferId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(transferId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Cancel")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(transferId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// Decline provides a mock function with given fields: transferId, userId
+func (_m *OwnershipTransferService) Decline(transferId *primitive.ObjectID, userId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(transferId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decline")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(transferId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// GetAllForAlbum provides a mock function with given fields: albumId
+func (_m *OwnershipTransferService) GetAllForAlbum(albumId *primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError) {
+	ret := _m.Called(albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForAlbum")
+	}
+
+	var r0 []model.OwnershipTransfer
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError)); ok {
+		return rf(albumId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.OwnershipTransfer); ok {
+		r0 = rf(albumId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(albumId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllPendingForUser provides a mock function with given fields: userId
+func (_m *OwnershipTransferService) GetAllPendingForUser(userId *primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllPendingForUser")
+	}
+
+	var r0 []model.OwnershipTransfer
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.OwnershipTransfer, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.OwnershipTransfer); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: transferId
+func (_m *OwnershipTransferService) GetById(transferId *primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError) {
+	ret := _m.Called(transferId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *model.OwnershipTransfer
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.OwnershipTransfer, utils.ServiceError)); ok {
+		return rf(transferId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.OwnershipTransfer); ok {
+		r0 = rf(transferId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(transferId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Propose provides a mock function with given fields: proposedBy, albumId, toUserId, includeMedias
+func (_m *OwnershipTransferService) Propose(proposedBy *primitive.ObjectID, albumId *primitive.ObjectID, toUserId *primitive.ObjectID, includeMedias bool) (*model.OwnershipTransfer, utils.ServiceError) {
+	ret := _m.Called(proposedBy, albumId, toUserId, includeMedias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Propose")
+	}
+
+	var r0 *model.OwnershipTransfer
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, *primitive.ObjectID, bool) (*model.OwnershipTransfer, utils.ServiceError)); ok {
+		return rf(proposedBy, albumId, toUserId, includeMedias)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID, *primitive.ObjectID, bool) *model.OwnershipTransfer); ok {
+		r0 = rf(proposedBy, albumId, toUserId, includeMedias)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID, *primitive.ObjectID, bool) utils.ServiceError); ok {
+		r1 = rf(proposedBy, albumId, toUserId, includeMedias)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewOwnershipTransferService creates a new instance of OwnershipTransferService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOwnershipTransferService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OwnershipTransferService {
+	mock := &OwnershipTransferService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,42 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// PasswordPolicy is an autogenerated mock type for the PasswordPolicy type
+type PasswordPolicy struct {
+	mock.Mock
+}
+
+// Check provides a mock function with given fields: password, email
+func (_m *PasswordPolicy) Check(password string, email string) error {
+	ret := _m.Called(password, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Check")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(password, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPasswordPolicy creates a new instance of PasswordPolicy. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPasswordPolicy(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PasswordPolicy {
+	mock := &PasswordPolicy{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,68 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// PasswordResetService is an autogenerated mock type for the PasswordResetService type
+type PasswordResetService struct {
+	mock.Mock
+}
+
+// RequestReset provides a mock function with given fields: email
+func (_m *PasswordResetService) RequestReset(email string) utils.ServiceError {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestReset")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string) utils.ServiceError); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// Reset provides a mock function with given fields: token, newPassword
+func (_m *PasswordResetService) Reset(token string, newPassword string) utils.ServiceError {
+	ret := _m.Called(token, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reset")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string) utils.ServiceError); ok {
+		r0 = rf(token, newPassword)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewPasswordResetService creates a new instance of PasswordResetService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPasswordResetService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PasswordResetService {
+	mock := &PasswordResetService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -15,6 +15,96 @@ type PermissionsManager struct {
 	mock.Mock
 }
 
+// CanAddMediaToAlbum provides a mock function with given fields: user, albumId, mediaId, sharedLink
+func (_m *PermissionsManager) CanAddMediaToAlbum(user *model.User, albumId *primitive.ObjectID, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	ret := _m.Called(user, albumId, mediaId, sharedLink)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanAddMediaToAlbum")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *primitive.ObjectID, *model.SharedLink) bool); ok {
+		r0 = rf(user, albumId, mediaId, sharedLink)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanAnswerTransfer provides a mock function with given fields: user, transfer
+func (_m *PermissionsManager) CanAnswerTransfer(user *model.User, transfer *model.OwnershipTransfer) bool {
+	ret := _m.Called(user, transfer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanAnswerTransfer")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.OwnershipTransfer) bool); ok {
+		r0 = rf(user, transfer)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanCancelTransfer provides a mock function with given fields: user, transfer
+func (_m *PermissionsManager) CanCancelTransfer(user *model.User, transfer *model.OwnershipTransfer) bool {
+	ret := _m.Called(user, transfer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanCancelTransfer")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.OwnershipTransfer) bool); ok {
+		r0 = rf(user, transfer)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanChangePassword provides a mock function with given fields: user, apiKey
+func (_m *PermissionsManager) CanChangePassword(user *model.User, apiKey *model.ApiKey) bool {
+	ret := _m.Called(user, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanChangePassword")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.ApiKey) bool); ok {
+		r0 = rf(user, apiKey)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanCheckStorage provides a mock function with given fields: user
+func (_m *PermissionsManager) CanCheckStorage(user *model.User) bool {
+	ret := _m.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanCheckStorage")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User) bool); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // CanConsumeDownload provides a mock function with given fields: user, downloadId, sharedLink
 func (_m *PermissionsManager) CanConsumeDownload(user *model.User, downloadId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
 	ret := _m.Called(user, downloadId, sharedLink)
@@ -51,6 +141,24 @@ func (_m *PermissionsManager) CanCreateAlbum(user *model.User) bool {
 	return r0
 }
 
+// CanCreateGroup provides a mock function with given fields: user
+func (_m *PermissionsManager) CanCreateGroup(user *model.User) bool {
+	ret := _m.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanCreateGroup")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User) bool); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // CanCreateMedia provides a mock function with given fields: user, sharedLink
 func (_m *PermissionsManager) CanCreateMedia(user *model.User, sharedLink *model.SharedLink) bool {
 	ret := _m.Called(user, sharedLink)
@@ -177,17 +285,53 @@ func (_m *PermissionsManager) CanEditAlbumAccesses(user *model.User, albumId *pr
 	return r0
 }
 
-// CanEditMediasInAlbum provides a mock function with given fields: user, albumId, sharedLink
-func (_m *PermissionsManager) CanEditMediasInAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	ret := _m.Called(user, albumId, sharedLink)
+// CanEditAlbumVisibility provides a mock function with given fields: user, albumId
+func (_m *PermissionsManager) CanEditAlbumVisibility(user *model.User, albumId *primitive.ObjectID) bool {
+	ret := _m.Called(user, albumId)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CanEditMediasInAlbum")
+		panic("no return value specified for CanEditAlbumVisibility")
 	}
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *model.SharedLink) bool); ok {
-		r0 = rf(user, albumId, sharedLink)
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, albumId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanEditGroup provides a mock function with given fields: user, groupId
+func (_m *PermissionsManager) CanEditGroup(user *model.User, groupId *primitive.ObjectID) bool {
+	ret := _m.Called(user, groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanEditGroup")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, groupId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanEditProfile provides a mock function with given fields: user, apiKey
+func (_m *PermissionsManager) CanEditProfile(user *model.User, apiKey *model.ApiKey) bool {
+	ret := _m.Called(user, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanEditProfile")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.ApiKey) bool); ok {
+		r0 = rf(user, apiKey)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -249,6 +393,24 @@ func (_m *PermissionsManager) CanGetDownload(user *model.User, downloadId *primi
 	return r0
 }
 
+// CanGetGroup provides a mock function with given fields: user, groupId
+func (_m *PermissionsManager) CanGetGroup(user *model.User, groupId *primitive.ObjectID) bool {
+	ret := _m.Called(user, groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanGetGroup")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, groupId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // CanGetMedia provides a mock function with given fields: user, mediaId, sharedLink
 func (_m *PermissionsManager) CanGetMedia(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
 	ret := _m.Called(user, mediaId, sharedLink)
@@ -267,17 +429,89 @@ func (_m *PermissionsManager) CanGetMedia(user *model.User, mediaId *primitive.O
 	return r0
 }
 
-// CanInitDownloadForAlbum provides a mock function with given fields: user, albumId, sharedLink
-func (_m *PermissionsManager) CanInitDownloadForAlbum(user *model.User, albumId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
-	ret := _m.Called(user, albumId, sharedLink)
+// CanGetMetaData provides a mock function with given fields: user, mediaId, sharedLink
+func (_m *PermissionsManager) CanGetMetaData(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	ret := _m.Called(user, mediaId, sharedLink)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CanInitDownloadForAlbum")
+		panic("no return value specified for CanGetMetaData")
 	}
 
 	var r0 bool
 	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *model.SharedLink) bool); ok {
-		r0 = rf(user, albumId, sharedLink)
+		r0 = rf(user, mediaId, sharedLink)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanGetOriginal provides a mock function with given fields: user, mediaId, sharedLink
+func (_m *PermissionsManager) CanGetOriginal(user *model.User, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	ret := _m.Called(user, mediaId, sharedLink)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanGetOriginal")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *model.SharedLink) bool); ok {
+		r0 = rf(user, mediaId, sharedLink)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanGetTransfer provides a mock function with given fields: user, transfer
+func (_m *PermissionsManager) CanGetTransfer(user *model.User, transfer *model.OwnershipTransfer) bool {
+	ret := _m.Called(user, transfer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanGetTransfer")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.OwnershipTransfer) bool); ok {
+		r0 = rf(user, transfer)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanInitDownloadForAlbum provides a mock function with given fields: user, albumId, renditions, sharedLink
+func (_m *PermissionsManager) CanInitDownloadForAlbum(user *model.User, albumId *primitive.ObjectID, renditions bool, sharedLink *model.SharedLink) bool {
+	ret := _m.Called(user, albumId, renditions, sharedLink)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanInitDownloadForAlbum")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, bool, *model.SharedLink) bool); ok {
+		r0 = rf(user, albumId, renditions, sharedLink)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanInviteUser provides a mock function with given fields: user, albumId
+func (_m *PermissionsManager) CanInviteUser(user *model.User, albumId *primitive.ObjectID) bool {
+	ret := _m.Called(user, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanInviteUser")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, albumId)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -303,6 +537,24 @@ func (_m *PermissionsManager) CanListAlbumAccesses(user *model.User, albumId *pr
 	return r0
 }
 
+// CanListAlbumTransfers provides a mock function with given fields: user, albumId
+func (_m *PermissionsManager) CanListAlbumTransfers(user *model.User, albumId *primitive.ObjectID) bool {
+	ret := _m.Called(user, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanListAlbumTransfers")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, albumId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // CanListSharedLinks provides a mock function with given fields: user, albumId
 func (_m *PermissionsManager) CanListSharedLinks(user *model.User, albumId *primitive.ObjectID) bool {
 	ret := _m.Called(user, albumId)
@@ -339,6 +591,204 @@ func (_m *PermissionsManager) CanListUsers(user *model.User) bool {
 	return r0
 }
 
+// CanManageApiKeys provides a mock function with given fields: user, apiKey
+func (_m *PermissionsManager) CanManageApiKeys(user *model.User, apiKey *model.ApiKey) bool {
+	ret := _m.Called(user, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanManageApiKeys")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.ApiKey) bool); ok {
+		r0 = rf(user, apiKey)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanManageQuotas provides a mock function with given fields: user
+func (_m *PermissionsManager) CanManageQuotas(user *model.User) bool {
+	ret := _m.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanManageQuotas")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User) bool); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanManageSessions provides a mock function with given fields: user, apiKey
+func (_m *PermissionsManager) CanManageSessions(user *model.User, apiKey *model.ApiKey) bool {
+	ret := _m.Called(user, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanManageSessions")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.ApiKey) bool); ok {
+		r0 = rf(user, apiKey)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanManageTwoFactor provides a mock function with given fields: user, apiKey
+func (_m *PermissionsManager) CanManageTwoFactor(user *model.User, apiKey *model.ApiKey) bool {
+	ret := _m.Called(user, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanManageTwoFactor")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *model.ApiKey) bool); ok {
+		r0 = rf(user, apiKey)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanManageUser provides a mock function with given fields: user, userId
+func (_m *PermissionsManager) CanManageUser(user *model.User, userId *primitive.ObjectID) bool {
+	ret := _m.Called(user, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanManageUser")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanProposeAlbumTransfer provides a mock function with given fields: user, albumId
+func (_m *PermissionsManager) CanProposeAlbumTransfer(user *model.User, albumId *primitive.ObjectID) bool {
+	ret := _m.Called(user, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanProposeAlbumTransfer")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, albumId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanRemoveGroupMember provides a mock function with given fields: user, groupId, memberId
+func (_m *PermissionsManager) CanRemoveGroupMember(user *model.User, groupId *primitive.ObjectID, memberId *primitive.ObjectID) bool {
+	ret := _m.Called(user, groupId, memberId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanRemoveGroupMember")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, groupId, memberId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanRemoveMediaFromAlbum provides a mock function with given fields: user, albumId, mediaId, sharedLink
+func (_m *PermissionsManager) CanRemoveMediaFromAlbum(user *model.User, albumId *primitive.ObjectID, mediaId *primitive.ObjectID, sharedLink *model.SharedLink) bool {
+	ret := _m.Called(user, albumId, mediaId, sharedLink)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanRemoveMediaFromAlbum")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *primitive.ObjectID, *model.SharedLink) bool); ok {
+		r0 = rf(user, albumId, mediaId, sharedLink)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanRevokeMediaAccess provides a mock function with given fields: user, mediaId, userId
+func (_m *PermissionsManager) CanRevokeMediaAccess(user *model.User, mediaId *primitive.ObjectID, userId *primitive.ObjectID) bool {
+	ret := _m.Called(user, mediaId, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanRevokeMediaAccess")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, mediaId, userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanShareAlbumWithGroup provides a mock function with given fields: user, albumId, groupId
+func (_m *PermissionsManager) CanShareAlbumWithGroup(user *model.User, albumId *primitive.ObjectID, groupId *primitive.ObjectID) bool {
+	ret := _m.Called(user, albumId, groupId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanShareAlbumWithGroup")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, albumId, groupId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CanShareMedia provides a mock function with given fields: user, mediaId
+func (_m *PermissionsManager) CanShareMedia(user *model.User, mediaId *primitive.ObjectID) bool {
+	ret := _m.Called(user, mediaId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanShareMedia")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) bool); ok {
+		r0 = rf(user, mediaId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // CanUpdateSharedLink provides a mock function with given fields: user, sharedLink
 func (_m *PermissionsManager) CanUpdateSharedLink(user *model.User, sharedLink *model.SharedLink) bool {
 	ret := _m.Called(user, sharedLink)
@@ -357,6 +807,24 @@ func (_m *PermissionsManager) CanUpdateSharedLink(user *model.User, sharedLink *
 	return r0
 }
 
+// GetAlbumRole provides a mock function with given fields: user, albumId
+func (_m *PermissionsManager) GetAlbumRole(user *model.User, albumId *primitive.ObjectID) model.AlbumRole {
+	ret := _m.Called(user, albumId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAlbumRole")
+	}
+
+	var r0 model.AlbumRole
+	if rf, ok := ret.Get(0).(func(*model.User, *primitive.ObjectID) model.AlbumRole); ok {
+		r0 = rf(user, albumId)
+	} else {
+		r0 = ret.Get(0).(model.AlbumRole)
+	}
+
+	return r0
+}
+
 // NewPermissionsManager creates a new instance of PermissionsManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPermissionsManager(t interface {
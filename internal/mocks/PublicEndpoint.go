@@ -0,0 +1,141 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	common "data-storage-svc/internal/api/common"
+
+	gin "github.com/gin-gonic/gin"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PublicEndpoint is an autogenerated mock type for the PublicEndpoint type
+type PublicEndpoint struct {
+	mock.Mock
+}
+
+// GetAlbum provides a mock function with given fields: c
+func (_m *PublicEndpoint) GetAlbum(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetCommonMiddlewares provides a mock function with no fields
+func (_m *PublicEndpoint) GetCommonMiddlewares() []gin.HandlerFunc {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommonMiddlewares")
+	}
+
+	var r0 []gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func() []gin.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetDirectory provides a mock function with given fields: c
+func (_m *PublicEndpoint) GetDirectory(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetEndpointName provides a mock function with no fields
+func (_m *PublicEndpoint) GetEndpointName() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointName")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetEndpointsList provides a mock function with no fields
+func (_m *PublicEndpoint) GetEndpointsList() map[common.MethodPath][]gin.HandlerFunc {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointsList")
+	}
+
+	var r0 map[common.MethodPath][]gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func() map[common.MethodPath][]gin.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[common.MethodPath][]gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetGallery provides a mock function with given fields: c
+func (_m *PublicEndpoint) GetGallery(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetGroupUrl provides a mock function with no fields
+func (_m *PublicEndpoint) GetGroupUrl() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroupUrl")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetPermissionsManager provides a mock function with no fields
+func (_m *PublicEndpoint) GetPermissionsManager() common.PermissionsManager {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermissionsManager")
+	}
+
+	var r0 common.PermissionsManager
+	if rf, ok := ret.Get(0).(func() common.PermissionsManager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(common.PermissionsManager)
+		}
+	}
+
+	return r0
+}
+
+// NewPublicEndpoint creates a new instance of PublicEndpoint. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPublicEndpoint(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PublicEndpoint {
+	mock := &PublicEndpoint{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,176 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// QuotaService is an autogenerated mock type for the QuotaService type
+type QuotaService struct {
+	mock.Mock
+}
+
+// Charge provides a mock function with given fields: userId, bytes, files
+func (_m *QuotaService) Charge(userId *primitive.ObjectID, bytes int64, files int64) utils.ServiceError {
+	ret := _m.Called(userId, bytes, files)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Charge")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, int64, int64) utils.ServiceError); ok {
+		r0 = rf(userId, bytes, files)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// CheckQuota provides a mock function with given fields: userId, size
+func (_m *QuotaService) CheckQuota(userId *primitive.ObjectID, size int64) utils.ServiceError {
+	ret := _m.Called(userId, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckQuota")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, int64) utils.ServiceError); ok {
+		r0 = rf(userId, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// GetDefaultQuota provides a mock function with no fields
+func (_m *QuotaService) GetDefaultQuota() (*model.StorageQuota, utils.ServiceError) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDefaultQuota")
+	}
+
+	var r0 *model.StorageQuota
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func() (*model.StorageQuota, utils.ServiceError)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *model.StorageQuota); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.StorageQuota)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() utils.ServiceError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUsage provides a mock function with given fields: userId
+func (_m *QuotaService) GetUsage(userId *primitive.ObjectID) (*model.UserUsage, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsage")
+	}
+
+	var r0 *model.UserUsage
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.UserUsage, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.UserUsage); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// SetDefaultQuota provides a mock function with given fields: quota
+func (_m *QuotaService) SetDefaultQuota(quota model.StorageQuota) utils.ServiceError {
+	ret := _m.Called(quota)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDefaultQuota")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(model.StorageQuota) utils.ServiceError); ok {
+		r0 = rf(quota)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// SetUserQuota provides a mock function with given fields: userId, quota
+func (_m *QuotaService) SetUserQuota(userId *primitive.ObjectID, quota *model.StorageQuota) utils.ServiceError {
+	ret := _m.Called(userId, quota)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserQuota")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *model.StorageQuota) utils.ServiceError); ok {
+		r0 = rf(userId, quota)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewQuotaService creates a new instance of QuotaService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQuotaService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *QuotaService {
+	mock := &QuotaService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,176 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	time "time"
+)
+
+// SessionRepository is an autogenerated mock type for the SessionRepository type
+type SessionRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: session
+func (_m *SessionRepository) Create(session *model.Session) (*primitive.ObjectID, error) {
+	ret := _m.Called(session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.Session) (*primitive.ObjectID, error)); ok {
+		return rf(session)
+	}
+	if rf, ok := ret.Get(0).(func(*model.Session) *primitive.ObjectID); ok {
+		r0 = rf(session)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.Session) error); ok {
+		r1 = rf(session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: userId, sessionId
+func (_m *SessionRepository) Delete(userId *primitive.ObjectID, sessionId *primitive.ObjectID) error {
+	ret := _m.Called(userId, sessionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(userId, sessionId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllForUser provides a mock function with given fields: userId
+func (_m *SessionRepository) DeleteAllForUser(userId *primitive.ObjectID) error {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllForUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllForUser provides a mock function with given fields: userId
+func (_m *SessionRepository) GetAllForUser(userId *primitive.ObjectID) ([]model.Session, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForUser")
+	}
+
+	var r0 []model.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Session, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Session); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: sessionId
+func (_m *SessionRepository) GetById(sessionId *primitive.ObjectID) (*model.Session, error) {
+	ret := _m.Called(sessionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *model.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*model.Session, error)); ok {
+		return rf(sessionId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *model.Session); ok {
+		r0 = rf(sessionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(sessionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Rotate provides a mock function with given fields: sessionId, currentHash, newHash, userAgent, ip, expiresAt
+func (_m *SessionRepository) Rotate(sessionId *primitive.ObjectID, currentHash string, newHash string, userAgent string, ip string, expiresAt time.Time) error {
+	ret := _m.Called(sessionId, currentHash, newHash, userAgent, ip, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rotate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, string, string, string, time.Time) error); ok {
+		r0 = rf(sessionId, currentHash, newHash, userAgent, ip, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSessionRepository creates a new instance of SessionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionRepository {
+	mock := &SessionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,188 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// SessionService is an autogenerated mock type for the SessionService type
+type SessionService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: user, userAgent, ip
+func (_m *SessionService) Create(user *model.User, userAgent string, ip string) (*model.SessionTokens, utils.ServiceError) {
+	ret := _m.Called(user, userAgent, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *model.SessionTokens
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*model.User, string, string) (*model.SessionTokens, utils.ServiceError)); ok {
+		return rf(user, userAgent, ip)
+	}
+	if rf, ok := ret.Get(0).(func(*model.User, string, string) *model.SessionTokens); ok {
+		r0 = rf(user, userAgent, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SessionTokens)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.User, string, string) utils.ServiceError); ok {
+		r1 = rf(user, userAgent, ip)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllForUser provides a mock function with given fields: userId
+func (_m *SessionService) GetAllForUser(userId *primitive.ObjectID) ([]model.Session, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForUser")
+	}
+
+	var r0 []model.Session
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) ([]model.Session, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) []model.Session); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Refresh provides a mock function with given fields: refreshToken, userAgent, ip
+func (_m *SessionService) Refresh(refreshToken string, userAgent string, ip string) (*model.SessionTokens, utils.ServiceError) {
+	ret := _m.Called(refreshToken, userAgent, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *model.SessionTokens
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string, string) (*model.SessionTokens, utils.ServiceError)); ok {
+		return rf(refreshToken, userAgent, ip)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) *model.SessionTokens); ok {
+		r0 = rf(refreshToken, userAgent, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SessionTokens)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) utils.ServiceError); ok {
+		r1 = rf(refreshToken, userAgent, ip)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: userId, sessionId
+func (_m *SessionService) Revoke(userId *primitive.ObjectID, sessionId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(userId, sessionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(userId, sessionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// RevokeAll provides a mock function with given fields: userId
+func (_m *SessionService) RevokeAll(userId *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAll")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// RevokeByRefreshToken provides a mock function with given fields: refreshToken
+func (_m *SessionService) RevokeByRefreshToken(refreshToken string) utils.ServiceError {
+	ret := _m.Called(refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeByRefreshToken")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string) utils.ServiceError); ok {
+		r0 = rf(refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewSessionService creates a new instance of SessionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionService {
+	mock := &SessionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,78 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SettingsRepository is an autogenerated mock type for the SettingsRepository type
+type SettingsRepository struct {
+	mock.Mock
+}
+
+// Get provides a mock function with no fields
+func (_m *SettingsRepository) Get() (*model.Settings, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.Settings
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*model.Settings, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *model.Settings); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Settings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: update
+func (_m *SettingsRepository) Update(update primitive.M) error {
+	ret := _m.Called(update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.M) error); ok {
+		r0 = rf(update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSettingsRepository creates a new instance of SettingsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSettingsRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SettingsRepository {
+	mock := &SettingsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,91 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// SetupService is an autogenerated mock type for the SetupService type
+type SetupService struct {
+	mock.Mock
+}
+
+// CreateAdmin provides a mock function with given fields: setupToken, email, password
+func (_m *SetupService) CreateAdmin(setupToken string, email string, password string) (*primitive.ObjectID, utils.ServiceError) {
+	ret := _m.Called(setupToken, email, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAdmin")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string, string) (*primitive.ObjectID, utils.ServiceError)); ok {
+		return rf(setupToken, email, password)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) *primitive.ObjectID); ok {
+		r0 = rf(setupToken, email, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) utils.ServiceError); ok {
+		r1 = rf(setupToken, email, password)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// IsPending provides a mock function with no fields
+func (_m *SetupService) IsPending() (bool, utils.ServiceError) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsPending")
+	}
+
+	var r0 bool
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func() (bool, utils.ServiceError)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func() utils.ServiceError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewSetupService creates a new instance of SetupService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSetupService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SetupService {
+	mock := &SetupService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -126,6 +126,11 @@ func (_m *SharedLinkEndpoint) List(c *gin.Context) {
 	_m.Called(c)
 }
 
+// Unlock provides a mock function with given fields: c
+func (_m *SharedLinkEndpoint) Unlock(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Update provides a mock function with given fields: c
 func (_m *SharedLinkEndpoint) Update(c *gin.Context) {
 	_m.Called(c)
@@ -8,6 +8,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	time "time"
 )
 
 // SharedLinkRepository is an autogenerated mock type for the SharedLinkRepository type
@@ -63,6 +65,24 @@ func (_m *SharedLinkRepository) Delete(sharedLinkId primitive.ObjectID) error {
 	return r0
 }
 
+// DeleteAllCreatedBy provides a mock function with given fields: userId
+func (_m *SharedLinkRepository) DeleteAllCreatedBy(userId *primitive.ObjectID) error {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllCreatedBy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: sharedLinkId
 func (_m *SharedLinkRepository) Get(sharedLinkId *primitive.ObjectID) (*model.SharedLink, error) {
 	ret := _m.Called(sharedLinkId)
@@ -153,17 +173,35 @@ func (_m *SharedLinkRepository) List(albumId *primitive.ObjectID) ([]model.Share
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: sharedLinkId, canEdit
-func (_m *SharedLinkRepository) Update(sharedLinkId primitive.ObjectID, canEdit bool) error {
-	ret := _m.Called(sharedLinkId, canEdit)
+// ReassignCreator provides a mock function with given fields: previousCreator, newCreator
+func (_m *SharedLinkRepository) ReassignCreator(previousCreator *primitive.ObjectID, newCreator *primitive.ObjectID) error {
+	ret := _m.Called(previousCreator, newCreator)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReassignCreator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r0 = rf(previousCreator, newCreator)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: sharedLinkId, update
+func (_m *SharedLinkRepository) Update(sharedLinkId primitive.ObjectID, update primitive.M) error {
+	ret := _m.Called(sharedLinkId, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID, bool) error); ok {
-		r0 = rf(sharedLinkId, canEdit)
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, primitive.M) error); ok {
+		r0 = rf(sharedLinkId, update)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -171,6 +209,36 @@ func (_m *SharedLinkRepository) Update(sharedLinkId primitive.ObjectID, canEdit
 	return r0
 }
 
+// Use provides a mock function with given fields: sharedLinkToken, now
+func (_m *SharedLinkRepository) Use(sharedLinkToken string, now time.Time) (*model.SharedLink, error) {
+	ret := _m.Called(sharedLinkToken, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Use")
+	}
+
+	var r0 *model.SharedLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) (*model.SharedLink, error)); ok {
+		return rf(sharedLinkToken, now)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time) *model.SharedLink); ok {
+		r0 = rf(sharedLinkToken, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SharedLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(sharedLinkToken, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewSharedLinkRepository creates a new instance of SharedLinkRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewSharedLinkRepository(t interface {
@@ -9,6 +9,8 @@ import (
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 
+	services "data-storage-svc/internal/api/services"
+
 	time "time"
 
 	utils "data-storage-svc/internal/utils"
@@ -19,9 +21,9 @@ type SharedLinkService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: albumId, createdBy, expirationDate, canEdit
-func (_m *SharedLinkService) Create(albumId primitive.ObjectID, createdBy primitive.ObjectID, expirationDate time.Time, canEdit bool) (*model.SharedLink, utils.ServiceError) {
-	ret := _m.Called(albumId, createdBy, expirationDate, canEdit)
+// Create provides a mock function with given fields: albumId, createdBy, expirationDate, scopes, maxUses, password
+func (_m *SharedLinkService) Create(albumId primitive.ObjectID, createdBy primitive.ObjectID, expirationDate time.Time, scopes []model.SharedLinkScope, maxUses int64, password string) (*model.SharedLink, utils.ServiceError) {
+	ret := _m.Called(albumId, createdBy, expirationDate, scopes, maxUses, password)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -29,19 +31,19 @@ func (_m *SharedLinkService) Create(albumId primitive.ObjectID, createdBy primit
 
 	var r0 *model.SharedLink
 	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID, primitive.ObjectID, time.Time, bool) (*model.SharedLink, utils.ServiceError)); ok {
-		return rf(albumId, createdBy, expirationDate, canEdit)
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, primitive.ObjectID, time.Time, []model.SharedLinkScope, int64, string) (*model.SharedLink, utils.ServiceError)); ok {
+		return rf(albumId, createdBy, expirationDate, scopes, maxUses, password)
 	}
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID, primitive.ObjectID, time.Time, bool) *model.SharedLink); ok {
-		r0 = rf(albumId, createdBy, expirationDate, canEdit)
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, primitive.ObjectID, time.Time, []model.SharedLinkScope, int64, string) *model.SharedLink); ok {
+		r0 = rf(albumId, createdBy, expirationDate, scopes, maxUses, password)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.SharedLink)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(primitive.ObjectID, primitive.ObjectID, time.Time, bool) utils.ServiceError); ok {
-		r1 = rf(albumId, createdBy, expirationDate, canEdit)
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID, primitive.ObjectID, time.Time, []model.SharedLinkScope, int64, string) utils.ServiceError); ok {
+		r1 = rf(albumId, createdBy, expirationDate, scopes, maxUses, password)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(utils.ServiceError)
@@ -135,24 +137,75 @@ func (_m *SharedLinkService) List(albumId primitive.ObjectID) ([]model.SharedLin
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: sharedLinkId, canEdit
-func (_m *SharedLinkService) Update(sharedLinkId primitive.ObjectID, canEdit bool) utils.ServiceError {
-	ret := _m.Called(sharedLinkId, canEdit)
+// Unlock provides a mock function with given fields: token, password
+func (_m *SharedLinkService) Unlock(token string, password string) (*model.SharedLink, string, utils.ServiceError) {
+	ret := _m.Called(token, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unlock")
+	}
+
+	var r0 *model.SharedLink
+	var r1 string
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string) (*model.SharedLink, string, utils.ServiceError)); ok {
+		return rf(token, password)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *model.SharedLink); ok {
+		r0 = rf(token, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SharedLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) string); ok {
+		r1 = rf(token, password)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string) utils.ServiceError); ok {
+		r2 = rf(token, password)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// Update provides a mock function with given fields: sharedLinkId, update
+func (_m *SharedLinkService) Update(sharedLinkId primitive.ObjectID, update services.SharedLinkUpdate) (*model.SharedLink, utils.ServiceError) {
+	ret := _m.Called(sharedLinkId, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
-	var r0 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID, bool) utils.ServiceError); ok {
-		r0 = rf(sharedLinkId, canEdit)
+	var r0 *model.SharedLink
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, services.SharedLinkUpdate) (*model.SharedLink, utils.ServiceError)); ok {
+		return rf(sharedLinkId, update)
+	}
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, services.SharedLinkUpdate) *model.SharedLink); ok {
+		r0 = rf(sharedLinkId, update)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(utils.ServiceError)
+			r0 = ret.Get(0).(*model.SharedLink)
 		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID, services.SharedLinkUpdate) utils.ServiceError); ok {
+		r1 = rf(sharedLinkId, update)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
 }
 
 // NewSharedLinkService creates a new instance of SharedLinkService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
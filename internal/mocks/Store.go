@@ -0,0 +1,132 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	ratelimit "data-storage-svc/internal/ratelimit"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// AddFailure provides a mock function with given fields: key, forgetAfter
+func (_m *Store) AddFailure(key string, forgetAfter time.Duration) int64 {
+	ret := _m.Called(key, forgetAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddFailure")
+	}
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, time.Duration) int64); ok {
+		r0 = rf(key, forgetAfter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	return r0
+}
+
+// Lock provides a mock function with given fields: key, until
+func (_m *Store) Lock(key string, until time.Time) {
+	_m.Called(key, until)
+}
+
+// LockedUntil provides a mock function with given fields: key
+func (_m *Store) LockedUntil(key string) time.Time {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockedUntil")
+	}
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(string) time.Time); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// Peek provides a mock function with given fields: key, limit
+func (_m *Store) Peek(key string, limit ratelimit.Limit) (bool, time.Duration) {
+	ret := _m.Called(key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Peek")
+	}
+
+	var r0 bool
+	var r1 time.Duration
+	if rf, ok := ret.Get(0).(func(string, ratelimit.Limit) (bool, time.Duration)); ok {
+		return rf(key, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, ratelimit.Limit) bool); ok {
+		r0 = rf(key, limit)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, ratelimit.Limit) time.Duration); ok {
+		r1 = rf(key, limit)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	return r0, r1
+}
+
+// Reset provides a mock function with given fields: key
+func (_m *Store) Reset(key string) {
+	_m.Called(key)
+}
+
+// Take provides a mock function with given fields: key, limit
+func (_m *Store) Take(key string, limit ratelimit.Limit) (bool, time.Duration) {
+	ret := _m.Called(key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Take")
+	}
+
+	var r0 bool
+	var r1 time.Duration
+	if rf, ok := ret.Get(0).(func(string, ratelimit.Limit) (bool, time.Duration)); ok {
+		return rf(key, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, ratelimit.Limit) bool); ok {
+		r0 = rf(key, limit)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, ratelimit.Limit) time.Duration); ok {
+		r1 = rf(key, limit)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	return r0, r1
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
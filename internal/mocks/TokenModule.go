@@ -2,16 +2,75 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // TokenModule is an autogenerated mock type for the TokenModule type
 type TokenModule struct {
 	mock.Mock
 }
 
-// CreateToken provides a mock function with given fields: Email
-func (_m *TokenModule) CreateToken(Email *string) (string, error) {
-	ret := _m.Called(Email)
+// CreateLinkSessionToken provides a mock function with given fields: sharedLinkId
+func (_m *TokenModule) CreateLinkSessionToken(sharedLinkId *primitive.ObjectID) (string, error) {
+	ret := _m.Called(sharedLinkId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateLinkSessionToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (string, error)); ok {
+		return rf(sharedLinkId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) string); ok {
+		r0 = rf(sharedLinkId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(sharedLinkId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMfaToken provides a mock function with given fields: userId
+func (_m *TokenModule) CreateMfaToken(userId *primitive.ObjectID) (string, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMfaToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (string, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) string); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateToken provides a mock function with given fields: userId, sessionId
+func (_m *TokenModule) CreateToken(userId *primitive.ObjectID, sessionId *primitive.ObjectID) (string, error) {
+	ret := _m.Called(userId, sessionId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateToken")
@@ -19,17 +78,77 @@ func (_m *TokenModule) CreateToken(Email *string) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*string) (string, error)); ok {
-		return rf(Email)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) (string, error)); ok {
+		return rf(userId, sessionId)
 	}
-	if rf, ok := ret.Get(0).(func(*string) string); ok {
-		r0 = rf(Email)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) string); ok {
+		r0 = rf(userId, sessionId)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(*string) error); ok {
-		r1 = rf(Email)
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, *primitive.ObjectID) error); ok {
+		r1 = rf(userId, sessionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParseLinkSessionToken provides a mock function with given fields: token
+func (_m *TokenModule) ParseLinkSessionToken(token string) (*primitive.ObjectID, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseLinkSessionToken")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*primitive.ObjectID, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) *primitive.ObjectID); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParseMfaToken provides a mock function with given fields: token
+func (_m *TokenModule) ParseMfaToken(token string) (*primitive.ObjectID, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseMfaToken")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*primitive.ObjectID, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) *primitive.ObjectID); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -37,6 +156,45 @@ func (_m *TokenModule) CreateToken(Email *string) (string, error) {
 	return r0, r1
 }
 
+// ParseToken provides a mock function with given fields: token
+func (_m *TokenModule) ParseToken(token string) (*primitive.ObjectID, *primitive.ObjectID, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseToken")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 *primitive.ObjectID
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (*primitive.ObjectID, *primitive.ObjectID, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) *primitive.ObjectID); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *primitive.ObjectID); ok {
+		r1 = rf(token)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // NewTokenModule creates a new instance of TokenModule. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTokenModule(t interface {
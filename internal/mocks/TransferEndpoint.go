@@ -0,0 +1,156 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	common "data-storage-svc/internal/api/common"
+
+	gin "github.com/gin-gonic/gin"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TransferEndpoint is an autogenerated mock type for the TransferEndpoint type
+type TransferEndpoint struct {
+	mock.Mock
+}
+
+// Accept provides a mock function with given fields: c
+func (_m *TransferEndpoint) Accept(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Cancel provides a mock function with given fields: c
+func (_m *TransferEndpoint) Cancel(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Decline provides a mock function with given fields: c
+func (_m *TransferEndpoint) Decline(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetAll provides a mock function with given fields: c
+func (_m *TransferEndpoint) GetAll(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetCommonMiddlewares provides a mock function with no fields
+func (_m *TransferEndpoint) GetCommonMiddlewares() []gin.HandlerFunc {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommonMiddlewares")
+	}
+
+	var r0 []gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func() []gin.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetEndpointName provides a mock function with no fields
+func (_m *TransferEndpoint) GetEndpointName() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointName")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetEndpointsList provides a mock function with no fields
+func (_m *TransferEndpoint) GetEndpointsList() map[common.MethodPath][]gin.HandlerFunc {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointsList")
+	}
+
+	var r0 map[common.MethodPath][]gin.HandlerFunc
+	if rf, ok := ret.Get(0).(func() map[common.MethodPath][]gin.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[common.MethodPath][]gin.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetGroupUrl provides a mock function with no fields
+func (_m *TransferEndpoint) GetGroupUrl() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroupUrl")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetOne provides a mock function with given fields: c
+func (_m *TransferEndpoint) GetOne(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetPermissionsManager provides a mock function with no fields
+func (_m *TransferEndpoint) GetPermissionsManager() common.PermissionsManager {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermissionsManager")
+	}
+
+	var r0 common.PermissionsManager
+	if rf, ok := ret.Get(0).(func() common.PermissionsManager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(common.PermissionsManager)
+		}
+	}
+
+	return r0
+}
+
+// Propose provides a mock function with given fields: c
+func (_m *TransferEndpoint) Propose(c *gin.Context) {
+	_m.Called(c)
+}
+
+// NewTransferEndpoint creates a new instance of TransferEndpoint. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTransferEndpoint(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TransferEndpoint {
+	mock := &TransferEndpoint{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,191 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	security "data-storage-svc/internal/api/security"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// TwoFactorService is an autogenerated mock type for the TwoFactorService type
+type TwoFactorService struct {
+	mock.Mock
+}
+
+// CompleteLogin provides a mock function with given fields: mfaToken, code, userAgent, ip
+func (_m *TwoFactorService) CompleteLogin(mfaToken string, code string, userAgent string, ip string) (*model.User, *model.SessionTokens, utils.ServiceError) {
+	ret := _m.Called(mfaToken, code, userAgent, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteLogin")
+	}
+
+	var r0 *model.User
+	var r1 *model.SessionTokens
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*model.User, *model.SessionTokens, utils.ServiceError)); ok {
+		return rf(mfaToken, code, userAgent, ip)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *model.User); ok {
+		r0 = rf(mfaToken, code, userAgent, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) *model.SessionTokens); ok {
+		r1 = rf(mfaToken, code, userAgent, ip)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.SessionTokens)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, string, string) utils.ServiceError); ok {
+		r2 = rf(mfaToken, code, userAgent, ip)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// ConfirmTotpEnrollment provides a mock function with given fields: userId, code
+func (_m *TwoFactorService) ConfirmTotpEnrollment(userId *primitive.ObjectID, code string) ([]string, utils.ServiceError) {
+	ret := _m.Called(userId, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmTotpEnrollment")
+	}
+
+	var r0 []string
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) ([]string, utils.ServiceError)); ok {
+		return rf(userId, code)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) []string); ok {
+		r0 = rf(userId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string) utils.ServiceError); ok {
+		r1 = rf(userId, code)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DisableTotp provides a mock function with given fields: userId, code
+func (_m *TwoFactorService) DisableTotp(userId *primitive.ObjectID, code string) utils.ServiceError {
+	ret := _m.Called(userId, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableTotp")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) utils.ServiceError); ok {
+		r0 = rf(userId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// RegenerateRecoveryCodes provides a mock function with given fields: userId, code
+func (_m *TwoFactorService) RegenerateRecoveryCodes(userId *primitive.ObjectID, code string) ([]string, utils.ServiceError) {
+	ret := _m.Called(userId, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegenerateRecoveryCodes")
+	}
+
+	var r0 []string
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) ([]string, utils.ServiceError)); ok {
+		return rf(userId, code)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) []string); ok {
+		r0 = rf(userId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string) utils.ServiceError); ok {
+		r1 = rf(userId, code)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// StartTotpEnrollment provides a mock function with given fields: userId
+func (_m *TwoFactorService) StartTotpEnrollment(userId *primitive.ObjectID) (*security.TotpEnrollment, utils.ServiceError) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartTotpEnrollment")
+	}
+
+	var r0 *security.TotpEnrollment
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) (*security.TotpEnrollment, utils.ServiceError)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) *security.TotpEnrollment); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*security.TotpEnrollment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID) utils.ServiceError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewTwoFactorService creates a new instance of TwoFactorService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTwoFactorService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TwoFactorService {
+	mock := &TwoFactorService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
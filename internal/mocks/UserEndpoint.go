@@ -15,16 +15,51 @@ type UserEndpoint struct {
 	mock.Mock
 }
 
+// AcceptInvitation provides a mock function with given fields: c
+func (_m *UserEndpoint) AcceptInvitation(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ChangePassword provides a mock function with given fields: c
+func (_m *UserEndpoint) ChangePassword(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ConfirmTotpEnrollment provides a mock function with given fields: c
+func (_m *UserEndpoint) ConfirmTotpEnrollment(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Create provides a mock function with given fields: c
 func (_m *UserEndpoint) Create(c *gin.Context) {
 	_m.Called(c)
 }
 
+// CreateApiKey provides a mock function with given fields: c
+func (_m *UserEndpoint) CreateApiKey(c *gin.Context) {
+	_m.Called(c)
+}
+
+// DisableTotp provides a mock function with given fields: c
+func (_m *UserEndpoint) DisableTotp(c *gin.Context) {
+	_m.Called(c)
+}
+
 // FetchToken provides a mock function with given fields: c
 func (_m *UserEndpoint) FetchToken(c *gin.Context) {
 	_m.Called(c)
 }
 
+// FetchTokenSecondFactor provides a mock function with given fields: c
+func (_m *UserEndpoint) FetchTokenSecondFactor(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ForgotPassword provides a mock function with given fields: c
+func (_m *UserEndpoint) ForgotPassword(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetCommonMiddlewares provides a mock function with no fields
 func (_m *UserEndpoint) GetCommonMiddlewares() []gin.HandlerFunc {
 	ret := _m.Called()
@@ -101,6 +136,11 @@ func (_m *UserEndpoint) GetGroupUrl() string {
 	return r0
 }
 
+// GetMe provides a mock function with given fields: c
+func (_m *UserEndpoint) GetMe(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetPermissionsManager provides a mock function with no fields
 func (_m *UserEndpoint) GetPermissionsManager() common.PermissionsManager {
 	ret := _m.Called()
@@ -121,21 +161,91 @@ func (_m *UserEndpoint) GetPermissionsManager() common.PermissionsManager {
 	return r0
 }
 
+// GetUsage provides a mock function with given fields: c
+func (_m *UserEndpoint) GetUsage(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Invite provides a mock function with given fields: c
+func (_m *UserEndpoint) Invite(c *gin.Context) {
+	_m.Called(c)
+}
+
 // List provides a mock function with given fields: c
 func (_m *UserEndpoint) List(c *gin.Context) {
 	_m.Called(c)
 }
 
+// ListApiKeys provides a mock function with given fields: c
+func (_m *UserEndpoint) ListApiKeys(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ListSessions provides a mock function with given fields: c
+func (_m *UserEndpoint) ListSessions(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Logout provides a mock function with given fields: c
 func (_m *UserEndpoint) Logout(c *gin.Context) {
 	_m.Called(c)
 }
 
+// OidcCallback provides a mock function with given fields: c
+func (_m *UserEndpoint) OidcCallback(c *gin.Context) {
+	_m.Called(c)
+}
+
+// OidcLogin provides a mock function with given fields: c
+func (_m *UserEndpoint) OidcLogin(c *gin.Context) {
+	_m.Called(c)
+}
+
 // PermissionCheck provides a mock function with given fields: c
 func (_m *UserEndpoint) PermissionCheck(c *gin.Context) {
 	_m.Called(c)
 }
 
+// Refresh provides a mock function with given fields: c
+func (_m *UserEndpoint) Refresh(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RegenerateRecoveryCodes provides a mock function with given fields: c
+func (_m *UserEndpoint) RegenerateRecoveryCodes(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ResetPassword provides a mock function with given fields: c
+func (_m *UserEndpoint) ResetPassword(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RevokeAllSessions provides a mock function with given fields: c
+func (_m *UserEndpoint) RevokeAllSessions(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RevokeApiKey provides a mock function with given fields: c
+func (_m *UserEndpoint) RevokeApiKey(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RevokeSession provides a mock function with given fields: c
+func (_m *UserEndpoint) RevokeSession(c *gin.Context) {
+	_m.Called(c)
+}
+
+// StartTotpEnrollment provides a mock function with given fields: c
+func (_m *UserEndpoint) StartTotpEnrollment(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UpdateMe provides a mock function with given fields: c
+func (_m *UserEndpoint) UpdateMe(c *gin.Context) {
+	_m.Called(c)
+}
+
 // NewUserEndpoint creates a new instance of UserEndpoint. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserEndpoint(t interface {
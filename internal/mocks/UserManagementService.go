@@ -0,0 +1,121 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	utils "data-storage-svc/internal/utils"
+)
+
+// UserManagementService is an autogenerated mock type for the UserManagementService type
+type UserManagementService struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: userId, transferTo
+func (_m *UserManagementService) Delete(userId *primitive.ObjectID, transferTo *primitive.ObjectID) utils.ServiceError {
+	ret := _m.Called(userId, transferTo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, *primitive.ObjectID) utils.ServiceError); ok {
+		r0 = rf(userId, transferTo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields: userId, newPassword
+func (_m *UserManagementService) ResetPassword(userId *primitive.ObjectID, newPassword string) (*string, utils.ServiceError) {
+	ret := _m.Called(userId, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 *string
+	var r1 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) (*string, utils.ServiceError)); ok {
+		return rf(userId, newPassword)
+	}
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string) *string); ok {
+		r0 = rf(userId, newPassword)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string) utils.ServiceError); ok {
+		r1 = rf(userId, newPassword)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1
+}
+
+// SetAdmin provides a mock function with given fields: userId, isAdmin
+func (_m *UserManagementService) SetAdmin(userId *primitive.ObjectID, isAdmin bool) utils.ServiceError {
+	ret := _m.Called(userId, isAdmin)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAdmin")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, bool) utils.ServiceError); ok {
+		r0 = rf(userId, isAdmin)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// SetDisabled provides a mock function with given fields: userId, disabled
+func (_m *UserManagementService) SetDisabled(userId *primitive.ObjectID, disabled bool) utils.ServiceError {
+	ret := _m.Called(userId, disabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDisabled")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, bool) utils.ServiceError); ok {
+		r0 = rf(userId, disabled)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// NewUserManagementService creates a new instance of UserManagementService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserManagementService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserManagementService {
+	mock := &UserManagementService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
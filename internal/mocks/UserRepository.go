@@ -15,6 +15,34 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// Count provides a mock function with no fields
+func (_m *UserRepository) Count() (int64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: user
 func (_m *UserRepository) Create(user *model.User) (*primitive.ObjectID, error) {
 	ret := _m.Called(user)
@@ -45,6 +73,24 @@ func (_m *UserRepository) Create(user *model.User) (*primitive.ObjectID, error)
 	return r0, r1
 }
 
+// Delete provides a mock function with given fields: id
+func (_m *UserRepository) Delete(id *primitive.ObjectID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAll provides a mock function with no fields
 func (_m *UserRepository) GetAll() ([]model.User, error) {
 	ret := _m.Called()
@@ -135,6 +181,36 @@ func (_m *UserRepository) GetById(id *primitive.ObjectID) (*model.User, error) {
 	return r0, r1
 }
 
+// GetByOidcSubject provides a mock function with given fields: subject
+func (_m *UserRepository) GetByOidcSubject(subject string) (*model.User, error) {
+	ret := _m.Called(subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByOidcSubject")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.User, error)); ok {
+		return rf(subject)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: id, update
 func (_m *UserRepository) Update(id *primitive.ObjectID, update primitive.M) error {
 	ret := _m.Called(id, update)
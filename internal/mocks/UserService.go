@@ -17,29 +17,29 @@ type UserService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: email, password
-func (_m *UserService) Create(email string, password string) (*primitive.ObjectID, utils.ServiceError) {
-	ret := _m.Called(email, password)
+// ChangeEmail provides a mock function with given fields: userId, currentPassword, email
+func (_m *UserService) ChangeEmail(userId *primitive.ObjectID, currentPassword string, email string) (*model.User, utils.ServiceError) {
+	ret := _m.Called(userId, currentPassword, email)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Create")
+		panic("no return value specified for ChangeEmail")
 	}
 
-	var r0 *primitive.ObjectID
+	var r0 *model.User
 	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(string, string) (*primitive.ObjectID, utils.ServiceError)); ok {
-		return rf(email, password)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, string) (*model.User, utils.ServiceError)); ok {
+		return rf(userId, currentPassword, email)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *primitive.ObjectID); ok {
-		r0 = rf(email, password)
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, string) *model.User); ok {
+		r0 = rf(userId, currentPassword, email)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*primitive.ObjectID)
+			r0 = ret.Get(0).(*model.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) utils.ServiceError); ok {
-		r1 = rf(email, password)
+	if rf, ok := ret.Get(1).(func(*primitive.ObjectID, string, string) utils.ServiceError); ok {
+		r1 = rf(userId, currentPassword, email)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(utils.ServiceError)
@@ -49,29 +49,49 @@ func (_m *UserService) Create(email string, password string) (*primitive.ObjectI
 	return r0, r1
 }
 
-// GenerateToken provides a mock function with given fields: email, password
-func (_m *UserService) GenerateToken(email string, password string) (*string, utils.ServiceError) {
-	ret := _m.Called(email, password)
+// ChangePassword provides a mock function with given fields: userId, currentPassword, newPassword
+func (_m *UserService) ChangePassword(userId *primitive.ObjectID, currentPassword string, newPassword string) utils.ServiceError {
+	ret := _m.Called(userId, currentPassword, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID, string, string) utils.ServiceError); ok {
+		r0 = rf(userId, currentPassword, newPassword)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.ServiceError)
+		}
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: email, password, isAdmin
+func (_m *UserService) Create(email string, password string, isAdmin bool) (*primitive.ObjectID, utils.ServiceError) {
+	ret := _m.Called(email, password, isAdmin)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GenerateToken")
+		panic("no return value specified for Create")
 	}
 
-	var r0 *string
+	var r0 *primitive.ObjectID
 	var r1 utils.ServiceError
-	if rf, ok := ret.Get(0).(func(string, string) (*string, utils.ServiceError)); ok {
-		return rf(email, password)
+	if rf, ok := ret.Get(0).(func(string, string, bool) (*primitive.ObjectID, utils.ServiceError)); ok {
+		return rf(email, password, isAdmin)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *string); ok {
-		r0 = rf(email, password)
+	if rf, ok := ret.Get(0).(func(string, string, bool) *primitive.ObjectID); ok {
+		r0 = rf(email, password, isAdmin)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*string)
+			r0 = ret.Get(0).(*primitive.ObjectID)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) utils.ServiceError); ok {
-		r1 = rf(email, password)
+	if rf, ok := ret.Get(1).(func(string, string, bool) utils.ServiceError); ok {
+		r1 = rf(email, password, isAdmin)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(utils.ServiceError)
@@ -177,6 +197,47 @@ func (_m *UserService) GetById(userId primitive.ObjectID) (*model.User, utils.Se
 	return r0, r1
 }
 
+// Login provides a mock function with given fields: email, password, userAgent, ip
+func (_m *UserService) Login(email string, password string, userAgent string, ip string) (*model.SessionTokens, *string, utils.ServiceError) {
+	ret := _m.Called(email, password, userAgent, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Login")
+	}
+
+	var r0 *model.SessionTokens
+	var r1 *string
+	var r2 utils.ServiceError
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*model.SessionTokens, *string, utils.ServiceError)); ok {
+		return rf(email, password, userAgent, ip)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *model.SessionTokens); ok {
+		r0 = rf(email, password, userAgent, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SessionTokens)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) *string); ok {
+		r1 = rf(email, password, userAgent, ip)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, string, string) utils.ServiceError); ok {
+		r2 = rf(email, password, userAgent, ip)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(utils.ServiceError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
 // NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserService(t interface {
@@ -0,0 +1,156 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "data-storage-svc/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserTokenRepository is an autogenerated mock type for the UserTokenRepository type
+type UserTokenRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: token
+func (_m *UserTokenRepository) Create(token *model.UserToken) (*primitive.ObjectID, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.UserToken) (*primitive.ObjectID, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(*model.UserToken) *primitive.ObjectID); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.UserToken) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: tokenId
+func (_m *UserTokenRepository) Delete(tokenId *primitive.ObjectID) error {
+	ret := _m.Called(tokenId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*primitive.ObjectID) error); ok {
+		r0 = rf(tokenId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAllForEmail provides a mock function with given fields: kind, email
+func (_m *UserTokenRepository) DeleteAllForEmail(kind model.UserTokenKind, email string) error {
+	ret := _m.Called(kind, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllForEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.UserTokenKind, string) error); ok {
+		r0 = rf(kind, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllForEmail provides a mock function with given fields: kind, email
+func (_m *UserTokenRepository) GetAllForEmail(kind model.UserTokenKind, email string) ([]model.UserToken, error) {
+	ret := _m.Called(kind, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllForEmail")
+	}
+
+	var r0 []model.UserToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.UserTokenKind, string) ([]model.UserToken, error)); ok {
+		return rf(kind, email)
+	}
+	if rf, ok := ret.Get(0).(func(model.UserTokenKind, string) []model.UserToken); ok {
+		r0 = rf(kind, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(model.UserTokenKind, string) error); ok {
+		r1 = rf(kind, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByHash provides a mock function with given fields: kind, tokenHash
+func (_m *UserTokenRepository) GetByHash(kind model.UserTokenKind, tokenHash string) (*model.UserToken, error) {
+	ret := _m.Called(kind, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.UserToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.UserTokenKind, string) (*model.UserToken, error)); ok {
+		return rf(kind, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(model.UserTokenKind, string) *model.UserToken); ok {
+		r0 = rf(kind, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(model.UserTokenKind, string) error); ok {
+		r1 = rf(kind, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUserTokenRepository creates a new instance of UserTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserTokenRepository {
+	mock := &UserTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
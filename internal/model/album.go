@@ -12,4 +12,10 @@ type Album struct {
 	Description  string              `bson:"description" json:"description"`
 	AuthorId     *primitive.ObjectID `bson:"authorId" json:"authorId"`
 	CreationDate time.Time           `bson:"creationDate" json:"creationDate"`
+	// Anyone can see the renditions of a public album, without account nor link
+	Public bool `bson:"public" json:"public"`
+	// Name of the album in its public URL, kept when the album is made private again so the URL stays the same
+	Slug string `bson:"slug,omitempty" json:"slug,omitempty"`
+	// The public album appears in the public directory
+	Listed bool `bson:"listed" json:"listed"`
 }
@@ -0,0 +1,56 @@
+package model
+
+type AlbumRole string
+
+const (
+	// Sees the album and downloads its medias
+	ALBUM_ROLE_VIEWER AlbumRole = "viewer"
+	// Also adds their own medias, and removes the medias they added
+	ALBUM_ROLE_CONTRIBUTOR AlbumRole = "contributor"
+	// Also adds and removes any media
+	ALBUM_ROLE_EDITOR AlbumRole = "editor"
+	// Also manages the accesses, the shared links and the public page of the album
+	ALBUM_ROLE_CO_OWNER AlbumRole = "coOwner"
+	// The author of the album, also deletes it. Never granted, it goes with the album.
+	ALBUM_ROLE_OWNER AlbumRole = "owner"
+)
+
+// Each role can do what the lower ones can
+var albumRoleRanks = map[AlbumRole]int{
+	ALBUM_ROLE_VIEWER:      1,
+	ALBUM_ROLE_CONTRIBUTOR: 2,
+	ALBUM_ROLE_EDITOR:      3,
+	ALBUM_ROLE_CO_OWNER:    4,
+	ALBUM_ROLE_OWNER:       5,
+}
+
+// Check if the role exists
+func (r AlbumRole) IsValid() bool {
+	return albumRoleRanks[r] > 0
+}
+
+// Check if the role can be granted to a user or a group
+func (r AlbumRole) IsGrantable() bool {
+	return r.IsValid() && r != ALBUM_ROLE_OWNER
+}
+
+// Check if the role can do what another role can, no role can do nothing
+func (r AlbumRole) AtLeast(role AlbumRole) bool {
+	return r.IsValid() && albumRoleRanks[r] >= albumRoleRanks[role]
+}
+
+// The role of accesses created with an edit flag, before roles existed
+func AlbumRoleFromCanEdit(canEdit bool) AlbumRole {
+	if canEdit {
+		return ALBUM_ROLE_EDITOR
+	}
+	return ALBUM_ROLE_VIEWER
+}
+
+// The highest of two roles
+func MaxAlbumRole(a AlbumRole, b AlbumRole) AlbumRole {
+	if albumRoleRanks[b] > albumRoleRanks[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ApiKeyScope string
+
+const (
+	// Allows safe requests only (GET, HEAD)
+	API_KEY_SCOPE_READ ApiKeyScope = "read"
+	// Allows requests modifying data (upload, delete...)
+	API_KEY_SCOPE_WRITE ApiKeyScope = "write"
+)
+
+// A long-lived personal key, authenticating its owner in scripts
+type ApiKey struct {
+	Id     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserId primitive.ObjectID `bson:"userId" json:"userId"`
+	// A name given by the user to remember what the key is used for
+	Name string `bson:"name" json:"name"`
+	// The first characters of the key, to recognize it once the key itself is not available anymore
+	Prefix string `bson:"prefix" json:"prefix"`
+	// SHA-256 of the key, the key itself is never stored
+	KeyHash    string        `bson:"keyHash" json:"-"`
+	Scopes     []ApiKeyScope `bson:"scopes" json:"scopes"`
+	CreatedAt  time.Time     `bson:"createdAt" json:"createdAt"`
+	LastUsedAt *time.Time    `bson:"lastUsedAt" json:"lastUsedAt"`
+	Revoked    bool          `bson:"revoked" json:"revoked"`
+	RevokedAt  *time.Time    `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+}
+
+// Check if the key has been given a scope
+func (k *ApiKey) HasScope(scope ApiKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// A stored original file, shared by all the medias having the same content
+type Blob struct {
+	// SHA-256 of the content
+	Hash string `bson:"_id" json:"hash"`
+	// Name of the original file in the original medias folder
+	StorageFileName string `bson:"storageFileName" json:"storageFileName"`
+	// Size of the original file in bytes
+	Size int64 `bson:"size" json:"size"`
+	// Number of medias pointing to this blob, the file is removed when it drops to 0
+	RefCount int64 `bson:"refCount" json:"refCount"`
+	// The date time at which the content was first uploaded
+	CreatedAt *time.Time `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type FsckIssueKind string
+
+const (
+	// A stored file that is referenced by no media nor download
+	FSCK_ORPHAN_FILE FsckIssueKind = "orphanFile"
+	// A tus .info/.lock file (and its data) left behind by an upload that never completed
+	FSCK_LEFTOVER_UPLOAD FsckIssueKind = "leftoverUpload"
+	// A media document whose original file is missing
+	FSCK_MISSING_ORIGINAL FsckIssueKind = "missingOriginal"
+	// A media whose original file content does not match its hash
+	FSCK_CORRUPTED_ORIGINAL FsckIssueKind = "corruptedOriginal"
+	// A media whose compressed rendition is missing
+	FSCK_MISSING_RENDITION FsckIssueKind = "missingRendition"
+	// A download document whose zip file is missing
+	FSCK_DANGLING_DOWNLOAD FsckIssueKind = "danglingDownload"
+	// A blob whose reference count does not match the number of medias using it
+	FSCK_BLOB_REFCOUNT FsckIssueKind = "blobRefCount"
+	// A media whose content is not tracked by any blob (e.g. uploaded before deduplication)
+	FSCK_UNTRACKED_BLOB FsckIssueKind = "untrackedBlob"
+)
+
+type FsckIssue struct {
+	Kind FsckIssueKind `json:"kind"`
+	// Storage key of the file concerned by the issue (if any)
+	Path string `json:"path,omitempty"`
+	// ID of the media or download document concerned by the issue (if any)
+	DocumentId *primitive.ObjectID `json:"documentId,omitempty"`
+	// Whether the issue has been fixed
+	Repaired bool `json:"repaired"`
+}
+
+type FsckReport struct {
+	CheckedAt time.Time   `json:"checkedAt"`
+	Repair    bool        `json:"repair"`
+	Issues    []FsckIssue `json:"issues"`
+}
+
+// Count the issues that are still present after the check
+func (r FsckReport) Unrepaired() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Users sharing albums together, an album shared with the group is shared with all its members
+type Group struct {
+	Id   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"`
+	// The user managing the members, always a member
+	OwnerId      primitive.ObjectID   `bson:"ownerId" json:"ownerId"`
+	MemberIds    []primitive.ObjectID `bson:"memberIds" json:"memberIds"`
+	CreationDate time.Time            `bson:"creationDate" json:"creationDate"`
+}
+
+// Check if a user is a member of the group
+func (g *Group) HasMember(userId primitive.ObjectID) bool {
+	for _, memberId := range g.MemberIds {
+		if memberId == userId {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,18 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Where the original file of a media is stored
+type StorageTier string
+
+const (
+	// Original is in the main storage, next to its renditions
+	TIER_HOT StorageTier = "hot"
+	// Original has been moved to the archive storage
+	TIER_ARCHIVED StorageTier = "archived"
+	// Original is in the archive storage, and has been requested back to the main storage
+	TIER_RESTORING StorageTier = "restoring"
+)
+
 type Media struct {
 	Id primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	// The original filename (keep it when user download its files back)
@@ -22,4 +34,19 @@ type Media struct {
 	UploadedViaSharedLink bool `bson:"uploadedViaSharedLink" json:"uploadedViaSharedLink"`
 	// Hash of the media data to ensure uniqueness
 	Hash *string `bson:"hash" json:"hash"`
+	// Where the original file lives, medias uploaded before tiering have no tier and are hot
+	OriginalTier StorageTier `bson:"originalTier,omitempty" json:"originalTier,omitempty"`
+	// Size of the original file in bytes
+	Size int64 `bson:"size" json:"size"`
+	// The user whose quota this media counts against (the uploader, or the creator of the shared link used)
+	ChargedTo *primitive.ObjectID `bson:"chargedTo,omitempty" json:"chargedTo,omitempty"`
+	// Bytes counted against the quota for this media (original, and rendition if configured)
+	ChargedBytes int64 `bson:"chargedBytes" json:"chargedBytes"`
+	// Last time the original file was downloaded
+	LastAccessTime *time.Time `bson:"lastAccessTime,omitempty" json:"lastAccessTime,omitempty"`
+}
+
+// Check if the original file is in the archive storage (being restored or not)
+func (m *Media) IsArchived() bool {
+	return m.OriginalTier == TIER_ARCHIVED || m.OriginalTier == TIER_RESTORING
 }
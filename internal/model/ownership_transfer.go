@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Where an ownership transfer stands
+type OwnershipTransferStatus string
+
+const (
+	// Waiting for the recipient to answer
+	TRANSFER_STATUS_PENDING OwnershipTransferStatus = "pending"
+	// The recipient accepted, the album is theirs
+	TRANSFER_STATUS_ACCEPTED OwnershipTransferStatus = "accepted"
+	// The recipient refused the album
+	TRANSFER_STATUS_DECLINED OwnershipTransferStatus = "declined"
+	// Withdrawn by the owner or an admin before the recipient answered
+	TRANSFER_STATUS_CANCELLED OwnershipTransferStatus = "cancelled"
+)
+
+// A proposal to give an album, and optionally the medias of its owner in it, to another user. Transfers are kept once
+// answered, as the history of the album owners.
+type OwnershipTransfer struct {
+	Id      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AlbumId primitive.ObjectID `bson:"albumId" json:"albumId"`
+	// The owner of the album when the transfer was proposed
+	FromUserId primitive.ObjectID `bson:"fromUserId" json:"fromUserId"`
+	ToUserId   primitive.ObjectID `bson:"toUserId" json:"toUserId"`
+	// The owner, or the admin who proposed the transfer in their place
+	ProposedBy primitive.ObjectID `bson:"proposedBy" json:"proposedBy"`
+	// Also give the medias of the owner in the album to the recipient
+	IncludeMedias bool                    `bson:"includeMedias" json:"includeMedias"`
+	Status        OwnershipTransferStatus `bson:"status" json:"status"`
+	CreationDate  time.Time               `bson:"creationDate" json:"creationDate"`
+	// Who answered the transfer, and when
+	ResolvedBy     *primitive.ObjectID `bson:"resolvedBy,omitempty" json:"resolvedBy,omitempty"`
+	ResolutionDate *time.Time          `bson:"resolutionDate,omitempty" json:"resolutionDate,omitempty"`
+	// Number of medias given to the recipient on acceptance
+	TransferredMedias int `bson:"transferredMedias" json:"transferredMedias"`
+}
@@ -0,0 +1,19 @@
+package model
+
+// Storage limits of a user, 0 means unlimited
+type StorageQuota struct {
+	MaxBytes int64 `bson:"maxBytes" json:"maxBytes"`
+	MaxFiles int64 `bson:"maxFiles" json:"maxFiles"`
+}
+
+// Storage used by a user
+type StorageUsage struct {
+	Bytes int64 `bson:"bytes" json:"bytes"`
+	Files int64 `bson:"files" json:"files"`
+}
+
+// Storage used by a user, along with the quota applying to them
+type UserUsage struct {
+	Usage StorageUsage `json:"usage"`
+	Quota StorageQuota `json:"quota"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A logged in device of a user. It lives as long as its refresh token is used, and can be revoked server-side.
+type Session struct {
+	Id     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserId primitive.ObjectID `bson:"userId" json:"userId"`
+	// SHA-256 of the current refresh token
+	RefreshTokenHash string `bson:"refreshTokenHash" json:"-"`
+	// SHA-256 of the refresh token replaced by the last rotation, using it again means it leaked
+	PreviousRefreshTokenHash string `bson:"previousRefreshTokenHash,omitempty" json:"-"`
+	// Device info, as seen on login or on the last refresh
+	UserAgent  string    `bson:"userAgent" json:"userAgent"`
+	Ip         string    `bson:"ip" json:"ip"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+	LastUsedAt time.Time `bson:"lastUsedAt" json:"lastUsedAt"`
+	ExpiresAt  time.Time `bson:"expiresAt" json:"expiresAt"`
+	// Set when listing sessions, true for the session making the request
+	Current bool `bson:"-" json:"current"`
+}
+
+// Tokens handed to a client on login or refresh
+type SessionTokens struct {
+	SessionId    primitive.ObjectID `json:"sessionId"`
+	AccessToken  string             `json:"jwt"`
+	RefreshToken string             `json:"refreshToken"`
+	// Validity of the access token in seconds
+	ExpiresIn int64 `json:"expiresIn"`
+}
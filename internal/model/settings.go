@@ -0,0 +1,7 @@
+package model
+
+// Instance wide settings, editable by admins
+type Settings struct {
+	// Quota applying to users without a specific quota
+	DefaultQuota StorageQuota `bson:"defaultQuota" json:"defaultQuota"`
+}
@@ -6,6 +6,46 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// What a shared link allows its visitors to do in the album
+type SharedLinkScope string
+
+const (
+	// Browse the album and its medias, renditions only
+	SHARED_LINK_SCOPE_VIEW SharedLinkScope = "view"
+	// Get the original files, one by one or as an album archive
+	SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS SharedLinkScope = "downloadOriginals"
+	// Get the renditions, one by one or as an album archive, but never the originals
+	SHARED_LINK_SCOPE_DOWNLOAD_RENDITIONS SharedLinkScope = "downloadRenditions"
+	// Upload new medias and add them to the album
+	SHARED_LINK_SCOPE_UPLOAD SharedLinkScope = "upload"
+	// Add medias already uploaded to the album
+	SHARED_LINK_SCOPE_ADD_EXISTING SharedLinkScope = "addExisting"
+	// Remove medias from the album
+	SHARED_LINK_SCOPE_REMOVE SharedLinkScope = "remove"
+	// Read the meta data of the medias, such as the camera and the location
+	SHARED_LINK_SCOPE_VIEW_METADATA SharedLinkScope = "viewMetadata"
+)
+
+// Check if the scope is one of the known scopes
+func (s SharedLinkScope) IsValid() bool {
+	switch s {
+	case SHARED_LINK_SCOPE_VIEW, SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS, SHARED_LINK_SCOPE_DOWNLOAD_RENDITIONS, SHARED_LINK_SCOPE_UPLOAD,
+		SHARED_LINK_SCOPE_ADD_EXISTING, SHARED_LINK_SCOPE_REMOVE, SHARED_LINK_SCOPE_VIEW_METADATA:
+		return true
+	}
+	return false
+}
+
+// Scopes matching what links could do before scopes existed: everything viewers can do, and editing the album medias
+// when canEdit is set
+func DefaultSharedLinkScopes(canEdit bool) []SharedLinkScope {
+	scopes := []SharedLinkScope{SHARED_LINK_SCOPE_VIEW, SHARED_LINK_SCOPE_DOWNLOAD_ORIGINALS, SHARED_LINK_SCOPE_DOWNLOAD_RENDITIONS, SHARED_LINK_SCOPE_VIEW_METADATA}
+	if canEdit {
+		scopes = append(scopes, SHARED_LINK_SCOPE_UPLOAD, SHARED_LINK_SCOPE_ADD_EXISTING, SHARED_LINK_SCOPE_REMOVE)
+	}
+	return scopes
+}
+
 type SharedLink struct {
 	Id             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	AlbumId        primitive.ObjectID `json:"albumId" bson:"albumId"`
@@ -13,5 +53,40 @@ type SharedLink struct {
 	CreatedAt      time.Time          `json:"created" bson:"created"`
 	Token          string             `json:"token" bson:"token"`
 	ExpirationDate time.Time          `json:"expiration" bson:"expiration"`
-	CanEdit        bool               `json:"canEdit" bson:"canEdit"`
+	// Only set on links created before scopes, which are given the default scopes
+	CanEdit bool              `json:"-" bson:"canEdit,omitempty"`
+	Scopes  []SharedLinkScope `json:"scopes" bson:"scopes,omitempty"`
+	// Number of visits the link can be used for, zero for no limit. A visit lasts as long as the link session it
+	// starts, its requests count as a single use.
+	MaxUses        int64      `json:"maxUses" bson:"maxUses"`
+	Uses           int64      `json:"uses" bson:"uses"`
+	Revoked        bool       `json:"revoked" bson:"revoked"`
+	LastAccessedAt *time.Time `json:"lastAccessed,omitempty" bson:"lastAccessed,omitempty"`
+	// Visitors must give the password before using the link, empty when the link has no password
+	PasswordHash      string `json:"-" bson:"passwordHash,omitempty"`
+	PasswordProtected bool   `json:"passwordProtected" bson:"passwordProtected"`
+}
+
+// Check if the link can still be used for a new visit: not revoked, not expired and not used up
+func (l *SharedLink) IsUsable(now time.Time) bool {
+	return l.IsActive(now) && (l.MaxUses <= 0 || l.Uses < l.MaxUses)
+}
+
+// Check if the link is neither revoked nor expired, visits already started go on even once the link is used up
+func (l *SharedLink) IsActive(now time.Time) bool {
+	return !l.Revoked && now.Before(l.ExpirationDate)
+}
+
+// Check if the link has been given a scope
+func (l *SharedLink) HasScope(scope SharedLinkScope) bool {
+	scopes := l.Scopes
+	if scopes == nil {
+		scopes = DefaultSharedLinkScopes(l.CanEdit)
+	}
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
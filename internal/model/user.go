@@ -7,10 +7,37 @@ import (
 )
 
 type User struct {
-	Id           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	IsAdmin      bool               `bson:"isAdmin,omitempty" json:"isAdmin"`
-	Email        string             `bson:"email" json:"email"`
-	JoinDate     time.Time          `bson:"joinDate,omitempty" json:"joinDate"`
-	LastLogin    time.Time          `bson:"lastLogin" json:"lastLogin"`
-	PasswordHash string             `bson:"passwordHash,omitempty" json:"passwordHash,omitempty"`
+	Id      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	IsAdmin bool               `bson:"isAdmin,omitempty" json:"isAdmin"`
+	// Disabled users cannot log in nor use their tokens and API keys
+	Disabled     bool      `bson:"disabled,omitempty" json:"disabled"`
+	Email        string    `bson:"email" json:"email"`
+	JoinDate     time.Time `bson:"joinDate,omitempty" json:"joinDate"`
+	LastLogin    time.Time `bson:"lastLogin" json:"lastLogin"`
+	PasswordHash string    `bson:"passwordHash,omitempty" json:"passwordHash,omitempty"`
+	// Subject of the user at the OpenID Connect provider, if they logged in with it
+	OidcSubject string `bson:"oidcSubject,omitempty" json:"oidcSubject,omitempty"`
+	// TOTP second factor, nil if the user never enrolled
+	Totp *TotpSettings `bson:"totp,omitempty" json:"-"`
+	// Storage used by the user's medias (including medias uploaded via their shared links)
+	Usage StorageUsage `bson:"usage" json:"usage"`
+	// User specific quota, the default quota applies when nil
+	Quota *StorageQuota `bson:"quota,omitempty" json:"quota,omitempty"`
+}
+
+type TotpSettings struct {
+	// Base32 shared secret
+	Secret string `bson:"secret"`
+	// False until the user proved their app generates valid codes
+	Enabled    bool       `bson:"enabled"`
+	EnrolledAt *time.Time `bson:"enrolledAt,omitempty"`
+	// Period of the last accepted code, a code cannot be used twice
+	LastUsedStep int64 `bson:"lastUsedStep"`
+	// SHA-256 of the recovery codes not used yet
+	RecoveryCodeHashes []string `bson:"recoveryCodeHashes"`
+}
+
+// Check if the user must give a second factor to log in
+func (u *User) HasTwoFactor() bool {
+	return u.Totp != nil && u.Totp.Enabled
 }
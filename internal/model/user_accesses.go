@@ -12,5 +12,12 @@ type UserAlbumAccess struct {
 	AccessId *primitive.ObjectID `bson:"_id,omitempty"`
 	UserId   *primitive.ObjectID `bson:"userId"`
 	AlbumId  *primitive.ObjectID `bson:"albumId"`
-	CanEdit  bool                `bool:"canEdit"`
+	Role     AlbumRole           `bson:"role"`
+}
+
+type GroupAlbumAccess struct {
+	AccessId *primitive.ObjectID `bson:"_id,omitempty"`
+	GroupId  *primitive.ObjectID `bson:"groupId"`
+	AlbumId  *primitive.ObjectID `bson:"albumId"`
+	Role     AlbumRole           `bson:"role"`
 }
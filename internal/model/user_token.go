@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserTokenKind string
+
+const (
+	// Lets the recipient of an email create their account
+	USER_TOKEN_INVITATION UserTokenKind = "invitation"
+	// Lets a user who forgot their password choose a new one
+	USER_TOKEN_PASSWORD_RESET UserTokenKind = "passwordReset"
+)
+
+// A single use token sent by email
+type UserToken struct {
+	Id   primitive.ObjectID `bson:"_id,omitempty"`
+	Kind UserTokenKind      `bson:"kind"`
+	// SHA-256 of the token, the token itself is only in the email
+	TokenHash string `bson:"tokenHash"`
+	// Recipient of the email
+	Email string `bson:"email"`
+	// User whose password is reset
+	UserId *primitive.ObjectID `bson:"userId,omitempty"`
+	// Album shared with the invited user, if any
+	AlbumId *primitive.ObjectID `bson:"albumId,omitempty"`
+	// Role of the invited user in the album
+	Role AlbumRole `bson:"role,omitempty"`
+	// Who sent the invitation
+	CreatedBy *primitive.ObjectID `bson:"createdBy,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt"`
+	ExpiresAt time.Time           `bson:"expiresAt"`
+}
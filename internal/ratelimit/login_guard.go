@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"strings"
+	"time"
+)
+
+// Lock an account after repeated failed logins, for a time doubling with each further failure
+type LockoutPolicy struct {
+	// Failures allowed before the account is locked, zero disables the lockout
+	Threshold int64
+	// Lock duration once the threshold is reached
+	Duration time.Duration
+	// The lock duration does not grow beyond this, zero keeps it constant
+	MaxDuration time.Duration
+	// Failures are forgotten after this time without any new failure
+	ForgetAfter time.Duration
+}
+
+// Protects the login of each account, whatever the IP addresses the attempts come from
+type LoginGuard interface {
+	// Check if a login attempt is allowed for an account, returns the time to wait otherwise
+	Allow(account string) (bool, time.Duration)
+	// Record a failed login attempt (wrong password or code)
+	Failure(account string)
+	// Record a successful login, past failures are forgotten
+	Success(account string)
+}
+
+type loginGuard struct {
+	store      Store
+	perAccount Limit
+	lockout    LockoutPolicy
+	clock      func() time.Time
+}
+
+func NewLoginGuard(store Store, perAccount Limit, lockout LockoutPolicy, clock func() time.Time) LoginGuard {
+	return loginGuard{store, perAccount, lockout, clock}
+}
+
+// Emails are compared case insensitively, so are the keys
+func accountKey(prefix string, account string) string {
+	return prefix + ":" + strings.ToLower(strings.TrimSpace(account))
+}
+
+func (g loginGuard) Allow(account string) (bool, time.Duration) {
+	if lockedUntil := g.store.LockedUntil(accountKey("lockout", account)); g.clock().Before(lockedUntil) {
+		return false, lockedUntil.Sub(g.clock())
+	}
+	return g.store.Take(accountKey("login", account), g.perAccount)
+}
+
+func (g loginGuard) Failure(account string) {
+	if g.lockout.Threshold <= 0 {
+		return
+	}
+	key := accountKey("lockout", account)
+	count := g.store.AddFailure(key, g.lockout.ForgetAfter)
+	if count < g.lockout.Threshold {
+		return
+	}
+	duration := g.lockout.Duration
+	for i := g.lockout.Threshold; i < count && duration < g.lockout.MaxDuration; i++ {
+		duration *= 2
+	}
+	if g.lockout.MaxDuration > 0 && duration > g.lockout.MaxDuration {
+		duration = g.lockout.MaxDuration
+	}
+	g.store.Lock(key, g.clock().Add(duration))
+}
+
+func (g loginGuard) Success(account string) {
+	g.store.Reset(accountKey("lockout", account))
+}
@@ -0,0 +1,67 @@
+package ratelimit_test
+
+import (
+	"data-storage-svc/internal/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockout(t *testing.T) {
+	clock := newFakeClock()
+	guard := ratelimit.NewLoginGuard(ratelimit.NewMemoryStore(clock.Now), ratelimit.Limit{}, ratelimit.LockoutPolicy{
+		Threshold:   3,
+		Duration:    time.Minute,
+		MaxDuration: 3 * time.Minute,
+		ForgetAfter: 24 * time.Hour,
+	}, clock.Now)
+
+	for i := 0; i < 2; i++ {
+		guard.Failure("test@test.fr")
+		allowed, _ := guard.Allow("test@test.fr")
+		assert.True(t, allowed)
+	}
+
+	// Threshold reached, emails are compared case insensitively
+	guard.Failure("Test@Test.fr")
+	allowed, retryAfter := guard.Allow("test@test.fr")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+	allowed, _ = guard.Allow("other@test.fr")
+	assert.True(t, allowed)
+
+	// The lock doubles with each further failure, up to the maximum
+	clock.Advance(time.Minute)
+	allowed, _ = guard.Allow("test@test.fr")
+	assert.True(t, allowed)
+	guard.Failure("test@test.fr")
+	_, retryAfter = guard.Allow("test@test.fr")
+	assert.Equal(t, 2*time.Minute, retryAfter)
+	clock.Advance(2 * time.Minute)
+	guard.Failure("test@test.fr")
+	_, retryAfter = guard.Allow("test@test.fr")
+	assert.Equal(t, 3*time.Minute, retryAfter)
+
+	// A successful login forgets everything
+	clock.Advance(3 * time.Minute)
+	guard.Success("test@test.fr")
+	guard.Failure("test@test.fr")
+	allowed, _ = guard.Allow("test@test.fr")
+	assert.True(t, allowed)
+}
+
+func TestPerAccountLimit(t *testing.T) {
+	clock := newFakeClock()
+	guard := ratelimit.NewLoginGuard(ratelimit.NewMemoryStore(clock.Now), ratelimit.PerMinute(2), ratelimit.LockoutPolicy{}, clock.Now)
+
+	for i := 0; i < 2; i++ {
+		allowed, _ := guard.Allow("test@test.fr")
+		assert.True(t, allowed)
+		// Lockout disabled
+		guard.Failure("test@test.fr")
+	}
+	allowed, retryAfter := guard.Allow("test@test.fr")
+	assert.False(t, allowed)
+	assert.Equal(t, 30*time.Second, retryAfter)
+}
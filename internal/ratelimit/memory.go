@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Entries not touched for this long are dropped, their buckets are full again anyway
+const MEMORY_STORE_SWEEP_PERIOD = 10 * time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+type failures struct {
+	count       int64
+	last        time.Time
+	forgetAfter time.Duration
+	lockedUntil time.Time
+}
+
+// Store keeping the state in the memory of this instance
+type memoryStore struct {
+	mutex     sync.Mutex
+	clock     func() time.Time
+	buckets   map[string]*bucket
+	failures  map[string]*failures
+	lastSweep time.Time
+}
+
+// Create an in memory store, clock gives the current time (time.Now outside of tests)
+func NewMemoryStore(clock func() time.Time) Store {
+	return &memoryStore{clock: clock, buckets: map[string]*bucket{}, failures: map[string]*failures{}, lastSweep: clock()}
+}
+
+func (s *memoryStore) Take(key string, limit Limit) (bool, time.Duration) {
+	return s.take(key, limit, true)
+}
+
+func (s *memoryStore) Peek(key string, limit Limit) (bool, time.Duration) {
+	return s.take(key, limit, false)
+}
+
+func (s *memoryStore) take(key string, limit Limit, consume bool) (bool, time.Duration) {
+	if limit.Disabled() {
+		return true, 0
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.clock()
+	s.sweep(now)
+
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		s.buckets[key] = b
+	}
+	// Refill for the time elapsed since the last request
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+float64(now.Sub(b.updated))/float64(limit.Interval))
+	b.updated = now
+	b.limit = limit
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(limit.Interval))
+	}
+	if consume {
+		b.tokens -= 1
+	}
+	return true, 0
+}
+
+func (s *memoryStore) AddFailure(key string, forgetAfter time.Duration) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.clock()
+	s.sweep(now)
+
+	f, exists := s.failures[key]
+	if !exists {
+		f = &failures{}
+		s.failures[key] = f
+	}
+	if now.Sub(f.last) > forgetAfter {
+		f.count = 0
+	}
+	f.count += 1
+	f.last = now
+	f.forgetAfter = forgetAfter
+	return f.count
+}
+
+func (s *memoryStore) Lock(key string, until time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f, exists := s.failures[key]
+	if !exists {
+		f = &failures{last: s.clock()}
+		s.failures[key] = f
+	}
+	f.lockedUntil = until
+}
+
+func (s *memoryStore) LockedUntil(key string) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if f, exists := s.failures[key]; exists {
+		return f.lockedUntil
+	}
+	return time.Time{}
+}
+
+func (s *memoryStore) Reset(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.failures, key)
+}
+
+// Drop the entries which do not limit anything anymore, so that memory does not grow with every client ever seen
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < MEMORY_STORE_SWEEP_PERIOD {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if float64(now.Sub(b.updated))/float64(b.limit.Interval) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, f := range s.failures {
+		if now.Sub(f.last) > f.forgetAfter && now.After(f.lockedUntil) {
+			delete(s.failures, key)
+		}
+	}
+}
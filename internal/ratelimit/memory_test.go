@@ -0,0 +1,99 @@
+package ratelimit_test
+
+import (
+	"data-storage-svc/internal/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Clock only moving when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestTake(t *testing.T) {
+	clock := newFakeClock()
+	store := ratelimit.NewMemoryStore(clock.Now)
+	limit := ratelimit.PerMinute(3)
+
+	// Burst
+	for i := 0; i < 3; i++ {
+		allowed, _ := store.Take("ip", limit)
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter := store.Take("ip", limit)
+	assert.False(t, allowed)
+	assert.Equal(t, 20*time.Second, retryAfter)
+	// Other keys have their own bucket
+	allowed, _ = store.Take("other", limit)
+	assert.True(t, allowed)
+
+	// One request more every 20 seconds
+	clock.Advance(10 * time.Second)
+	allowed, retryAfter = store.Take("ip", limit)
+	assert.False(t, allowed)
+	assert.Equal(t, 10*time.Second, retryAfter)
+	clock.Advance(10 * time.Second)
+	allowed, _ = store.Take("ip", limit)
+	assert.True(t, allowed)
+	allowed, _ = store.Take("ip", limit)
+	assert.False(t, allowed)
+
+	// Never more than the burst
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _ = store.Take("ip", limit)
+		assert.True(t, allowed)
+	}
+	allowed, _ = store.Take("ip", limit)
+	assert.False(t, allowed)
+}
+
+func TestPeek(t *testing.T) {
+	store := ratelimit.NewMemoryStore(newFakeClock().Now)
+	limit := ratelimit.PerMinute(1)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := store.Peek("ip", limit)
+		assert.True(t, allowed)
+	}
+	store.Take("ip", limit)
+	allowed, retryAfter := store.Peek("ip", limit)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+}
+
+func TestDisabledLimit(t *testing.T) {
+	store := ratelimit.NewMemoryStore(newFakeClock().Now)
+	for i := 0; i < 100; i++ {
+		allowed, _ := store.Take("ip", ratelimit.PerMinute(0))
+		assert.True(t, allowed)
+	}
+}
+
+func TestFailuresForgotten(t *testing.T) {
+	clock := newFakeClock()
+	store := ratelimit.NewMemoryStore(clock.Now)
+
+	assert.Equal(t, int64(1), store.AddFailure("account", time.Hour))
+	clock.Advance(30 * time.Minute)
+	assert.Equal(t, int64(2), store.AddFailure("account", time.Hour))
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, int64(1), store.AddFailure("account", time.Hour))
+	store.Reset("account")
+	assert.Equal(t, int64(1), store.AddFailure("account", time.Hour))
+}
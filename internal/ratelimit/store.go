@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// A token bucket: Burst requests at once, then one more every Interval. A zero limit never blocks.
+type Limit struct {
+	Burst    int64
+	Interval time.Duration
+}
+
+// Allow n requests per minute, with bursts of n requests. Zero disables the limit.
+func PerMinute(n int64) Limit {
+	if n <= 0 {
+		return Limit{}
+	}
+	return Limit{Burst: n, Interval: time.Minute / time.Duration(n)}
+}
+
+func (l Limit) Disabled() bool {
+	return l.Burst <= 0
+}
+
+// State of the rate limits and lockouts, keyed by client (IP address, account...). Kept in memory by default, a store
+// shared between instances can implement it as well.
+type Store interface {
+	// Take a request from the bucket of key. When the bucket is empty, returns false and the time until it has a
+	// request again.
+	Take(key string, limit Limit) (bool, time.Duration)
+	// Check if the bucket of key has a request left, without taking it
+	Peek(key string, limit Limit) (bool, time.Duration)
+	// Count a failure for key, returns the number of failures. Failures are forgotten after forgetAfter without any
+	// new failure.
+	AddFailure(key string, forgetAfter time.Duration) int64
+	// Lock key until the given time
+	Lock(key string, until time.Time)
+	// Time at which key is unlocked, zero if it has never been locked
+	LockedUntil(key string) time.Time
+	// Forget the failures and the lock of key
+	Reset(key string)
+}
@@ -13,8 +13,8 @@ import (
 )
 
 type AlbumAccessRepository interface {
-	// Create an album access entry to a given album to for a given user
-	Create(userId *primitive.ObjectID, albumId *primitive.ObjectID, canEdit bool) error
+	// Create an album access entry to a given album to for a given user, or change its role
+	Create(userId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) error
 	// Remove an album access entry to a given album for a given user
 	Remove(userId *primitive.ObjectID, albumId *primitive.ObjectID) error
 	// Remove all album access entries for all users (after that, no body will be able to access/edit the album)
@@ -25,9 +25,22 @@ type AlbumAccessRepository interface {
 	GetAllByAlbum(albumId *primitive.ObjectID) ([]model.UserAlbumAccess, error)
 	// Get a specific album access for a given userId and albumId
 	Get(userId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.UserAlbumAccess, error)
+	// Create an album access entry to a given album for all the members of a group, or change its role
+	CreateForGroup(groupId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) error
+	// Remove an album access entry to a given album for a group
+	RemoveForGroup(groupId *primitive.ObjectID, albumId *primitive.ObjectID) error
+	// Remove all album access entries of a group
+	RemoveAllForGroup(groupId *primitive.ObjectID) error
+	// Get all group album accesses associated to a given album id
+	GetAllGroupsByAlbum(albumId *primitive.ObjectID) ([]model.GroupAlbumAccess, error)
+	// Get the access of a user to an album, granted to the user or to one of their groups, with the highest role granted
+	GetEffective(userId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.UserAlbumAccess, error)
+	// Get the accesses of a user to all albums, granted to the user or to one of their groups, one per album
+	GetAllEffectiveByUser(userId *primitive.ObjectID) ([]model.UserAlbumAccess, error)
 }
 
 const USER_ALBUM_ACCESS_COLLECTION = "users_album_access"
+const GROUP_ALBUM_ACCESS_COLLECTION = "groups_album_access"
 
 type albumAccessRepository struct {
 	db *mongo.Database
@@ -37,14 +50,14 @@ func NewAlbumAccessRepository(db *mongo.Database) albumAccessRepository {
 	return albumAccessRepository{db}
 }
 
-func (r albumAccessRepository) Create(userId *primitive.ObjectID, albumId *primitive.ObjectID, canEdit bool) error {
+func (r albumAccessRepository) Create(userId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) error {
 	filter := bson.M{
 		"userId":  userId,
 		"albumId": albumId,
 	}
 	update := bson.M{
 		"$set": bson.M{
-			"canEdit": canEdit,
+			"role": role,
 		},
 	}
 
@@ -67,7 +80,10 @@ func (r albumAccessRepository) RemoveAllAccesses(albumId *primitive.ObjectID) er
 	filter := bson.M{
 		"albumId": albumId,
 	}
-	_, err := r.db.Collection(USER_ALBUM_ACCESS_COLLECTION).DeleteMany(context.Background(), filter)
+	if _, err := r.db.Collection(USER_ALBUM_ACCESS_COLLECTION).DeleteMany(context.Background(), filter); err != nil {
+		return err
+	}
+	_, err := r.db.Collection(GROUP_ALBUM_ACCESS_COLLECTION).DeleteMany(context.Background(), filter)
 	return err
 }
 
@@ -129,3 +145,135 @@ func (r albumAccessRepository) GetAllByAlbum(albumId *primitive.ObjectID) ([]mod
 
 	return userAlbumAccesses, nil
 }
+
+func (r albumAccessRepository) CreateForGroup(groupId *primitive.ObjectID, albumId *primitive.ObjectID, role model.AlbumRole) error {
+	filter := bson.M{
+		"groupId": groupId,
+		"albumId": albumId,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"role": role,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+
+	_, err := r.db.Collection(GROUP_ALBUM_ACCESS_COLLECTION).UpdateOne(context.Background(), filter, update, opts)
+	return err
+}
+
+func (r albumAccessRepository) RemoveForGroup(groupId *primitive.ObjectID, albumId *primitive.ObjectID) error {
+	filter := bson.M{
+		"groupId": groupId,
+		"albumId": albumId,
+	}
+	_, err := r.db.Collection(GROUP_ALBUM_ACCESS_COLLECTION).DeleteOne(context.Background(), filter)
+	return err
+}
+
+func (r albumAccessRepository) RemoveAllForGroup(groupId *primitive.ObjectID) error {
+	_, err := r.db.Collection(GROUP_ALBUM_ACCESS_COLLECTION).DeleteMany(context.Background(), bson.M{"groupId": groupId})
+	return err
+}
+
+func (r albumAccessRepository) GetAllGroupsByAlbum(albumId *primitive.ObjectID) ([]model.GroupAlbumAccess, error) {
+	return r.findGroupAccesses(bson.M{"albumId": albumId})
+}
+
+func (r albumAccessRepository) GetEffective(userId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.UserAlbumAccess, error) {
+	accesses, err := r.getAllEffective(userId, albumId)
+	if err != nil {
+		return nil, err
+	}
+	if len(accesses) == 0 {
+		slog.Debug("couldn't find album access. User probably don't have permission to view this album", "userId", userId.Hex(), "albumId", albumId.Hex())
+		return nil, fmt.Errorf("couldn't find album access. User probably don't have permission to view this album")
+	}
+	return &accesses[0], nil
+}
+
+func (r albumAccessRepository) GetAllEffectiveByUser(userId *primitive.ObjectID) ([]model.UserAlbumAccess, error) {
+	return r.getAllEffective(userId, nil)
+}
+
+// Merge the accesses granted to a user and to their groups, to an album or to all albums when nil
+func (r albumAccessRepository) getAllEffective(userId *primitive.ObjectID, albumId *primitive.ObjectID) ([]model.UserAlbumAccess, error) {
+	userFilter := bson.M{"userId": userId}
+	if albumId != nil {
+		userFilter["albumId"] = albumId
+	}
+	userAccesses, err := r.find(userFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Groups the user is a member of
+	cursor, err := r.db.Collection(GROUP_COLLECTION).Find(context.Background(), bson.M{"memberIds": userId}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(context.Background(), &groups); err != nil {
+		return nil, err
+	}
+	var groupIds []primitive.ObjectID = make([]primitive.ObjectID, 0, len(groups))
+	for _, group := range groups {
+		groupIds = append(groupIds, group.Id)
+	}
+	var groupAccesses []model.GroupAlbumAccess
+	if len(groupIds) > 0 {
+		groupFilter := bson.M{"groupId": bson.M{"$in": groupIds}}
+		if albumId != nil {
+			groupFilter["albumId"] = albumId
+		}
+		if groupAccesses, err = r.findGroupAccesses(groupFilter); err != nil {
+			return nil, err
+		}
+	}
+
+	// One access per album, with the highest role of its grants
+	var accesses []model.UserAlbumAccess = make([]model.UserAlbumAccess, 0)
+	indexes := map[primitive.ObjectID]int{}
+	merge := func(access model.UserAlbumAccess) {
+		if i, ok := indexes[*access.AlbumId]; ok {
+			accesses[i].Role = model.MaxAlbumRole(accesses[i].Role, access.Role)
+			return
+		}
+		indexes[*access.AlbumId] = len(accesses)
+		accesses = append(accesses, access)
+	}
+	for _, access := range userAccesses {
+		merge(access)
+	}
+	for _, access := range groupAccesses {
+		merge(model.UserAlbumAccess{UserId: userId, AlbumId: access.AlbumId, Role: access.Role})
+	}
+	return accesses, nil
+}
+
+func (r albumAccessRepository) find(filter bson.M) ([]model.UserAlbumAccess, error) {
+	cursor, err := r.db.Collection(USER_ALBUM_ACCESS_COLLECTION).Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	var userAlbumAccesses []model.UserAlbumAccess = make([]model.UserAlbumAccess, 0)
+	if err := cursor.All(context.Background(), &userAlbumAccesses); err != nil {
+		return nil, err
+	}
+	return userAlbumAccesses, nil
+}
+
+func (r albumAccessRepository) findGroupAccesses(filter bson.M) ([]model.GroupAlbumAccess, error) {
+	cursor, err := r.db.Collection(GROUP_ALBUM_ACCESS_COLLECTION).Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	var groupAlbumAccesses []model.GroupAlbumAccess = make([]model.GroupAlbumAccess, 0)
+	if err := cursor.All(context.Background(), &groupAlbumAccesses); err != nil {
+		return nil, err
+	}
+	return groupAlbumAccesses, nil
+}
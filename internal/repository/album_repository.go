@@ -3,7 +3,7 @@ package repository
 import (
 	"context"
 	"data-storage-svc/internal/model"
-	"errors"
+	"log/slog"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,10 +13,18 @@ import (
 type AlbumRepository interface {
 	// Retrieve a specific album from its ID
 	GetById(id primitive.ObjectID) (*model.Album, error)
+	// Retrieve all albums created by a given user
+	GetAllByAuthor(authorId *primitive.ObjectID) ([]model.Album, error)
+	// Retrieve the album having the given slug, public or not
+	GetBySlug(slug string) (*model.Album, error)
+	// Retrieve all public albums listed in the public directory
+	GetAllListed() ([]model.Album, error)
 	// Create a new album resource in the DB
 	Create(album *model.Album) (*primitive.ObjectID, error)
 	// Update an existing album in the DB
 	Update(album *model.Album) error
+	// Give an album to another author, fails with mongo.ErrNoDocuments if the album is no longer the previous author's
+	SetAuthor(albumId *primitive.ObjectID, previousAuthorId *primitive.ObjectID, authorId *primitive.ObjectID) error
 	// Delete an existing album in the DB
 	Delete(albumId *primitive.ObjectID) error
 }
@@ -54,8 +62,65 @@ func (r albumRepository) Create(Album *model.Album) (*primitive.ObjectID, error)
 	return &generatedId, nil
 }
 
+func (r albumRepository) GetAllByAuthor(authorId *primitive.ObjectID) ([]model.Album, error) {
+	return r.find(bson.M{"authorId": authorId})
+}
+
+func (r albumRepository) GetBySlug(slug string) (*model.Album, error) {
+	var album model.Album
+	if err := r.db.Collection(ALBUM_COLLECTION).FindOne(context.Background(), bson.M{"slug": slug}).Decode(&album); err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+func (r albumRepository) GetAllListed() ([]model.Album, error) {
+	return r.find(bson.M{"public": true, "listed": true})
+}
+
+func (r albumRepository) find(filter bson.M) ([]model.Album, error) {
+	cursor, err := r.db.Collection(ALBUM_COLLECTION).Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var albums []model.Album = make([]model.Album, 0)
+	for cursor.Next(context.Background()) {
+		var album model.Album
+		if err = cursor.Decode(&album); err != nil {
+			slog.Error("Couldn't decode album", "error", err)
+		} else {
+			albums = append(albums, album)
+		}
+	}
+	return albums, nil
+}
+
 func (r albumRepository) Update(album *model.Album) error {
-	return errors.New("not yet implemented")
+	filter := bson.M{"_id": album.Id}
+	result, err := r.db.Collection(ALBUM_COLLECTION).ReplaceOne(context.Background(), filter, album)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r albumRepository) SetAuthor(albumId *primitive.ObjectID, previousAuthorId *primitive.ObjectID, authorId *primitive.ObjectID) error {
+	// Only the author changes, so that concurrent updates of the album are not overwritten
+	filter := bson.M{"_id": albumId, "authorId": previousAuthorId}
+	result, err := r.db.Collection(ALBUM_COLLECTION).UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"authorId": authorId}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
 }
 
 func (r albumRepository) Delete(albumId *primitive.ObjectID) error {
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	API_KEY_COLLECTION = "api_keys"
+)
+
+type ApiKeyRepository interface {
+	// Create a new API key in DB
+	Create(apiKey *model.ApiKey) (*primitive.ObjectID, error)
+	// Get an API key from the hash of the key
+	GetByHash(keyHash string) (*model.ApiKey, error)
+	// Get all API keys of a user
+	GetAllForUser(userId *primitive.ObjectID) ([]model.ApiKey, error)
+	// Revoke an API key of a user, returns mongo.ErrNoDocuments if the user has no such key
+	Revoke(userId *primitive.ObjectID, apiKeyId *primitive.ObjectID) error
+	// Delete all API keys of a user
+	DeleteAllForUser(userId *primitive.ObjectID) error
+	// Record the last time an API key was used
+	SetLastUsed(apiKeyId *primitive.ObjectID, lastUsed time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *mongo.Database
+}
+
+func NewApiKeyRepository(db *mongo.Database) apiKeyRepository {
+	return apiKeyRepository{db}
+}
+
+func (r apiKeyRepository) Create(apiKey *model.ApiKey) (*primitive.ObjectID, error) {
+	result, err := r.db.Collection(API_KEY_COLLECTION).InsertOne(context.Background(), apiKey)
+	if err != nil {
+		return nil, err
+	}
+	generatedId := result.InsertedID.(primitive.ObjectID)
+	return &generatedId, nil
+}
+
+func (r apiKeyRepository) GetByHash(keyHash string) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	err := r.db.Collection(API_KEY_COLLECTION).FindOne(context.Background(), bson.M{"keyHash": keyHash}).Decode(&apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r apiKeyRepository) GetAllForUser(userId *primitive.ObjectID) ([]model.ApiKey, error) {
+	cursor, err := r.db.Collection(API_KEY_COLLECTION).Find(context.Background(), bson.M{"userId": userId})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var apiKeys []model.ApiKey = make([]model.ApiKey, 0)
+	for cursor.Next(context.Background()) {
+		var apiKey model.ApiKey
+		if err = cursor.Decode(&apiKey); err != nil {
+			return nil, fmt.Errorf("unable to decode api key from database")
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, nil
+}
+
+func (r apiKeyRepository) Revoke(userId *primitive.ObjectID, apiKeyId *primitive.ObjectID) error {
+	filter := bson.M{"_id": apiKeyId, "userId": userId}
+	update := bson.M{"$set": bson.M{"revoked": true, "revokedAt": time.Now()}}
+	result, err := r.db.Collection(API_KEY_COLLECTION).UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r apiKeyRepository) SetLastUsed(apiKeyId *primitive.ObjectID, lastUsed time.Time) error {
+	filter := bson.M{"_id": apiKeyId}
+	update := bson.M{"$set": bson.M{"lastUsedAt": lastUsed}}
+	_, err := r.db.Collection(API_KEY_COLLECTION).UpdateOne(context.Background(), filter, update)
+	return err
+}
+
+func (r apiKeyRepository) DeleteAllForUser(userId *primitive.ObjectID) error {
+	_, err := r.db.Collection(API_KEY_COLLECTION).DeleteMany(context.Background(), bson.M{"userId": userId})
+	return err
+}
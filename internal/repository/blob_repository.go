@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	BLOB_COLLECTION = "blobs"
+)
+
+type BlobRepository interface {
+	// Add a reference to the blob with the given hash, creating it with the given file if it does not exist yet.
+	// Returns the blob, whose file may differ from the given one when the content was already stored.
+	Acquire(hash string, storageFileName string, size int64) (*model.Blob, error)
+	// Remove a reference to the blob stored in the given file. Returns true when the file is not referenced anymore
+	// and can be deleted (also the case for files stored before deduplication, which are not tracked by any blob).
+	Release(hash string, storageFileName string) (bool, error)
+	// Get all blobs
+	GetAll() ([]model.Blob, error)
+	// Create or replace a blob
+	Save(blob *model.Blob) error
+	// Delete a blob from DB (will not delete the underlying file)
+	Delete(hash string) error
+}
+
+type blobRepository struct {
+	db *mongo.Database
+}
+
+func NewBlobRepository(db *mongo.Database) blobRepository {
+	return blobRepository{db}
+}
+
+func (r blobRepository) Acquire(hash string, storageFileName string, size int64) (*model.Blob, error) {
+	filter := bson.M{"_id": hash}
+	update := bson.M{
+		"$inc":         bson.M{"refCount": 1},
+		"$setOnInsert": bson.M{"storageFileName": storageFileName, "size": size, "createdAt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var blob model.Blob
+	err := r.db.Collection(BLOB_COLLECTION).FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&blob)
+	if err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+func (r blobRepository) Release(hash string, storageFileName string) (bool, error) {
+	filter := bson.M{"_id": hash, "storageFileName": storageFileName}
+	update := bson.M{"$inc": bson.M{"refCount": -1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var blob model.Blob
+	err := r.db.Collection(BLOB_COLLECTION).FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&blob)
+	if err == mongo.ErrNoDocuments {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if blob.RefCount > 0 {
+		return false, nil
+	}
+	// Only delete the blob if nobody acquired it in the meantime
+	result, err := r.db.Collection(BLOB_COLLECTION).DeleteOne(context.Background(), bson.M{"_id": hash, "refCount": bson.M{"$lte": 0}})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+func (r blobRepository) GetAll() ([]model.Blob, error) {
+	cursor, err := r.db.Collection(BLOB_COLLECTION).Find(context.Background(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var blobs []model.Blob = make([]model.Blob, 0)
+	for cursor.Next(context.Background()) {
+		var blob model.Blob
+		if err = cursor.Decode(&blob); err != nil {
+			return nil, fmt.Errorf("unable to decode blob from database")
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, nil
+}
+
+func (r blobRepository) Save(blob *model.Blob) error {
+	filter := bson.M{"_id": blob.Hash}
+	_, err := r.db.Collection(BLOB_COLLECTION).ReplaceOne(context.Background(), filter, blob, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r blobRepository) Delete(hash string) error {
+	filter := bson.M{"_id": hash}
+	_, err := r.db.Collection(BLOB_COLLECTION).DeleteOne(context.Background(), filter)
+	return err
+}
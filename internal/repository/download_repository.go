@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"data-storage-svc/internal/model"
+	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,6 +21,10 @@ type DownloadRepository interface {
 	MarkAsReady(downloadId *primitive.ObjectID) error
 	// Get a download by ID
 	Get(downloadId *primitive.ObjectID) (*model.Download, error)
+	// Get all downloads
+	GetAll() ([]model.Download, error)
+	// Delete a download from DB (will not delete the underlying zip file)
+	Delete(downloadId *primitive.ObjectID) error
 }
 
 type downloadRepository struct {
@@ -56,3 +61,28 @@ func (r downloadRepository) Get(downloadId *primitive.ObjectID) (*model.Download
 	}
 	return &download, err
 }
+
+func (r downloadRepository) GetAll() ([]model.Download, error) {
+	cursor, err := r.db.Collection(DOWNLOAD_COLLECTION).Find(context.Background(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var downloads []model.Download = make([]model.Download, 0)
+	for cursor.Next(context.Background()) {
+		var download model.Download
+		if err = cursor.Decode(&download); err != nil {
+			return nil, fmt.Errorf("unable to decode download from database")
+		}
+		downloads = append(downloads, download)
+	}
+	return downloads, nil
+}
+
+func (r downloadRepository) Delete(downloadId *primitive.ObjectID) error {
+	filter := bson.M{"_id": downloadId}
+	_, err := r.db.Collection(DOWNLOAD_COLLECTION).DeleteOne(context.Background(), filter)
+	return err
+}
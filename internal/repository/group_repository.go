@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	GROUP_COLLECTION = "groups"
+)
+
+type GroupRepository interface {
+	// Create a new group in DB
+	Create(group *model.Group) (*primitive.ObjectID, error)
+	// Get a group by id
+	Get(groupId *primitive.ObjectID) (*model.Group, error)
+	// Get all groups a user is a member of
+	GetAllByMember(userId *primitive.ObjectID) ([]model.Group, error)
+	// Get all groups owned by a user
+	GetAllByOwner(userId *primitive.ObjectID) ([]model.Group, error)
+	// Add a member to a group, nothing changes if the user is already a member
+	AddMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) error
+	// Remove a member from a group
+	RemoveMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) error
+	// Remove a user from all the groups they are a member of
+	RemoveMemberFromAll(userId *primitive.ObjectID) error
+	// Give a group to another user, who becomes a member
+	SetOwner(groupId *primitive.ObjectID, ownerId *primitive.ObjectID) error
+	// Delete a group
+	Delete(groupId *primitive.ObjectID) error
+}
+
+type groupRepository struct {
+	db *mongo.Database
+}
+
+func NewGroupRepository(db *mongo.Database) groupRepository {
+	return groupRepository{db}
+}
+
+func (r groupRepository) Create(group *model.Group) (*primitive.ObjectID, error) {
+	result, err := r.db.Collection(GROUP_COLLECTION).InsertOne(context.Background(), group)
+	if err != nil {
+		return nil, err
+	}
+	generatedId := result.InsertedID.(primitive.ObjectID)
+	return &generatedId, nil
+}
+
+func (r groupRepository) Get(groupId *primitive.ObjectID) (*model.Group, error) {
+	var group model.Group
+	err := r.db.Collection(GROUP_COLLECTION).FindOne(context.Background(), bson.M{"_id": groupId}).Decode(&group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r groupRepository) GetAllByMember(userId *primitive.ObjectID) ([]model.Group, error) {
+	return r.find(bson.M{"memberIds": userId})
+}
+
+func (r groupRepository) GetAllByOwner(userId *primitive.ObjectID) ([]model.Group, error) {
+	return r.find(bson.M{"ownerId": userId})
+}
+
+func (r groupRepository) find(filter bson.M) ([]model.Group, error) {
+	cursor, err := r.db.Collection(GROUP_COLLECTION).Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var groups []model.Group = make([]model.Group, 0)
+	for cursor.Next(context.Background()) {
+		var group model.Group
+		if err = cursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("unable to decode group from database")
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func (r groupRepository) AddMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) error {
+	return r.update(groupId, bson.M{"$addToSet": bson.M{"memberIds": userId}})
+}
+
+func (r groupRepository) RemoveMember(groupId *primitive.ObjectID, userId *primitive.ObjectID) error {
+	return r.update(groupId, bson.M{"$pull": bson.M{"memberIds": userId}})
+}
+
+func (r groupRepository) RemoveMemberFromAll(userId *primitive.ObjectID) error {
+	_, err := r.db.Collection(GROUP_COLLECTION).UpdateMany(context.Background(), bson.M{"memberIds": userId}, bson.M{"$pull": bson.M{"memberIds": userId}})
+	return err
+}
+
+func (r groupRepository) SetOwner(groupId *primitive.ObjectID, ownerId *primitive.ObjectID) error {
+	return r.update(groupId, bson.M{"$set": bson.M{"ownerId": ownerId}, "$addToSet": bson.M{"memberIds": ownerId}})
+}
+
+func (r groupRepository) update(groupId *primitive.ObjectID, update bson.M) error {
+	result, err := r.db.Collection(GROUP_COLLECTION).UpdateOne(context.Background(), bson.M{"_id": groupId}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r groupRepository) Delete(groupId *primitive.ObjectID) error {
+	_, err := r.db.Collection(GROUP_COLLECTION).DeleteOne(context.Background(), bson.M{"_id": groupId})
+	return err
+}
@@ -20,6 +20,8 @@ type MediaAccessRepository interface {
 	RemoveAll(mediaId *primitive.ObjectID) error
 	// Get all media accesses associated to a given user
 	GetAllForUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, error)
+	// Get all media accesses to a given media
+	GetAllForMedia(mediaId *primitive.ObjectID) ([]model.UserMediaAccess, error)
 	// Get a media access (if it exists) from user and media
 	Get(userId *primitive.ObjectID, mediaId *primitive.ObjectID) (*model.UserMediaAccess, error)
 }
@@ -53,7 +55,14 @@ func (r mediaAccessRepository) RemoveAll(mediaId *primitive.ObjectID) error {
 }
 
 func (r mediaAccessRepository) GetAllForUser(userId *primitive.ObjectID) ([]model.UserMediaAccess, error) {
-	filter := bson.D{{Key: "userId", Value: userId}}
+	return r.find(bson.D{{Key: "userId", Value: userId}})
+}
+
+func (r mediaAccessRepository) GetAllForMedia(mediaId *primitive.ObjectID) ([]model.UserMediaAccess, error) {
+	return r.find(bson.D{{Key: "mediaId", Value: mediaId}})
+}
+
+func (r mediaAccessRepository) find(filter bson.D) ([]model.UserMediaAccess, error) {
 	cursor, err := r.db.Collection(USER_MEDIA_ACCESS_COLLECTION).Find(context.Background(), filter)
 	if err != nil {
 		return nil, err
@@ -22,8 +22,12 @@ type MediaInAlbumRepository interface {
 	UnlinkAlbumFromAllMedias(albumId *primitive.ObjectID) error
 	// List all medias in an album
 	ListAllMedias(albumId *primitive.ObjectID) ([]model.MediaInAlbum, error)
+	// List all albums a media is in
+	ListAllAlbums(mediaId *primitive.ObjectID) ([]model.MediaInAlbum, error)
 	// Check if a given media in in a given album
 	IsInAlbum(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) bool
+	// Get the link between a media and an album, to know who added the media
+	Get(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.MediaInAlbum, error)
 }
 
 type mediaInAlbumRepository struct {
@@ -58,7 +62,14 @@ func (r mediaInAlbumRepository) UnlinkAlbumFromAllMedias(albumId *primitive.Obje
 }
 
 func (r mediaInAlbumRepository) ListAllMedias(albumId *primitive.ObjectID) ([]model.MediaInAlbum, error) {
-	filter := bson.M{"albumId": albumId}
+	return r.find(bson.M{"albumId": albumId})
+}
+
+func (r mediaInAlbumRepository) ListAllAlbums(mediaId *primitive.ObjectID) ([]model.MediaInAlbum, error) {
+	return r.find(bson.M{"mediaId": mediaId})
+}
+
+func (r mediaInAlbumRepository) find(filter bson.M) ([]model.MediaInAlbum, error) {
 	cursor, err := r.db.Collection(MEDIA_IN_ALBUM_COLLECTION).Find(context.Background(), filter)
 	if err != nil {
 		return nil, err
@@ -83,3 +94,12 @@ func (r mediaInAlbumRepository) IsInAlbum(mediaId *primitive.ObjectID, albumId *
 	result := r.db.Collection(MEDIA_IN_ALBUM_COLLECTION).FindOne(context.Background(), filter)
 	return result.Err() == nil
 }
+
+func (r mediaInAlbumRepository) Get(mediaId *primitive.ObjectID, albumId *primitive.ObjectID) (*model.MediaInAlbum, error) {
+	var mediaInAlbum model.MediaInAlbum
+	err := r.db.Collection(MEDIA_IN_ALBUM_COLLECTION).FindOne(context.Background(), bson.M{"mediaId": mediaId, "albumId": albumId}).Decode(&mediaInAlbum)
+	if err != nil {
+		return nil, err
+	}
+	return &mediaInAlbum, nil
+}
@@ -17,10 +17,24 @@ type MediaRepository interface {
 	Get(mediaId *primitive.ObjectID) (*model.Media, error)
 	// Get all media uploaded by a given user
 	GetAllUploadedBy(userId *primitive.ObjectID) ([]model.Media, error)
+	// Get all media counted against the quota of a given user
+	GetAllChargedTo(userId *primitive.ObjectID) ([]model.Media, error)
+	// Get the media with the given content hash uploaded by a given user
+	GetByHash(hash string, uploader *primitive.ObjectID) (*model.Media, error)
+	// Get any media whose original is stored in the given file
+	GetOneByStorageFileName(storageFileName string) (*model.Media, error)
+	// Get all medias whose original is stored in the given file
+	GetAllByStorageFileName(storageFileName string) ([]model.Media, error)
+	// Get all medias stored in DB
+	GetAll() ([]model.Media, error)
+	// Get all medias whose original file is in the given storage tier
+	GetAllInTier(tier model.StorageTier) ([]model.Media, error)
 	// Delete a media from media collection only (will not delete underlying file or any other link!)
 	Delete(mediaId *primitive.ObjectID) error
-	// Update a media
+	// Set the given fields of a media
 	Update(mediaId *primitive.ObjectID, update bson.M) error
+	// Update all medias sharing the given original file
+	UpdateAllByStorageFileName(storageFileName string, update bson.M) error
 }
 
 type mediaRepository struct {
@@ -55,8 +69,48 @@ func (r mediaRepository) Get(mediaId *primitive.ObjectID) (*model.Media, error)
 	return &media, err
 }
 
+func (r mediaRepository) GetByHash(hash string, uploader *primitive.ObjectID) (*model.Media, error) {
+	return r.findOne(bson.M{"hash": hash, "uploadedBy": uploader})
+}
+
+func (r mediaRepository) GetOneByStorageFileName(storageFileName string) (*model.Media, error) {
+	return r.findOne(bson.M{"storageFileName": storageFileName})
+}
+
+func (r mediaRepository) GetAllByStorageFileName(storageFileName string) ([]model.Media, error) {
+	return r.find(bson.M{"storageFileName": storageFileName})
+}
+
+func (r mediaRepository) findOne(filter bson.M) (*model.Media, error) {
+	var media model.Media
+	err := r.db.Collection(MEDIA_COLLECTION).FindOne(context.Background(), filter).Decode(&media)
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
 func (r mediaRepository) GetAllUploadedBy(userId *primitive.ObjectID) ([]model.Media, error) {
-	filter := bson.M{"uploadedBy": userId}
+	return r.find(bson.M{"uploadedBy": userId})
+}
+
+func (r mediaRepository) GetAllChargedTo(userId *primitive.ObjectID) ([]model.Media, error) {
+	return r.find(bson.M{"chargedTo": userId})
+}
+
+func (r mediaRepository) GetAll() ([]model.Media, error) {
+	return r.find(bson.M{})
+}
+
+func (r mediaRepository) GetAllInTier(tier model.StorageTier) ([]model.Media, error) {
+	if tier == model.TIER_HOT {
+		// Medias uploaded before tiering have no tier
+		return r.find(bson.M{"originalTier": bson.M{"$in": bson.A{nil, model.TIER_HOT}}})
+	}
+	return r.find(bson.M{"originalTier": tier})
+}
+
+func (r mediaRepository) find(filter bson.M) ([]model.Media, error) {
 	cursor, err := r.db.Collection(MEDIA_COLLECTION).Find(context.Background(), filter)
 	if err != nil {
 		return nil, err
@@ -89,3 +143,11 @@ func (r mediaRepository) Update(mediaId *primitive.ObjectID, update bson.M) erro
 	_, err := r.db.Collection(MEDIA_COLLECTION).UpdateOne(context.Background(), filter, updateDoc)
 	return err
 }
+
+func (r mediaRepository) UpdateAllByStorageFileName(storageFileName string, update bson.M) error {
+	filter := bson.M{"storageFileName": storageFileName}
+	updateDoc := bson.M{"$set": update}
+
+	_, err := r.db.Collection(MEDIA_COLLECTION).UpdateMany(context.Background(), filter, updateDoc)
+	return err
+}
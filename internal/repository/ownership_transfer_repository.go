@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	OWNERSHIP_TRANSFER_COLLECTION = "ownership_transfers"
+)
+
+type OwnershipTransferRepository interface {
+	// Create a new transfer in DB
+	Create(transfer *model.OwnershipTransfer) (*primitive.ObjectID, error)
+	// Get a transfer by id
+	Get(transferId *primitive.ObjectID) (*model.OwnershipTransfer, error)
+	// Get the pending transfer of an album, if any
+	GetPendingForAlbum(albumId *primitive.ObjectID) (*model.OwnershipTransfer, error)
+	// Get all the transfers of an album, the most recent first
+	GetAllByAlbum(albumId *primitive.ObjectID) ([]model.OwnershipTransfer, error)
+	// Get the pending transfers a user proposed, gives or receives
+	GetAllPendingForUser(userId *primitive.ObjectID) ([]model.OwnershipTransfer, error)
+	// Answer a pending transfer, fails with mongo.ErrNoDocuments if it was answered already
+	Resolve(transferId *primitive.ObjectID, status model.OwnershipTransferStatus, resolvedBy *primitive.ObjectID, transferredMedias int) error
+	// Put an accepted transfer back to pending, when applying it failed
+	Reopen(transferId *primitive.ObjectID) error
+	// Cancel the pending transfers given or received by a user
+	CancelAllPendingForUser(userId *primitive.ObjectID) error
+}
+
+type ownershipTransferRepository struct {
+	db *mongo.Database
+}
+
+func NewOwnershipTransferRepository(db *mongo.Database) ownershipTransferRepository {
+	return ownershipTransferRepository{db}
+}
+
+func (r ownershipTransferRepository) Create(transfer *model.OwnershipTransfer) (*primitive.ObjectID, error) {
+	result, err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).InsertOne(context.Background(), transfer)
+	if err != nil {
+		return nil, err
+	}
+	generatedId := result.InsertedID.(primitive.ObjectID)
+	return &generatedId, nil
+}
+
+func (r ownershipTransferRepository) Get(transferId *primitive.ObjectID) (*model.OwnershipTransfer, error) {
+	var transfer model.OwnershipTransfer
+	err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).FindOne(context.Background(), bson.M{"_id": transferId}).Decode(&transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r ownershipTransferRepository) GetPendingForAlbum(albumId *primitive.ObjectID) (*model.OwnershipTransfer, error) {
+	var transfer model.OwnershipTransfer
+	filter := bson.M{"albumId": albumId, "status": model.TRANSFER_STATUS_PENDING}
+	err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).FindOne(context.Background(), filter).Decode(&transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r ownershipTransferRepository) GetAllByAlbum(albumId *primitive.ObjectID) ([]model.OwnershipTransfer, error) {
+	return r.find(bson.M{"albumId": albumId})
+}
+
+func (r ownershipTransferRepository) GetAllPendingForUser(userId *primitive.ObjectID) ([]model.OwnershipTransfer, error) {
+	return r.find(bson.M{
+		"status": model.TRANSFER_STATUS_PENDING,
+		"$or":    bson.A{bson.M{"fromUserId": userId}, bson.M{"toUserId": userId}, bson.M{"proposedBy": userId}},
+	})
+}
+
+func (r ownershipTransferRepository) find(filter bson.M) ([]model.OwnershipTransfer, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "creationDate", Value: -1}})
+	cursor, err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var transfers []model.OwnershipTransfer = make([]model.OwnershipTransfer, 0)
+	for cursor.Next(context.Background()) {
+		var transfer model.OwnershipTransfer
+		if err = cursor.Decode(&transfer); err != nil {
+			return nil, fmt.Errorf("unable to decode ownership transfer from database")
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+func (r ownershipTransferRepository) Resolve(transferId *primitive.ObjectID, status model.OwnershipTransferStatus, resolvedBy *primitive.ObjectID, transferredMedias int) error {
+	// Only a pending transfer can be answered, so two answers cannot both succeed
+	filter := bson.M{"_id": transferId, "status": model.TRANSFER_STATUS_PENDING}
+	update := bson.M{"$set": bson.M{
+		"status":            status,
+		"resolvedBy":        resolvedBy,
+		"resolutionDate":    time.Now(),
+		"transferredMedias": transferredMedias,
+	}}
+	result, err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r ownershipTransferRepository) Reopen(transferId *primitive.ObjectID) error {
+	filter := bson.M{"_id": transferId, "status": model.TRANSFER_STATUS_ACCEPTED}
+	update := bson.M{
+		"$set":   bson.M{"status": model.TRANSFER_STATUS_PENDING, "transferredMedias": 0},
+		"$unset": bson.M{"resolvedBy": "", "resolutionDate": ""},
+	}
+	_, err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).UpdateOne(context.Background(), filter, update)
+	return err
+}
+
+func (r ownershipTransferRepository) CancelAllPendingForUser(userId *primitive.ObjectID) error {
+	filter := bson.M{
+		"status": model.TRANSFER_STATUS_PENDING,
+		"$or":    bson.A{bson.M{"fromUserId": userId}, bson.M{"toUserId": userId}},
+	}
+	update := bson.M{"$set": bson.M{"status": model.TRANSFER_STATUS_CANCELLED, "resolutionDate": time.Now()}}
+	_, err := r.db.Collection(OWNERSHIP_TRANSFER_COLLECTION).UpdateMany(context.Background(), filter, update)
+	return err
+}
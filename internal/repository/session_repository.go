@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	SESSION_COLLECTION = "sessions"
+)
+
+type SessionRepository interface {
+	// Create a new session in DB
+	Create(session *model.Session) (*primitive.ObjectID, error)
+	// Get a session by id
+	GetById(sessionId *primitive.ObjectID) (*model.Session, error)
+	// Get all unexpired sessions of a user
+	GetAllForUser(userId *primitive.ObjectID) ([]model.Session, error)
+	// Replace the refresh token of a session, only if its current token is still currentHash. Returns
+	// mongo.ErrNoDocuments if the token has been rotated in the meantime.
+	Rotate(sessionId *primitive.ObjectID, currentHash string, newHash string, userAgent string, ip string, expiresAt time.Time) error
+	// Delete (revoke) a session of a user, returns mongo.ErrNoDocuments if the user has no such session
+	Delete(userId *primitive.ObjectID, sessionId *primitive.ObjectID) error
+	// Delete (revoke) all sessions of a user
+	DeleteAllForUser(userId *primitive.ObjectID) error
+}
+
+type sessionRepository struct {
+	db *mongo.Database
+}
+
+func NewSessionRepository(db *mongo.Database) sessionRepository {
+	return sessionRepository{db}
+}
+
+func (r sessionRepository) Create(session *model.Session) (*primitive.ObjectID, error) {
+	result, err := r.db.Collection(SESSION_COLLECTION).InsertOne(context.Background(), session)
+	if err != nil {
+		return nil, err
+	}
+	generatedId := result.InsertedID.(primitive.ObjectID)
+	return &generatedId, nil
+}
+
+func (r sessionRepository) GetById(sessionId *primitive.ObjectID) (*model.Session, error) {
+	var session model.Session
+	err := r.db.Collection(SESSION_COLLECTION).FindOne(context.Background(), bson.M{"_id": sessionId}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r sessionRepository) GetAllForUser(userId *primitive.ObjectID) ([]model.Session, error) {
+	filter := bson.M{"userId": userId, "expiresAt": bson.M{"$gt": time.Now()}}
+	cursor, err := r.db.Collection(SESSION_COLLECTION).Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var sessions []model.Session = make([]model.Session, 0)
+	for cursor.Next(context.Background()) {
+		var session model.Session
+		if err = cursor.Decode(&session); err != nil {
+			return nil, fmt.Errorf("unable to decode session from database")
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (r sessionRepository) Rotate(sessionId *primitive.ObjectID, currentHash string, newHash string, userAgent string, ip string, expiresAt time.Time) error {
+	filter := bson.M{"_id": sessionId, "refreshTokenHash": currentHash}
+	update := bson.M{"$set": bson.M{
+		"refreshTokenHash":         newHash,
+		"previousRefreshTokenHash": currentHash,
+		"userAgent":                userAgent,
+		"ip":                       ip,
+		"lastUsedAt":               time.Now(),
+		"expiresAt":                expiresAt,
+	}}
+	result, err := r.db.Collection(SESSION_COLLECTION).UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r sessionRepository) Delete(userId *primitive.ObjectID, sessionId *primitive.ObjectID) error {
+	result, err := r.db.Collection(SESSION_COLLECTION).DeleteOne(context.Background(), bson.M{"_id": sessionId, "userId": userId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r sessionRepository) DeleteAllForUser(userId *primitive.ObjectID) error {
+	_, err := r.db.Collection(SESSION_COLLECTION).DeleteMany(context.Background(), bson.M{"userId": userId})
+	return err
+}
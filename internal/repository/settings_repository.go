@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	SETTINGS_COLLECTION = "settings"
+	// Settings are stored in a single document
+	SETTINGS_ID = "global"
+)
+
+type SettingsRepository interface {
+	// Get the instance settings, default settings if never saved
+	Get() (*model.Settings, error)
+	// Update the instance settings
+	Update(update bson.M) error
+}
+
+type settingsRepository struct {
+	db *mongo.Database
+}
+
+func NewSettingsRepository(db *mongo.Database) settingsRepository {
+	return settingsRepository{db}
+}
+
+func (r settingsRepository) Get() (*model.Settings, error) {
+	var settings model.Settings
+	err := r.db.Collection(SETTINGS_COLLECTION).FindOne(context.Background(), bson.M{"_id": SETTINGS_ID}).Decode(&settings)
+	if err == mongo.ErrNoDocuments {
+		return &model.Settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r settingsRepository) Update(update bson.M) error {
+	filter := bson.M{"_id": SETTINGS_ID}
+	updateDoc := bson.M{"$set": update}
+	_, err := r.db.Collection(SETTINGS_COLLECTION).UpdateOne(context.Background(), filter, updateDoc, options.Update().SetUpsert(true))
+	return err
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"data-storage-svc/internal/model"
 	"log/slog"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SharedLinkRepository interface {
@@ -15,14 +17,21 @@ type SharedLinkRepository interface {
 	Create(sharedLink *model.SharedLink) (*primitive.ObjectID, error)
 	// Retrieve a shared link by ID
 	Get(sharedLinkId *primitive.ObjectID) (*model.SharedLink, error)
-	// Retrieve a shared link by its token
+	// Retrieve a shared link by its token, whether it is usable or not
 	GetByToken(sharedLinkToken string) (*model.SharedLink, error)
+	// Retrieve a usable shared link by its token, counting a use and recording the access time at once. Returns
+	// mongo.ErrNoDocuments if the link is unknown, revoked, expired or used up.
+	Use(sharedLinkToken string, now time.Time) (*model.SharedLink, error)
 	// List all shared link for a given album id
 	List(albumId *primitive.ObjectID) ([]model.SharedLink, error)
 	// Delete a given shared link
 	Delete(sharedLinkId primitive.ObjectID) error
 	// Update a given link
-	Update(sharedLinkId primitive.ObjectID, canEdit bool) error
+	Update(sharedLinkId primitive.ObjectID, update bson.M) error
+	// Give all links created by a user to another user
+	ReassignCreator(previousCreator *primitive.ObjectID, newCreator *primitive.ObjectID) error
+	// Delete all links created by a user
+	DeleteAllCreatedBy(userId *primitive.ObjectID) error
 }
 
 type sharedLinkRepository struct {
@@ -31,6 +40,8 @@ type sharedLinkRepository struct {
 
 const (
 	SHARED_LINK_COLLECTION = "shared_links"
+	// Expired links are kept for a while so their creator can still see or extend them, then Mongo purges them
+	SHARED_LINK_RETENTION = 30 * 24 * time.Hour
 )
 
 func NewSharedLinkRepository(db *mongo.Database) SharedLinkRepository {
@@ -68,6 +79,28 @@ func (r sharedLinkRepository) GetByToken(sharedLinkToken string) (*model.SharedL
 	return &sharedLink, err
 }
 
+func (r sharedLinkRepository) Use(sharedLinkToken string, now time.Time) (*model.SharedLink, error) {
+	filter := bson.M{
+		"token":      sharedLinkToken,
+		"revoked":    bson.M{"$ne": true},
+		"expiration": bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"maxUses": bson.M{"$not": bson.M{"$gt": 0}}},
+			bson.M{"$expr": bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{"$uses", 0}}, "$maxUses"}}},
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{"uses": 1},
+		"$set": bson.M{"lastAccessed": now},
+	}
+	var sharedLink model.SharedLink
+	err := r.db.Collection(SHARED_LINK_COLLECTION).FindOneAndUpdate(context.Background(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&sharedLink)
+	if err != nil {
+		return nil, err
+	}
+	return &sharedLink, nil
+}
+
 func (r sharedLinkRepository) List(albumId *primitive.ObjectID) ([]model.SharedLink, error) {
 	filter := bson.M{"albumId": albumId}
 	cursor, err := r.db.Collection(SHARED_LINK_COLLECTION).Find(context.Background(), filter)
@@ -96,12 +129,24 @@ func (r sharedLinkRepository) Delete(sharedLinkId primitive.ObjectID) error {
 	return err
 }
 
-func (r sharedLinkRepository) Update(sharedLinkId primitive.ObjectID, canEdit bool) error {
+func (r sharedLinkRepository) Update(sharedLinkId primitive.ObjectID, update bson.M) error {
+	_, err := r.db.Collection(SHARED_LINK_COLLECTION).UpdateByID(context.Background(), sharedLinkId, update)
+	return err
+}
+
+func (r sharedLinkRepository) ReassignCreator(previousCreator *primitive.ObjectID, newCreator *primitive.ObjectID) error {
+	filter := bson.M{"createdBy": previousCreator}
 	update := bson.M{
 		"$set": bson.M{
-			"canEdit": canEdit,
+			"createdBy": newCreator,
 		},
 	}
-	_, err := r.db.Collection(SHARED_LINK_COLLECTION).UpdateByID(context.Background(), sharedLinkId, update)
+	_, err := r.db.Collection(SHARED_LINK_COLLECTION).UpdateMany(context.Background(), filter, update)
+	return err
+}
+
+func (r sharedLinkRepository) DeleteAllCreatedBy(userId *primitive.ObjectID) error {
+	filter := bson.M{"createdBy": userId}
+	_, err := r.db.Collection(SHARED_LINK_COLLECTION).DeleteMany(context.Background(), filter)
 	return err
 }
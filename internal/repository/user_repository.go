@@ -18,10 +18,16 @@ type UserRepository interface {
 	GetByEmail(email string) (*model.User, error)
 	// Get a specific user by id
 	GetById(id *primitive.ObjectID) (*model.User, error)
+	// Get the user linked to an OpenID Connect subject
+	GetByOidcSubject(subject string) (*model.User, error)
 	// Get all users
 	GetAll() ([]model.User, error)
+	// Count the users
+	Count() (int64, error)
 	// Update a user's data
 	Update(id *primitive.ObjectID, update bson.M) error
+	// Delete a user (will not delete any resource owned by the user!)
+	Delete(id *primitive.ObjectID) error
 }
 
 type userRepository struct {
@@ -37,8 +43,12 @@ const (
 )
 
 func (r userRepository) Create(user *model.User) (*primitive.ObjectID, error) {
-	_, err := r.db.Collection(USER_COLLECTION).InsertOne(context.Background(), user)
-	return nil, err
+	result, err := r.db.Collection(USER_COLLECTION).InsertOne(context.Background(), user)
+	if err != nil {
+		return nil, err
+	}
+	generatedId := result.InsertedID.(primitive.ObjectID)
+	return &generatedId, nil
 }
 
 func (r userRepository) GetByEmail(email string) (*model.User, error) {
@@ -66,6 +76,15 @@ func (r userRepository) GetById(id *primitive.ObjectID) (*model.User, error) {
 	return &user, nil
 }
 
+func (r userRepository) GetByOidcSubject(subject string) (*model.User, error) {
+	var user model.User
+	err := r.db.Collection(USER_COLLECTION).FindOne(context.Background(), bson.M{"oidcSubject": subject}).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r userRepository) GetAll() ([]model.User, error) {
 	cursor, err := r.db.Collection(USER_COLLECTION).Find(context.Background(), bson.M{}, nil)
 	if err != nil {
@@ -86,7 +105,16 @@ func (r userRepository) GetAll() ([]model.User, error) {
 	return users, nil
 }
 
+func (r userRepository) Count() (int64, error) {
+	return r.db.Collection(USER_COLLECTION).CountDocuments(context.Background(), bson.M{})
+}
+
 func (r userRepository) Update(id *primitive.ObjectID, update bson.M) error {
 	_, err := r.db.Collection(USER_COLLECTION).UpdateOne(context.Background(), bson.M{"_id": id}, update)
 	return err
 }
+
+func (r userRepository) Delete(id *primitive.ObjectID) error {
+	_, err := r.db.Collection(USER_COLLECTION).DeleteOne(context.Background(), bson.M{"_id": id})
+	return err
+}
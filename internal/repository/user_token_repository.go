@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"data-storage-svc/internal/model"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	USER_TOKEN_COLLECTION = "user_tokens"
+)
+
+type UserTokenRepository interface {
+	// Create a new token in DB
+	Create(token *model.UserToken) (*primitive.ObjectID, error)
+	// Get a token of the given kind from its hash
+	GetByHash(kind model.UserTokenKind, tokenHash string) (*model.UserToken, error)
+	// Get all tokens of the given kind sent to an email
+	GetAllForEmail(kind model.UserTokenKind, email string) ([]model.UserToken, error)
+	// Delete a token
+	Delete(tokenId *primitive.ObjectID) error
+	// Delete all tokens of the given kind sent to an email
+	DeleteAllForEmail(kind model.UserTokenKind, email string) error
+}
+
+type userTokenRepository struct {
+	db *mongo.Database
+}
+
+func NewUserTokenRepository(db *mongo.Database) userTokenRepository {
+	return userTokenRepository{db}
+}
+
+func (r userTokenRepository) Create(token *model.UserToken) (*primitive.ObjectID, error) {
+	result, err := r.db.Collection(USER_TOKEN_COLLECTION).InsertOne(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+	generatedId := result.InsertedID.(primitive.ObjectID)
+	return &generatedId, nil
+}
+
+func (r userTokenRepository) GetByHash(kind model.UserTokenKind, tokenHash string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.Collection(USER_TOKEN_COLLECTION).FindOne(context.Background(), bson.M{"kind": kind, "tokenHash": tokenHash}).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r userTokenRepository) GetAllForEmail(kind model.UserTokenKind, email string) ([]model.UserToken, error) {
+	cursor, err := r.db.Collection(USER_TOKEN_COLLECTION).Find(context.Background(), bson.M{"kind": kind, "email": email})
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(context.Background())
+
+	var tokens []model.UserToken = make([]model.UserToken, 0)
+	for cursor.Next(context.Background()) {
+		var token model.UserToken
+		if err = cursor.Decode(&token); err != nil {
+			return nil, fmt.Errorf("unable to decode user token from database")
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (r userTokenRepository) Delete(tokenId *primitive.ObjectID) error {
+	_, err := r.db.Collection(USER_TOKEN_COLLECTION).DeleteOne(context.Background(), bson.M{"_id": tokenId})
+	return err
+}
+
+func (r userTokenRepository) DeleteAllForEmail(kind model.UserTokenKind, email string) error {
+	_, err := r.db.Collection(USER_TOKEN_COLLECTION).DeleteMany(context.Background(), bson.M{"kind": kind, "email": email})
+	return err
+}
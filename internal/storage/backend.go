@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"data-storage-svc/internal"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Size and modification time of a stored object
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// A place where media files, renditions and downloads are stored. Keys are slash separated paths, e.g. originalMedias/xxx.jpg
+type Backend interface {
+	// Store data under the given key, replacing any existing object. Size is -1 when unknown.
+	Put(key string, data io.Reader, size int64) error
+	// Read an object starting at offset. Reads until the end of the object when length is -1.
+	Get(key string, offset int64, length int64) (io.ReadCloser, error)
+	// Delete an object. Deleting an object that does not exist is not an error.
+	Delete(key string) error
+	// Get information about a given object, returns ErrNotFound if it does not exist
+	Stat(key string) (*ObjectInfo, error)
+	// List all objects whose key starts with the given prefix
+	List(prefix string) ([]ObjectInfo, error)
+}
+
+// Create the backend selected in the configuration
+func New() (Backend, error) {
+	switch internal.STORAGE_BACKEND {
+	case "", "local":
+		return NewLocalBackend(internal.DATA_DIRECTORY), nil
+	case "s3":
+		return NewS3Backend(S3Config{
+			Endpoint:  internal.S3_ENDPOINT,
+			Bucket:    internal.S3_BUCKET,
+			AccessKey: internal.S3_ACCESS_KEY,
+			SecretKey: internal.S3_SECRET_KEY,
+			Region:    internal.S3_REGION,
+			UseSSL:    internal.S3_USE_SSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend [%s]", internal.STORAGE_BACKEND)
+	}
+}
+
+// Create the archive backend selected in the configuration, where originals are moved once they are rarely accessed.
+// Returns a nil backend when tiering is disabled.
+func NewArchive() (Backend, error) {
+	switch internal.ARCHIVE_BACKEND {
+	case "":
+		return nil, nil
+	case "local":
+		if internal.ARCHIVE_DIRECTORY == "" {
+			return nil, fmt.Errorf("an archive directory is needed for the local archive backend")
+		}
+		return NewLocalBackend(internal.ARCHIVE_DIRECTORY), nil
+	case "s3":
+		return NewS3Backend(S3Config{
+			Endpoint:  internal.S3_ENDPOINT,
+			Bucket:    internal.ARCHIVE_S3_BUCKET,
+			AccessKey: internal.S3_ACCESS_KEY,
+			SecretKey: internal.S3_SECRET_KEY,
+			Region:    internal.S3_REGION,
+			UseSSL:    internal.S3_USE_SSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown archive backend [%s]", internal.ARCHIVE_BACKEND)
+	}
+}
+
+// Copy an object from one backend to another, keeping the same key
+func Copy(from Backend, to Backend, key string) error {
+	info, err := from.Stat(key)
+	if err != nil {
+		return err
+	}
+	data, err := from.Get(key, 0, -1)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+	return to.Put(key, data, info.Size)
+}
+
+// Store a local file under the given key, the local file is removed once stored
+func MoveFile(backend Backend, key string, localPath string) error {
+	if mover, ok := backend.(interface {
+		moveFile(key string, localPath string) error
+	}); ok {
+		return mover.moveFile(key, localPath)
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := backend.Put(key, file, info.Size()); err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(localPath)
+}
+
+// Get a local file holding the object content, e.g. to feed it to ffmpeg. The cleanup function must be called once
+// the file is not needed anymore.
+func LocalFile(backend Backend, key string) (string, func(), error) {
+	if local, ok := backend.(interface{ localPath(key string) string }); ok {
+		path := local.localPath(key)
+		if _, err := os.Stat(path); err != nil {
+			return "", nil, ErrNotFound
+		}
+		return path, func() {}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "media-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+	data, err := backend.Get(key, 0, -1)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	defer data.Close()
+	path := filepath.Join(tmpDir, filepath.Base(key))
+	file, err := os.Create(path)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, data); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return path, cleanup, nil
+}
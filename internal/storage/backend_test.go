@@ -0,0 +1,156 @@
+package storage_test
+
+import (
+	"bytes"
+	"data-storage-svc/internal/storage"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/assert"
+)
+
+// Every backend must behave the same way, run the same checks against each driver
+func backends(t *testing.T) map[string]storage.Backend {
+	// In memory S3 server standing in for MinIO
+	server := httptest.NewServer(gofakes3.New(s3mem.New()).Server())
+	t.Cleanup(server.Close)
+	s3, err := storage.NewS3Backend(storage.S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    "test-bucket",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Region:    "us-east-1",
+		UseSSL:    false,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create s3 backend: %s", err)
+	}
+	return map[string]storage.Backend{
+		"local": storage.NewLocalBackend(t.TempDir()),
+		"s3":    s3,
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			// Known size
+			assert.NoError(t, backend.Put("originalMedias/a.jpg", strings.NewReader("hello world"), 11))
+			// Unknown size
+			assert.NoError(t, backend.Put("downloads/b.zip", strings.NewReader("zip content"), -1))
+
+			reader, err := backend.Get("originalMedias/a.jpg", 0, -1)
+			assert.NoError(t, err)
+			content, _ := io.ReadAll(reader)
+			reader.Close()
+			assert.Equal(t, "hello world", string(content))
+
+			// Range read
+			reader, err = backend.Get("originalMedias/a.jpg", 6, 3)
+			assert.NoError(t, err)
+			content, _ = io.ReadAll(reader)
+			reader.Close()
+			assert.Equal(t, "wor", string(content))
+
+			// Replace
+			assert.NoError(t, backend.Put("originalMedias/a.jpg", strings.NewReader("bye"), 3))
+			info, err := backend.Stat("originalMedias/a.jpg")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(3), info.Size)
+			assert.Equal(t, "originalMedias/a.jpg", info.Key)
+
+			info, err = backend.Stat("downloads/b.zip")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(11), info.Size)
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			_, err := backend.Stat("originalMedias/missing.jpg")
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+			_, err = backend.Get("originalMedias/missing.jpg", 0, -1)
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+			// Deleting a missing object is fine
+			assert.NoError(t, backend.Delete("originalMedias/missing.jpg"))
+		})
+	}
+}
+
+func TestListDelete(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, backend.Put("compressedMedias/1.jpg", strings.NewReader("1"), 1))
+			assert.NoError(t, backend.Put("compressedMedias/2.jpg", strings.NewReader("2"), 1))
+			assert.NoError(t, backend.Put("originalMedias/3.jpg", strings.NewReader("3"), 1))
+
+			objects, err := backend.List("compressedMedias/")
+			assert.NoError(t, err)
+			keys := []string{}
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+			}
+			assert.ElementsMatch(t, []string{"compressedMedias/1.jpg", "compressedMedias/2.jpg"}, keys)
+
+			assert.NoError(t, backend.Delete("compressedMedias/1.jpg"))
+			objects, err = backend.List("compressedMedias/")
+			assert.NoError(t, err)
+			assert.Len(t, objects, 1)
+
+			// Listing an empty prefix is not an error
+			objects, err = backend.List("downloads/")
+			assert.NoError(t, err)
+			assert.Len(t, objects, 0)
+		})
+	}
+}
+
+func TestOpenSeek(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			data := bytes.Repeat([]byte("0123456789"), 100)
+			assert.NoError(t, backend.Put("originalMedias/seek.mp4", bytes.NewReader(data), int64(len(data))))
+
+			reader, info, err := storage.Open(backend, "originalMedias/seek.mp4")
+			assert.NoError(t, err)
+			defer reader.Close()
+			assert.Equal(t, int64(len(data)), info.Size)
+
+			end, err := reader.Seek(0, io.SeekEnd)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(len(data)), end)
+
+			_, err = reader.Seek(505, io.SeekStart)
+			assert.NoError(t, err)
+			buffer := make([]byte, 5)
+			_, err = io.ReadFull(reader, buffer)
+			assert.NoError(t, err)
+			assert.Equal(t, "56789", string(buffer))
+		})
+	}
+}
+
+func TestMoveAndLocalFile(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			localPath := t.TempDir() + "/staged"
+			assert.NoError(t, os.WriteFile(localPath, []byte("staged content"), 0644))
+			assert.NoError(t, storage.MoveFile(backend, "originalMedias/moved.jpg", localPath))
+			assert.NoFileExists(t, localPath)
+
+			path, cleanup, err := storage.LocalFile(backend, "originalMedias/moved.jpg")
+			assert.NoError(t, err)
+			defer cleanup()
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, "staged content", string(content))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Store objects as plain files under a root directory
+type localBackend struct {
+	root string
+}
+
+func NewLocalBackend(root string) Backend {
+	return localBackend{root}
+}
+
+func (b localBackend) localPath(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (b localBackend) Put(key string, data io.Reader, size int64) error {
+	target := b.localPath(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that readers never see a partially written object
+	tmp := target + ".tmp-" + uuid.NewString()
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, data); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+func (b localBackend) moveFile(key string, localPath string) error {
+	target := b.localPath(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(localPath, target); err == nil {
+		return nil
+	}
+	// Renaming fails across file systems, fallback to a copy
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := b.Put(key, file, -1); err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(localPath)
+}
+
+type limitedFile struct {
+	io.Reader
+	file *os.File
+}
+
+func (l limitedFile) Close() error {
+	return l.file.Close()
+}
+
+func (b localBackend) Get(key string, offset int64, length int64) (io.ReadCloser, error) {
+	file, err := os.Open(b.localPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return file, nil
+	}
+	return limitedFile{io.LimitReader(file, length), file}, nil
+}
+
+func (b localBackend) Delete(key string) error {
+	err := os.Remove(b.localPath(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b localBackend) Stat(key string) (*ObjectInfo, error) {
+	info, err := os.Stat(b.localPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (b localBackend) List(prefix string) ([]ObjectInfo, error) {
+	// Only walk the deepest directory covering the prefix
+	walkRoot := b.root
+	if index := strings.LastIndex(prefix, "/"); index >= 0 {
+		walkRoot = b.localPath(prefix[:index])
+	}
+	objects := make([]ObjectInfo, 0)
+	err := filepath.WalkDir(walkRoot, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(b.root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relativePath)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
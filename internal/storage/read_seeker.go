@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// Lazily read an object through ranged reads, so that it can be served with http.ServeContent without downloading it
+// entirely first
+type objectReader struct {
+	backend Backend
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+// Open an object for random access reading. The returned reader must be closed.
+func Open(backend Backend, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	info, err := backend.Stat(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &objectReader{backend: backend, key: key, size: info.Size}, info, nil
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.backend.Get(r.key, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = r.offset + offset
+	case io.SeekEnd:
+		position = r.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if position < 0 {
+		return 0, errors.New("negative position")
+	}
+	if position != r.offset && r.body != nil {
+		// The current stream is not positioned correctly anymore, open a new one on next read
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = position
+	return position, nil
+}
+
+func (r *objectReader) Close() error {
+	if r.body != nil {
+		return r.body.Close()
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Size of the parts sent when the object size is unknown, keeps memory usage low on small hosts
+const S3_PART_SIZE = 16 * 1024 * 1024
+
+type S3Config struct {
+	// Host (and port) of the S3 compatible API, e.g. s3.eu-west-3.amazonaws.com or localhost:9000
+	Endpoint  string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Region    string
+	UseSSL    bool
+}
+
+// Store objects in an S3 compatible object storage (AWS S3, MinIO, Garage...)
+type s3Backend struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Backend(config S3Config) (Backend, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Create the bucket on first use
+	exists, err := client.BucketExists(context.Background(), config.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't check bucket [%s] - error [%s]", config.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(context.Background(), config.Bucket, minio.MakeBucketOptions{Region: config.Region}); err != nil {
+			return nil, fmt.Errorf("couldn't create bucket [%s] - error [%s]", config.Bucket, err)
+		}
+	}
+	return s3Backend{client: client, bucket: config.Bucket}, nil
+}
+
+func (b s3Backend) Put(key string, data io.Reader, size int64) error {
+	opts := minio.PutObjectOptions{}
+	if size < 0 {
+		// Parts of unknown length are sent as plain unsigned payloads rather than aws-chunked ones,
+		// which not every S3 compatible server decodes
+		opts.PartSize = S3_PART_SIZE
+		opts.DisableContentSha256 = true
+	}
+	_, err := b.client.PutObject(context.Background(), b.bucket, key, data, size, opts)
+	return err
+}
+
+func (b s3Backend) Get(key string, offset int64, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if length >= 0 {
+		if length == 0 {
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+		opts.SetRange(offset, offset+length-1)
+	} else if offset > 0 {
+		opts.SetRange(offset, 0)
+	}
+	// GetObject is lazy, stat the object first to report missing keys right away
+	if _, err := b.Stat(key); err != nil {
+		return nil, err
+	}
+	object, err := b.client.GetObject(context.Background(), b.bucket, key, opts)
+	if err != nil {
+		return nil, toStorageError(err)
+	}
+	return object, nil
+}
+
+func (b s3Backend) Delete(key string) error {
+	return b.client.RemoveObject(context.Background(), b.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (b s3Backend) Stat(key string) (*ObjectInfo, error) {
+	info, err := b.client.StatObject(context.Background(), b.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, toStorageError(err)
+	}
+	return &ObjectInfo{Key: key, Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+func (b s3Backend) List(prefix string) ([]ObjectInfo, error) {
+	objects := make([]ObjectInfo, 0)
+	for object := range b.client.ListObjects(context.Background(), b.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		objects = append(objects, ObjectInfo{Key: object.Key, Size: object.Size, ModTime: object.LastModified})
+	}
+	return objects, nil
+}
+
+func toStorageError(err error) error {
+	if response := minio.ToErrorResponse(err); response.Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
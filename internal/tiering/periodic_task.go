@@ -0,0 +1,114 @@
+package tiering
+
+import (
+	"data-storage-svc/internal/api/common"
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/repository"
+	"data-storage-svc/internal/storage"
+	"log/slog"
+	"path"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// An original accessed recently is never archived, so a restored original stays in the main storage for a while
+const RECENT_ACCESS_RETENTION = 7 * 24 * time.Hour
+
+// Periodic task moving originals between the main storage and the archive storage. It will run every delaySeconds,
+// restore originals that have been requested back, then archive originals matching the tiering policy.
+func TieringTask(delaySeconds int64, afterDays int64, idleDays int64, mediaRepository repository.MediaRepository, hot storage.Backend, archive storage.Backend) {
+	ticker := time.NewTicker(time.Duration(delaySeconds) * time.Second)
+	for range ticker.C {
+		slog.Debug("Executing tiering task")
+		// Restore first, users are waiting for these ones
+		restoring, err := mediaRepository.GetAllInTier(model.TIER_RESTORING)
+		if err != nil {
+			slog.Error("Couldn't fetch medias to restore", "error", err)
+			continue
+		}
+		nbrRestored := 0
+		// Medias sharing the same original are moved at once
+		moved := map[string]bool{}
+		for _, media := range restoring {
+			if moved[*media.StorageFileName] {
+				continue
+			}
+			moved[*media.StorageFileName] = true
+			if err := restoreOriginal(&media, mediaRepository, hot, archive); err != nil {
+				slog.Error("Couldn't restore original", "mediaId", media.Id.Hex(), "error", err)
+			} else {
+				nbrRestored += 1
+			}
+		}
+
+		hotMedias, err := mediaRepository.GetAllInTier(model.TIER_HOT)
+		if err != nil {
+			slog.Error("Couldn't fetch medias to archive", "error", err)
+			continue
+		}
+		nbrArchived := 0
+		now := time.Now()
+		for _, media := range hotMedias {
+			if moved[*media.StorageFileName] || !ShouldArchive(&media, now, afterDays, idleDays) {
+				continue
+			}
+			moved[*media.StorageFileName] = true
+			if err := archiveOriginal(&media, mediaRepository, hot, archive); err != nil {
+				slog.Error("Couldn't archive original", "mediaId", media.Id.Hex(), "error", err)
+			} else {
+				nbrArchived += 1
+			}
+		}
+		slog.Debug("Tiering results", "restored", nbrRestored, "archived", nbrArchived)
+	}
+}
+
+// Check if the original of a media should be moved to the archive storage. Only originals having a compressed
+// rendition are archived, so the media can still be browsed. Setting afterDays or idleDays to 0 disables the rule.
+func ShouldArchive(media *model.Media, now time.Time, afterDays int64, idleDays int64) bool {
+	if media.IsArchived() || media.CompressedFileName == nil || media.UploadTime == nil {
+		return false
+	}
+	lastAccess := *media.UploadTime
+	if media.LastAccessTime != nil && media.LastAccessTime.After(lastAccess) {
+		lastAccess = *media.LastAccessTime
+	}
+	if now.Sub(lastAccess) < RECENT_ACCESS_RETENTION {
+		return false
+	}
+	// Uploaded more than afterDays ago
+	if afterDays > 0 && now.Sub(*media.UploadTime) >= time.Duration(afterDays)*24*time.Hour {
+		return true
+	}
+	// Not accessed for idleDays
+	return idleDays > 0 && now.Sub(lastAccess) >= time.Duration(idleDays)*24*time.Hour
+}
+
+// Move an original to the archive storage. The medias point to the archive before the main copy is removed, so it
+// always points to an existing file.
+func archiveOriginal(media *model.Media, mediaRepository repository.MediaRepository, hot storage.Backend, archive storage.Backend) error {
+	key := path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName)
+	if err := storage.Copy(hot, archive, key); err != nil {
+		return err
+	}
+	if err := mediaRepository.UpdateAllByStorageFileName(*media.StorageFileName, bson.M{"originalTier": model.TIER_ARCHIVED}); err != nil {
+		archive.Delete(key)
+		return err
+	}
+	return hot.Delete(key)
+}
+
+// Move an original back to the main storage
+func restoreOriginal(media *model.Media, mediaRepository repository.MediaRepository, hot storage.Backend, archive storage.Backend) error {
+	key := path.Join(common.ORIGINAL_MEDIA_DIRECTORY, *media.StorageFileName)
+	if err := storage.Copy(archive, hot, key); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := mediaRepository.UpdateAllByStorageFileName(*media.StorageFileName, bson.M{"originalTier": model.TIER_HOT, "lastAccessTime": now}); err != nil {
+		hot.Delete(key)
+		return err
+	}
+	return archive.Delete(key)
+}
@@ -0,0 +1,43 @@
+package tiering_test
+
+import (
+	"data-storage-svc/internal/model"
+	"data-storage-svc/internal/tiering"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldArchive(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) *time.Time {
+		date := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &date
+	}
+	compressed := "compressed.jpg"
+
+	testCases := []struct {
+		name      string
+		media     model.Media
+		afterDays int64
+		idleDays  int64
+		expected  bool
+	}{
+		{"recent upload", model.Media{UploadTime: daysAgo(1), CompressedFileName: &compressed}, 30, 30, false},
+		{"old upload", model.Media{UploadTime: daysAgo(40), CompressedFileName: &compressed}, 30, 0, true},
+		{"old upload rule disabled", model.Media{UploadTime: daysAgo(40), CompressedFileName: &compressed}, 0, 0, false},
+		{"idle", model.Media{UploadTime: daysAgo(100), LastAccessTime: daysAgo(95), CompressedFileName: &compressed}, 0, 90, true},
+		{"accessed lately", model.Media{UploadTime: daysAgo(100), LastAccessTime: daysAgo(20), CompressedFileName: &compressed}, 0, 90, false},
+		{"old but just restored", model.Media{UploadTime: daysAgo(100), LastAccessTime: daysAgo(1), CompressedFileName: &compressed}, 30, 0, false},
+		{"no rendition yet", model.Media{UploadTime: daysAgo(100)}, 30, 30, false},
+		{"already archived", model.Media{UploadTime: daysAgo(100), CompressedFileName: &compressed, OriginalTier: model.TIER_ARCHIVED}, 30, 30, false},
+		{"being restored", model.Media{UploadTime: daysAgo(100), CompressedFileName: &compressed, OriginalTier: model.TIER_RESTORING}, 30, 30, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tiering.ShouldArchive(&tc.media, now, tc.afterDays, tc.idleDays))
+		})
+	}
+}
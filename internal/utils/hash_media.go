@@ -13,16 +13,14 @@ func HashMedia(filename string) (*string, error) {
 		return nil, err
 	}
 	defer file.Close()
+	return HashData(file)
+}
+
+// Compute the hash of the given data, in the same format as HashMedia
+func HashData(data io.Reader) (*string, error) {
 	hasher := sha256.New()
-	buffer := make([]byte, hasher.BlockSize())
-	var readBytes int
-	for err != io.EOF {
-		if readBytes, err = file.Read(buffer); err != nil && err != io.EOF {
-			return nil, err
-		}
-		if _, hashErr := hasher.Write(buffer[0:readBytes]); hashErr != nil {
-			return nil, hashErr
-		}
+	if _, err := io.Copy(hasher, data); err != nil {
+		return nil, err
 	}
 	hashResult := hex.EncodeToString(hasher.Sum(nil))
 	return &hashResult, nil
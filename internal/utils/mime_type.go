@@ -2,13 +2,30 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 )
 
 var ACCEPTED_FILE_EXTENSIONS = []string{"jpg", "jpeg", "png", "mp4"}
 
+// Detect the mime type of a file from its first bytes, the file is read from the start again afterwards
+func DetectMimeType(file io.ReadSeeker) (string, error) {
+	fileHeader := make([]byte, 512)
+	readBytes, err := io.ReadFull(file, fileHeader)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	mimeType, _, _ := CheckFileExtension(fileHeader[:readBytes])
+	return mimeType, nil
+}
+
 func CheckFileExtension(fileHeader []byte) (string, string, bool) {
 	mimeType := http.DetectContentType(fileHeader)
 	extension, _ := MimeTypeToFileExtension(mimeType)
@@ -47,3 +64,17 @@ func MimeTypeToFileExtension(mimeType string) (string, error) {
 		return "", fmt.Errorf("unknown mime type")
 	}
 }
+
+// Get the file extension to store a media with from its original file name. Only used for media types that cannot be
+// detected from the file content.
+func FileNameToFileExtension(fileName string) (string, error) {
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	switch extension {
+	case "jpg", "jpeg":
+		return "jpg", nil
+	case "png", "mp4", "heic", "gif":
+		return extension, nil
+	default:
+		return "", fmt.Errorf("unknown file extension")
+	}
+}
@@ -82,6 +82,20 @@ func GetUser(request *gin.Context) (*model.User, error) {
 	}
 }
 
+// Get the API key used to authenticate the request, nil if the user authenticated otherwise
+func GetApiKey(request *gin.Context) *model.ApiKey {
+	rawApiKey, _ := request.Get(common.API_KEY)
+	apiKey, _ := rawApiKey.(*model.ApiKey)
+	return apiKey
+}
+
+// Get the session of the access token used to authenticate the request, nil if the user authenticated otherwise
+func GetSession(request *gin.Context) *model.Session {
+	rawSession, _ := request.Get(common.SESSION)
+	session, _ := rawSession.(*model.Session)
+	return session
+}
+
 func GetUserOrSharedLink(request *gin.Context) (*model.User, *model.SharedLink, error) {
 	rawUser, userExists := request.Get(common.USER)
 	rawSharedLink, linkExists := request.Get(common.SHARED_LINK)